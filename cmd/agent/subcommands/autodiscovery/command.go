@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package autodiscovery implements 'agent autodiscovery'.
+package autodiscovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/listeners"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/providers"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/simulation"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/core/tagger"
+	taggernoop "github.com/DataDog/datadog-agent/comp/core/tagger/noopimpl"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/flare"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// cliParams are the command-line arguments for the simulate subcommand
+type cliParams struct {
+	*command.GlobalParams
+
+	templatesDir  string
+	workloadsFile string
+}
+
+// Commands returns a slice of subcommands for the 'agent' command.
+func Commands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &cliParams{
+		GlobalParams: globalParams,
+	}
+
+	autodiscoveryCmd := &cobra.Command{
+		Use:   "autodiscovery",
+		Short: "Autodiscovery tools",
+		Long:  ``,
+	}
+
+	simulateCmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Resolve autodiscovery templates against a workload snapshot, without a running agent",
+		Long: `Resolve the autodiscovery templates of a directory against the containers and pods of a snapshot
+taken with 'agent workload-list --snapshot', and print the configs that would be scheduled for each
+service along with the reasons why matching templates would not be. The agent configuration is used
+for container filtering. Tags and secrets are not resolved. The command fails if a template would not
+be scheduled for a matching service or can't be read, so that templates can be tested in CI.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if cliParams.templatesDir == "" || cliParams.workloadsFile == "" {
+				return errors.New("both --templates and --workloads are required")
+			}
+			return fxutil.OneShot(simulate,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath, config.WithExtraConfFiles(cliParams.ExtraConfFilePath), config.WithFleetPoliciesDirPath(cliParams.FleetPoliciesDirPath)),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle(),
+				taggernoop.Module(),
+			)
+		},
+	}
+	simulateCmd.Flags().StringVar(&cliParams.templatesDir, "templates", "", "directory of the templates, laid out like conf.d")
+	simulateCmd.Flags().StringVar(&cliParams.workloadsFile, "workloads", "", "JSON snapshot of the workloads, as printed by 'agent workload-list --snapshot'")
+	autodiscoveryCmd.AddCommand(simulateCmd)
+
+	return []*cobra.Command{autodiscoveryCmd}
+}
+
+func simulate(_ log.Component, _ config.Component, taggerComp tagger.Component, cliParams *cliParams) error {
+	// the services get their tags from the global tagger, which has none here
+	tagger.SetGlobalTaggerClient(taggerComp)
+
+	snapshot, err := readSnapshot(cliParams.workloadsFile)
+	if err != nil {
+		return err
+	}
+	services, err := listeners.ServicesFromSnapshot(snapshot)
+	if err != nil {
+		return fmt.Errorf("unable to create the services: %w", err)
+	}
+
+	providers.InitConfigFilesReader([]string{cliParams.templatesDir})
+	provider := providers.NewFileConfigProvider(nil)
+	configs, err := provider.Collect(context.Background())
+	if err != nil {
+		return fmt.Errorf("unable to read the templates: %w", err)
+	}
+	for i := range configs {
+		configs[i].Provider = names.File
+	}
+	// the templates with advanced AD identifiers are served by the cluster
+	// agent providers of kube services and endpoints
+	advancedTemplates, _, err := providers.ReadConfigFiles(providers.WithAdvancedADOnly)
+	if err != nil {
+		return fmt.Errorf("unable to read the templates: %w", err)
+	}
+	configs = append(configs, advancedTemplates...)
+
+	result := simulation.Run(context.Background(), configs, services)
+	printResult(color.Output, result, provider.Errors)
+	return resultError(result, provider.Errors)
+}
+
+// resultError returns an error if a template would not be scheduled for a
+// service matching its AD identifiers, if a template can't be read, or if the
+// AD identifiers of a service can't be determined
+func resultError(result simulation.Result, templateErrors map[string]string) error {
+	rejected, servicesWithErrors := 0, 0
+	for _, svc := range result.Services {
+		rejected += len(svc.Rejected)
+		if svc.ADIdentifiersError != nil {
+			servicesWithErrors++
+		}
+	}
+	if rejected == 0 && len(templateErrors) == 0 && servicesWithErrors == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d template(s) not scheduled for a matching service, %d template(s) with errors, %d service(s) without AD identifiers", rejected, len(templateErrors), servicesWithErrors)
+}
+
+func readSnapshot(path string) (workloadmeta.WorkloadSnapshot, error) {
+	var snapshot workloadmeta.WorkloadSnapshot
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, fmt.Errorf("unable to read the workload snapshot: %w", err)
+	}
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("unable to parse the workload snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+func printResult(w io.Writer, result simulation.Result, templateErrors map[string]string) {
+	for _, svc := range result.Services {
+		fmt.Fprintf(w, "\n=== Service %s ===\n", color.GreenString(svc.ServiceID))
+		if svc.ADIdentifiersError != nil {
+			fmt.Fprintf(w, "%s: %v, the service is not monitored\n", color.RedString("Unable to get the auto-discovery IDs"), svc.ADIdentifiersError)
+			fmt.Fprintln(w, "===")
+			continue
+		}
+		fmt.Fprintf(w, "%s: %s\n", color.BlueString("Auto-discovery IDs"), strings.Join(svc.ADIdentifiers, ", "))
+		if len(svc.Scheduled) == 0 && len(svc.Rejected) == 0 {
+			fmt.Fprintln(w, "No template matches the AD identifiers of the service")
+		}
+		for _, config := range svc.Scheduled {
+			flare.PrintConfig(w, config, "")
+		}
+		for _, rejection := range svc.Rejected {
+			fmt.Fprintf(w, "\n%s %s (%s): %s\n", color.YellowString("Not scheduled:"), rejection.Template.Name, rejection.Template.Source, rejection.Reason)
+		}
+		fmt.Fprintln(w, "===")
+	}
+
+	if len(result.UnmatchedTemplates) > 0 {
+		fmt.Fprintf(w, "\n=== %s ===\n", color.YellowString("Templates matching no service"))
+		for _, tpl := range result.UnmatchedTemplates {
+			fmt.Fprintf(w, "%s (%s): no service with the AD identifiers %s\n", tpl.Name, tpl.Source, strings.Join(templateIdentifiers(tpl), ", "))
+		}
+	}
+
+	if len(result.Configs) > 0 {
+		fmt.Fprintf(w, "\n=== %s ===\n", color.GreenString("Configs scheduled regardless of the services"))
+		for _, config := range result.Configs {
+			fmt.Fprintf(w, "%s (%s)\n", config.Name, config.Source)
+		}
+	}
+
+	if len(templateErrors) > 0 {
+		fmt.Fprintf(w, "\n=== %s ===\n", color.RedString("Template errors"))
+		checks := make([]string, 0, len(templateErrors))
+		for check := range templateErrors {
+			checks = append(checks, check)
+		}
+		sort.Strings(checks)
+		for _, check := range checks {
+			fmt.Fprintf(w, "%s: %s\n", check, templateErrors[check])
+		}
+	}
+}
+
+// templateIdentifiers returns the AD identifiers of a template, along with
+// the kube endpoints targeted by its advanced AD identifiers
+func templateIdentifiers(tpl integration.Config) []string {
+	identifiers := append([]string(nil), tpl.ADIdentifiers...)
+	for _, advancedID := range tpl.AdvancedADIdentifiers {
+		if !advancedID.KubeEndpoints.IsEmpty() {
+			identifiers = append(identifiers, fmt.Sprintf("kube_endpoints %s/%s", advancedID.KubeEndpoints.Namespace, advancedID.KubeEndpoints.Name))
+		}
+	}
+	return identifiers
+}
@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/providers"
+	"github.com/DataDog/datadog-agent/comp/core/tagger"
+	taggernoop "github.com/DataDog/datadog-agent/comp/core/tagger/noopimpl"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestSimulateCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"autodiscovery", "simulate", "--templates", "conf.d", "--workloads", "workloads.json"},
+		simulate,
+		func(cliParams *cliParams) {
+			require.Equal(t, "conf.d", cliParams.templatesDir)
+			require.Equal(t, "workloads.json", cliParams.workloadsFile)
+		})
+}
+
+func TestReadSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workloads.json")
+	content := `{
+  "containers": [
+    {
+      "Kind": "container",
+      "ID": "abc",
+      "Name": "redis",
+      "Image": {"RawName": "redis:7.2", "ShortName": "redis", "Tag": "7.2"},
+      "Ports": [{"Name": "redis", "Port": 6379}],
+      "State": {"Running": true},
+      "Runtime": "containerd"
+    }
+  ],
+  "kubernetes_pods": null
+}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	snapshot, err := readSnapshot(path)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Containers, 1)
+	container := snapshot.Containers[0]
+	assert.Equal(t, "abc", container.ID)
+	assert.Equal(t, "redis", container.Name)
+	assert.Equal(t, "7.2", container.Image.Tag)
+	assert.Equal(t, 6379, container.Ports[0].Port)
+	assert.True(t, container.State.Running)
+	assert.Empty(t, snapshot.KubernetesPods)
+
+	_, err = readSnapshot(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestSimulateExitStatus(t *testing.T) {
+	workloadsFile := filepath.Join(t.TempDir(), "workloads.json")
+	content := `{
+  "containers": [
+    {
+      "Kind": "container",
+      "ID": "abc",
+      "Name": "redis",
+      "Image": {"RawName": "redis:7.2", "ShortName": "redis", "Tag": "7.2"},
+      "NetworkIPs": {"bridge": "172.17.0.2"},
+      "State": {"Running": true},
+      "Runtime": "docker"
+    }
+  ],
+  "kubernetes_pods": null
+}`
+	require.NoError(t, os.WriteFile(workloadsFile, []byte(content), 0o644))
+	taggerComp := fxutil.Test[tagger.Component](t, taggernoop.Module())
+
+	for name, tc := range map[string]struct {
+		template      string
+		expectedError string
+	}{
+		"scheduled": {
+			template: "ad_identifiers: [redis]\ninit_config:\ninstances:\n  - host: \"%%host%%\"\n",
+		},
+		"rejected": {
+			template:      "ad_identifiers: [redis]\ninit_config:\ninstances:\n  - password: \"%%env_REDIS_SIMULATE_PASSWORD%%\"\n",
+			expectedError: "1 template(s) not scheduled for a matching service, 0 template(s) with errors, 0 service(s) without AD identifiers",
+		},
+		"advanced AD identifiers": {
+			template: "advanced_ad_identifiers:\n  - kube_service:\n      name: redis\n      namespace: default\ninit_config:\ninstances:\n  - host: \"%%host%%\"\n",
+		},
+		"invalid": {
+			template:      "ad_identifiers: [redis\n",
+			expectedError: "0 template(s) not scheduled for a matching service, 1 template(s) with errors, 0 service(s) without AD identifiers",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			templatesDir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(templatesDir, "redisdb.d"), 0o755))
+			require.NoError(t, os.WriteFile(filepath.Join(templatesDir, "redisdb.d", "auto_conf.yaml"), []byte(tc.template), 0o644))
+			// the templates are read by a global reader, initialized once
+			providers.ResetReader([]string{templatesDir})
+
+			err := simulate(nil, nil, taggerComp, &cliParams{templatesDir: templatesDir, workloadsFile: workloadsFile})
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
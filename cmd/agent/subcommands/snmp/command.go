@@ -36,6 +36,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/forwarder/eventplatformreceiver/eventplatformreceiverimpl"
 	"github.com/DataDog/datadog-agent/comp/forwarder/orchestrator/orchestratorimpl"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/snmplog"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/profilevalidation"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
 	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
@@ -232,6 +233,76 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	// This command does nothing until the backend supports it, so it isn't enabled yet.
 	// snmpCmd.AddCommand(snmpScanCmd)
 
+	topologyConnParams := &connectionParams{}
+	topologyCmdParams := &topologyParams{}
+	snmpTopologyCmd := &cobra.Command{
+		Use:   "topology <subnet>",
+		Short: "Print the LLDP/CDP topology of a subnet.",
+		Long: `Query the LLDP and CDP neighbors of every device of a subnet, printing the discovered topology graph as DOT or JSON.
+		The subnet is an IP address or a CIDR of at most 1024 hosts.
+		Flags that aren't specified will be pulled from the agent SNMP config of each device if possible.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := fxutil.OneShot(snmpTopology,
+				fx.Supply(topologyConnParams, topologyCmdParams, globalParams, cmd),
+				fx.Provide(func() argsType { return args }),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath, config.WithExtraConfFiles(globalParams.ExtraConfFilePath), config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+					SecretParams: secrets.NewEnabledParams(),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle(),
+			)
+			if err != nil {
+				var ue configErr
+				if errors.As(err, &ue) {
+					fmt.Println("Usage:", cmd.UseLine())
+				}
+				return err
+			}
+			return nil
+		},
+	}
+	snmpTopologyCmd.Flags().StringVarP(&topologyCmdParams.format, "format", "f", "dot", "Set the output format (dot, json)")
+	snmpTopologyCmd.Flags().VarP(versionOpts.Flag(&topologyConnParams.Version), "snmp-version", "v", fmt.Sprintf("Specify SNMP version to use (%s)", versionOpts.OptsStr()))
+
+	// snmp v1 or v2c specific
+	snmpTopologyCmd.Flags().StringVarP(&topologyConnParams.CommunityString, "community-string", "C", "", "Set the community string")
+
+	// snmp v3 specific
+	snmpTopologyCmd.Flags().VarP(authOpts.Flag(&topologyConnParams.AuthProtocol), "auth-protocol", "a", fmt.Sprintf("Set authentication protocol (%s)", authOpts.OptsStr()))
+	snmpTopologyCmd.Flags().StringVarP(&topologyConnParams.AuthKey, "auth-key", "A", "", "Set authentication protocol pass phrase")
+	snmpTopologyCmd.Flags().VarP(levelOpts.Flag(&topologyConnParams.SecurityLevel), "security-level", "l", fmt.Sprintf("Set security level (%s)", levelOpts.OptsStr()))
+	snmpTopologyCmd.Flags().StringVarP(&topologyConnParams.Context, "context", "N", "", "Set context name")
+	snmpTopologyCmd.Flags().StringVarP(&topologyConnParams.Username, "user-name", "u", "", "Set security name")
+	snmpTopologyCmd.Flags().VarP(privOpts.Flag(&topologyConnParams.PrivProtocol), "priv-protocol", "x", fmt.Sprintf("Set privacy protocol (%s)", privOpts.OptsStr()))
+	snmpTopologyCmd.Flags().StringVarP(&topologyConnParams.PrivKey, "priv-key", "X", "", "Set privacy protocol pass phrase")
+
+	// general communication options, with shorter defaults since most hosts of a subnet usually don't respond
+	snmpTopologyCmd.Flags().Uint16Var(&topologyConnParams.Port, "port", 0, "Set the SNMP port (defaults to 161)")
+	snmpTopologyCmd.Flags().IntVarP(&topologyConnParams.Retries, "retries", "r", defaultTopologyRetries, "Set the number of retries")
+	snmpTopologyCmd.Flags().IntVarP(&topologyConnParams.Timeout, "timeout", "t", defaultTopologyTimeout, "Set the request timeout (in seconds)")
+	snmpTopologyCmd.Flags().BoolVar(&topologyConnParams.UseUnconnectedUDPSocket, "use-unconnected-udp-socket", defaultUseUnconnectedUDPSocket, "If specified, changes net connection to be unconnected UDP socket")
+
+	snmpCmd.AddCommand(snmpTopologyCmd)
+
+	snmpValidateProfileCmd := &cobra.Command{
+		Use:   "validate-profile [profile file]...",
+		Short: "Validate SNMP profiles.",
+		Long: `Validate SNMP profiles, reporting schema errors, unknown MIB symbols and sysobjectid conflicts with other user profiles.
+		If no profile file is specified, the profiles of the user profiles folder (conf.d/snmp.d/profiles) are validated.`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return fxutil.OneShot(validateProfiles,
+				fx.Supply(globalParams),
+				fx.Provide(func() argsType { return args }),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath, config.WithExtraConfFiles(globalParams.ExtraConfFilePath), config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+					SecretParams: secrets.NewEnabledParams(),
+					LogParams:    log.ForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle(),
+			)
+		},
+	}
+	snmpCmd.AddCommand(snmpValidateProfileCmd)
+
 	return []*cobra.Command{snmpCmd}
 }
 
@@ -543,3 +614,41 @@ func printValue(pdu gosnmp.SnmpPDU) error {
 
 	return nil
 }
+
+// validateProfiles prints the errors and warnings of the profiles, it fails if a profile is invalid
+func validateProfiles(args argsType, _ config.Component) error {
+	var validations []profilevalidation.ProfileValidation
+	if len(args) == 0 {
+		var err error
+		validations, err = profilevalidation.ValidateUserProfiles()
+		if err != nil {
+			return err
+		}
+	} else {
+		validations = profilevalidation.ValidateProfileFiles(args)
+	}
+	if len(validations) == 0 {
+		fmt.Println("No profile to validate")
+		return nil
+	}
+
+	invalid := 0
+	for _, validation := range validations {
+		status := "OK"
+		if !validation.IsValid() {
+			status = "INVALID"
+			invalid++
+		}
+		fmt.Printf("%s (%s): %s\n", validation.Name, validation.File, status)
+		for _, validationErr := range validation.Errors {
+			fmt.Printf("  error: %s\n", validationErr)
+		}
+		for _, warning := range validation.Warnings {
+			fmt.Printf("  warning: %s\n", warning)
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d profiles are invalid", invalid, len(validations))
+	}
+	return nil
+}
@@ -36,6 +36,25 @@ func TestCommand(t *testing.T) {
 			require.Equal(t, argsType{"1.2.3.4", "10.9.8.7"}, args)
 			require.True(t, cliParams.UseUnconnectedUDPSocket)
 		})
+
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "topology", "10.0.0.0/24", "--format", "json", "-C", "private"},
+		snmpTopology,
+		func(cliParams *connectionParams, params *topologyParams, args argsType) {
+			require.Equal(t, argsType{"10.0.0.0/24"}, args)
+			require.Equal(t, "json", params.format)
+			require.Equal(t, "private", cliParams.CommunityString)
+			require.Equal(t, defaultTopologyTimeout, cliParams.Timeout)
+		})
+
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "validate-profile", "a.yaml", "b.yaml"},
+		validateProfiles,
+		func(args argsType) {
+			require.Equal(t, argsType{"a.yaml", "b.yaml"}, args)
+		})
 }
 
 func TestSplitIP(t *testing.T) {
@@ -0,0 +1,358 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package snmp
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/topology"
+	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
+)
+
+const (
+	defaultTopologyTimeout = 2
+	defaultTopologyRetries = 1
+	topologyWorkers        = 16
+	maxTopologyHosts       = 1024
+
+	sysNameOID = "1.3.6.1.2.1.1.5.0"
+	// IF-MIB ifXEntry, only the ifName column is walked
+	ifXEntryOID = "1.3.6.1.2.1.31.1.1.1"
+	ifNameOID   = ifXEntryOID + ".1"
+	// LLDP-MIB lldpLocPortTable, lldpRemTable and lldpRemManAddrTable
+	lldpLocPortOID    = "1.0.8802.1.1.2.1.3.7.1"
+	lldpRemOID        = "1.0.8802.1.1.2.1.4.1.1"
+	lldpRemManAddrOID = "1.0.8802.1.1.2.1.4.2.1"
+	// CISCO-CDP-MIB cdpCacheTable
+	cdpCacheOID = "1.3.6.1.4.1.9.9.23.1.2.1.1"
+)
+
+// topologyParams are the options of the topology command
+type topologyParams struct {
+	format string
+}
+
+// deviceTopology is a device discovered by the topology command
+type deviceTopology struct {
+	id        string
+	name      string
+	ipAddress string
+	links     []metadata.TopologyLinkMetadata
+}
+
+// snmpTopology queries the LLDP and CDP neighbors of the devices of a subnet and prints the topology graph
+func snmpTopology(connParams *connectionParams, params *topologyParams, args argsType, conf config.Component, logger log.Component) error {
+	if len(args) == 0 {
+		return confErrf("missing argument: subnet")
+	}
+	if len(args) > 1 {
+		return confErrf("unexpected extra arguments; only one argument expected.")
+	}
+	if params.format != "dot" && params.format != "json" {
+		return confErrf("unknown format %q; must be dot or json", params.format)
+	}
+	ipAddresses, err := subnetHosts(args[0])
+	if err != nil {
+		return configErr{err}
+	}
+	namespace := conf.GetString("network_devices.namespace")
+	devices := make([]*deviceTopology, len(ipAddresses))
+	errs := make([]error, len(ipAddresses))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < topologyWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				devices[index], errs[index] = queryDeviceTopology(*connParams, ipAddresses[index], namespace, conf, logger)
+			}
+		}()
+	}
+	for index := range ipAddresses {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	graph := topology.NewGraph()
+	discovered := 0
+	for _, device := range devices {
+		if device != nil {
+			graph.AddDevice(device.id, device.name, device.ipAddress)
+			discovered++
+		}
+	}
+	for _, device := range devices {
+		if device != nil {
+			graph.AddLinks(device.links)
+		}
+	}
+	if discovered == 0 {
+		// invalid connection parameters are reported if no host could be queried
+		for _, err := range errs {
+			if err != nil {
+				return configErr{err}
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d of %d hosts responded to SNMP\n", discovered, len(ipAddresses))
+
+	if params.format == "json" {
+		payload, err := graph.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(payload))
+		return nil
+	}
+	fmt.Print(graph.DOT())
+	return nil
+}
+
+// queryDeviceTopology returns the neighbors of a device, it returns nil if the device can't be queried
+// and an error if the connection parameters are invalid
+func queryDeviceTopology(connParams connectionParams, ipAddress string, namespace string, conf config.Component, logger log.Component) (*deviceTopology, error) {
+	connParams.IPAddress = ipAddress
+	if err := setDefaultsFromAgent(&connParams, conf); err != nil {
+		logger.Debugf("%s: %s", ipAddress, err)
+	}
+	snmp, err := newSNMP(&connParams, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := snmp.Connect(); err != nil {
+		logger.Debugf("unable to connect to SNMP agent on %s: %s", ipAddress, err)
+		return nil, nil
+	}
+	defer snmp.Conn.Close()
+
+	result, err := snmp.Get([]string{sysNameOID})
+	if err != nil || len(result.Variables) == 0 {
+		logger.Debugf("unable to get sysName of %s: %v", ipAddress, err)
+		return nil, nil
+	}
+	device := &deviceTopology{
+		id:        namespace + ":" + ipAddress,
+		name:      pduString(result.Variables[0]),
+		ipAddress: ipAddress,
+	}
+
+	var pdus []gosnmp.SnmpPDU
+	for _, rootOID := range []string{ifNameOID, lldpLocPortOID, lldpRemOID, lldpRemManAddrOID, cdpCacheOID} {
+		var tablePDUs []gosnmp.SnmpPDU
+		if snmp.Version == gosnmp.Version1 {
+			tablePDUs, err = snmp.WalkAll(rootOID)
+		} else {
+			tablePDUs, err = snmp.BulkWalkAll(rootOID)
+		}
+		if err != nil {
+			logger.Debugf("unable to walk %s on %s: %s", rootOID, ipAddress, err)
+			continue
+		}
+		pdus = append(pdus, tablePDUs...)
+	}
+	device.links = buildTopologyLinks(device.id, pdus)
+	return device, nil
+}
+
+// buildTopologyLinks builds the LLDP and CDP links of a device from the PDUs of the neighbor tables,
+// CDP neighbors are skipped for the interfaces having an LLDP neighbor
+func buildTopologyLinks(deviceID string, pdus []gosnmp.SnmpPDU) []metadata.TopologyLinkMetadata {
+	tables := make(map[string]map[string]gosnmp.SnmpPDU)
+	for _, pdu := range pdus {
+		for _, rootOID := range []string{ifXEntryOID, lldpLocPortOID, lldpRemOID, lldpRemManAddrOID, cdpCacheOID} {
+			// column and index of the PDU in the table
+			suffix, ok := strings.CutPrefix(strings.TrimPrefix(pdu.Name, "."), rootOID+".")
+			if !ok {
+				continue
+			}
+			column, index, ok := strings.Cut(suffix, ".")
+			if !ok {
+				continue
+			}
+			key := rootOID + "." + column
+			if tables[key] == nil {
+				tables[key] = make(map[string]gosnmp.SnmpPDU)
+			}
+			tables[key][index] = pdu
+		}
+	}
+	column := func(rootOID string, column int, index string) gosnmp.SnmpPDU {
+		return tables[rootOID+"."+strconv.Itoa(column)][index]
+	}
+
+	remManAddrs := make(map[string]string)
+	for index := range tables[lldpRemManAddrOID+".3"] {
+		// index: lldpRemTimeMark.lldpRemLocalPortNum.lldpRemIndex.lldpRemManAddrSubtype.length.address
+		elems := strings.Split(index, ".")
+		if len(elems) == 9 && elems[3] == "1" && elems[4] == "4" {
+			remManAddrs[strings.Join(elems[:3], ".")] = strings.Join(elems[5:], ".")
+		}
+	}
+
+	var links []metadata.TopologyLinkMetadata
+	lldpInterfaces := make(map[string]struct{})
+	for _, index := range sortedIndexes(tables[lldpRemOID+".7"]) {
+		// index: lldpRemTimeMark.lldpRemLocalPortNum.lldpRemIndex
+		elems := strings.Split(index, ".")
+		if len(elems) != 3 {
+			continue
+		}
+		localPortNum := elems[1]
+		localInterface := formatLLDPID(column(lldpLocPortOID, 3, localPortNum), pduString(column(lldpLocPortOID, 2, localPortNum)), "3")
+		if localInterface == "" {
+			localInterface = localPortNum
+		}
+		lldpInterfaces[localInterface] = struct{}{}
+		links = append(links, metadata.TopologyLinkMetadata{
+			ID:         deviceID + ":" + localPortNum + "." + elems[2],
+			SourceType: "lldp",
+			Local: &metadata.TopologyLinkSide{
+				Device:    &metadata.TopologyLinkDevice{DDID: deviceID},
+				Interface: &metadata.TopologyLinkInterface{ID: localInterface},
+			},
+			Remote: &metadata.TopologyLinkSide{
+				Device: &metadata.TopologyLinkDevice{
+					ID:        formatLLDPID(column(lldpRemOID, 5, index), pduString(column(lldpRemOID, 4, index)), "4"),
+					Name:      pduString(column(lldpRemOID, 9, index)),
+					IPAddress: remManAddrs[index],
+				},
+				Interface: &metadata.TopologyLinkInterface{
+					ID:          formatLLDPID(column(lldpRemOID, 7, index), pduString(column(lldpRemOID, 6, index)), "3"),
+					Description: pduString(column(lldpRemOID, 8, index)),
+				},
+			},
+		})
+	}
+
+	for _, index := range sortedIndexes(tables[cdpCacheOID+".7"]) {
+		// index: cdpCacheIfIndex.cdpCacheDeviceIndex
+		ifIndex, _, ok := strings.Cut(index, ".")
+		if !ok {
+			continue
+		}
+		localInterface := pduString(column(ifXEntryOID, 1, ifIndex))
+		if localInterface == "" {
+			localInterface = ifIndex
+		}
+		if _, ok := lldpInterfaces[localInterface]; ok {
+			continue
+		}
+		var ipAddress string
+		if address := column(cdpCacheOID, 20, index); pduString(column(cdpCacheOID, 19, index)) == "1" {
+			if value, ok := address.Value.([]byte); ok && len(value) == net.IPv4len {
+				ipAddress = net.IP(value).String()
+			}
+		}
+		links = append(links, metadata.TopologyLinkMetadata{
+			ID:         deviceID + ":" + index,
+			SourceType: "cdp",
+			Local: &metadata.TopologyLinkSide{
+				Device:    &metadata.TopologyLinkDevice{DDID: deviceID},
+				Interface: &metadata.TopologyLinkInterface{ID: localInterface},
+			},
+			Remote: &metadata.TopologyLinkSide{
+				Device: &metadata.TopologyLinkDevice{
+					ID:        pduString(column(cdpCacheOID, 6, index)),
+					Name:      pduString(column(cdpCacheOID, 17, index)),
+					IPAddress: ipAddress,
+				},
+				Interface: &metadata.TopologyLinkInterface{
+					ID: pduString(column(cdpCacheOID, 7, index)),
+				},
+			},
+		})
+	}
+	return links
+}
+
+// subnetHosts returns the host addresses of a subnet, a single IP address is also accepted
+func subnetHosts(subnet string) ([]string, error) {
+	if ip := net.ParseIP(subnet); ip != nil {
+		return []string{ip.String()}, nil
+	}
+	ip, ipNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet %q: %w", subnet, err)
+	}
+	ones, bits := ipNet.Mask.Size()
+	if bits-ones > 30 || 1<<(bits-ones) > maxTopologyHosts+2 {
+		return nil, fmt.Errorf("subnet %s is too large, at most %d hosts can be queried", subnet, maxTopologyHosts)
+	}
+	var hosts []string
+	for current := ip.Mask(ipNet.Mask); ipNet.Contains(current); current = nextIP(current) {
+		hosts = append(hosts, current.String())
+	}
+	// skip the network and broadcast addresses of IPv4 subnets
+	if ip.To4() != nil && len(hosts) > 2 {
+		hosts = hosts[1 : len(hosts)-1]
+	}
+	return hosts, nil
+}
+
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+func sortedIndexes(column map[string]gosnmp.SnmpPDU) []string {
+	indexes := make([]string, 0, len(column))
+	for index := range column {
+		indexes = append(indexes, index)
+	}
+	sort.Strings(indexes)
+	return indexes
+}
+
+// formatLLDPID formats an LLDP chassis or port ID, macAddressSubtype is the subtype of MAC addresses IDs
+func formatLLDPID(pdu gosnmp.SnmpPDU, subtype string, macAddressSubtype string) string {
+	value, ok := pdu.Value.([]byte)
+	if ok && subtype == macAddressSubtype && len(value) == 6 {
+		parts := make([]string, 0, len(value))
+		for _, b := range value {
+			parts = append(parts, hex.EncodeToString([]byte{b}))
+		}
+		return strings.Join(parts, ":")
+	}
+	return pduString(pdu)
+}
+
+// pduString returns the value of a PDU as a string, non printable octet strings are hex encoded
+func pduString(pdu gosnmp.SnmpPDU) string {
+	switch value := pdu.Value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		if !gosnmplib.IsStringPrintable(value) {
+			return hex.EncodeToString(value)
+		}
+		return string(value)
+	case string:
+		return value
+	default:
+		return gosnmp.ToBigInt(value).String()
+	}
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package snmp
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+func TestBuildTopologyLinks(t *testing.T) {
+	pdus := []gosnmp.SnmpPDU{
+		// ifName
+		{Name: ".1.3.6.1.2.1.31.1.1.1.1.1", Type: gosnmp.OctetString, Value: []byte("Gi0/1")},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.1.2", Type: gosnmp.OctetString, Value: []byte("Gi0/2")},
+		// LLDP local port 1 is Gi0/1
+		{Name: ".1.0.8802.1.1.2.1.3.7.1.2.1", Type: gosnmp.Integer, Value: 5},
+		{Name: ".1.0.8802.1.1.2.1.3.7.1.3.1", Type: gosnmp.OctetString, Value: []byte("Gi0/1")},
+		// LLDP neighbor of port 1
+		{Name: ".1.0.8802.1.1.2.1.4.1.1.4.0.1.3", Type: gosnmp.Integer, Value: 4},
+		{Name: ".1.0.8802.1.1.2.1.4.1.1.5.0.1.3", Type: gosnmp.OctetString, Value: []byte{0x00, 0x1c, 0x73, 0x01, 0x02, 0x03}},
+		{Name: ".1.0.8802.1.1.2.1.4.1.1.6.0.1.3", Type: gosnmp.Integer, Value: 5},
+		{Name: ".1.0.8802.1.1.2.1.4.1.1.7.0.1.3", Type: gosnmp.OctetString, Value: []byte("Ethernet48")},
+		{Name: ".1.0.8802.1.1.2.1.4.1.1.8.0.1.3", Type: gosnmp.OctetString, Value: []byte("uplink")},
+		{Name: ".1.0.8802.1.1.2.1.4.1.1.9.0.1.3", Type: gosnmp.OctetString, Value: []byte("core-1")},
+		{Name: ".1.0.8802.1.1.2.1.4.2.1.3.0.1.3.1.4.10.0.0.1", Type: gosnmp.Integer, Value: 2},
+		// CDP neighbors of Gi0/1, already reported by LLDP, and Gi0/2
+		{Name: ".1.3.6.1.4.1.9.9.23.1.2.1.1.6.1.1", Type: gosnmp.OctetString, Value: []byte("core-1.example.com")},
+		{Name: ".1.3.6.1.4.1.9.9.23.1.2.1.1.7.1.1", Type: gosnmp.OctetString, Value: []byte("Ethernet48")},
+		{Name: ".1.3.6.1.4.1.9.9.23.1.2.1.1.6.2.5", Type: gosnmp.OctetString, Value: []byte("SEP001122334455")},
+		{Name: ".1.3.6.1.4.1.9.9.23.1.2.1.1.7.2.5", Type: gosnmp.OctetString, Value: []byte("Port 1")},
+		{Name: ".1.3.6.1.4.1.9.9.23.1.2.1.1.17.2.5", Type: gosnmp.OctetString, Value: []byte("phone-1")},
+		{Name: ".1.3.6.1.4.1.9.9.23.1.2.1.1.19.2.5", Type: gosnmp.Integer, Value: 1},
+		{Name: ".1.3.6.1.4.1.9.9.23.1.2.1.1.20.2.5", Type: gosnmp.OctetString, Value: []byte{10, 0, 0, 50}},
+	}
+
+	links := buildTopologyLinks("default:10.0.0.2", pdus)
+
+	assert.Equal(t, []metadata.TopologyLinkMetadata{
+		{
+			ID:         "default:10.0.0.2:1.3",
+			SourceType: "lldp",
+			Local: &metadata.TopologyLinkSide{
+				Device:    &metadata.TopologyLinkDevice{DDID: "default:10.0.0.2"},
+				Interface: &metadata.TopologyLinkInterface{ID: "Gi0/1"},
+			},
+			Remote: &metadata.TopologyLinkSide{
+				Device:    &metadata.TopologyLinkDevice{ID: "00:1c:73:01:02:03", Name: "core-1", IPAddress: "10.0.0.1"},
+				Interface: &metadata.TopologyLinkInterface{ID: "Ethernet48", Description: "uplink"},
+			},
+		},
+		{
+			ID:         "default:10.0.0.2:2.5",
+			SourceType: "cdp",
+			Local: &metadata.TopologyLinkSide{
+				Device:    &metadata.TopologyLinkDevice{DDID: "default:10.0.0.2"},
+				Interface: &metadata.TopologyLinkInterface{ID: "Gi0/2"},
+			},
+			Remote: &metadata.TopologyLinkSide{
+				Device:    &metadata.TopologyLinkDevice{ID: "SEP001122334455", Name: "phone-1", IPAddress: "10.0.0.50"},
+				Interface: &metadata.TopologyLinkInterface{ID: "Port 1"},
+			},
+		},
+	}, links)
+}
+
+func TestSubnetHosts(t *testing.T) {
+	hosts, err := subnetHosts("10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1"}, hosts)
+
+	hosts, err = subnetHosts("10.0.0.0/30")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, hosts)
+
+	hosts, err = subnetHosts("10.0.0.4/31")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.4", "10.0.0.5"}, hosts)
+
+	hosts, err = subnetHosts("10.0.0.0/22")
+	require.NoError(t, err)
+	assert.Len(t, hosts, 1022)
+
+	_, err = subnetHosts("10.0.0.0/21")
+	assert.EqualError(t, err, "subnet 10.0.0.0/21 is too large, at most 1024 hosts can be queried")
+
+	_, err = subnetHosts("10.0.0.0/33")
+	assert.ErrorContains(t, err, "invalid subnet")
+}
@@ -8,6 +8,7 @@ package subcommands
 
 import (
 	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	cmdautodiscovery "github.com/DataDog/datadog-agent/cmd/agent/subcommands/autodiscovery"
 	cmdcheck "github.com/DataDog/datadog-agent/cmd/agent/subcommands/check"
 	cmdconfig "github.com/DataDog/datadog-agent/cmd/agent/subcommands/config"
 	cmdconfigcheck "github.com/DataDog/datadog-agent/cmd/agent/subcommands/configcheck"
@@ -43,6 +44,7 @@ import (
 // with the current build flags.
 func AgentSubcommands() []command.SubcommandFactory {
 	return []command.SubcommandFactory{
+		cmdautodiscovery.Commands,
 		cmdcheck.Commands,
 		cmdconfigcheck.Commands,
 		cmdconfig.Commands,
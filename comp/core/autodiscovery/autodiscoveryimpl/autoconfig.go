@@ -86,6 +86,12 @@ type AutoConfig struct {
 	logs                     logComp.Component
 	telemetryStore           *acTelemetry.Store
 
+	// changedSecrets holds the handles of the secrets whose value changed,
+	// secretsChanged signals that it is not empty
+	changedSecrets     map[string]struct{}
+	changedSecretsLock sync.Mutex
+	secretsChanged     chan struct{}
+
 	// m covers the `configPollers`, `listenerCandidates`, `listeners`, and `listenerRetryStop`, but
 	// not the values they point to.
 	m sync.RWMutex
@@ -173,10 +179,47 @@ func createNewAutoConfig(schedulerController *scheduler.Controller, secretResolv
 		taggerComp:               taggerComp,
 		logs:                     logs,
 		telemetryStore:           acTelemetry.NewStore(telemetryComp),
+		changedSecrets:           make(map[string]struct{}),
+		secretsChanged:           make(chan struct{}, 1),
+	}
+	if secretResolver != nil {
+		secretResolver.SubscribeToChanges(ac.onSecretChange)
 	}
 	return ac
 }
 
+// onSecretChange records the secrets whose value changed, the configs using them
+// are decrypted again by serviceListening. The callback is called with the lock
+// of the secret resolver held, so it must not resolve secrets itself.
+func (ac *AutoConfig) onSecretChange(handle, _ string, _ []string, oldValue, newValue any) {
+	// secrets resolved for the first time have no previous value
+	if oldValue == newValue || oldValue == "" {
+		return
+	}
+	ac.changedSecretsLock.Lock()
+	ac.changedSecrets[handle] = struct{}{}
+	ac.changedSecretsLock.Unlock()
+
+	select {
+	case ac.secretsChanged <- struct{}{}:
+	default:
+	}
+}
+
+// processSecretChanges reschedules the configs using the secrets that changed
+func (ac *AutoConfig) processSecretChanges() {
+	ac.changedSecretsLock.Lock()
+	handles := ac.changedSecrets
+	ac.changedSecrets = make(map[string]struct{})
+	ac.changedSecretsLock.Unlock()
+
+	changes := ac.cfgMgr.processSecretChanges(handles)
+	if !changes.IsEmpty() {
+		log.Infof("Rescheduling %d configurations after a change of their secrets", len(changes.Schedule))
+		ac.applyChanges(changes)
+	}
+}
+
 // serviceListening is the main management goroutine for services.
 // It waits for service events to trigger template resolution and
 // checks the tags on existing services are up to date.
@@ -196,6 +239,8 @@ func (ac *AutoConfig) serviceListening() {
 			ac.processNewService(ctx, svc)
 		case svc := <-ac.delService:
 			ac.processDelService(ctx, svc)
+		case <-ac.secretsChanged:
+			ac.processSecretChanges()
 		}
 	}
 }
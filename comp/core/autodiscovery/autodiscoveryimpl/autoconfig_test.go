@@ -403,6 +403,55 @@ func countLoadedConfigs(ac *AutoConfig) int {
 	return count
 }
 
+func TestSecretChangeReschedulesConfig(t *testing.T) {
+	deps := createDeps(t)
+
+	msch := scheduler.NewController()
+	sch := &MockScheduler{scheduled: make(map[string]integration.Config)}
+	msch.Register("mock", sch, false)
+
+	mockResolver := MockSecretResolver{t, []mockSecretScenario{
+		{
+			expectedData:   []byte("password: ENC[db_password]"),
+			expectedOrigin: "postgres",
+			returnedData:   []byte("password: first"),
+		},
+		{
+			expectedData:   []byte{},
+			expectedOrigin: "postgres",
+			returnedData:   []byte{},
+		},
+	}}
+	ac := getAutoConfig(msch, &mockResolver, deps.WMeta, deps.TaggerComp, deps.LogsComp, deps.Telemetry)
+	ac.applyChanges(ac.processNewConfig(integration.Config{
+		Name:      "postgres",
+		Instances: []integration.Data{integration.Data("password: ENC[db_password]")},
+	}))
+
+	scheduledInstances := func() []string {
+		sch.mutex.Lock()
+		defer sch.mutex.Unlock()
+		instances := []string{}
+		for _, config := range sch.scheduled {
+			instances = append(instances, string(config.Instances[0]))
+		}
+		return instances
+	}
+	require.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"password: first"}, scheduledInstances())
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// secrets resolved for the first time don't trigger a reschedule
+	ac.onSecretChange("db_password", "postgres", nil, "", "first")
+	assert.Empty(t, ac.changedSecrets)
+
+	mockResolver.scenarios[0].returnedData = []byte("password: second")
+	ac.onSecretChange("db_password", "postgres", nil, "first", "second")
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"password: second"}, scheduledInstances())
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
 func TestRemoveTemplate(t *testing.T) {
 	deps := createDeps(t)
 	ctx := context.Background()
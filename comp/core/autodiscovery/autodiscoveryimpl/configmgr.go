@@ -8,6 +8,7 @@ package autodiscoveryimpl
 import (
 	"context"
 	"fmt"
+	"maps"
 	"sync"
 
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/configresolver"
@@ -41,6 +42,11 @@ type configManager interface {
 	// interface apply to only one config.
 	processDelConfigs(configs []integration.Config) integration.ConfigChanges
 
+	// processSecretChanges decrypts again the configs using any of the given
+	// secret handles, replacing the configs decrypted with the previous values
+	// of the secrets.
+	processSecretChanges(handles map[string]struct{}) integration.ConfigChanges
+
 	// mapOverLoadedConfigs calls the given function with a map of all
 	// loaded configs (those which have been scheduled but not unscheduled).
 	// The call is made with the manager's lock held, so callers should perform
@@ -48,6 +54,11 @@ type configManager interface {
 	mapOverLoadedConfigs(func(map[string]integration.Config))
 }
 
+// resolutionObserver is notified of the outcome of the resolution of a
+// template matching the AD identifiers of a service: the resolved config, or
+// the reason why the template is not scheduled for the service.
+type resolutionObserver func(svc listeners.Service, tpl integration.Config, resolved integration.Config, reason string)
+
 // serviceAndADIDs bundles a service and its associated AD identifiers.
 type serviceAndADIDs struct {
 	svc   listeners.Service
@@ -98,7 +109,15 @@ type reconcilingConfigManager struct {
 	// methods correspond exactly to changes in this map.
 	scheduledConfigs map[string]integration.Config
 
+	// decryptedDigests maps the digest of each active non-template config to
+	// the digest of the decrypted config that was scheduled for it.
+	decryptedDigests map[string]string
+
 	secretResolver secrets.Component
+
+	// observer, if set, is notified of the resolutions of the templates for
+	// each service. It is used to explain the resolutions in simulations.
+	observer resolutionObserver
 }
 
 var _ configManager = &reconcilingConfigManager{}
@@ -112,6 +131,7 @@ func newReconcilingConfigManager(secretResolver secrets.Component) configManager
 		servicesByADID:     newMultimap(),
 		serviceResolutions: map[string]map[string]string{},
 		scheduledConfigs:   map[string]integration.Config{},
+		decryptedDigests:   map[string]string{},
 		secretResolver:     secretResolver,
 	}
 }
@@ -228,6 +248,7 @@ func (cm *reconcilingConfigManager) processNewConfig(config integration.Config)
 		}
 
 		changes.ScheduleConfig(decryptedConfig)
+		cm.decryptedDigests[digest] = decryptedConfig.Digest()
 	}
 
 	//  4. update scheduledConfigs
@@ -268,14 +289,21 @@ func (cm *reconcilingConfigManager) processDelConfigs(configs []integration.Conf
 				changes.Merge(cm.reconcileService(svcID))
 			}
 		} else {
-			// Secrets need to be resolved before being unscheduled as otherwise
-			// the computed hashes can be different from the ones computed at schedule time.
-			config, err := decryptConfig(config, cm.secretResolver)
-			if err != nil {
-				log.Errorf("Unable to resolve secrets for config '%s', check may not be unscheduled properly, err: %s", config.Name, err.Error())
-			}
+			// The config that was scheduled is unscheduled, decrypting the
+			// config again would not match it if its secrets have changed since.
+			if decrypted, found := cm.scheduledConfigs[cm.decryptedDigests[digest]]; found {
+				changes.UnscheduleConfig(decrypted)
+			} else {
+				// Secrets need to be resolved before being unscheduled as otherwise
+				// the computed hashes can be different from the ones computed at schedule time.
+				config, err := decryptConfig(config, cm.secretResolver)
+				if err != nil {
+					log.Errorf("Unable to resolve secrets for config '%s', check may not be unscheduled properly, err: %s", config.Name, err.Error())
+				}
 
-			changes.UnscheduleConfig(config)
+				changes.UnscheduleConfig(config)
+			}
+			delete(cm.decryptedDigests, digest)
 		}
 
 		//  4. update scheduledConfigs
@@ -285,6 +313,54 @@ func (cm *reconcilingConfigManager) processDelConfigs(configs []integration.Conf
 	return allChanges
 }
 
+// processSecretChanges implements configManager#processSecretChanges.
+func (cm *reconcilingConfigManager) processSecretChanges(handles map[string]struct{}) integration.ConfigChanges {
+	cm.m.Lock()
+	defer cm.m.Unlock()
+
+	var changes integration.ConfigChanges
+	for digest, config := range cm.activeConfigs {
+		if !usesSecrets(config, handles) {
+			continue
+		}
+
+		if config.IsTemplate() {
+			for svcID, resolutions := range cm.serviceResolutions {
+				resolvedDigest, found := resolutions[digest]
+				if !found {
+					continue
+				}
+				// on failure, the config decrypted with the previous values is kept
+				resolved, ok := cm.resolveTemplateForService(config, cm.activeServices[svcID].svc)
+				if !ok || resolved.Digest() == resolvedDigest {
+					continue
+				}
+				changes.UnscheduleConfig(cm.scheduledConfigs[resolvedDigest])
+				changes.ScheduleConfig(resolved)
+				resolutions[digest] = resolved.Digest()
+			}
+			continue
+		}
+
+		decryptedConfig, err := decryptConfig(config, cm.secretResolver)
+		if err != nil {
+			log.Errorf("Unable to resolve secrets for config '%s', keeping the previous check configuration, err: %s", config.Name, err.Error())
+			continue
+		}
+		scheduledDigest := cm.decryptedDigests[digest]
+		if decryptedConfig.Digest() == scheduledDigest {
+			continue
+		}
+		if scheduled, found := cm.scheduledConfigs[scheduledDigest]; found {
+			changes.UnscheduleConfig(scheduled)
+		}
+		changes.ScheduleConfig(decryptedConfig)
+		cm.decryptedDigests[digest] = decryptedConfig.Digest()
+	}
+
+	return cm.applyChanges(changes)
+}
+
 // mapOverLoadedConfigs implements configManager#mapOverLoadedConfigs.
 func (cm *reconcilingConfigManager) mapOverLoadedConfigs(f func(map[string]integration.Config)) {
 	cm.m.Lock()
@@ -327,7 +403,17 @@ func (cm *reconcilingConfigManager) reconcileService(svcID string) integration.C
 	// allow the service to filter those templates, unless we are removing
 	// the service, in which case no resolutions are expected.
 	if svc != nil {
+		var matchingTemplates map[string]integration.Config
+		if cm.observer != nil {
+			matchingTemplates = maps.Clone(expectedResolutions)
+		}
 		svc.FilterTemplates(expectedResolutions)
+		for digest, tpl := range matchingTemplates {
+			if _, found := expectedResolutions[digest]; !found {
+				cm.observe(svc, tpl, integration.Config{}, "dropped by the service: its labels or annotations define the same check or an empty list of checks, or it has another logs config")
+			}
+		}
+		cm.filterTemplatesBySelector(expectedResolutions, svc)
 	}
 
 	// compare existing to expected, generating changes and modifying
@@ -347,6 +433,7 @@ func (cm *reconcilingConfigManager) reconcileService(svcID string) integration.C
 			if !ok {
 				continue
 			}
+			cm.observe(svc, config, resolved, "")
 			changes.ScheduleConfig(resolved)
 			existingResolutions[digest] = resolved.Digest()
 		}
@@ -361,6 +448,26 @@ func (cm *reconcilingConfigManager) reconcileService(svcID string) integration.C
 	return changes
 }
 
+// filterTemplatesBySelector drops the templates whose cel_selector doesn't
+// match the service. Templates with an invalid selector are dropped too.
+func (cm *reconcilingConfigManager) filterTemplatesBySelector(templates map[string]integration.Config, svc listeners.Service) {
+	for digest, tpl := range templates {
+		matches, err := configresolver.MatchSelector(tpl, svc)
+		if err != nil {
+			msg := fmt.Sprintf("error matching template %s with service %s: %v", tpl.Name, svc.GetServiceID(), err)
+			errorStats.setResolveWarning(tpl.Name, msg)
+			cm.observe(svc, tpl, integration.Config{}, err.Error())
+		}
+		if !matches {
+			log.Debugf("Template %s does not match the cel_selector for service %s", tpl.Name, svc.GetServiceID())
+			if err == nil {
+				cm.observe(svc, tpl, integration.Config{}, fmt.Sprintf("the cel_selector %q does not match the service", tpl.CELSelector))
+			}
+			delete(templates, digest)
+		}
+	}
+}
+
 // resolveTemplateForService resolves a template config for the given service,
 // updating errorStats in the process.  If the resolution fails, this method
 // returns false.
@@ -369,18 +476,27 @@ func (cm *reconcilingConfigManager) resolveTemplateForService(tpl integration.Co
 	if err != nil {
 		msg := fmt.Sprintf("error resolving template %s for service %s: %v", tpl.Name, svc.GetServiceID(), err)
 		errorStats.setResolveWarning(tpl.Name, msg)
+		cm.observe(svc, tpl, integration.Config{}, fmt.Sprintf("resolution failed: %v", err))
 		return tpl, false
 	}
 	resolvedConfig, err := decryptConfig(config, cm.secretResolver)
 	if err != nil {
 		msg := fmt.Sprintf("error decrypting secrets in config %s for service %s: %v", config.Name, svc.GetServiceID(), err)
 		errorStats.setResolveWarning(tpl.Name, msg)
+		cm.observe(svc, tpl, integration.Config{}, fmt.Sprintf("secrets decryption failed: %v", err))
 		return config, false
 	}
 	errorStats.removeResolveWarnings(tpl.Name)
 	return resolvedConfig, true
 }
 
+// observe notifies the observer, if any, of the resolution of a template for a service
+func (cm *reconcilingConfigManager) observe(svc listeners.Service, tpl integration.Config, resolved integration.Config, reason string) {
+	if cm.observer != nil {
+		cm.observer(svc, tpl, resolved, reason)
+	}
+}
+
 // applyChanges applies the given changes to cm.scheduledConfigs
 //
 // This method must be called with cm.m locked.
@@ -176,6 +176,49 @@ func (suite *ConfigManagerSuite) TestNewNonTemplateWithSecretsScheduled() {
 	require.True(suite.T(), strings.Contains(string(changes.Unschedule[0].Instances[0]), "barDecoded"))
 }
 
+// A non-template config is rescheduled when one of its secrets changes, and the
+// rescheduled config is the one unscheduled when the config is deleted
+func (suite *ConfigManagerSuite) TestNonTemplateSecretChangeRescheduled() {
+	mockResolver := MockSecretResolver{suite.T(), []mockSecretScenario{
+		{
+			expectedData:   []byte("foo: ENC[bar]"),
+			expectedOrigin: nonTemplateConfigWithSecrets.Name,
+			returnedData:   []byte("foo: barDecoded"),
+		},
+		{
+			expectedData:   []byte{},
+			expectedOrigin: nonTemplateConfigWithSecrets.Name,
+			returnedData:   []byte{},
+		},
+	}}
+	cm := suite.cm.(*reconcilingConfigManager)
+	cm.secretResolver = &mockResolver
+
+	changes, _ := suite.cm.processNewConfig(deepcopy.Copy(nonTemplateConfigWithSecrets).(integration.Config))
+	assertConfigsMatch(suite.T(), changes.Schedule, matchName(nonTemplateConfigWithSecrets.Name))
+	oldDigest := changes.Schedule[0].Digest()
+
+	// other secrets don't affect the config
+	changes = suite.cm.processSecretChanges(map[string]struct{}{"other": {}})
+	assert.True(suite.T(), changes.IsEmpty())
+
+	// the secret is rotated
+	mockResolver.scenarios[0].returnedData = []byte("foo: barRotated")
+	changes = suite.cm.processSecretChanges(map[string]struct{}{"bar": {}})
+	assertConfigsMatch(suite.T(), changes.Unschedule, matchDigest(oldDigest))
+	assertConfigsMatch(suite.T(), changes.Schedule, matchName(nonTemplateConfigWithSecrets.Name))
+	require.True(suite.T(), strings.Contains(string(changes.Schedule[0].Instances[0]), "barRotated"))
+	newDigest := changes.Schedule[0].Digest()
+
+	// the value didn't change since the last refresh
+	changes = suite.cm.processSecretChanges(map[string]struct{}{"bar": {}})
+	assert.True(suite.T(), changes.IsEmpty())
+
+	changes = suite.cm.processDelConfigs([]integration.Config{deepcopy.Copy(nonTemplateConfigWithSecrets).(integration.Config)})
+	assertConfigsMatch(suite.T(), changes.Schedule)
+	assertConfigsMatch(suite.T(), changes.Unschedule, matchDigest(newDigest))
+}
+
 func (suite *ConfigManagerSuite) TestNewClusterCheckWithSecretsScheduled() {
 	mockResolver := MockSecretResolver{suite.T(), []mockSecretScenario{
 		{
@@ -6,6 +6,7 @@
 package autodiscoveryimpl
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
@@ -54,3 +55,19 @@ func decryptConfig(conf integration.Config, secretResolver secrets.Component) (i
 
 	return conf, nil
 }
+
+// usesSecrets returns whether the config uses any of the secret handles
+func usesSecrets(conf integration.Config, handles map[string]struct{}) bool {
+	data := make([]integration.Data, 0, len(conf.Instances)+3)
+	data = append(data, conf.InitConfig, conf.MetricConfig, conf.LogsConfig)
+	data = append(data, conf.Instances...)
+	for handle := range handles {
+		encoded := []byte("ENC[" + handle + "]")
+		for _, d := range data {
+			if bytes.Contains(d, encoded) {
+				return true
+			}
+		}
+	}
+	return false
+}
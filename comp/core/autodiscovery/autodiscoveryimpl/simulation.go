@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package autodiscoveryimpl
+
+import (
+	"io"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/listeners"
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+)
+
+// SimulatedService is a service along with its AD identifiers.
+type SimulatedService struct {
+	Service       listeners.Service
+	ADIdentifiers []string
+}
+
+// Resolution is the outcome of the resolution of a template matching the AD
+// identifiers of a service: the scheduled config, or the reason why the
+// template is not scheduled for the service.
+type Resolution struct {
+	Template integration.Config
+	Config   integration.Config
+	Reason   string
+}
+
+// SimulateResolutions processes the configs, then the services, with the
+// config manager of autodiscovery, without scheduling anything. It returns the
+// resolutions of the templates for each service, keyed by service ID, and the
+// configs that are not templates. Secrets are not decrypted.
+func SimulateResolutions(configs []integration.Config, services []SimulatedService) (map[string][]Resolution, []integration.Config) {
+	resolutions := map[string][]Resolution{}
+	cm := newReconcilingConfigManager(noopSecretResolver{}).(*reconcilingConfigManager)
+	cm.observer = func(svc listeners.Service, tpl integration.Config, resolved integration.Config, reason string) {
+		svcID := svc.GetServiceID()
+		resolutions[svcID] = append(resolutions[svcID], Resolution{Template: tpl, Config: resolved, Reason: reason})
+	}
+
+	var nonTemplates []integration.Config
+	for _, config := range configs {
+		changes, _ := cm.processNewConfig(config)
+		if !config.IsTemplate() {
+			nonTemplates = append(nonTemplates, changes.Schedule...)
+		}
+	}
+	for _, svc := range services {
+		cm.processNewService(svc.ADIdentifiers, svc.Service)
+	}
+	return resolutions, nonTemplates
+}
+
+// noopSecretResolver leaves the secrets handles as-is
+type noopSecretResolver struct{}
+
+func (noopSecretResolver) Configure(secrets.ConfigParams) {}
+
+func (noopSecretResolver) GetDebugInfo(io.Writer) {}
+
+func (noopSecretResolver) Resolve(data []byte, _ string) ([]byte, error) {
+	return data, nil
+}
+
+func (noopSecretResolver) SubscribeToChanges(secrets.SecretChangeCallback) {}
+
+func (noopSecretResolver) Refresh() (string, error) {
+	return "", nil
+}
@@ -32,13 +32,16 @@ import (
 type variableGetter func(ctx context.Context, key string, svc listeners.Service) (string, error)
 
 var templateVariables = map[string]variableGetter{
-	"host":     getHost,
-	"pid":      getPid,
-	"port":     getPort,
-	"hostname": getHostname,
-	"env":      getEnvvar,
-	"extra":    getAdditionalTplVariables,
-	"kube":     getAdditionalTplVariables,
+	"host":       getHost,
+	"pid":        getPid,
+	"port":       getPort,
+	"hostname":   getHostname,
+	"env":        getEnvvar,
+	"extra":      getAdditionalTplVariables,
+	"kube":       getAdditionalTplVariables,
+	"label":      getLabel,
+	"annotation": getAnnotation,
+	"workload":   getWorkloadField,
 }
 
 // NoServiceError represents an error that indicates that there's a problem with a service
@@ -625,3 +628,49 @@ func getEnvvar(_ context.Context, envVar string, svc listeners.Service) (string,
 	}
 	return value, nil
 }
+
+// getLabel returns a label of the pod, or of the container outside of
+// Kubernetes, `%%label_<key>|<default>%%`
+func getLabel(_ context.Context, tplVar string, svc listeners.Service) (string, error) {
+	return getWorkloadValue("label", tplVar, svc, listeners.WorkloadService.GetLabels)
+}
+
+// getAnnotation returns an annotation of the pod, `%%annotation_<key>|<default>%%`
+func getAnnotation(_ context.Context, tplVar string, svc listeners.Service) (string, error) {
+	return getWorkloadValue("annotation", tplVar, svc, listeners.WorkloadService.GetAnnotations)
+}
+
+// getWorkloadField returns a field of the workloadmeta entity, like
+// `image_tag` or `namespace`, `%%workload_<field>|<default>%%`
+func getWorkloadField(_ context.Context, tplVar string, svc listeners.Service) (string, error) {
+	return getWorkloadValue("workload", tplVar, svc, listeners.WorkloadService.GetWorkloadFields)
+}
+
+// getWorkloadValue looks up a key of the metadata of the workloadmeta entity
+// behind the service. The default value after `|`, if any, is returned when
+// the key is not found.
+func getWorkloadValue(varName string, tplVar string, svc listeners.Service, getValues func(listeners.WorkloadService) map[string]string) (string, error) {
+	if svc == nil {
+		return "", NewNoServiceError(fmt.Sprintf("No service. %%%%%s_*%%%% is not allowed", varName))
+	}
+
+	key, defaultValue, hasDefault := strings.Cut(tplVar, "|")
+	if key == "" {
+		return "", fmt.Errorf("%s name is missing, skipping service %s", varName, svc.GetServiceID())
+	}
+
+	var values map[string]string
+	if workloadSvc, ok := svc.(listeners.WorkloadService); ok {
+		values = getValues(workloadSvc)
+	} else if !hasDefault {
+		return "", fmt.Errorf("%%%%%s_*%%%% is not supported by service %s, skipping config", varName, svc.GetServiceID())
+	}
+
+	if value, found := values[key]; found {
+		return value, nil
+	}
+	if hasDefault {
+		return defaultValue, nil
+	}
+	return "", fmt.Errorf("%s %q not found for service %s, skipping config", varName, key, svc.GetServiceID())
+}
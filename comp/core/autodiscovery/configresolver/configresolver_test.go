@@ -93,6 +93,29 @@ func (s *dummyService) GetExtraConfig(key string) (string, error) {
 func (s *dummyService) FilterTemplates(map[string]integration.Config) {
 }
 
+// dummyWorkloadService is a dummyService backed by a workloadmeta entity
+type dummyWorkloadService struct {
+	dummyService
+	Labels         map[string]string
+	Annotations    map[string]string
+	WorkloadFields map[string]string
+}
+
+// GetLabels returns dummy labels
+func (s *dummyWorkloadService) GetLabels() map[string]string {
+	return s.Labels
+}
+
+// GetAnnotations returns dummy annotations
+func (s *dummyWorkloadService) GetAnnotations() map[string]string {
+	return s.Annotations
+}
+
+// GetWorkloadFields returns dummy workload fields
+func (s *dummyWorkloadService) GetWorkloadFields() map[string]string {
+	return s.WorkloadFields
+}
+
 func TestGetFallbackHost(t *testing.T) {
 	ip, err := getFallbackHost(map[string]string{"bridge": "172.17.0.1"})
 	assert.Equal(t, "172.17.0.1", ip)
@@ -626,6 +649,79 @@ func TestResolve(t *testing.T) {
 				ServiceID:     "a5901276aed1",
 			},
 		},
+		//// %%label_*%%, %%annotation_*%% and %%workload_*%% testing
+		{
+			testName: "workload labels, annotations and fields",
+			svc: &dummyWorkloadService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"redis"},
+				},
+				Labels:         map[string]string{"app": "cache", "app.kubernetes.io/version": "7.2"},
+				Annotations:    map[string]string{"example.com/db_name": "sessions"},
+				WorkloadFields: map[string]string{"image_tag": "7.2.4", "namespace": "prod"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("db: %%annotation_example.com/db_name%%\nservice: %%label_app%%-%%workload_namespace%%\nversion: %%label_app.kubernetes.io/version%%\ntags:\n- image_tag:%%workload_image_tag%%")},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("db: sessions\nservice: cache-prod\ntags:\n- foo:bar\n- image_tag:7.2.4\nversion: \"7.2\"\n")},
+				ServiceID:     "a5901276aed1",
+			},
+		},
+		{
+			testName: "workload variables with default values",
+			svc: &dummyWorkloadService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"redis"},
+				},
+				Labels: map[string]string{"app": "cache"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("service: %%label_app|unknown%%\nteam: %%label_team|unknown%%\nport: %%annotation_example.com/port|6379%%\nempty: '%%workload_owner_name|%%'")},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("empty: \"\"\nport: 6379\nservice: cache\ntags:\n- foo:bar\nteam: unknown\n")},
+				ServiceID:     "a5901276aed1",
+			},
+		},
+		{
+			testName: "missing label without default value",
+			svc: &dummyWorkloadService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"redis"},
+				},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("service: %%label_app%%")},
+			},
+			errorString: `label "app" not found for service a5901276aed1, skipping config`,
+		},
+		{
+			testName: "workload variables on a service without workload",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("service: %%label_app%%")},
+			},
+			errorString: "%%label_*%% is not supported by service a5901276aed1, skipping config",
+		},
 		{
 			testName: "IPv6 %%host%%",
 			svc: &dummyService{
@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package configresolver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/listeners"
+)
+
+var (
+	// selectorEnv declares the attributes of the service available to the
+	// cel_selector of templates
+	selectorEnv     *cel.Env
+	selectorEnvErr  error
+	selectorEnvOnce sync.Once
+)
+
+func getSelectorEnv() (*cel.Env, error) {
+	selectorEnvOnce.Do(func() {
+		stringMap := cel.MapType(cel.StringType, cel.StringType)
+		selectorEnv, selectorEnvErr = cel.NewEnv(
+			cel.Variable("id", cel.StringType),
+			cel.Variable("ad_identifiers", cel.ListType(cel.StringType)),
+			cel.Variable("kube_namespace", cel.StringType),
+			cel.Variable("labels", stringMap),
+			cel.Variable("annotations", stringMap),
+			cel.Variable("workload", stringMap),
+			cel.Variable("ports", cel.ListType(cel.IntType)),
+			cel.Variable("port_names", cel.ListType(cel.StringType)),
+		)
+	})
+	return selectorEnv, selectorEnvErr
+}
+
+// selectorPrograms caches the compiled selectors by expression
+var selectorPrograms sync.Map
+
+type compiledSelector struct {
+	program cel.Program
+	err     error
+}
+
+// ValidateSelector returns an error if the cel_selector expression is invalid
+func ValidateSelector(selector string) error {
+	_, err := compileSelector(selector)
+	return err
+}
+
+func compileSelector(selector string) (cel.Program, error) {
+	if compiled, found := selectorPrograms.Load(selector); found {
+		return compiled.(compiledSelector).program, compiled.(compiledSelector).err
+	}
+
+	program, err := func() (cel.Program, error) {
+		env, err := getSelectorEnv()
+		if err != nil {
+			return nil, err
+		}
+		ast, issues := env.Compile(selector)
+		if issues != nil && issues.Err() != nil {
+			return nil, issues.Err()
+		}
+		if ast.OutputType() != cel.BoolType {
+			return nil, fmt.Errorf("the expression must return a bool, not %s", ast.OutputType())
+		}
+		return env.Program(ast)
+	}()
+	selectorPrograms.Store(selector, compiledSelector{program: program, err: err})
+	return program, err
+}
+
+// MatchSelector returns whether the service matches the cel_selector of the
+// template. Templates without a selector match all the services.
+func MatchSelector(tpl integration.Config, svc listeners.Service) (bool, error) {
+	if tpl.CELSelector == "" {
+		return true, nil
+	}
+
+	program, err := compileSelector(tpl.CELSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid cel_selector: %w", err)
+	}
+	out, _, err := program.Eval(selectorAttributes(svc))
+	if err != nil {
+		return false, fmt.Errorf("could not evaluate cel_selector for service %s: %w", svc.GetServiceID(), err)
+	}
+	matches, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("cel_selector returned %v instead of a bool", out.Value())
+	}
+	return matches, nil
+}
+
+// selectorAttributes returns the variables of the cel_selector for a service.
+// All the variables are defined, the ones that don't apply to the service are
+// empty.
+func selectorAttributes(svc listeners.Service) map[string]interface{} {
+	ctx := context.TODO()
+	adIdentifiers, _ := svc.GetADIdentifiers(ctx)
+	ports, _ := svc.GetPorts(ctx)
+	portNumbers := make([]int, 0, len(ports))
+	portNames := make([]string, 0, len(ports))
+	for _, port := range ports {
+		portNumbers = append(portNumbers, port.Port)
+		if port.Name != "" {
+			portNames = append(portNames, port.Name)
+		}
+	}
+
+	labels := map[string]string{}
+	annotations := map[string]string{}
+	workload := map[string]string{}
+	if workloadSvc, ok := svc.(listeners.WorkloadService); ok {
+		labels = nonNilMap(workloadSvc.GetLabels())
+		annotations = nonNilMap(workloadSvc.GetAnnotations())
+		workload = workloadSvc.GetWorkloadFields()
+	}
+
+	return map[string]interface{}{
+		"id":             svc.GetServiceID(),
+		"ad_identifiers": nonNilSlice(adIdentifiers),
+		"kube_namespace": workload["namespace"],
+		"labels":         labels,
+		"annotations":    annotations,
+		"workload":       workload,
+		"ports":          portNumbers,
+		"port_names":     portNames,
+	}
+}
+
+func nonNilMap(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
+func nonNilSlice(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package configresolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/listeners"
+)
+
+func TestValidateSelector(t *testing.T) {
+	assert.NoError(t, ValidateSelector(`kube_namespace.startsWith("prod-")`))
+	assert.NoError(t, ValidateSelector(`"http" in port_names && 6379 in ports`))
+	assert.Error(t, ValidateSelector(`kube_namespace ==`))
+	assert.Error(t, ValidateSelector(`unknown_variable == "foo"`))
+	assert.Error(t, ValidateSelector(`kube_namespace`))
+}
+
+func TestMatchSelector(t *testing.T) {
+	redisContainer := &dummyWorkloadService{
+		dummyService: dummyService{
+			ID:            "container://abc",
+			ADIdentifiers: []string{"redis"},
+			Ports:         []listeners.ContainerPort{{Port: 6379, Name: "redis"}},
+		},
+		Labels:      map[string]string{"team": "cache"},
+		Annotations: map[string]string{"example.com/tier": "gold"},
+		WorkloadFields: map[string]string{
+			"namespace": "prod-eu",
+			"image_tag": "7.2",
+		},
+	}
+	// services that aren't backed by a workloadmeta entity have no labels,
+	// annotations nor workload fields
+	plainService := &dummyService{
+		ID:            "kube_service://ns/redis",
+		ADIdentifiers: []string{"kube_service://ns/redis"},
+	}
+
+	testCases := []struct {
+		name     string
+		selector string
+		svc      listeners.Service
+		matches  bool
+		errorMsg string
+	}{
+		{
+			name:     "no selector",
+			selector: "",
+			svc:      redisContainer,
+			matches:  true,
+		},
+		{
+			name:     "namespace prefix",
+			selector: `kube_namespace.startsWith("prod-")`,
+			svc:      redisContainer,
+			matches:  true,
+		},
+		{
+			name:     "namespace not matching",
+			selector: `kube_namespace.matches("^staging-.*")`,
+			svc:      redisContainer,
+			matches:  false,
+		},
+		{
+			name:     "labels, annotations and workload fields",
+			selector: `labels["team"] == "cache" && annotations["example.com/tier"] == "gold" && workload["image_tag"].startsWith("7.")`,
+			svc:      redisContainer,
+			matches:  true,
+		},
+		{
+			name:     "ports and port names",
+			selector: `6379 in ports && "redis" in port_names`,
+			svc:      redisContainer,
+			matches:  true,
+		},
+		{
+			name:     "missing label",
+			selector: `has(labels.app) && labels.app == "redis"`,
+			svc:      redisContainer,
+			matches:  false,
+		},
+		{
+			name:     "service without workload",
+			selector: `kube_namespace == "" && size(labels) == 0 && id == "kube_service://ns/redis"`,
+			svc:      plainService,
+			matches:  true,
+		},
+		{
+			name:     "missing key",
+			selector: `labels["app"] == "redis"`,
+			svc:      plainService,
+			matches:  false,
+			errorMsg: "could not evaluate cel_selector",
+		},
+		{
+			name:     "invalid selector",
+			selector: `kube_namespace ==`,
+			svc:      redisContainer,
+			matches:  false,
+			errorMsg: "invalid cel_selector",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tpl := integration.Config{
+				Name:          "redisdb",
+				ADIdentifiers: []string{"redis"},
+				CELSelector:   tc.selector,
+			}
+			matches, err := MatchSelector(tpl, tc.svc)
+			if tc.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorMsg)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.matches, matches)
+		})
+	}
+}
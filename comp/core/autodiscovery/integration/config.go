@@ -73,6 +73,11 @@ type Config struct {
 	// see ADIdentifiers.  (optional)
 	AdvancedADIdentifiers []AdvancedADIdentifier `json:"advanced_ad_identifiers"` // (include in digest: false)
 
+	// CELSelector is a CEL expression further restricting the services a
+	// template applies to, among the ones matching its AD identifiers.
+	// (optional)
+	CELSelector string `json:"cel_selector"` // (include in digest: true)
+
 	// Provider is the name of the config provider that issued the config.  If
 	// this is "", then the config is a service config, representing a service
 	// discovered by a listener.
@@ -411,6 +416,10 @@ func (c *Config) IntDigest() uint64 {
 	for _, i := range c.ADIdentifiers {
 		_, _ = h.Write([]byte(i))
 	}
+	// only hashed when set, to keep the digest of existing configs stable
+	if c.CELSelector != "" {
+		_, _ = h.Write([]byte(c.CELSelector))
+	}
 	_, _ = h.Write([]byte(c.NodeName))
 	_, _ = h.Write([]byte(c.LogsConfig))
 	_, _ = h.Write([]byte(c.ServiceID))
@@ -434,6 +443,10 @@ func (c *Config) FastDigest() uint64 {
 	for _, i := range c.ADIdentifiers {
 		_, _ = h.Write([]byte(i))
 	}
+	// only hashed when set, to keep the digest of existing configs stable
+	if c.CELSelector != "" {
+		_, _ = h.Write([]byte(c.CELSelector))
+	}
 	_, _ = h.Write([]byte(c.NodeName))
 	_, _ = h.Write([]byte(c.LogsConfig))
 	_, _ = h.Write([]byte(c.ServiceID))
@@ -475,6 +488,7 @@ func (c *Config) Dump(multiline bool) string {
 	fmt.Fprintf(&b, ws("LogsConfig: %s,"), dataField(c.LogsConfig))
 	fmt.Fprintf(&b, ws("ADIdentifiers: %#v,"), c.ADIdentifiers)
 	fmt.Fprintf(&b, ws("AdvancedADIdentifiers: %#v,"), c.AdvancedADIdentifiers)
+	fmt.Fprintf(&b, ws("CELSelector: %#v,"), c.CELSelector)
 	fmt.Fprintf(&b, ws("Provider: %#v,"), c.Provider)
 	fmt.Fprintf(&b, ws("ServiceID: %#v,"), c.ServiceID)
 	fmt.Fprintf(&b, ws("TaggerEntity: %#v,"), c.TaggerEntity)
@@ -126,6 +126,7 @@ func (l *ContainerListener) createContainerService(entity workloadmeta.Entity) {
 	}
 
 	if pod != nil {
+		svc.pod = pod
 		svc.hosts = map[string]string{"pod": pod.IP}
 		svc.ready = pod.Ready
 
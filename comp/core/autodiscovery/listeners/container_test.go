@@ -255,6 +255,7 @@ func TestCreateContainerService(t *testing.T) {
 				"container://foo": {
 					service: &service{
 						entity: kubernetesContainer,
+						pod:    pod,
 						adIdentifiers: []string{
 							"docker://foo",
 							"gcr.io/foobar",
@@ -25,6 +25,7 @@ import (
 // workloadmeta.Store.
 type service struct {
 	entity          workloadmeta.Entity
+	pod             *workloadmeta.KubernetesPod // set for the containers running in a Kubernetes pod
 	tagsHash        string
 	adIdentifiers   []string
 	hosts           map[string]string
@@ -38,7 +39,7 @@ type service struct {
 	logsExcluded    bool
 }
 
-var _ Service = &service{}
+var _ WorkloadService = &service{}
 
 // Equal returns whether the two service are equal
 func (s *service) Equal(o Service) bool {
@@ -55,7 +56,9 @@ func (s *service) Equal(o Service) bool {
 		reflect.DeepEqual(s.checkNames, s2.checkNames) &&
 		s.hostname == s2.hostname &&
 		s.pid == s2.pid &&
-		s.ready == s2.ready
+		s.ready == s2.ready &&
+		reflect.DeepEqual(s.GetLabels(), s2.GetLabels()) &&
+		reflect.DeepEqual(s.GetAnnotations(), s2.GetAnnotations())
 }
 
 // GetServiceID returns the AD entity ID of the service.
@@ -203,3 +206,68 @@ func (s *service) GetExtraConfig(key string) (string, error) {
 
 	return result, nil
 }
+
+// GetLabels returns the labels of the pod of the service. Outside of
+// Kubernetes, it returns the labels of the container.
+func (s *service) GetLabels() map[string]string {
+	switch e := s.entity.(type) {
+	case *workloadmeta.KubernetesPod:
+		return e.Labels
+	case *workloadmeta.Container:
+		if s.pod != nil {
+			return s.pod.Labels
+		}
+		return e.Labels
+	}
+	return nil
+}
+
+// GetAnnotations returns the annotations of the pod of the service.
+func (s *service) GetAnnotations() map[string]string {
+	switch e := s.entity.(type) {
+	case *workloadmeta.KubernetesPod:
+		return e.Annotations
+	case *workloadmeta.Container:
+		if s.pod != nil {
+			return s.pod.Annotations
+		}
+	}
+	return nil
+}
+
+// GetWorkloadFields returns the fields of the workloadmeta entities of the
+// service that can be used in templates. Empty fields are not returned.
+func (s *service) GetWorkloadFields() map[string]string {
+	fields := map[string]string{}
+	pod := s.pod
+	switch e := s.entity.(type) {
+	case *workloadmeta.KubernetesPod:
+		pod = e
+	case *workloadmeta.Container:
+		fields["container_id"] = e.ID
+		fields["container_name"] = e.Name
+		fields["image"] = e.Image.RawName
+		fields["image_name"] = e.Image.Name
+		fields["image_short_name"] = e.Image.ShortName
+		fields["image_tag"] = e.Image.Tag
+		fields["runtime"] = string(e.Runtime)
+	}
+	if pod != nil {
+		fields["pod_name"] = pod.Name
+		fields["pod_uid"] = pod.ID
+		fields["namespace"] = pod.Namespace
+		fields["pod_ip"] = pod.IP
+		fields["qos_class"] = pod.QOSClass
+		fields["priority_class"] = pod.PriorityClass
+		if len(pod.Owners) > 0 {
+			fields["owner_kind"] = pod.Owners[0].Kind
+			fields["owner_name"] = pod.Owners[0].Name
+		}
+	}
+	for field, value := range fields {
+		if value == "" {
+			delete(fields, field)
+		}
+	}
+	return fields
+}
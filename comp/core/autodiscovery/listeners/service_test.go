@@ -150,3 +150,53 @@ func TestServiceFilterTemplatesCCA(t *testing.T) {
 			filterDrops(&service{}, noLogsTpl, logsTpl, ccaTpl))
 	})
 }
+
+func TestServiceWorkloadMetadata(t *testing.T) {
+	pod := &workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesPod, ID: "pod-uid"},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        "redis-0",
+			Namespace:   "prod",
+			Labels:      map[string]string{"app": "cache"},
+			Annotations: map[string]string{"example.com/db_name": "sessions"},
+		},
+		Owners: []workloadmeta.KubernetesPodOwner{{Kind: "StatefulSet", Name: "redis"}},
+		IP:     "10.0.0.1",
+	}
+	container := &workloadmeta.Container{
+		EntityID:   workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "container-id"},
+		EntityMeta: workloadmeta.EntityMeta{Name: "redis", Labels: map[string]string{"io.kubernetes.pod.name": "redis-0"}},
+		Image:      workloadmeta.ContainerImage{RawName: "redis:7.2", Name: "redis", ShortName: "redis", Tag: "7.2"},
+		Runtime:    workloadmeta.ContainerRuntimeContainerd,
+	}
+
+	svc := &service{entity: container, pod: pod}
+	assert.Equal(t, map[string]string{"app": "cache"}, svc.GetLabels())
+	assert.Equal(t, map[string]string{"example.com/db_name": "sessions"}, svc.GetAnnotations())
+	assert.Equal(t, map[string]string{
+		"container_id":     "container-id",
+		"container_name":   "redis",
+		"image":            "redis:7.2",
+		"image_name":       "redis",
+		"image_short_name": "redis",
+		"image_tag":        "7.2",
+		"runtime":          "containerd",
+		"pod_name":         "redis-0",
+		"pod_uid":          "pod-uid",
+		"namespace":        "prod",
+		"pod_ip":           "10.0.0.1",
+		"owner_kind":       "StatefulSet",
+		"owner_name":       "redis",
+	}, svc.GetWorkloadFields())
+
+	// outside of Kubernetes, the labels of the container are used
+	svc = &service{entity: container}
+	assert.Equal(t, map[string]string{"io.kubernetes.pod.name": "redis-0"}, svc.GetLabels())
+	assert.Nil(t, svc.GetAnnotations())
+	assert.NotContains(t, svc.GetWorkloadFields(), "namespace")
+
+	svc = &service{entity: pod}
+	assert.Equal(t, map[string]string{"app": "cache"}, svc.GetLabels())
+	assert.Equal(t, "redis-0", svc.GetWorkloadFields()["pod_name"])
+	assert.NotContains(t, svc.GetWorkloadFields(), "image")
+}
@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+
+package listeners
+
+import (
+	"fmt"
+	"sort"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+// ServicesFromSnapshot returns the services created for the entities of a
+// workloadmeta snapshot, as used by `agent autodiscovery simulate`. The
+// kubelet listener processes the pods of the snapshot, or the container
+// listener processes its containers when it has no pods, as in the agent.
+// The services are sorted by service ID.
+func ServicesFromSnapshot(snapshot workloadmeta.WorkloadSnapshot) ([]Service, error) {
+	filters, err := newContainerFilters()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &snapshotStore{
+		containers: make(map[string]*workloadmeta.Container, len(snapshot.Containers)),
+		pods:       snapshot.KubernetesPods,
+	}
+	for _, container := range snapshot.Containers {
+		store.containers[container.ID] = container
+	}
+
+	l := &snapshotListener{
+		store:    store,
+		filters:  filters,
+		services: map[string]Service{},
+	}
+
+	if len(snapshot.KubernetesPods) > 0 {
+		kubeletListener := &KubeletListener{workloadmetaListener: l}
+		for _, pod := range snapshot.KubernetesPods {
+			kubeletListener.processPod(pod)
+		}
+	} else {
+		containerListener := &ContainerListener{workloadmetaListener: l}
+		for _, container := range snapshot.Containers {
+			containerListener.createContainerService(container)
+		}
+	}
+
+	services := make([]Service, 0, len(l.services))
+	for _, svc := range l.services {
+		services = append(services, svc)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].GetServiceID() < services[j].GetServiceID()
+	})
+
+	return services, nil
+}
+
+// snapshotStore serves the entities of a snapshot to the listeners. Only the
+// lookups done by the listeners when creating services are implemented.
+type snapshotStore struct {
+	workloadmeta.Component
+
+	containers map[string]*workloadmeta.Container
+	pods       []*workloadmeta.KubernetesPod
+}
+
+func (s *snapshotStore) GetContainer(id string) (*workloadmeta.Container, error) {
+	container, found := s.containers[id]
+	if !found {
+		return nil, fmt.Errorf("container %q not found in the snapshot", id)
+	}
+	return container, nil
+}
+
+func (s *snapshotStore) GetKubernetesPodForContainer(containerID string) (*workloadmeta.KubernetesPod, error) {
+	for _, pod := range s.pods {
+		for _, podContainer := range pod.GetAllContainers() {
+			if podContainer.ID == containerID {
+				return pod, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("pod of container %q not found in the snapshot", containerID)
+}
+
+// snapshotListener collects the services created by the listeners instead of
+// sending them to autodiscovery.
+type snapshotListener struct {
+	store    *snapshotStore
+	filters  *containerFilters
+	services map[string]Service
+}
+
+var _ workloadmetaListener = &snapshotListener{}
+
+func (l *snapshotListener) Listen(_ chan<- Service, _ chan<- Service) {}
+
+func (l *snapshotListener) Stop() {}
+
+func (l *snapshotListener) Store() workloadmeta.Component {
+	return l.store
+}
+
+func (l *snapshotListener) AddService(svcID string, svc Service, _ string) {
+	l.services[svcID] = svc
+}
+
+func (l *snapshotListener) IsExcluded(ft containers.FilterType, annotations map[string]string, name, image, ns string) bool {
+	return l.filters.IsExcluded(ft, annotations, name, image, ns)
+}
@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+
+package listeners
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+)
+
+func TestServicesFromSnapshot(t *testing.T) {
+	container := &workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainer,
+			ID:   "abc",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "redis",
+		},
+		Image: workloadmeta.ContainerImage{
+			RawName:   "redis:7.2",
+			ShortName: "redis",
+		},
+		State: workloadmeta.ContainerState{
+			Running: true,
+		},
+		Runtime: workloadmeta.ContainerRuntimeContainerd,
+	}
+
+	pod := &workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesPod,
+			ID:   "pod-uid",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "redis-0",
+			Namespace: "prod",
+			Labels:    map[string]string{"app": "redis"},
+		},
+		Containers: []workloadmeta.OrchestratorContainer{
+			{
+				ID:    "abc",
+				Name:  "redis",
+				Image: workloadmeta.ContainerImage{RawName: "redis:7.2", ShortName: "redis"},
+			},
+		},
+		Ready: true,
+		IP:    "10.0.0.1",
+	}
+
+	t.Run("containers only", func(t *testing.T) {
+		services, err := ServicesFromSnapshot(workloadmeta.WorkloadSnapshot{
+			Containers: []*workloadmeta.Container{container},
+		})
+		require.NoError(t, err)
+		require.Len(t, services, 1)
+
+		adIDs, err := services[0].GetADIdentifiers(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"containerd://abc", "redis"}, adIDs)
+	})
+
+	t.Run("kubernetes", func(t *testing.T) {
+		services, err := ServicesFromSnapshot(workloadmeta.WorkloadSnapshot{
+			Containers:     []*workloadmeta.Container{container},
+			KubernetesPods: []*workloadmeta.KubernetesPod{pod},
+		})
+		require.NoError(t, err)
+		require.Len(t, services, 2)
+
+		// sorted by service ID
+		assert.Equal(t, "containerd://abc", services[0].GetServiceID())
+		assert.Equal(t, "kubernetes_pod://pod-uid", services[1].GetServiceID())
+
+		workloadSvc, ok := services[0].(WorkloadService)
+		require.True(t, ok)
+		assert.Equal(t, "prod", workloadSvc.GetWorkloadFields()["namespace"])
+		assert.Equal(t, map[string]string{"app": "redis"}, workloadSvc.GetLabels())
+	})
+}
@@ -47,6 +47,17 @@ type Service interface {
 	FilterTemplates(map[string]integration.Config)
 }
 
+// WorkloadService is implemented by the services created from workloadmeta
+// entities. It exposes the metadata of the entity, and of its pod for
+// containers, to the %%label_*%%, %%annotation_*%% and %%workload_*%%
+// template variables.
+type WorkloadService interface {
+	Service
+	GetLabels() map[string]string      // labels of the pod, or of the container outside of Kubernetes
+	GetAnnotations() map[string]string // annotations of the pod
+	GetWorkloadFields() map[string]string
+}
+
 // ServiceListener monitors running services and triggers check (un)scheduling
 //
 // It holds a cache of running services, listens to new/killed services and
@@ -28,6 +28,7 @@ import (
 type configFormat struct {
 	ADIdentifiers           []string                           `yaml:"ad_identifiers"`
 	AdvancedADIdentifiers   []integration.AdvancedADIdentifier `yaml:"advanced_ad_identifiers"`
+	CELSelector             string                             `yaml:"cel_selector"`
 	ClusterCheck            bool                               `yaml:"cluster_check"`
 	InitConfig              interface{}                        `yaml:"init_config"`
 	MetricConfig            interface{}                        `yaml:"jmx_metrics"`
@@ -378,6 +379,14 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 		log.Warnf("reading config file %v: %v\n", fpath, strictErr)
 	}
 
+	return integrationConfigFromFormat(name, cf, "file:"+fpath)
+}
+
+// integrationConfigFromFormat returns the integration.Config defined by a
+// parsed configuration, read from the given source.
+func integrationConfigFromFormat(name string, cf configFormat, source string) (integration.Config, error) {
+	conf := integration.Config{Name: name}
+
 	// If no valid instances were found & this is neither a metrics file, nor a logs file
 	// this is not a valid configuration file
 	if cf.MetricConfig == nil && cf.LogsConfig == nil && len(cf.Instances) < 1 {
@@ -401,7 +410,7 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 			tags := configUtils.GetConfiguredTags(config.Datadog(), false)
 			err := dataConf.MergeAdditionalTags(tags)
 			if err != nil {
-				log.Debugf("Could not add agent-level tags to instance of %v: %v", fpath, err)
+				log.Debugf("Could not add agent-level tags to instance of %v: %v", source, err)
 			}
 		}
 		conf.Instances = append(conf.Instances, dataConf)
@@ -423,6 +432,7 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 	// Copy auto discovery identifiers
 	conf.ADIdentifiers = cf.ADIdentifiers
 	conf.AdvancedADIdentifiers = cf.AdvancedADIdentifiers
+	conf.CELSelector = cf.CELSelector
 
 	// Copy cluster_check status
 	conf.ClusterCheck = cf.ClusterCheck
@@ -435,6 +445,15 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 		return conf, errors.New("the 'docker_images' section is deprecated, please use 'ad_identifiers' instead")
 	}
 
+	if cf.CELSelector != "" {
+		if len(cf.ADIdentifiers) == 0 {
+			return conf, errors.New("the 'cel_selector' section requires 'ad_identifiers'")
+		}
+		if err := configresolver.ValidateSelector(cf.CELSelector); err != nil {
+			return conf, fmt.Errorf("invalid 'cel_selector': %w", err)
+		}
+	}
+
 	// Interpolate env vars. Returns an error a variable wasn't substituted, ignore it.
 	e := configresolver.SubstituteTemplateEnvVars(&conf)
 	if e != nil {
@@ -444,9 +463,9 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 		}
 	}
 
-	conf.Source = "file:" + fpath
+	conf.Source = source
 
-	return conf, err
+	return conf, nil
 }
 
 func containsString(slice []string, str string) bool {
@@ -92,6 +92,61 @@ func TestGetIntegrationConfig(t *testing.T) {
 	assert.Empty(t, config.ServiceID)
 }
 
+func TestGetIntegrationConfigCELSelector(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig := func(name, content string) string {
+		fpath := path.Join(dir, name)
+		require.NoError(t, os.WriteFile(fpath, []byte(content), 0o644))
+		return fpath
+	}
+
+	fpath := writeConfig("selector.yaml", `
+ad_identifiers:
+  - redis
+cel_selector: kube_namespace.startsWith("prod-") && labels["team"] == "cache"
+init_config:
+instances:
+  - host: "%%host%%"
+`)
+	config, err := GetIntegrationConfigFromFile("redis", fpath)
+	require.NoError(t, err)
+	assert.Equal(t, `kube_namespace.startsWith("prod-") && labels["team"] == "cache"`, config.CELSelector)
+
+	// the selector only restricts the services matching the AD identifiers
+	fpath = writeConfig("no_identifiers.yaml", `
+cel_selector: kube_namespace == "prod"
+init_config:
+instances:
+  - host: localhost
+`)
+	_, err = GetIntegrationConfigFromFile("redis", fpath)
+	assert.Error(t, err)
+
+	// invalid expression
+	fpath = writeConfig("invalid.yaml", `
+ad_identifiers:
+  - redis
+cel_selector: kube_namespace ==
+init_config:
+instances:
+  - host: "%%host%%"
+`)
+	_, err = GetIntegrationConfigFromFile("redis", fpath)
+	assert.Error(t, err)
+
+	// non-boolean expression
+	fpath = writeConfig("not_bool.yaml", `
+ad_identifiers:
+  - redis
+cel_selector: kube_namespace
+init_config:
+instances:
+  - host: "%%host%%"
+`)
+	_, err = GetIntegrationConfigFromFile("redis", fpath)
+	assert.Error(t, err)
+}
+
 func TestReadConfigFiles(t *testing.T) {
 	paths := []string{"tests"}
 	ResetReader(paths)
@@ -0,0 +1,299 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// httpCatalogMaxSize is the maximum size of a catalog response
+const httpCatalogMaxSize = 10 * 1024 * 1024
+
+// httpCatalog is the format of the catalog served by the endpoint, in JSON or
+// YAML. Each entry has the format of a check configuration file, plus the
+// name of the check.
+type httpCatalog struct {
+	Configs []httpCatalogEntry `yaml:"configs"`
+}
+
+type httpCatalogEntry struct {
+	Name         string `yaml:"name"`
+	configFormat `yaml:",inline"`
+}
+
+// HTTPConfigProvider implements the ConfigProvider interface. It polls an
+// HTTP(S) endpoint serving a catalog of check configurations and templates.
+// The ETag of the last response is sent back in the If-None-Match header, so
+// that the catalog is only parsed again when it changes.
+type HTTPConfigProvider struct {
+	sync.Mutex
+
+	client         *http.Client
+	templateURL    string
+	providerConfig config.ConfigurationProviders
+
+	etag    string
+	configs []integration.Config
+	// fetched is true when IsUpToDate fetched a new catalog that Collect
+	// hasn't returned yet
+	fetched      bool
+	configErrors map[string]ErrorMsgSet
+}
+
+// NewHTTPConfigProvider returns a new HTTPConfigProvider polling the
+// template_url of the provider config. The ca_file, cert_file and key_file
+// settings configure TLS, and mutual TLS when a client certificate is given.
+// The token is sent as a bearer token, username and password as basic auth,
+// and headers as-is; they can all be secrets, read again on each request so
+// that refreshed secrets are used.
+func NewHTTPConfigProvider(providerConfig *config.ConfigurationProviders, _ *telemetry.Store) (ConfigProvider, error) {
+	if providerConfig == nil {
+		providerConfig = &config.ConfigurationProviders{}
+	}
+
+	templateURL, err := url.Parse(providerConfig.TemplateURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template_url: %w", err)
+	}
+	if templateURL.Scheme != "http" && templateURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid template_url %q: the scheme must be http or https", providerConfig.TemplateURL)
+	}
+
+	tlsConfig, err := buildHTTPProviderTLSConfig(providerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &HTTPConfigProvider{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Duration(config.Datadog().GetInt("autoconf_template_url_timeout")) * time.Second,
+		},
+		templateURL:    providerConfig.TemplateURL,
+		providerConfig: *providerConfig,
+		configErrors:   make(map[string]ErrorMsgSet),
+	}, nil
+}
+
+// buildHTTPProviderTLSConfig returns the TLS config of the HTTP provider
+func buildHTTPProviderTLSConfig(providerConfig *config.ConfigurationProviders) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	var caFiles []string
+	if providerConfig.CAFile != "" {
+		caFiles = append(caFiles, providerConfig.CAFile)
+	}
+	if providerConfig.CAPath != "" {
+		matches, err := filepath.Glob(filepath.Join(providerConfig.CAPath, "*.pem"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ca_path: %w", err)
+		}
+		caFiles = append(caFiles, matches...)
+	}
+	if len(caFiles) > 0 {
+		pool := x509.NewCertPool()
+		for _, caFile := range caFiles {
+			pem, err := os.ReadFile(caFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read the CA certificate: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no valid certificate found in %s", caFile)
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if providerConfig.CertFile != "" || providerConfig.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(providerConfig.CertFile, providerConfig.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// currentProviderConfig returns the provider config from the agent
+// configuration, where refreshed secrets are updated. The config the provider
+// was created with is returned when it isn't found there.
+func (p *HTTPConfigProvider) currentProviderConfig() config.ConfigurationProviders {
+	var providerConfigs []config.ConfigurationProviders
+	if err := config.Datadog().UnmarshalKey("config_providers", &providerConfigs); err != nil {
+		log.Debugf("Unable to read the config providers, using the initial credentials of %s: %s", p.templateURL, err)
+		return p.providerConfig
+	}
+	for _, providerConfig := range providerConfigs {
+		if providerConfig.Name == p.providerConfig.Name && providerConfig.TemplateURL == p.providerConfig.TemplateURL {
+			return providerConfig
+		}
+	}
+	return p.providerConfig
+}
+
+// String returns a string representation of the HTTPConfigProvider
+func (p *HTTPConfigProvider) String() string {
+	return names.HTTP
+}
+
+// Collect returns the configurations of the catalog
+func (p *HTTPConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if !p.fetched {
+		if _, err := p.fetch(ctx); err != nil {
+			return nil, err
+		}
+	}
+	p.fetched = false
+
+	return p.configs, nil
+}
+
+// IsUpToDate fetches the catalog and returns whether it changed since the
+// last call to Collect
+func (p *HTTPConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.fetched {
+		return false, nil
+	}
+
+	changed, err := p.fetch(ctx)
+	if err != nil {
+		return false, err
+	}
+	p.fetched = changed
+	return !changed, nil
+}
+
+// fetch requests the catalog and parses it, unless the server replies that
+// it's unchanged since the last response. It returns whether the catalog
+// changed.
+//
+// This method must be called with p locked.
+func (p *HTTPConfigProvider) fetch(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.templateURL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json, application/yaml")
+	providerConfig := p.currentProviderConfig()
+	for name, value := range providerConfig.Headers {
+		req.Header.Set(name, value)
+	}
+	if providerConfig.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+providerConfig.Token)
+	}
+	if providerConfig.Username != "" || providerConfig.Password != "" {
+		req.SetBasicAuth(providerConfig.Username, providerConfig.Password)
+	}
+	if p.etag != "" {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("unable to fetch the configuration catalog: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unable to fetch the configuration catalog: unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, httpCatalogMaxSize+1))
+	if err != nil {
+		return false, fmt.Errorf("unable to read the configuration catalog: %w", err)
+	}
+	if len(body) > httpCatalogMaxSize {
+		return false, fmt.Errorf("the configuration catalog is larger than %d bytes", httpCatalogMaxSize)
+	}
+
+	configs, configErrors, err := p.parseCatalog(body)
+	if err != nil {
+		return false, err
+	}
+
+	p.configs = configs
+	p.configErrors = configErrors
+	p.etag = resp.Header.Get("ETag")
+	return true, nil
+}
+
+// parseCatalog returns the valid configurations of a catalog, and the errors
+// of the invalid ones indexed by check name
+func (p *HTTPConfigProvider) parseCatalog(body []byte) ([]integration.Config, map[string]ErrorMsgSet, error) {
+	// JSON being a subset of YAML, both formats are parsed the same way
+	catalog := httpCatalog{}
+	if err := yaml.Unmarshal(body, &catalog); err != nil {
+		return nil, nil, fmt.Errorf("unable to parse the configuration catalog: %w", err)
+	}
+
+	configs := make([]integration.Config, 0, len(catalog.Configs))
+	configErrors := make(map[string]ErrorMsgSet)
+	for i, entry := range catalog.Configs {
+		if entry.Name == "" {
+			addConfigError(configErrors, fmt.Sprintf("configs[%d]", i), errors.New("the configuration has no name"))
+			continue
+		}
+		conf, err := integrationConfigFromFormat(entry.Name, entry.configFormat, fmt.Sprintf("%s:%s", names.HTTP, p.templateURL))
+		if err != nil {
+			log.Warnf("Ignoring configuration %s of %s: %s", entry.Name, p.templateURL, err)
+			addConfigError(configErrors, entry.Name, err)
+			continue
+		}
+		configs = append(configs, conf)
+	}
+
+	return configs, configErrors, nil
+}
+
+func addConfigError(configErrors map[string]ErrorMsgSet, key string, err error) {
+	if _, found := configErrors[key]; !found {
+		configErrors[key] = ErrorMsgSet{}
+	}
+	configErrors[key][err.Error()] = struct{}{}
+}
+
+// GetConfigErrors returns the errors of the invalid configurations of the
+// last catalog
+func (p *HTTPConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.Lock()
+	defer p.Unlock()
+
+	return p.configErrors
+}
@@ -0,0 +1,227 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+)
+
+const testHTTPCatalog = `
+configs:
+  - name: redisdb
+    ad_identifiers:
+      - redis
+    init_config:
+    instances:
+      - host: "%%host%%"
+        port: 6379
+  - name: http_check
+    init_config:
+    instances:
+      - url: https://example.com
+  - name: invalid
+    init_config:
+`
+
+type testCatalogServer struct {
+	sync.Mutex
+	catalog  string
+	etag     string
+	requests []*http.Request
+}
+
+func (s *testCatalogServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.requests = append(s.requests, r)
+	if r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", s.etag)
+	w.Write([]byte(s.catalog))
+}
+
+func (s *testCatalogServer) update(catalog, etag string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.catalog = catalog
+	s.etag = etag
+}
+
+func (s *testCatalogServer) requestCount() int {
+	s.Lock()
+	defer s.Unlock()
+
+	return len(s.requests)
+}
+
+func (s *testCatalogServer) lastRequest() *http.Request {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.requests[len(s.requests)-1]
+}
+
+func TestHTTPConfigProviderCollect(t *testing.T) {
+	server := &testCatalogServer{catalog: testHTTPCatalog, etag: `"v1"`}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{
+		TemplateURL: ts.URL,
+		Token:       "secret-token",
+		Headers:     map[string]string{"X-Catalog-Team": "infra"},
+	}, nil)
+	require.NoError(t, err)
+	httpProvider := provider.(*HTTPConfigProvider)
+	ctx := context.Background()
+
+	configs, err := httpProvider.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+	assert.Equal(t, "redisdb", configs[0].Name)
+	assert.Equal(t, []string{"redis"}, configs[0].ADIdentifiers)
+	assert.Equal(t, "http:"+ts.URL, configs[0].Source)
+	assert.Equal(t, "http_check", configs[1].Name)
+	assert.Equal(t, []integration.Data{integration.Data("url: https://example.com\n")}, configs[1].Instances)
+	assert.Contains(t, httpProvider.GetConfigErrors(), "invalid")
+
+	request := server.lastRequest()
+	assert.Equal(t, "Bearer secret-token", request.Header.Get("Authorization"))
+	assert.Equal(t, "infra", request.Header.Get("X-Catalog-Team"))
+	assert.Empty(t, request.Header.Get("If-None-Match"))
+
+	// the catalog is unchanged
+	upToDate, err := httpProvider.IsUpToDate(ctx)
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+	assert.Equal(t, `"v1"`, server.lastRequest().Header.Get("If-None-Match"))
+
+	// the catalog changes
+	server.update(`{"configs": [{"name": "redisdb", "ad_identifiers": ["redis"], "instances": [{"host": "%%host%%", "port": 6380}]}]}`, `"v2"`)
+	upToDate, err = httpProvider.IsUpToDate(ctx)
+	require.NoError(t, err)
+	assert.False(t, upToDate)
+
+	// the catalog fetched by IsUpToDate is returned without another request
+	requests := server.requestCount()
+	configs, err = httpProvider.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Contains(t, string(configs[0].Instances[0]), "port: 6380")
+	assert.Equal(t, requests, server.requestCount())
+	assert.Empty(t, httpProvider.GetConfigErrors())
+
+	upToDate, err = httpProvider.IsUpToDate(ctx)
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+}
+
+func TestHTTPConfigProviderRefreshedCredentials(t *testing.T) {
+	server := &testCatalogServer{catalog: testHTTPCatalog, etag: `"v1"`}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	providerSettings := func(key, password string) []map[string]interface{} {
+		return []map[string]interface{}{{
+			"name":         "http",
+			"template_url": ts.URL,
+			"username":     "agent",
+			"password":     password,
+			"headers":      map[string]interface{}{"X-Catalog-Key": key},
+		}}
+	}
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("config_providers", providerSettings("old-key", "old-password"))
+
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{
+		Name:        "http",
+		TemplateURL: ts.URL,
+		Username:    "agent",
+		Password:    "old-password",
+		Headers:     map[string]string{"X-Catalog-Key": "old-key"},
+	}, nil)
+	require.NoError(t, err)
+	httpProvider := provider.(*HTTPConfigProvider)
+	ctx := context.Background()
+
+	_, err = httpProvider.Collect(ctx)
+	require.NoError(t, err)
+	request := server.lastRequest()
+	username, password, ok := request.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "agent", username)
+	assert.Equal(t, "old-password", password)
+	assert.Equal(t, "old-key", request.Header.Get("X-Catalog-Key"))
+
+	// the secrets are refreshed in the configuration
+	mockConfig.SetWithoutSource("config_providers", providerSettings("new-key", "new-password"))
+
+	_, err = httpProvider.IsUpToDate(ctx)
+	require.NoError(t, err)
+	request = server.lastRequest()
+	_, password, _ = request.BasicAuth()
+	assert.Equal(t, "new-password", password)
+	assert.Equal(t, "new-key", request.Header.Get("X-Catalog-Key"))
+}
+
+func TestHTTPConfigProviderErrors(t *testing.T) {
+	_, err := NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: "ftp://catalog"}, nil)
+	assert.Error(t, err)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: ts.URL}, nil)
+	require.NoError(t, err)
+	_, err = provider.(*HTTPConfigProvider).Collect(context.Background())
+	assert.ErrorContains(t, err, "403")
+	_, err = provider.(*HTTPConfigProvider).IsUpToDate(context.Background())
+	assert.Error(t, err)
+}
+
+func TestHTTPConfigProviderTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(&testCatalogServer{catalog: testHTTPCatalog, etag: `"v1"`})
+	defer ts.Close()
+
+	// the certificate of the server isn't trusted
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: ts.URL}, nil)
+	require.NoError(t, err)
+	_, err = provider.(*HTTPConfigProvider).Collect(context.Background())
+	assert.Error(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0o600))
+
+	provider, err = NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: ts.URL, CAFile: caFile}, nil)
+	require.NoError(t, err)
+	configs, err := provider.(*HTTPConfigProvider).Collect(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, configs, 2)
+
+	_, err = NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: ts.URL, CertFile: "missing.pem", KeyFile: "missing.key"}, nil)
+	assert.Error(t, err)
+}
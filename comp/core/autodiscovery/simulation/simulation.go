@@ -0,0 +1,173 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package simulation resolves autodiscovery templates against services
+// offline, with the autodiscovery config manager, and explains why templates
+// are not scheduled. It backs `agent autodiscovery simulate`.
+package simulation
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/autodiscoveryimpl"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/listeners"
+)
+
+// Rejection is a template that matches the AD identifiers of a service but
+// is not scheduled for it.
+type Rejection struct {
+	Template integration.Config
+	Reason   string
+}
+
+// ServiceResult contains the configs that would be scheduled for a service,
+// and the templates that match its AD identifiers but would not be.
+type ServiceResult struct {
+	ServiceID     string
+	ADIdentifiers []string
+	// ADIdentifiersError is set when the AD identifiers of the service can't
+	// be determined, the service is not monitored then.
+	ADIdentifiersError error
+	Scheduled          []integration.Config
+	Rejected           []Rejection
+}
+
+// Result is the outcome of a simulation.
+type Result struct {
+	// Services contains a result for every service, sorted by service ID.
+	Services []ServiceResult
+	// UnmatchedTemplates are the templates whose AD identifiers match no
+	// service.
+	UnmatchedTemplates []integration.Config
+	// Configs are the configs that are not templates. They are scheduled
+	// as-is, regardless of the services.
+	Configs []integration.Config
+}
+
+// The prefixes of the AD identifiers of the kube services and endpoints, see
+// pkg/util/kubernetes/apiserver.
+const (
+	kubeServiceIDPrefix  = "kube_service://"
+	kubeEndpointIDPrefix = "kube_endpoint_uid://"
+)
+
+// Run resolves the configs against the services with the config manager of
+// autodiscovery. Secrets are not decrypted.
+func Run(ctx context.Context, configs []integration.Config, services []listeners.Service) Result {
+	var result Result
+
+	var simulatedServices []autodiscoveryimpl.SimulatedService
+	var serviceADIDs []string
+	for _, svc := range services {
+		// like the autodiscovery, services whose AD identifiers can't be
+		// determined are not processed
+		adIDs, err := svc.GetADIdentifiers(ctx)
+		if err != nil {
+			result.Services = append(result.Services, ServiceResult{ServiceID: svc.GetServiceID(), ADIdentifiersError: err})
+			continue
+		}
+		simulatedServices = append(simulatedServices, autodiscoveryimpl.SimulatedService{Service: svc, ADIdentifiers: adIDs})
+		serviceADIDs = append(serviceADIDs, adIDs...)
+	}
+
+	templates := map[string]integration.Config{}
+	resolvedConfigs := make([]integration.Config, 0, len(configs))
+	for _, config := range configs {
+		if len(config.AdvancedADIdentifiers) > 0 {
+			config = withAdvancedADIdentifiers(config, serviceADIDs)
+		}
+		if config.IsTemplate() {
+			templates[config.Digest()] = config
+		}
+		resolvedConfigs = append(resolvedConfigs, config)
+	}
+
+	resolutions, nonTemplates := autodiscoveryimpl.SimulateResolutions(resolvedConfigs, simulatedServices)
+	result.Configs = nonTemplates
+
+	matchedTemplates := map[string]struct{}{}
+	for _, svc := range simulatedServices {
+		svcResult := ServiceResult{
+			ServiceID:     svc.Service.GetServiceID(),
+			ADIdentifiers: svc.ADIdentifiers,
+		}
+		svcResolutions := resolutions[svcResult.ServiceID]
+		sort.SliceStable(svcResolutions, func(i, j int) bool {
+			return lessTemplate(svcResolutions[i].Template, svcResolutions[j].Template)
+		})
+		for _, resolution := range svcResolutions {
+			matchedTemplates[resolution.Template.Digest()] = struct{}{}
+			if resolution.Reason != "" {
+				svcResult.Rejected = append(svcResult.Rejected, Rejection{Template: resolution.Template, Reason: resolution.Reason})
+				continue
+			}
+			svcResult.Scheduled = append(svcResult.Scheduled, resolution.Config)
+		}
+		result.Services = append(result.Services, svcResult)
+	}
+
+	for _, digest := range sortedDigests(templates) {
+		if _, found := matchedTemplates[digest]; !found {
+			result.UnmatchedTemplates = append(result.UnmatchedTemplates, templates[digest])
+		}
+	}
+
+	sort.SliceStable(result.Services, func(i, j int) bool {
+		return result.Services[i].ServiceID < result.Services[j].ServiceID
+	})
+
+	return result
+}
+
+// withAdvancedADIdentifiers adds the AD identifiers of the kube services and
+// endpoints targeted by the advanced AD identifiers of a template, like the
+// kube_services_file and kube_endpoints_file config providers. The endpoints
+// are found among the AD identifiers of the services.
+func withAdvancedADIdentifiers(tpl integration.Config, serviceADIDs []string) integration.Config {
+	adIDs := append([]string(nil), tpl.ADIdentifiers...)
+	for _, advancedID := range tpl.AdvancedADIdentifiers {
+		if !advancedID.KubeService.IsEmpty() {
+			adIDs = append(adIDs, kubeServiceIDPrefix+advancedID.KubeService.Namespace+"/"+advancedID.KubeService.Name)
+		}
+		if !advancedID.KubeEndpoints.IsEmpty() {
+			prefix := kubeEndpointIDPrefix + advancedID.KubeEndpoints.Namespace + "/" + advancedID.KubeEndpoints.Name + "/"
+			for _, adID := range serviceADIDs {
+				if strings.HasPrefix(adID, prefix) && !slices.Contains(adIDs, adID) {
+					adIDs = append(adIDs, adID)
+				}
+			}
+		}
+	}
+	tpl.ADIdentifiers = adIDs
+	return tpl
+}
+
+// sortedDigests returns the digests of the templates sorted by template name
+// then source, for a stable output.
+func sortedDigests(templates map[string]integration.Config) []string {
+	digests := make([]string, 0, len(templates))
+	for digest := range templates {
+		digests = append(digests, digest)
+	}
+	sort.Slice(digests, func(i, j int) bool {
+		return lessTemplate(templates[digests[i]], templates[digests[j]])
+	})
+	return digests
+}
+
+// lessTemplate orders the templates by name, then source, then digest
+func lessTemplate(a, b integration.Config) bool {
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	if a.Source != b.Source {
+		return a.Source < b.Source
+	}
+	return a.Digest() < b.Digest()
+}
@@ -0,0 +1,258 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package simulation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/listeners"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+type dummyService struct {
+	ID                 string
+	ADIdentifiers      []string
+	ADIdentifiersError error
+	Hosts              map[string]string
+	DroppedChecks      []string
+}
+
+func (s *dummyService) Equal(o listeners.Service) bool {
+	return reflect.DeepEqual(s, o)
+}
+
+func (s *dummyService) GetServiceID() string {
+	return s.ID
+}
+
+func (s *dummyService) GetADIdentifiers(context.Context) ([]string, error) {
+	return s.ADIdentifiers, s.ADIdentifiersError
+}
+
+func (s *dummyService) GetHosts(context.Context) (map[string]string, error) {
+	return s.Hosts, nil
+}
+
+func (s *dummyService) GetPorts(context.Context) ([]listeners.ContainerPort, error) {
+	return nil, nil
+}
+
+func (s *dummyService) GetTags() ([]string, error) {
+	return nil, nil
+}
+
+func (s *dummyService) GetPid(context.Context) (int, error) {
+	return 0, nil
+}
+
+func (s *dummyService) GetHostname(context.Context) (string, error) {
+	return "", nil
+}
+
+func (s *dummyService) IsReady(context.Context) bool {
+	return true
+}
+
+func (s *dummyService) HasFilter(containers.FilterType) bool {
+	return false
+}
+
+func (s *dummyService) GetExtraConfig(string) (string, error) {
+	return "", nil
+}
+
+// FilterTemplates drops the templates of the checks in DroppedChecks
+func (s *dummyService) FilterTemplates(configs map[string]integration.Config) {
+	for digest, config := range configs {
+		for _, check := range s.DroppedChecks {
+			if config.Name == check {
+				delete(configs, digest)
+			}
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	redis := integration.Config{
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("host: \"%%host%%\"")},
+		Source:        "file:/etc/datadog-agent/conf.d/redisdb.d/auto_conf.yaml",
+	}
+	redisProd := integration.Config{
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis"},
+		CELSelector:   `id.startsWith("prod-")`,
+		Instances:     []integration.Data{integration.Data("host: \"%%host%%\"\nprod: true")},
+		Source:        "file:/etc/datadog-agent/conf.d/redisdb.d/prod.yaml",
+	}
+	redisEnv := integration.Config{
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("password: \"%%env_REDIS_PASSWORD%%\"")},
+		Source:        "file:/etc/datadog-agent/conf.d/redisdb.d/password.yaml",
+	}
+	nginx := integration.Config{
+		Name:          "nginx",
+		ADIdentifiers: []string{"nginx"},
+		Instances:     []integration.Data{integration.Data("host: \"%%host%%\"")},
+		Source:        "file:/etc/datadog-agent/conf.d/nginx.d/auto_conf.yaml",
+	}
+	mysql := integration.Config{
+		Name:          "mysql",
+		ADIdentifiers: []string{"mysql"},
+		Instances:     []integration.Data{integration.Data("host: \"%%host%%\"")},
+		Source:        "file:/etc/datadog-agent/conf.d/mysql.d/auto_conf.yaml",
+	}
+	cpu := integration.Config{
+		Name:      "cpu",
+		Instances: []integration.Data{integration.Data("{}")},
+		Source:    "file:/etc/datadog-agent/conf.d/cpu.d/conf.yaml.default",
+	}
+
+	services := []listeners.Service{
+		&dummyService{
+			ID:            "prod-redis",
+			ADIdentifiers: []string{"redis"},
+			Hosts:         map[string]string{"pod": "10.0.0.1"},
+		},
+		&dummyService{
+			ID:            "dev-redis",
+			ADIdentifiers: []string{"redis"},
+			Hosts:         map[string]string{"pod": "10.0.0.2"},
+		},
+		&dummyService{
+			ID:            "nginx",
+			ADIdentifiers: []string{"nginx"},
+			Hosts:         map[string]string{"pod": "10.0.0.3"},
+			DroppedChecks: []string{"nginx"},
+		},
+	}
+
+	result := Run(context.Background(), []integration.Config{redis, redisProd, redisEnv, nginx, mysql, cpu}, services)
+
+	assert.Equal(t, []integration.Config{cpu}, result.Configs)
+	assert.Equal(t, []integration.Config{mysql}, result.UnmatchedTemplates)
+	require.Len(t, result.Services, 3)
+
+	devRedis := result.Services[0]
+	assert.Equal(t, "dev-redis", devRedis.ServiceID)
+	require.Len(t, devRedis.Scheduled, 1)
+	assert.Equal(t, redis.Source, devRedis.Scheduled[0].Source)
+	assert.Equal(t, "dev-redis", devRedis.Scheduled[0].ServiceID)
+	assert.Contains(t, string(devRedis.Scheduled[0].Instances[0]), "host: 10.0.0.2")
+	require.Len(t, devRedis.Rejected, 2)
+	assert.Equal(t, redisEnv.Source, devRedis.Rejected[0].Template.Source)
+	assert.Contains(t, devRedis.Rejected[0].Reason, "resolution failed")
+	assert.Equal(t, redisProd.Source, devRedis.Rejected[1].Template.Source)
+	assert.Contains(t, devRedis.Rejected[1].Reason, "cel_selector")
+
+	nginxResult := result.Services[1]
+	assert.Equal(t, "nginx", nginxResult.ServiceID)
+	assert.Empty(t, nginxResult.Scheduled)
+	require.Len(t, nginxResult.Rejected, 1)
+	assert.Contains(t, nginxResult.Rejected[0].Reason, "dropped by the service")
+
+	prodRedis := result.Services[2]
+	assert.Equal(t, "prod-redis", prodRedis.ServiceID)
+	require.Len(t, prodRedis.Scheduled, 2)
+	assert.Equal(t, redis.Source, prodRedis.Scheduled[0].Source)
+	assert.Equal(t, redisProd.Source, prodRedis.Scheduled[1].Source)
+	require.Len(t, prodRedis.Rejected, 1)
+}
+
+func TestRunAdvancedADIdentifiers(t *testing.T) {
+	serviceCheck := integration.Config{
+		Name: "http_check",
+		AdvancedADIdentifiers: []integration.AdvancedADIdentifier{
+			{KubeService: integration.KubeNamespacedName{Name: "redis", Namespace: "default"}},
+		},
+		Instances: []integration.Data{integration.Data("url: \"http://%%host%%\"")},
+		Source:    "file:/etc/datadog-agent/conf.d/http_check.d/service.yaml",
+	}
+	endpointsCheck := integration.Config{
+		Name: "redisdb",
+		AdvancedADIdentifiers: []integration.AdvancedADIdentifier{
+			{KubeEndpoints: integration.KubeNamespacedName{Name: "redis", Namespace: "default"}},
+		},
+		Instances: []integration.Data{integration.Data("host: \"%%host%%\"")},
+		Source:    "file:/etc/datadog-agent/conf.d/redisdb.d/endpoints.yaml",
+	}
+	otherEndpointsCheck := integration.Config{
+		Name: "nginx",
+		AdvancedADIdentifiers: []integration.AdvancedADIdentifier{
+			{KubeEndpoints: integration.KubeNamespacedName{Name: "nginx", Namespace: "default"}},
+		},
+		Instances: []integration.Data{integration.Data("host: \"%%host%%\"")},
+		Source:    "file:/etc/datadog-agent/conf.d/nginx.d/endpoints.yaml",
+	}
+
+	services := []listeners.Service{
+		&dummyService{
+			ID:            "kube_service://default/redis",
+			ADIdentifiers: []string{"kube_service://default/redis"},
+			Hosts:         map[string]string{"service": "10.96.0.10"},
+		},
+		&dummyService{
+			ID:            "kube_endpoint_uid://default/redis/10.0.0.1",
+			ADIdentifiers: []string{"kube_endpoint_uid://default/redis/10.0.0.1"},
+			Hosts:         map[string]string{"endpoint": "10.0.0.1"},
+		},
+		&dummyService{
+			ID:            "kube_endpoint_uid://default/redis/10.0.0.2",
+			ADIdentifiers: []string{"kube_endpoint_uid://default/redis/10.0.0.2"},
+			Hosts:         map[string]string{"endpoint": "10.0.0.2"},
+		},
+	}
+
+	result := Run(context.Background(), []integration.Config{serviceCheck, endpointsCheck, otherEndpointsCheck}, services)
+
+	require.Len(t, result.Services, 3)
+	for _, svcResult := range result.Services {
+		require.Len(t, svcResult.Scheduled, 1, svcResult.ServiceID)
+		assert.Empty(t, svcResult.Rejected)
+		assert.Equal(t, svcResult.ServiceID, svcResult.Scheduled[0].ServiceID)
+	}
+	assert.Equal(t, endpointsCheck.Source, result.Services[0].Scheduled[0].Source)
+	assert.Contains(t, string(result.Services[0].Scheduled[0].Instances[0]), "host: 10.0.0.1")
+	assert.Equal(t, endpointsCheck.Source, result.Services[1].Scheduled[0].Source)
+	assert.Contains(t, string(result.Services[1].Scheduled[0].Instances[0]), "host: 10.0.0.2")
+	assert.Equal(t, serviceCheck.Source, result.Services[2].Scheduled[0].Source)
+	assert.Contains(t, string(result.Services[2].Scheduled[0].Instances[0]), "url: http://10.96.0.10")
+
+	require.Len(t, result.UnmatchedTemplates, 1)
+	assert.Equal(t, otherEndpointsCheck.Source, result.UnmatchedTemplates[0].Source)
+}
+
+func TestRunADIdentifiersError(t *testing.T) {
+	redis := integration.Config{
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("host: \"%%host%%\"")},
+		Source:        "file:/etc/datadog-agent/conf.d/redisdb.d/auto_conf.yaml",
+	}
+	services := []listeners.Service{
+		&dummyService{
+			ID:                 "redis",
+			ADIdentifiersError: errors.New("container not found"),
+		},
+	}
+
+	result := Run(context.Background(), []integration.Config{redis}, services)
+
+	require.Len(t, result.Services, 1)
+	assert.Equal(t, "redis", result.Services[0].ServiceID)
+	assert.EqualError(t, result.Services[0].ADIdentifiersError, "container not found")
+	assert.Empty(t, result.Services[0].Scheduled)
+	assert.Equal(t, []integration.Config{redis}, result.UnmatchedTemplates)
+}
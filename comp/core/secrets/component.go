@@ -14,15 +14,30 @@ import (
 
 // ConfigParams holds parameters for configuration
 type ConfigParams struct {
-	Command          string
-	Arguments        []string
-	Timeout          int
-	MaxSize          int
-	RefreshInterval  int
-	GroupExecPerm    bool
-	RemoveLinebreak  bool
-	RunPath          string
-	AuditFileMaxSize int
+	Command           string
+	Arguments         []string
+	Timeout           int
+	MaxSize           int
+	RefreshInterval   int
+	GroupExecPerm     bool
+	RemoveLinebreak   bool
+	RunPath           string
+	AuditFileMaxSize  int
+	Backends          []BackendConfig
+	RefreshAllHandles bool
+}
+
+// BackendConfig holds the configuration of a native secret backend, resolving the
+// handles of the form `ENC[<prefix>:<secret>]` without invoking an executable
+type BackendConfig struct {
+	// Type of the backend: file, env, vault or kubernetes
+	Type string `yaml:"type"`
+	// Prefix selecting the backend in the handles, defaults to the type
+	Prefix string `yaml:"prefix"`
+	// TTL in seconds during which a fetched secret is not fetched again, 0 disables the cache
+	TTL int `yaml:"ttl"`
+	// Config holds the settings specific to the type of the backend
+	Config map[string]interface{} `yaml:"config"`
 }
 
 // Component is the component type.
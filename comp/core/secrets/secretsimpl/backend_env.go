@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package secretsimpl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// envBackend reads secrets from the environment variables of the agent, `ENC[env:DB_PASSWORD]`
+type envBackend struct{}
+
+func newEnvBackend(config map[string]interface{}, _ time.Duration) (nativeBackend, error) {
+	if err := unmarshalBackendConfig(config, &struct{}{}); err != nil {
+		return nil, err
+	}
+	return envBackend{}, nil
+}
+
+func (envBackend) fetch(_ context.Context, secret string) (string, error) {
+	value, ok := os.LookupEnv(secret)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", secret)
+	}
+	return value, nil
+}
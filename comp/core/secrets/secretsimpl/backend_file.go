@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package secretsimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const maxSecretFileSize = 8192
+
+type fileBackendConfig struct {
+	// Root restricts the secrets to the files under this directory, relative paths are relative to it
+	Root string `yaml:"root"`
+}
+
+// fileBackend reads secrets from files, `ENC[file:/run/secrets/db_password]`
+type fileBackend struct {
+	root string
+}
+
+func newFileBackend(config map[string]interface{}, _ time.Duration) (nativeBackend, error) {
+	var cfg fileBackendConfig
+	if err := unmarshalBackendConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Root != "" {
+		root, err := filepath.Abs(cfg.Root)
+		if err != nil {
+			return nil, err
+		}
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolved
+		}
+		cfg.Root = root
+	}
+	return &fileBackend{root: cfg.Root}, nil
+}
+
+func (b *fileBackend) fetch(_ context.Context, secret string) (string, error) {
+	path := filepath.Clean(secret)
+	if b.root != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(b.root, path)
+		}
+		// symlinks are resolved so that they can't point outside of the root, like the
+		// ones created by the kubelet when mounting secrets
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return "", err
+		}
+		if !strings.HasPrefix(resolved, b.root+string(filepath.Separator)) {
+			return "", fmt.Errorf("secret file %s is outside of %s", secret, b.root)
+		}
+		path = resolved
+	} else if !filepath.IsAbs(path) {
+		return "", errors.New("the path of the secret file must be absolute when no root is configured")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > maxSecretFileSize {
+		return "", fmt.Errorf("secret file %s exceeds max allowed size of %d bytes", secret, maxSecretFileSize)
+	}
+	value, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package secretsimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultServiceAccountCAFile = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+
+type kubernetesBackendConfig struct {
+	// Host is the URL of the API server, defaults to the in-cluster one
+	Host string `yaml:"host"`
+	// TokenFile defaults to the token of the service account of the agent
+	TokenFile     string `yaml:"token_file"`
+	CAFile        string `yaml:"ca_file"`
+	TLSSkipVerify bool   `yaml:"tls_skip_verify"`
+}
+
+// kubernetesBackend reads Kubernetes secrets from the API server, `ENC[kubernetes:<namespace>/<name>/<key>]`
+type kubernetesBackend struct {
+	host      string
+	tokenFile string
+	client    *http.Client
+}
+
+func newKubernetesBackend(config map[string]interface{}, timeout time.Duration) (nativeBackend, error) {
+	var cfg kubernetesBackendConfig
+	if err := unmarshalBackendConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Host == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, errors.New("host is required when the agent is not running in a Kubernetes cluster")
+		}
+		cfg.Host = "https://" + net.JoinHostPort(host, port)
+		if cfg.CAFile == "" && !cfg.TLSSkipVerify {
+			cfg.CAFile = defaultServiceAccountCAFile
+		}
+	}
+	if cfg.TokenFile == "" {
+		cfg.TokenFile = defaultServiceAccountTokenFile
+	}
+
+	client, err := newBackendHTTPClient(cfg.CAFile, cfg.TLSSkipVerify, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &kubernetesBackend{
+		host:      strings.TrimSuffix(cfg.Host, "/"),
+		tokenFile: cfg.TokenFile,
+		client:    client,
+	}, nil
+}
+
+type kubernetesSecret struct {
+	// values are base64 encoded, which encoding/json decodes into []byte
+	Data map[string][]byte `json:"data"`
+	// Message is set when the API server returns a Status object
+	Message string `json:"message"`
+}
+
+func (b *kubernetesBackend) fetch(ctx context.Context, secret string) (string, error) {
+	parts := strings.Split(secret, "/")
+	if len(parts) != 3 {
+		return "", errors.New("invalid format. Use: \"namespace/name/key\"")
+	}
+	namespace, name, key := parts[0], parts[1], parts[2]
+
+	token, err := readTokenFile(b.tokenFile)
+	if err != nil {
+		return "", err
+	}
+	secretURL := fmt.Sprintf("%s/api/v1/namespaces/%s/secrets/%s", b.host, url.PathEscape(namespace), url.PathEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, secretURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, SecretBackendOutputMaxSizeDefault))
+	if err != nil {
+		return "", err
+	}
+
+	var response kubernetesSecret
+	unmarshalErr := json.Unmarshal(body, &response)
+	if resp.StatusCode != http.StatusOK {
+		if response.Message != "" {
+			return "", fmt.Errorf("kubernetes API server returned status code %d: %s", resp.StatusCode, response.Message)
+		}
+		return "", fmt.Errorf("kubernetes API server returned status code %d", resp.StatusCode)
+	}
+	if unmarshalErr != nil {
+		return "", fmt.Errorf("could not unmarshal kubernetes secret: %s", unmarshalErr)
+	}
+
+	value, ok := response.Data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s/%s", key, namespace, name)
+	}
+	return string(value), nil
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package secretsimpl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubernetesBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sa-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"kind":"Status","message":"Unauthorized"}`))
+			return
+		}
+		if r.URL.Path != "/api/v1/namespaces/default/secrets/db" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"kind":"Status","message":"secrets \"other\" not found"}`))
+			return
+		}
+		// "cGFzc3dvcmQ=" is "password"
+		w.Write([]byte(`{"kind":"Secret","data":{"password":"cGFzc3dvcmQ="}}`))
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("sa-token\n"), 0600))
+
+	backend, err := newKubernetesBackend(map[string]interface{}{"host": server.URL, "token_file": tokenFile}, time.Second)
+	require.NoError(t, err)
+
+	value, err := backend.fetch(context.Background(), "default/db/password")
+	require.NoError(t, err)
+	assert.Equal(t, "password", value)
+
+	_, err = backend.fetch(context.Background(), "default/db/user")
+	assert.EqualError(t, err, "key user not found in secret default/db")
+
+	_, err = backend.fetch(context.Background(), "default/other/password")
+	assert.EqualError(t, err, `kubernetes API server returned status code 404: secrets "other" not found`)
+
+	_, err = backend.fetch(context.Background(), "default/db")
+	assert.EqualError(t, err, `invalid format. Use: "namespace/name/key"`)
+
+	// the token file is read again on every request
+	require.NoError(t, os.WriteFile(tokenFile, []byte("expired"), 0600))
+	_, err = backend.fetch(context.Background(), "default/db/password")
+	assert.EqualError(t, err, "kubernetes API server returned status code 401: Unauthorized")
+}
+
+func TestNewKubernetesBackendOutsideCluster(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "")
+
+	_, err := newKubernetesBackend(nil, time.Second)
+	assert.EqualError(t, err, "host is required when the agent is not running in a Kubernetes cluster")
+}
@@ -0,0 +1,279 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package secretsimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	vaultAuthToken      = "token"
+	vaultAuthKubernetes = "kubernetes"
+	vaultAuthAppRole    = "approle"
+
+	defaultServiceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+type vaultBackendConfig struct {
+	// Address of the Vault server, defaults to the VAULT_ADDR environment variable
+	Address   string `yaml:"address"`
+	Namespace string `yaml:"namespace"`
+	// AuthMethod is one of token, kubernetes or approle
+	AuthMethod string `yaml:"auth_method"`
+	// AuthMount is the path the auth method is mounted at, defaults to the name of the method
+	AuthMount string `yaml:"auth_mount"`
+	// token auth method, defaults to the VAULT_TOKEN environment variable
+	Token     string `yaml:"token"`
+	TokenFile string `yaml:"token_file"`
+	// kubernetes auth method
+	Role    string `yaml:"role"`
+	JWTFile string `yaml:"jwt_file"`
+	// approle auth method
+	RoleID       string `yaml:"role_id"`
+	SecretIDFile string `yaml:"secret_id_file"`
+
+	CAFile        string `yaml:"ca_file"`
+	TLSSkipVerify bool   `yaml:"tls_skip_verify"`
+}
+
+// vaultBackend reads secrets from Vault KV secrets engines, `ENC[vault:<path>#<key>]`.
+// Both versions of the engine are supported, the path of version 2 includes `data/`:
+// `ENC[vault:secret/data/db#password]`
+type vaultBackend struct {
+	config vaultBackendConfig
+	client *http.Client
+
+	// token obtained by logging in with the kubernetes or approle auth methods,
+	// a zero expiry means that the token doesn't expire
+	lock        sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+func newVaultBackend(config map[string]interface{}, timeout time.Duration) (nativeBackend, error) {
+	var cfg vaultBackendConfig
+	if err := unmarshalBackendConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("VAULT_ADDR")
+	}
+	if cfg.Address == "" {
+		return nil, errors.New("address is required")
+	}
+	cfg.Address = strings.TrimSuffix(cfg.Address, "/")
+	if cfg.AuthMethod == "" {
+		cfg.AuthMethod = vaultAuthToken
+	}
+	if cfg.AuthMount == "" {
+		cfg.AuthMount = cfg.AuthMethod
+	}
+
+	switch cfg.AuthMethod {
+	case vaultAuthToken:
+		if cfg.Token == "" && cfg.TokenFile == "" {
+			cfg.Token = os.Getenv("VAULT_TOKEN")
+		}
+		if cfg.Token == "" && cfg.TokenFile == "" {
+			return nil, errors.New("token or token_file is required for the token auth method")
+		}
+	case vaultAuthKubernetes:
+		if cfg.Role == "" {
+			return nil, errors.New("role is required for the kubernetes auth method")
+		}
+		if cfg.JWTFile == "" {
+			cfg.JWTFile = defaultServiceAccountTokenFile
+		}
+	case vaultAuthAppRole:
+		if cfg.RoleID == "" || cfg.SecretIDFile == "" {
+			return nil, errors.New("role_id and secret_id_file are required for the approle auth method")
+		}
+	default:
+		return nil, fmt.Errorf("unknown auth_method '%s', expected one of token, kubernetes, approle", cfg.AuthMethod)
+	}
+
+	client, err := newBackendHTTPClient(cfg.CAFile, cfg.TLSSkipVerify, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &vaultBackend{
+		config: cfg,
+		client: client,
+	}, nil
+}
+
+type vaultResponse struct {
+	Data map[string]interface{} `json:"data"`
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// vaultError is returned when the Vault server answers with an error status code
+type vaultError struct {
+	statusCode int
+	errors     []string
+}
+
+func (e *vaultError) Error() string {
+	if len(e.errors) == 0 {
+		return fmt.Sprintf("vault returned status code %d", e.statusCode)
+	}
+	return fmt.Sprintf("vault returned status code %d: %s", e.statusCode, strings.Join(e.errors, ", "))
+}
+
+func (b *vaultBackend) fetch(ctx context.Context, secret string) (string, error) {
+	path, key, ok := strings.Cut(secret, "#")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("invalid format. Use: \"<path>#<key>\"")
+	}
+
+	response, err := b.readSecret(ctx, path)
+	var vaultErr *vaultError
+	if errors.As(err, &vaultErr) && vaultErr.statusCode == http.StatusForbidden && b.config.AuthMethod != vaultAuthToken {
+		// the token may have been revoked before the end of its lease, log in again
+		b.resetToken()
+		response, err = b.readSecret(ctx, path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	data := response.Data
+	// KV version 2 nests the secret and its metadata under data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s", key, path)
+	}
+	strValue, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("key %s of secret %s is not a string", key, path)
+	}
+	return strValue, nil
+}
+
+func (b *vaultBackend) readSecret(ctx context.Context, path string) (*vaultResponse, error) {
+	token, err := b.getToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return b.do(ctx, http.MethodGet, "/v1/"+strings.TrimPrefix(path, "/"), token, nil)
+}
+
+func (b *vaultBackend) resetToken() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.token = ""
+}
+
+// getToken returns the token used to read the secrets, logging in if needed
+func (b *vaultBackend) getToken(ctx context.Context) (string, error) {
+	switch {
+	case b.config.Token != "":
+		return b.config.Token, nil
+	case b.config.TokenFile != "":
+		return readTokenFile(b.config.TokenFile)
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.token != "" && (b.tokenExpiry.IsZero() || time.Now().Before(b.tokenExpiry)) {
+		return b.token, nil
+	}
+
+	body := map[string]string{}
+	switch b.config.AuthMethod {
+	case vaultAuthKubernetes:
+		jwt, err := readTokenFile(b.config.JWTFile)
+		if err != nil {
+			return "", err
+		}
+		body["role"] = b.config.Role
+		body["jwt"] = jwt
+	case vaultAuthAppRole:
+		secretID, err := readTokenFile(b.config.SecretIDFile)
+		if err != nil {
+			return "", err
+		}
+		body["role_id"] = b.config.RoleID
+		body["secret_id"] = secretID
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	response, err := b.do(ctx, http.MethodPost, "/v1/auth/"+b.config.AuthMount+"/login", "", payload)
+	if err != nil {
+		return "", fmt.Errorf("unable to log in to vault with the %s auth method: %w", b.config.AuthMethod, err)
+	}
+	if response.Auth.ClientToken == "" {
+		return "", fmt.Errorf("unable to log in to vault with the %s auth method: no token returned", b.config.AuthMethod)
+	}
+
+	b.token = response.Auth.ClientToken
+	// renew the token before the end of its lease, a token without lease such as
+	// a periodic token is only renewed once it's rejected
+	b.tokenExpiry = time.Time{}
+	if response.Auth.LeaseDuration > 0 {
+		lease := time.Duration(response.Auth.LeaseDuration) * time.Second
+		b.tokenExpiry = time.Now().Add(lease * 9 / 10)
+	}
+	return b.token, nil
+}
+
+func (b *vaultBackend) do(ctx context.Context, method string, path string, token string, payload []byte) (*vaultResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, method, b.config.Address+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if b.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", b.config.Namespace)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, SecretBackendOutputMaxSizeDefault))
+	if err != nil {
+		return nil, err
+	}
+
+	var response vaultResponse
+	// error responses may not be JSON, they are still reported with their status code
+	unmarshalErr := json.Unmarshal(body, &response)
+	if resp.StatusCode != http.StatusOK {
+		return nil, &vaultError{statusCode: resp.StatusCode, errors: response.Errors}
+	}
+	if unmarshalErr != nil {
+		return nil, fmt.Errorf("could not unmarshal vault response: %s", unmarshalErr)
+	}
+	return &response, nil
+}
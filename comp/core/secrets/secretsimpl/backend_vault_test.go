@@ -0,0 +1,215 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package secretsimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault is a local stand-in for a Vault server with a KV v1 and a KV v2 engine
+type fakeVault struct {
+	lock          sync.Mutex
+	tokens        map[string]bool
+	logins        int
+	leaseDuration int
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	vault := &fakeVault{tokens: map[string]bool{"root-token": true}, leaseDuration: 3600}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/auth/kubernetes/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["role"] != "datadog" || body["jwt"] != "service-account-jwt" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["invalid role or jwt"]}`))
+			return
+		}
+		vault.lock.Lock()
+		vault.logins++
+		vault.tokens["login-token"] = true
+		leaseDuration := vault.leaseDuration
+		vault.lock.Unlock()
+		fmt.Fprintf(w, `{"auth":{"client_token":"login-token","lease_duration":%d}}`, leaseDuration)
+	})
+	mux.HandleFunc("/v1/", func(w http.ResponseWriter, r *http.Request) {
+		vault.lock.Lock()
+		valid := vault.tokens[r.Header.Get("X-Vault-Token")]
+		vault.lock.Unlock()
+		if !valid || r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/data/db":
+			w.Write([]byte(`{"data":{"data":{"password":"v2-password","port":5432},"metadata":{"version":3}}}`))
+		case "/v1/secret/db":
+			w.Write([]byte(`{"data":{"password":"v1-password"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return vault, server
+}
+
+func (v *fakeVault) revoke(token string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	delete(v.tokens, token)
+}
+
+func TestVaultBackendToken(t *testing.T) {
+	_, server := newFakeVault(t)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("root-token\n"), 0600))
+
+	for name, config := range map[string]map[string]interface{}{
+		"token":      {"address": server.URL, "namespace": "team", "token": "root-token"},
+		"token_file": {"address": server.URL, "namespace": "team", "token_file": tokenFile},
+	} {
+		t.Run(name, func(t *testing.T) {
+			backend, err := newVaultBackend(config, time.Second)
+			require.NoError(t, err)
+
+			value, err := backend.fetch(context.Background(), "kv/data/db#password")
+			require.NoError(t, err)
+			assert.Equal(t, "v2-password", value)
+
+			value, err = backend.fetch(context.Background(), "secret/db#password")
+			require.NoError(t, err)
+			assert.Equal(t, "v1-password", value)
+
+			_, err = backend.fetch(context.Background(), "kv/data/db#user")
+			assert.EqualError(t, err, "key user not found in secret kv/data/db")
+
+			_, err = backend.fetch(context.Background(), "kv/data/db#port")
+			assert.EqualError(t, err, "key port of secret kv/data/db is not a string")
+
+			_, err = backend.fetch(context.Background(), "kv/data/unknown#password")
+			assert.EqualError(t, err, "vault returned status code 404")
+
+			_, err = backend.fetch(context.Background(), "kv/data/db")
+			assert.EqualError(t, err, `invalid format. Use: "<path>#<key>"`)
+		})
+	}
+
+	backend, err := newVaultBackend(map[string]interface{}{"address": server.URL, "namespace": "team", "token": "wrong"}, time.Second)
+	require.NoError(t, err)
+	_, err = backend.fetch(context.Background(), "kv/data/db#password")
+	assert.EqualError(t, err, "vault returned status code 403: permission denied")
+}
+
+func TestVaultBackendKubernetesAuth(t *testing.T) {
+	vault, server := newFakeVault(t)
+	jwtFile := filepath.Join(t.TempDir(), "jwt")
+	require.NoError(t, os.WriteFile(jwtFile, []byte("service-account-jwt"), 0600))
+
+	backend, err := newVaultBackend(map[string]interface{}{
+		"address":     server.URL,
+		"namespace":   "team",
+		"auth_method": "kubernetes",
+		"role":        "datadog",
+		"jwt_file":    jwtFile,
+	}, time.Second)
+	require.NoError(t, err)
+
+	value, err := backend.fetch(context.Background(), "kv/data/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "v2-password", value)
+	_, err = backend.fetch(context.Background(), "secret/db#password")
+	require.NoError(t, err)
+	// the token is reused until the end of its lease
+	assert.Equal(t, 1, vault.logins)
+
+	// a revoked token triggers a new login
+	vault.revoke("login-token")
+	value, err = backend.fetch(context.Background(), "kv/data/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "v2-password", value)
+	assert.Equal(t, 2, vault.logins)
+
+	backend, err = newVaultBackend(map[string]interface{}{
+		"address":     server.URL,
+		"auth_method": "kubernetes",
+		"role":        "other",
+		"jwt_file":    jwtFile,
+	}, time.Second)
+	require.NoError(t, err)
+	_, err = backend.fetch(context.Background(), "kv/data/db#password")
+	assert.EqualError(t, err, "unable to log in to vault with the kubernetes auth method: vault returned status code 400: invalid role or jwt")
+}
+
+func TestVaultBackendTokenWithoutLease(t *testing.T) {
+	vault, server := newFakeVault(t)
+	vault.leaseDuration = 0
+	jwtFile := filepath.Join(t.TempDir(), "jwt")
+	require.NoError(t, os.WriteFile(jwtFile, []byte("service-account-jwt"), 0600))
+
+	backend, err := newVaultBackend(map[string]interface{}{
+		"address":     server.URL,
+		"namespace":   "team",
+		"auth_method": "kubernetes",
+		"role":        "datadog",
+		"jwt_file":    jwtFile,
+	}, time.Second)
+	require.NoError(t, err)
+
+	// a token without lease doesn't expire
+	for i := 0; i < 3; i++ {
+		_, err = backend.fetch(context.Background(), "kv/data/db#password")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, vault.logins)
+
+	// it's only renewed once it's rejected
+	vault.revoke("login-token")
+	value, err := backend.fetch(context.Background(), "kv/data/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "v2-password", value)
+	assert.Equal(t, 2, vault.logins)
+}
+
+func TestNewVaultBackendErrors(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("VAULT_TOKEN", "")
+
+	for _, tt := range []struct {
+		config        map[string]interface{}
+		expectedError string
+	}{
+		{config: map[string]interface{}{}, expectedError: "address is required"},
+		{config: map[string]interface{}{"address": "http://vault:8200"}, expectedError: "token or token_file is required for the token auth method"},
+		{config: map[string]interface{}{"address": "http://vault:8200", "auth_method": "kubernetes"}, expectedError: "role is required for the kubernetes auth method"},
+		{config: map[string]interface{}{"address": "http://vault:8200", "auth_method": "approle", "role_id": "id"}, expectedError: "role_id and secret_id_file are required for the approle auth method"},
+		{config: map[string]interface{}{"address": "http://vault:8200", "token": "t", "ca_file": "/does/not/exist"}, expectedError: "unable to read CA file: open /does/not/exist: no such file or directory"},
+	} {
+		_, err := newVaultBackend(tt.config, time.Second)
+		assert.EqualError(t, err, tt.expectedError)
+	}
+
+	t.Setenv("VAULT_ADDR", "http://vault:8200")
+	t.Setenv("VAULT_TOKEN", "root-token")
+	backend, err := newVaultBackend(nil, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "http://vault:8200", backend.(*vaultBackend).config.Address)
+	assert.Equal(t, "root-token", backend.(*vaultBackend).config.Token)
+}
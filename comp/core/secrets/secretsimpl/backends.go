@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package secretsimpl
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+)
+
+// nativeBackend fetches secrets in-process, without invoking the secret_backend_command
+type nativeBackend interface {
+	fetch(ctx context.Context, secret string) (string, error)
+}
+
+type nativeBackendFactory func(config map[string]interface{}, timeout time.Duration) (nativeBackend, error)
+
+var nativeBackendFactories = map[string]nativeBackendFactory{
+	"env":        newEnvBackend,
+	"file":       newFileBackend,
+	"kubernetes": newKubernetesBackend,
+	"vault":      newVaultBackend,
+}
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// registeredBackend is a native backend selected by the prefix of the handles, along with
+// the values it fetched
+type registeredBackend struct {
+	backendType string
+	prefix      string
+	ttl         time.Duration
+	timeout     time.Duration
+	backend     nativeBackend
+	cache       map[string]cachedSecret
+}
+
+func newRegisteredBackend(config secrets.BackendConfig, timeout time.Duration) (*registeredBackend, error) {
+	factory, ok := nativeBackendFactories[config.Type]
+	if !ok {
+		types := make([]string, 0, len(nativeBackendFactories))
+		for backendType := range nativeBackendFactories {
+			types = append(types, backendType)
+		}
+		sort.Strings(types)
+		return nil, fmt.Errorf("unknown secret backend type '%s', expected one of %s", config.Type, strings.Join(types, ", "))
+	}
+	prefix := config.Prefix
+	if prefix == "" {
+		prefix = config.Type
+	}
+	if strings.Contains(prefix, ":") {
+		return nil, fmt.Errorf("invalid secret backend prefix '%s': it can't contain ':'", prefix)
+	}
+	backend, err := factory(config.Config, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid '%s' secret backend configuration: %s", prefix, err)
+	}
+	return &registeredBackend{
+		backendType: config.Type,
+		prefix:      prefix,
+		ttl:         time.Duration(config.TTL) * time.Second,
+		timeout:     timeout,
+		backend:     backend,
+		cache:       make(map[string]cachedSecret),
+	}, nil
+}
+
+// get returns the value of the secret, from the cache if it was fetched less than ttl ago
+func (b *registeredBackend) get(secret string, now time.Time) (string, error) {
+	if cached, ok := b.cache[secret]; ok && now.Before(cached.expires) {
+		return cached.value, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	value, err := b.backend.fetch(ctx, secret)
+	if err != nil {
+		return "", err
+	}
+	if b.ttl > 0 {
+		b.cache[secret] = cachedSecret{value: value, expires: now.Add(b.ttl)}
+	}
+	return value, nil
+}
+
+// splitHandle splits a handle of the form `<prefix>:<secret>`
+func splitHandle(handle string) (string, string, bool) {
+	return strings.Cut(handle, ":")
+}
+
+// unmarshalBackendConfig decodes the settings of a backend into its configuration struct,
+// rejecting unknown settings
+func unmarshalBackendConfig(config map[string]interface{}, out interface{}) error {
+	if len(config) == 0 {
+		return nil
+	}
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return yaml.UnmarshalStrict(data, out)
+}
+
+// newBackendHTTPClient returns an HTTP client trusting the certificate authority of caFile
+// in addition to the system ones
+func newBackendHTTPClient(caFile string, skipVerify bool, timeout time.Duration) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: skipVerify, //nolint:gosec // explicitly enabled by the user
+	}
+	if caFile != "" {
+		caCert, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA file: %s", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in CA file %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}, nil
+}
+
+// readTokenFile reads a token file, it's read on every request since tokens are rotated
+func readTokenFile(tokenFile string) (string, error) {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("unable to read token file: %s", err)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
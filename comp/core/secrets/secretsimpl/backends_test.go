@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package secretsimpl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	"github.com/DataDog/datadog-agent/comp/core/telemetry"
+	nooptelemetry "github.com/DataDog/datadog-agent/comp/core/telemetry/noopsimpl"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// countingBackend returns the number of times it was called
+type countingBackend struct {
+	calls int
+}
+
+func (b *countingBackend) fetch(_ context.Context, secret string) (string, error) {
+	b.calls++
+	return fmt.Sprintf("%s-%d", secret, b.calls), nil
+}
+
+func TestConfigureBackends(t *testing.T) {
+	tel := fxutil.Test[telemetry.Component](t, nooptelemetry.Module())
+	resolver := newEnabledSecretResolver(tel)
+	resolver.Configure(secrets.ConfigParams{
+		Backends: []secrets.BackendConfig{
+			{Type: "env"},
+			{Type: "file", Prefix: "secrets", TTL: 60, Config: map[string]interface{}{"root": t.TempDir()}},
+			// duplicated prefix
+			{Type: "file", Prefix: "env"},
+			// unknown type
+			{Type: "aws"},
+			// invalid prefix
+			{Type: "env", Prefix: "a:b"},
+			// unknown setting
+			{Type: "file", Prefix: "file2", Config: map[string]interface{}{"path": "/run/secrets"}},
+			// invalid vault configuration
+			{Type: "vault", Config: map[string]interface{}{"address": "http://127.0.0.1:8200", "auth_method": "kubernetes"}},
+		},
+	})
+
+	require.Len(t, resolver.backends, 2)
+	assert.Equal(t, "env", resolver.backends["env"].backendType)
+	assert.Equal(t, "file", resolver.backends["secrets"].backendType)
+	assert.Equal(t, time.Minute, resolver.backends["secrets"].ttl)
+	assert.True(t, resolver.isConfigured())
+}
+
+func TestNewRegisteredBackendErrors(t *testing.T) {
+	_, err := newRegisteredBackend(secrets.BackendConfig{Type: "aws"}, time.Second)
+	assert.EqualError(t, err, "unknown secret backend type 'aws', expected one of env, file, kubernetes, vault")
+
+	_, err = newRegisteredBackend(secrets.BackendConfig{Type: "env", Prefix: "a:b"}, time.Second)
+	assert.EqualError(t, err, "invalid secret backend prefix 'a:b': it can't contain ':'")
+
+	_, err = newRegisteredBackend(secrets.BackendConfig{Type: "vault", Prefix: "prod", Config: map[string]interface{}{"address": "http://127.0.0.1:8200", "auth_method": "ldap"}}, time.Second)
+	assert.EqualError(t, err, "invalid 'prod' secret backend configuration: unknown auth_method 'ldap', expected one of token, kubernetes, approle")
+}
+
+func TestResolveWithBackends(t *testing.T) {
+	t.Setenv("TEST_DB_PASSWORD", "env-password")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api_key"), []byte("file-password\n"), 0600))
+
+	tel := fxutil.Test[telemetry.Component](t, nooptelemetry.Module())
+	resolver := newEnabledSecretResolver(tel)
+	resolver.Configure(secrets.ConfigParams{
+		RemoveLinebreak: true,
+		Backends: []secrets.BackendConfig{
+			{Type: "env"},
+			{Type: "file", Config: map[string]interface{}{"root": dir}},
+		},
+	})
+	// handles without a known prefix are sent to the command
+	resolver.fetchHookFunc = func(handles []string) (map[string]string, error) {
+		assert.Equal(t, []string{"arn:aws:secret"}, handles)
+		return map[string]string{"arn:aws:secret": "command-password"}, nil
+	}
+
+	resolved, err := resolver.Resolve([]byte(`instances:
+- password: ENC[env:TEST_DB_PASSWORD]
+  api_key: ENC[file:api_key]
+  token: ENC[arn:aws:secret]
+`), "test")
+	require.NoError(t, err)
+	assert.Equal(t, `instances:
+- api_key: file-password
+  password: env-password
+  token: command-password
+`, string(resolved))
+
+	_, err = resolver.Resolve([]byte(`password: ENC[env:TEST_UNSET_VARIABLE]`), "test")
+	assert.EqualError(t, err, "an error occurred while resolving 'env:TEST_UNSET_VARIABLE' with the 'env' backend: environment variable TEST_UNSET_VARIABLE is not set")
+
+	_, err = resolver.Resolve([]byte(`password: ENC[file:../api_key]`), "test")
+	assert.ErrorContains(t, err, "an error occurred while resolving 'file:../api_key' with the 'file' backend")
+}
+
+func TestResolveWithBackendsNoCommand(t *testing.T) {
+	tel := fxutil.Test[telemetry.Component](t, nooptelemetry.Module())
+	resolver := newEnabledSecretResolver(tel)
+	resolver.Configure(secrets.ConfigParams{
+		Backends: []secrets.BackendConfig{{Type: "env"}},
+	})
+
+	_, err := resolver.Resolve([]byte(`password: ENC[db_password]`), "test")
+	assert.EqualError(t, err, "secret handle 'db_password' does not match any secret backend and no secret_backend_command is set")
+}
+
+func TestBackendTTL(t *testing.T) {
+	// disable the allowlist for the test, let any secret changes happen
+	originalAllowlistPaths := allowlistPaths
+	allowlistPaths = nil
+	defer func() { allowlistPaths = originalAllowlistPaths }()
+
+	tel := fxutil.Test[telemetry.Component](t, nooptelemetry.Module())
+	resolver := newEnabledSecretResolver(tel)
+	clk := clock.NewMock()
+	resolver.timeNow = clk.Now
+	cached := &countingBackend{}
+	uncached := &countingBackend{}
+	resolver.backends = map[string]*registeredBackend{
+		"cached":   {prefix: "cached", ttl: time.Minute, timeout: time.Second, backend: cached, cache: map[string]cachedSecret{}},
+		"uncached": {prefix: "uncached", timeout: time.Second, backend: uncached, cache: map[string]cachedSecret{}},
+	}
+
+	resolved, err := resolver.Resolve([]byte("a: ENC[cached:a]\nb: ENC[uncached:b]\n"), "test")
+	require.NoError(t, err)
+	assert.Equal(t, "a: a-1\nb: b-1\n", string(resolved))
+
+	// the value of the cached backend is still valid
+	clk.Add(30 * time.Second)
+	_, err = resolver.Refresh()
+	require.NoError(t, err)
+	assert.Equal(t, 1, cached.calls)
+	assert.Equal(t, 2, uncached.calls)
+	assert.Equal(t, "a-1", resolver.cache["cached:a"])
+	assert.Equal(t, "b-2", resolver.cache["uncached:b"])
+
+	clk.Add(time.Minute)
+	_, err = resolver.Refresh()
+	require.NoError(t, err)
+	assert.Equal(t, 2, cached.calls)
+	assert.Equal(t, "a-2", resolver.cache["cached:a"])
+}
+
+func TestFileBackend(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "password"), []byte("secret"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "too_large"), bytes.Repeat([]byte("a"), maxSecretFileSize+1), 0600))
+	outside := filepath.Join(t.TempDir(), "outside")
+	require.NoError(t, os.WriteFile(outside, []byte("outside"), 0600))
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "link")))
+
+	backend, err := newFileBackend(map[string]interface{}{"root": root}, time.Second)
+	require.NoError(t, err)
+
+	value, err := backend.fetch(context.Background(), "password")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	value, err = backend.fetch(context.Background(), filepath.Join(root, "password"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	_, err = backend.fetch(context.Background(), "link")
+	assert.ErrorContains(t, err, "is outside of")
+
+	_, err = backend.fetch(context.Background(), outside)
+	assert.ErrorContains(t, err, "is outside of")
+
+	_, err = backend.fetch(context.Background(), "too_large")
+	assert.ErrorContains(t, err, "exceeds max allowed size")
+
+	backend, err = newFileBackend(nil, time.Second)
+	require.NoError(t, err)
+	value, err = backend.fetch(context.Background(), outside)
+	require.NoError(t, err)
+	assert.Equal(t, "outside", value)
+
+	_, err = backend.fetch(context.Background(), "password")
+	assert.EqualError(t, err, "the path of the secret file must be absolute when no root is configured")
+}
+
+func TestDebugInfoWithBackends(t *testing.T) {
+	tel := fxutil.Test[telemetry.Component](t, nooptelemetry.Module())
+	resolver := newEnabledSecretResolver(tel)
+	resolver.Configure(secrets.ConfigParams{
+		Backends: []secrets.BackendConfig{
+			{Type: "env"},
+			{Type: "file", Prefix: "docker", TTL: 300},
+		},
+	})
+	t.Setenv("TEST_DB_PASSWORD", "env-password")
+
+	_, err := resolver.Resolve([]byte(`password: ENC[env:TEST_DB_PASSWORD]`), "test")
+	require.NoError(t, err)
+
+	var buffer bytes.Buffer
+	resolver.GetDebugInfo(&buffer)
+
+	expectedResult := `=== Native secret backends ===
+- 'docker': type 'file', cache TTL 5m0s
+- 'env': type 'env', cache TTL 0s
+
+=== Secrets stats ===
+Number of secrets resolved: 1
+Secrets handle resolved:
+
+- 'env:TEST_DB_PASSWORD':
+	used in 'test' configuration in entry 'password'
+`
+	assert.Equal(t, expectedResult, buffer.String())
+}
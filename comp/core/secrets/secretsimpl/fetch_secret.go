@@ -150,3 +150,54 @@ func (r *secretResolver) fetchSecret(secretsHandle []string) (map[string]string,
 	}
 	return res, nil
 }
+
+// fetchSecrets fetches the handles whose prefix selects a native backend in-process, and
+// the remaining ones with the secret_backend_command
+func (r *secretResolver) fetchSecrets(handles []string) (map[string]string, error) {
+	res := map[string]string{}
+	commandHandles := []string{}
+	for _, handle := range handles {
+		prefix, secret, ok := splitHandle(handle)
+		backend, found := r.backends[prefix]
+		if !ok || !found {
+			commandHandles = append(commandHandles, handle)
+			continue
+		}
+
+		value, err := backend.get(secret, r.timeNow())
+		if err != nil {
+			r.tlmSecretResolveError.Inc("error", handle)
+			return nil, fmt.Errorf("an error occurred while resolving '%s' with the '%s' backend: %s", handle, backend.prefix, err)
+		}
+		if r.removeTrailingLinebreak {
+			value = strings.TrimRight(value, "\r\n")
+		}
+		if value == "" {
+			r.tlmSecretResolveError.Inc("empty", handle)
+			return nil, fmt.Errorf("resolved secret for '%s' is empty", handle)
+		}
+		res[handle] = value
+	}
+	if len(commandHandles) == 0 {
+		return res, nil
+	}
+
+	var commandResponse map[string]string
+	var err error
+	switch {
+	case r.fetchHookFunc != nil:
+		// hook used only for tests
+		commandResponse, err = r.fetchHookFunc(commandHandles)
+	case r.backendCommand == "":
+		return nil, fmt.Errorf("secret handle '%s' does not match any secret backend and no secret_backend_command is set", commandHandles[0])
+	default:
+		commandResponse, err = r.fetchSecret(commandHandles)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for handle, value := range commandResponse {
+		res[handle] = value
+	}
+	return res, nil
+}
@@ -90,12 +90,18 @@ type secretResolver struct {
 	// refresh secrets at a regular interval
 	refreshInterval time.Duration
 	ticker          *time.Ticker
+	// refresh all secrets, instead of only the ones used by the settings of allowlistPaths
+	refreshAllHandles bool
 	// filename to write audit records to
 	auditFilename    string
 	auditFileMaxSize int
 	auditRotRecs     *rotatingNDRecords
 	// subscriptions want to be notified about changes to the secrets
 	subscriptions []secrets.SecretChangeCallback
+	// native backends resolving the handles in-process, by prefix
+	backends map[string]*registeredBackend
+	// can be overridden for testing purposes
+	timeNow func() time.Time
 
 	// can be overridden for testing purposes
 	commandHookFunc func(string) ([]byte, error)
@@ -115,6 +121,8 @@ func newEnabledSecretResolver(telemetry telemetry.Component) *secretResolver {
 		cache:                   make(map[string]string),
 		origin:                  make(handleToContext),
 		enabled:                 true,
+		backends:                make(map[string]*registeredBackend),
+		timeNow:                 time.Now,
 		tlmSecretBackendElapsed: telemetry.NewGauge("secret_backend", "elapsed_ms", []string{"command", "exit_code"}, "Elapsed time of secret backend invocation"),
 		tlmSecretUnmarshalError: telemetry.NewCounter("secret_backend", "unmarshal_errors_count", []string{}, "Count of errors when unmarshalling the output of the secret binary"),
 		tlmSecretResolveError:   telemetry.NewCounter("secret_backend", "resolve_errors_count", []string{"error_kind", "handle"}, "Count of errors when resolving a secret"),
@@ -216,6 +224,7 @@ func (r *secretResolver) Configure(params secrets.ConfigParams) {
 		r.responseMaxSize = SecretBackendOutputMaxSizeDefault
 	}
 	r.refreshInterval = time.Duration(params.RefreshInterval) * time.Second
+	r.refreshAllHandles = params.RefreshAllHandles
 	r.commandAllowGroupExec = params.GroupExecPerm
 	r.removeTrailingLinebreak = params.RemoveLinebreak
 	if r.commandAllowGroupExec {
@@ -226,6 +235,25 @@ func (r *secretResolver) Configure(params secrets.ConfigParams) {
 	if r.auditFileMaxSize == 0 {
 		r.auditFileMaxSize = SecretAuditFileMaxSizeDefault
 	}
+
+	r.backends = make(map[string]*registeredBackend)
+	for _, backendConfig := range params.Backends {
+		backend, err := newRegisteredBackend(backendConfig, time.Duration(r.backendTimeout)*time.Second)
+		if err != nil {
+			log.Errorf("Ignoring secret backend: %s", err)
+			continue
+		}
+		if _, ok := r.backends[backend.prefix]; ok {
+			log.Errorf("Ignoring secret backend: prefix '%s' is already used by another backend", backend.prefix)
+			continue
+		}
+		r.backends[backend.prefix] = backend
+	}
+}
+
+// isConfigured returns whether secrets can be fetched, from the command or from native backends
+func (r *secretResolver) isConfigured() bool {
+	return r.backendCommand != "" || len(r.backends) > 0
 }
 
 func isEnc(str string) (bool, string) {
@@ -271,7 +299,7 @@ func (r *secretResolver) Resolve(data []byte, origin string) ([]byte, error) {
 		log.Infof("Agent secrets is disabled by caller")
 		return nil, nil
 	}
-	if data == nil || r.backendCommand == "" {
+	if data == nil || !r.isConfigured() {
 		return data, nil
 	}
 
@@ -322,14 +350,7 @@ func (r *secretResolver) Resolve(data []byte, origin string) ([]byte, error) {
 
 	// check if any new secrets need to be fetch
 	if len(newHandles) != 0 {
-		var secretResponse map[string]string
-		var err error
-		if r.fetchHookFunc != nil {
-			// hook used only for tests
-			secretResponse, err = r.fetchHookFunc(newHandles)
-		} else {
-			secretResponse, err = r.fetchSecret(newHandles)
-		}
+		secretResponse, err := r.fetchSecrets(newHandles)
 		if err != nil {
 			return nil, err
 		}
@@ -441,9 +462,12 @@ func (r *secretResolver) Refresh() (string, error) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
+	// the allowlist doesn't apply when all the secrets are refreshed
+	useAllowlist := !r.refreshAllHandles
+
 	// get handles from the cache that match the allowlist
 	newHandles := maps.Keys(r.cache)
-	if allowlistPaths != nil {
+	if useAllowlist && allowlistPaths != nil {
 		filteredHandles := make([]string, 0, len(newHandles))
 		for _, handle := range newHandles {
 			if r.matchesAllowlist(handle) {
@@ -458,21 +482,14 @@ func (r *secretResolver) Refresh() (string, error) {
 
 	log.Infof("Refreshing secrets for %d handles", len(newHandles))
 
-	var secretResponse map[string]string
-	var err error
-	if r.fetchHookFunc != nil {
-		// hook used only for tests
-		secretResponse, err = r.fetchHookFunc(newHandles)
-	} else {
-		secretResponse, err = r.fetchSecret(newHandles)
-	}
+	secretResponse, err := r.fetchSecrets(newHandles)
 	if err != nil {
 		return "", err
 	}
 
 	var auditRecordErr error
-	// when Refreshing secrets, only update what the allowlist allows by passing `true`
-	refreshResult := r.processSecretResponse(secretResponse, true)
+	// when Refreshing secrets, only update what the allowlist allows, unless all secrets are refreshed
+	refreshResult := r.processSecretResponse(secretResponse, useAllowlist)
 	if len(refreshResult.Handles) > 0 {
 		// add the results to the audit file, if any secrets have new values
 		if err := r.addToAuditFile(secretResponse); err != nil {
@@ -551,9 +568,16 @@ type secretInfo struct {
 	ExecutablePermissions        string
 	ExecutablePermissionsDetails interface{}
 	ExecutablePermissionsError   string
+	Backends                     []backendInfo
 	Handles                      map[string][][]string
 }
 
+type backendInfo struct {
+	Prefix string
+	Type   string
+	TTL    time.Duration
+}
+
 type secretRefreshInfo struct {
 	Handles []handleInfo
 }
@@ -580,7 +604,7 @@ func (r *secretResolver) GetDebugInfo(w io.Writer) {
 		fmt.Fprintf(w, "Agent secrets is disabled by caller")
 		return
 	}
-	if r.backendCommand == "" {
+	if !r.isConfigured() {
 		fmt.Fprintf(w, "No secret_backend_command set: secrets feature is not enabled")
 		return
 	}
@@ -598,24 +622,29 @@ func (r *secretResolver) GetDebugInfo(w io.Writer) {
 		return
 	}
 
-	err = checkRights(r.backendCommand, r.commandAllowGroupExec)
-
-	permissions := "OK, the executable has the correct permissions"
-	if err != nil {
-		permissions = fmt.Sprintf("error: %s", err)
-	}
-
-	details, err := r.getExecutablePermissions()
 	info := secretInfo{
-		Executable:                   r.backendCommand,
-		ExecutablePermissions:        permissions,
-		ExecutablePermissionsDetails: details,
-		Handles:                      map[string][][]string{},
+		Handles: map[string][][]string{},
 	}
-	if err != nil {
-		info.ExecutablePermissionsError = err.Error()
+	if r.backendCommand != "" {
+		info.Executable = r.backendCommand
+		info.ExecutablePermissions = "OK, the executable has the correct permissions"
+		if err := checkRights(r.backendCommand, r.commandAllowGroupExec); err != nil {
+			info.ExecutablePermissions = fmt.Sprintf("error: %s", err)
+		}
+		details, err := r.getExecutablePermissions()
+		info.ExecutablePermissionsDetails = details
+		if err != nil {
+			info.ExecutablePermissionsError = err.Error()
+		}
 	}
 
+	for _, backend := range r.backends {
+		info.Backends = append(info.Backends, backendInfo{Prefix: backend.prefix, Type: backend.backendType, TTL: backend.ttl})
+	}
+	sort.Slice(info.Backends, func(i, j int) bool {
+		return info.Backends[i].Prefix < info.Backends[j].Prefix
+	})
+
 	// we sort handles so the output is consistent and testable
 	orderedHandles := []string{}
 	for handle := range r.origin {
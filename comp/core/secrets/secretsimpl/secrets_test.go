@@ -755,6 +755,45 @@ func TestRefreshAllowlistAppliesToEachSettingPath(t *testing.T) {
 	assert.Equal(t, changedPaths, []string{"instances/0/password"})
 }
 
+// test that the allowlist doesn't apply when all the secrets are refreshed
+func TestRefreshAllHandles(t *testing.T) {
+	tel := fxutil.Test[telemetry.Component](t, nooptelemetry.Module())
+	resolver := newEnabledSecretResolver(tel)
+	resolver.backendCommand = "some_command"
+	resolver.refreshAllHandles = true
+
+	resolver.fetchHookFunc = func([]string) (map[string]string, error) {
+		return map[string]string{
+			"pass1": "password1",
+		}, nil
+	}
+	resolved, err := resolver.Resolve(testMultiUsageConf, "test")
+	require.NoError(t, err)
+	require.Equal(t, testMultiUsageConfResolved, string(resolved))
+
+	// only allow api_key config setting to change
+	originalAllowlistPaths := allowlistPaths
+	allowlistPaths = map[string]struct{}{"api_key": {}}
+	defer func() { allowlistPaths = originalAllowlistPaths }()
+
+	changedPaths := []string{}
+	resolver.SubscribeToChanges(func(_, _ string, path []string, _, _ any) {
+		changedPaths = append(changedPaths, strings.Join(path, "/"))
+	})
+
+	resolver.fetchHookFunc = func([]string) (map[string]string, error) {
+		return map[string]string{
+			"pass1": "second_password",
+		}, nil
+	}
+
+	// both setting paths got updated
+	_, err = resolver.Refresh()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instances/0/password", "more_endpoints/http://example.com/0"}, changedPaths)
+	assert.Equal(t, "second_password", resolver.cache["pass1"])
+}
+
 // test that adding to the audit file stops working when the file gets too large
 func TestRefreshAddsToAuditFile(t *testing.T) {
 	tmpfile, err := os.CreateTemp("", "")
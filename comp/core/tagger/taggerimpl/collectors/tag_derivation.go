@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package collectors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/taglist"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const tagDerivationRulesConfigKey = "tag_derivation_rules"
+
+// tagDerivationRule computes a tag from the values of another tag of the
+// same entity. The value of the source tag is first matched against the
+// regex, if any, and replaced by the expansion of the replacement. It is then
+// looked up in the mapping, if any, falling back on the default value when
+// it's not found. The derived tag is added at the cardinality of the rule.
+type tagDerivationRule struct {
+	SourceTag   string            `mapstructure:"source_tag" json:"source_tag"`
+	Tag         string            `mapstructure:"tag" json:"tag"`
+	Regex       string            `mapstructure:"regex" json:"regex"`
+	Replacement string            `mapstructure:"replacement" json:"replacement"`
+	Mapping     map[string]string `mapstructure:"mapping" json:"mapping"`
+	Default     string            `mapstructure:"default" json:"default"`
+	Cardinality string            `mapstructure:"cardinality" json:"cardinality"`
+
+	pattern     *regexp.Regexp
+	cardinality types.TagCardinality
+}
+
+// compile validates the rule and prepares it to be applied
+func (r *tagDerivationRule) compile() error {
+	if r.SourceTag == "" || r.Tag == "" {
+		return errors.New("source_tag and tag are required")
+	}
+	if r.Regex == "" && r.Mapping == nil {
+		return errors.New("either regex or mapping is required")
+	}
+	if r.Default != "" && r.Mapping == nil {
+		return errors.New("default requires a mapping")
+	}
+
+	if r.Regex != "" {
+		pattern, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+		r.pattern = pattern
+		if r.Replacement == "" {
+			if pattern.NumSubexp() == 0 {
+				return errors.New("the regex must have a capture group when no replacement is given")
+			}
+			r.Replacement = "$1"
+		}
+	}
+
+	r.cardinality = types.LowCardinality
+	if r.Cardinality != "" {
+		cardinality, err := types.StringToTagCardinality(r.Cardinality)
+		if err != nil {
+			return err
+		}
+		r.cardinality = cardinality
+	}
+
+	return nil
+}
+
+// derive returns the value of the derived tag for the given value of the
+// source tag, or an empty string if the rule doesn't apply to it
+func (r *tagDerivationRule) derive(value string) string {
+	if r.pattern != nil {
+		match := r.pattern.FindStringSubmatchIndex(value)
+		if match == nil {
+			return ""
+		}
+		value = string(r.pattern.ExpandString(nil, r.Replacement, value, match))
+	}
+
+	if r.Mapping != nil {
+		mapped, found := r.Mapping[value]
+		if !found {
+			return r.Default
+		}
+		value = mapped
+	}
+
+	return value
+}
+
+// apply adds the tags derived from the tag list to it
+func (r *tagDerivationRule) apply(tagList *taglist.TagList) {
+	for _, value := range tagList.Values(r.SourceTag) {
+		derived := r.derive(value)
+		switch r.cardinality {
+		case types.HighCardinality:
+			tagList.AddHigh(r.Tag, derived)
+		case types.OrchestratorCardinality:
+			tagList.AddOrchestrator(r.Tag, derived)
+		default:
+			tagList.AddLow(r.Tag, derived)
+		}
+	}
+}
+
+// retrieveTagDerivationRules returns the valid tag derivation rules of the
+// configuration. They can be given as a JSON string when set through the
+// environment. Invalid rules are logged and ignored.
+func retrieveTagDerivationRules(cfg config.Component) []*tagDerivationRule {
+	raw := cfg.Get(tagDerivationRulesConfigKey)
+	if raw == nil {
+		return nil
+	}
+
+	var rules []*tagDerivationRule
+	var err error
+	if s, ok := raw.(string); ok {
+		if s == "" {
+			return nil
+		}
+		err = json.Unmarshal([]byte(s), &rules)
+	} else {
+		err = cfg.UnmarshalKey(tagDerivationRulesConfigKey, &rules)
+	}
+	if err != nil {
+		log.Errorf("Unable to parse %s: %s", tagDerivationRulesConfigKey, err)
+		return nil
+	}
+
+	validRules := make([]*tagDerivationRule, 0, len(rules))
+	for i, rule := range rules {
+		if err := rule.compile(); err != nil {
+			log.Errorf("Ignoring %s[%d]: %s", tagDerivationRulesConfigKey, i, err)
+			continue
+		}
+		validRules = append(validRules, rule)
+	}
+	return validRules
+}
+
+// deriveTags applies the tag derivation rules to the tag list, in order, so
+// that a rule can derive a tag from the ones of the previous rules
+func (c *WorkloadMetaCollector) deriveTags(tagList *taglist.TagList) {
+	for _, rule := range c.tagDerivationRules {
+		rule.apply(tagList)
+	}
+}
@@ -0,0 +1,247 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package collectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/taglist"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	workloadmetafxmock "github.com/DataDog/datadog-agent/comp/core/workloadmeta/fx-mock"
+	workloadmetamock "github.com/DataDog/datadog-agent/comp/core/workloadmeta/mock"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestTagDerivationRuleCompile(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     tagDerivationRule
+		errorMsg string
+	}{
+		{
+			name: "regex",
+			rule: tagDerivationRule{SourceTag: "image_name", Tag: "team", Regex: `^registry\.example\.com/([^/]+)/`},
+		},
+		{
+			name: "mapping",
+			rule: tagDerivationRule{SourceTag: "kube_namespace", Tag: "cost_center", Mapping: map[string]string{"payments": "cc-1234"}, Default: "cc-0000"},
+		},
+		{
+			name:     "missing tag",
+			rule:     tagDerivationRule{SourceTag: "image_name", Regex: "(.*)"},
+			errorMsg: "source_tag and tag are required",
+		},
+		{
+			name:     "no regex nor mapping",
+			rule:     tagDerivationRule{SourceTag: "image_name", Tag: "team"},
+			errorMsg: "either regex or mapping is required",
+		},
+		{
+			name:     "default without mapping",
+			rule:     tagDerivationRule{SourceTag: "image_name", Tag: "team", Regex: "(.*)", Default: "none"},
+			errorMsg: "default requires a mapping",
+		},
+		{
+			name:     "invalid regex",
+			rule:     tagDerivationRule{SourceTag: "image_name", Tag: "team", Regex: "(.*"},
+			errorMsg: "invalid regex",
+		},
+		{
+			name:     "regex without capture group",
+			rule:     tagDerivationRule{SourceTag: "image_name", Tag: "team", Regex: "payments"},
+			errorMsg: "capture group",
+		},
+		{
+			name:     "invalid cardinality",
+			rule:     tagDerivationRule{SourceTag: "image_name", Tag: "team", Regex: "(.*)", Cardinality: "extreme"},
+			errorMsg: "unsupported value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.compile()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestTagDerivationRuleApply(t *testing.T) {
+	tests := []struct {
+		name         string
+		rule         tagDerivationRule
+		expectedLow  []string
+		expectedOrch []string
+		expectedHigh []string
+	}{
+		{
+			name:        "regex with default replacement",
+			rule:        tagDerivationRule{SourceTag: "image_name", Tag: "team", Regex: `^registry\.example\.com/([^/]+)/`},
+			expectedLow: []string{"team:payments"},
+		},
+		{
+			name:        "regex with named groups",
+			rule:        tagDerivationRule{SourceTag: "image_name", Tag: "app", Regex: `^(?P<host>[^/]+)/(?P<team>[^/]+)/(?P<name>.+)$`, Replacement: "${team}-${name}"},
+			expectedLow: []string{"app:payments-api"},
+		},
+		{
+			name: "regex not matching",
+			rule: tagDerivationRule{SourceTag: "image_name", Tag: "team", Regex: `^docker\.io/([^/]+)/`},
+		},
+		{
+			name:         "mapping at orchestrator cardinality",
+			rule:         tagDerivationRule{SourceTag: "kube_namespace", Tag: "cost_center", Mapping: map[string]string{"payments-prod": "cc-1234"}, Cardinality: "orchestrator"},
+			expectedOrch: []string{"cost_center:cc-1234"},
+		},
+		{
+			name:        "mapping default",
+			rule:        tagDerivationRule{SourceTag: "kube_namespace", Tag: "cost_center", Mapping: map[string]string{"billing": "cc-5678"}, Default: "cc-0000"},
+			expectedLow: []string{"cost_center:cc-0000"},
+		},
+		{
+			name: "mapping without match nor default",
+			rule: tagDerivationRule{SourceTag: "kube_namespace", Tag: "cost_center", Mapping: map[string]string{"billing": "cc-5678"}},
+		},
+		{
+			name:         "regex then mapping at high cardinality",
+			rule:         tagDerivationRule{SourceTag: "kube_namespace", Tag: "environment", Regex: `-(\w+)$`, Mapping: map[string]string{"prod": "production"}, Cardinality: "high"},
+			expectedHigh: []string{"environment:production"},
+		},
+		{
+			name: "missing source tag",
+			rule: tagDerivationRule{SourceTag: "kube_deployment", Tag: "team", Regex: `(.*)`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configmock.New(t)
+			require.NoError(t, tt.rule.compile())
+
+			tagList := taglist.NewTagList()
+			tagList.AddLow("image_name", "registry.example.com/payments/api")
+			tagList.AddLow("kube_namespace", "payments-prod")
+			tt.rule.apply(tagList)
+
+			low, orch, high, _ := tagList.Compute()
+			assert.ElementsMatch(t, append([]string{"image_name:registry.example.com/payments/api", "kube_namespace:payments-prod"}, tt.expectedLow...), low)
+			assert.ElementsMatch(t, tt.expectedOrch, orch)
+			assert.ElementsMatch(t, tt.expectedHigh, high)
+		})
+	}
+}
+
+func TestRetrieveTagDerivationRules(t *testing.T) {
+	cfg := configmock.New(t)
+	cfg.SetWithoutSource("tag_derivation_rules", []interface{}{
+		map[string]interface{}{
+			"source_tag": "image_name",
+			"tag":        "team",
+			"regex":      `^registry\.example\.com/([^/]+)/`,
+		},
+		map[string]interface{}{
+			"source_tag": "image_name",
+		},
+		map[string]interface{}{
+			"source_tag":  "kube_namespace",
+			"tag":         "cost_center",
+			"mapping":     map[string]interface{}{"payments": "cc-1234"},
+			"default":     "cc-0000",
+			"cardinality": "orchestrator",
+		},
+	})
+
+	rules := retrieveTagDerivationRules(cfg)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "team", rules[0].Tag)
+	assert.Equal(t, types.LowCardinality, rules[0].cardinality)
+	assert.Equal(t, "cost_center", rules[1].Tag)
+	assert.Equal(t, map[string]string{"payments": "cc-1234"}, rules[1].Mapping)
+	assert.Equal(t, types.OrchestratorCardinality, rules[1].cardinality)
+
+	// rules set through the environment are a JSON string
+	cfg.SetWithoutSource("tag_derivation_rules", `[{"source_tag": "kube_namespace", "tag": "cost_center", "mapping": {"payments": "cc-1234"}}]`)
+	rules = retrieveTagDerivationRules(cfg)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "cost_center", rules[0].Tag)
+
+	cfg.SetWithoutSource("tag_derivation_rules", `not json`)
+	assert.Empty(t, retrieveTagDerivationRules(cfg))
+}
+
+func TestHandleKubePodWithTagDerivationRules(t *testing.T) {
+	cfg := configmock.New(t)
+	cfg.SetWithoutSource("tag_derivation_rules", `[
+		{"source_tag": "kube_namespace", "tag": "cost_center", "mapping": {"payments": "cc-1234"}, "default": "cc-0000"},
+		{"source_tag": "image_name", "tag": "team", "regex": "^registry\\.example\\.com/([^/]+)/"},
+		{"source_tag": "team", "tag": "owner", "regex": "(.+)", "replacement": "team-$1"}
+	]`)
+
+	containerID := "foobarquux"
+	pod := &workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesPod,
+			ID:   "foobar",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "api-7d9f",
+			Namespace: "payments",
+		},
+		Containers: []workloadmeta.OrchestratorContainer{
+			{
+				ID:   containerID,
+				Name: "api",
+				Image: workloadmeta.ContainerImage{
+					Name: "registry.example.com/payments/api",
+				},
+			},
+		},
+	}
+
+	store := fxutil.Test[workloadmetamock.Mock](t, fx.Options(
+		fx.Provide(func() log.Component { return logmock.New(t) }),
+		config.MockModule(),
+		fx.Supply(context.Background()),
+		workloadmetafxmock.MockModule(workloadmeta.NewParams()),
+	))
+	store.Set(&workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindContainer,
+			ID:   containerID,
+		},
+	})
+	collector := NewWorkloadMetaCollector(context.Background(), cfg, store, nil)
+
+	tagInfos := collector.handleKubePod(workloadmeta.Event{
+		Type:   workloadmeta.EventTypeSet,
+		Entity: pod,
+	})
+	require.Len(t, tagInfos, 2)
+
+	// the pod has no image, so only the namespace rule applies
+	assert.Contains(t, tagInfos[0].LowCardTags, "cost_center:cc-1234")
+	assert.NotContains(t, tagInfos[0].LowCardTags, "team:payments")
+
+	// the container gets the tags derived from its image too, and the ones
+	// derived from them by the following rules
+	assert.Contains(t, tagInfos[1].LowCardTags, "cost_center:cc-1234")
+	assert.Contains(t, tagInfos[1].LowCardTags, "team:payments")
+	assert.Contains(t, tagInfos[1].LowCardTags, "owner:team-payments")
+}
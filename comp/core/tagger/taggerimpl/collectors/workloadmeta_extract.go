@@ -150,6 +150,8 @@ func (c *WorkloadMetaCollector) processEvents(evBundle workloadmeta.EventBundle)
 				// tagInfos = append(tagInfos, c.handleProcess(ev)...) No tags for now
 			case workloadmeta.KindKubernetesDeployment:
 				tagInfos = append(tagInfos, c.handleKubeDeployment(ev)...)
+			case workloadmeta.KindSystemdUnit:
+				tagInfos = append(tagInfos, c.handleSystemdUnit(ev)...)
 			default:
 				log.Errorf("cannot handle event for entity %q with kind %q", entityID.ID, entityID.Kind)
 			}
@@ -230,6 +232,8 @@ func (c *WorkloadMetaCollector) handleContainer(ev workloadmeta.Event) []*types.
 		tagList.AddLow(tag, value)
 	}
 
+	c.deriveTags(tagList)
+
 	low, orch, high, standard := tagList.Compute()
 	return []*types.TagInfo{
 		{
@@ -280,6 +284,8 @@ func (c *WorkloadMetaCollector) handleContainerImage(ev workloadmeta.Event) []*t
 
 	c.labelsToTags(image.Labels, tagList)
 
+	c.deriveTags(tagList)
+
 	low, orch, high, standard := tagList.Compute()
 	return []*types.TagInfo{
 		{
@@ -408,6 +414,8 @@ func (c *WorkloadMetaCollector) handleKubePod(ev workloadmeta.Event) []*types.Ta
 		tagList.AddLow(tag, value)
 	}
 
+	c.deriveTags(tagList)
+
 	low, orch, high, standard := tagList.Compute()
 	tagInfos := []*types.TagInfo{
 		{
@@ -480,6 +488,8 @@ func (c *WorkloadMetaCollector) handleECSTask(ev workloadmeta.Event) []*types.Ta
 
 		tagList.AddLow(tags.EcsContainerName, taskContainer.Name)
 
+		c.deriveTags(tagList)
+
 		low, orch, high, standard := tagList.Compute()
 		tagInfos = append(tagInfos, &types.TagInfo{
 			// taskSource here is not a mistake. the source is
@@ -494,6 +504,8 @@ func (c *WorkloadMetaCollector) handleECSTask(ev workloadmeta.Event) []*types.Ta
 	}
 
 	if task.LaunchType == workloadmeta.ECSLaunchTypeFargate {
+		c.deriveTags(taskTags)
+
 		low, orch, high, standard := taskTags.Compute()
 		tagInfos = append(tagInfos, &types.TagInfo{
 			Source:               taskSource,
@@ -543,6 +555,8 @@ func (c *WorkloadMetaCollector) handleKubeDeployment(ev workloadmeta.Event) []*t
 		k8smetadata.AddMetadataAsTags(name, value, annotationsAsTags, globAnnotations, tagList)
 	}
 
+	c.deriveTags(tagList)
+
 	low, orch, high, standard := tagList.Compute()
 
 	if len(low)+len(orch)+len(high)+len(standard) == 0 {
@@ -585,6 +599,8 @@ func (c *WorkloadMetaCollector) handleKubeMetadata(ev workloadmeta.Event) []*typ
 		k8smetadata.AddMetadataAsTags(name, value, annotationsAsTags, globAnnotations, tagList)
 	}
 
+	c.deriveTags(tagList)
+
 	low, orch, high, standard := tagList.Compute()
 
 	if len(low)+len(orch)+len(high)+len(standard) == 0 {
@@ -605,6 +621,30 @@ func (c *WorkloadMetaCollector) handleKubeMetadata(ev workloadmeta.Event) []*typ
 	return tagInfos
 }
 
+func (c *WorkloadMetaCollector) handleSystemdUnit(ev workloadmeta.Event) []*types.TagInfo {
+	unit := ev.Entity.(*workloadmeta.SystemdUnit)
+
+	tagList := taglist.NewTagList()
+	tagList.AddLow(tags.SystemdUnit, unit.Name)
+
+	// standard tags from the environment of the unit
+	c.extractFromMapWithFn(unit.EnvVars, standardEnvKeys, tagList.AddStandard)
+
+	c.deriveTags(tagList)
+
+	low, orch, high, standard := tagList.Compute()
+	return []*types.TagInfo{
+		{
+			Source:               systemdUnitSource,
+			EntityID:             common.BuildTaggerEntityID(unit.EntityID),
+			HighCardTags:         high,
+			OrchestratorCardTags: orch,
+			LowCardTags:          low,
+			StandardTags:         standard,
+		},
+	}
+}
+
 func (c *WorkloadMetaCollector) extractTagsFromPodLabels(pod *workloadmeta.KubernetesPod, tagList *taglist.TagList) {
 	for name, value := range pod.Labels {
 		switch name {
@@ -713,6 +753,8 @@ func (c *WorkloadMetaCollector) extractTagsFromPodContainer(pod *workloadmeta.Ku
 	annotation := fmt.Sprintf(podContainerTagsAnnotationFormat, containerName)
 	c.extractTagsFromJSONInMap(annotation, pod.Annotations, tagList)
 
+	c.deriveTags(tagList)
+
 	low, orch, high, standard := tagList.Compute()
 	return &types.TagInfo{
 		// podSource here is not a mistake. the source is
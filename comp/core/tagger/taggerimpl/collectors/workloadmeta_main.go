@@ -37,6 +37,7 @@ const (
 	hostSource           = workloadmetaCollectorName + "-" + string(workloadmeta.KindHost)
 	kubeMetadataSource   = workloadmetaCollectorName + "-" + string(workloadmeta.KindKubernetesMetadata)
 	deploymentSource     = workloadmetaCollectorName + "-" + string(workloadmeta.KindKubernetesDeployment)
+	systemdUnitSource    = workloadmetaCollectorName + "-" + string(workloadmeta.KindSystemdUnit)
 
 	clusterTagNamePrefix = "kube_cluster_name"
 )
@@ -66,6 +67,8 @@ type WorkloadMetaCollector struct {
 	globK8sResourcesAnnotations   map[string]map[string]glob.Glob
 	globK8sResourcesLabels        map[string]map[string]glob.Glob
 
+	tagDerivationRules []*tagDerivationRule
+
 	collectEC2ResourceTags            bool
 	collectPersistentVolumeClaimsTags bool
 }
@@ -189,6 +192,8 @@ func NewWorkloadMetaCollector(_ context.Context, cfg config.Component, store wor
 	metadataAsTags := configutils.GetMetadataAsTags(cfg)
 	c.initK8sResourcesMetaAsTags(metadataAsTags.GetResourcesLabelsAsTags(), metadataAsTags.GetResourcesAnnotationsAsTags())
 
+	c.tagDerivationRules = retrieveTagDerivationRules(cfg)
+
 	return c
 }
 
@@ -2167,6 +2167,52 @@ func TestHandleContainerImage(t *testing.T) {
 	}
 }
 
+func TestHandleSystemdUnit(t *testing.T) {
+	entityID := workloadmeta.EntityID{
+		Kind: workloadmeta.KindSystemdUnit,
+		ID:   "nginx.service",
+	}
+
+	cfg := configmock.New(t)
+	collector := NewWorkloadMetaCollector(context.Background(), cfg, nil, nil)
+
+	actual := collector.handleSystemdUnit(workloadmeta.Event{
+		Type: workloadmeta.EventTypeSet,
+		Entity: &workloadmeta.SystemdUnit{
+			EntityID: entityID,
+			EntityMeta: workloadmeta.EntityMeta{
+				Name: "nginx.service",
+			},
+			MainPID: 1234,
+			EnvVars: map[string]string{
+				"DD_SERVICE": "web",
+				"DD_ENV":     "prod",
+				"DD_VERSION": "1.25",
+			},
+		},
+	})
+
+	assertTagInfoListEqual(t, []*types.TagInfo{
+		{
+			Source:               systemdUnitSource,
+			EntityID:             types.NewEntityID(types.SystemdUnit, entityID.ID),
+			HighCardTags:         []string{},
+			OrchestratorCardTags: []string{},
+			LowCardTags: []string{
+				"env:prod",
+				"service:web",
+				"systemd_unit:nginx.service",
+				"version:1.25",
+			},
+			StandardTags: []string{
+				"env:prod",
+				"service:web",
+				"version:1.25",
+			},
+		},
+	}, actual)
+}
+
 func TestHandleDelete(t *testing.T) {
 	const (
 		podName       = "datadog-agent-foobar"
@@ -36,6 +36,7 @@ type Tagger struct {
 
 	ctx            context.Context
 	cancel         context.CancelFunc
+	snapshotsDone  chan struct{}
 	telemetryStore *telemetry.Store
 	empty.Tagger
 }
@@ -55,6 +56,14 @@ func NewTagger(cfg config.Component, workloadStore workloadmeta.Component, telem
 func (t *Tagger) Start(ctx context.Context) error {
 	t.ctx, t.cancel = context.WithCancel(ctx)
 
+	// the snapshot must be restored before the collectors run, so that it
+	// doesn't override the tags they collect
+	if t.warmStartEnabled() {
+		t.restoreSnapshot()
+		t.snapshotsDone = make(chan struct{})
+		go t.writeSnapshots(t.ctx)
+	}
+
 	t.collector = collectors.NewWorkloadMetaCollector(
 		t.ctx,
 		t.cfg,
@@ -68,9 +77,13 @@ func (t *Tagger) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop queues a shutdown of Tagger
+// Stop queues a shutdown of Tagger. It waits for the last snapshot of the
+// tag store to be written when warm start is enabled.
 func (t *Tagger) Stop() error {
 	t.cancel()
+	if t.snapshotsDone != nil {
+		<-t.snapshotsDone
+	}
 	return nil
 }
 
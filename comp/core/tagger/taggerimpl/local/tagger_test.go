@@ -7,6 +7,7 @@ package local
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -65,3 +66,41 @@ func TestTagBuilder(t *testing.T) {
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, []string{"high", "low1", "low2"}, tb.Get())
 }
+
+func TestWarmStart(t *testing.T) {
+	entityID := types.NewEntityID(types.ContainerID, "abc")
+
+	store := fxutil.Test[workloadmetamock.Mock](t, fx.Options(
+		fx.Supply(config.Params{}),
+		fx.Supply(log.Params{}),
+		fx.Provide(func() log.Component { return logmock.New(t) }),
+		config.MockModule(),
+		workloadmetafxmock.MockModule(workloadmeta.NewParams()),
+	))
+
+	tel := fxutil.Test[telemetry.Component](t, telemetryimpl.MockModule())
+	telemetryStore := taggerTelemetry.NewStore(tel)
+	cfg := configmock.New(t)
+	cfg.SetWithoutSource("tagger.warm_start.enabled", true)
+	cfg.SetWithoutSource("tagger.warm_start.path", filepath.Join(t.TempDir(), "tagger-snapshot.json"))
+
+	tagger := NewTagger(cfg, store, telemetryStore)
+	tagger.Start(context.Background())
+	tagger.tagStore.ProcessTagInfo([]*types.TagInfo{
+		{
+			EntityID:    entityID,
+			Source:      "workloadmeta-container",
+			LowCardTags: []string{"image_name:redis"},
+		},
+	})
+	// the snapshot is written when the tagger stops
+	tagger.Stop()
+
+	restartedTagger := NewTagger(cfg, store, telemetryStore)
+	restartedTagger.Start(context.Background())
+	defer restartedTagger.Stop()
+
+	tags, err := restartedTagger.Tag(entityID.String(), types.LowCardinality)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"image_name:redis"}, tags)
+}
@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package local
+
+import (
+	"context"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// warmStartEnabled returns whether the tag store is persisted on disk to be
+// restored at startup, so that data isn't left untagged while the collectors
+// repopulate the store after a restart
+func (t *Tagger) warmStartEnabled() bool {
+	return t.cfg.GetBool("tagger.warm_start.enabled") && t.cfg.GetString("tagger.warm_start.path") != ""
+}
+
+// restoreSnapshot loads the last snapshot of the tag store, if any
+func (t *Tagger) restoreSnapshot() {
+	path := t.cfg.GetString("tagger.warm_start.path")
+	restored, err := t.tagStore.RestoreSnapshot(
+		path,
+		t.cfg.GetDuration("tagger.warm_start.grace_period"),
+		t.cfg.GetDuration("tagger.warm_start.max_age"),
+	)
+	if err != nil {
+		log.Warnf("Unable to restore the tagger snapshot from %s: %s", path, err)
+		return
+	}
+	log.Infof("Restored %d tagger entries from %s", restored, path)
+}
+
+// writeSnapshots periodically writes a snapshot of the tag store until the
+// context is cancelled, and a last one before returning
+func (t *Tagger) writeSnapshots(ctx context.Context) {
+	defer close(t.snapshotsDone)
+
+	path := t.cfg.GetString("tagger.warm_start.path")
+	interval := t.cfg.GetDuration("tagger.warm_start.snapshot_interval")
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.tagStore.WriteSnapshot(path); err != nil {
+				log.Warnf("Unable to write the tagger snapshot to %s: %s", path, err)
+			}
+
+		case <-ctx.Done():
+			if err := t.tagStore.WriteSnapshot(path); err != nil {
+				log.Warnf("Unable to write the tagger snapshot to %s: %s", path, err)
+			}
+			return
+		}
+	}
+}
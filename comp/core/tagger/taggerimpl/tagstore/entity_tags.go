@@ -333,6 +333,8 @@ func (e *EntityTagsWithSingleSource) setTagsForSource(source string, tags source
 	}
 
 	e.standardTags = tags.standardTags
+	e.expiryDate = tags.expiryDate
+	e.isExpired = false
 
 	all := make([]string, 0, len(tags.lowCardTags)+len(tags.orchestratorCardTags)+len(tags.highCardTags))
 	all = append(all, tags.lowCardTags...)
@@ -181,6 +181,29 @@ func TestDeleteSource(t *testing.T) {
 	assert.Equal(t, expiryDate, entityTags.expiryDate)
 }
 
+func TestSetTagsForSourceWithExpiryDate(t *testing.T) {
+	expiryDate := time.Now()
+
+	entityTags := newEntityTagsWithSingleSource(testEntityID, testSource)
+	entityTags.setTagsForSource(testSource, sourceTags{
+		lowCardTags: []string{"l1:v1"},
+		expiryDate:  expiryDate,
+	})
+	assert.Equal(t, expiryDate, entityTags.tagsForSource(testSource).expiryDate)
+
+	assert.False(t, entityTags.deleteExpired(expiryDate.Add(-time.Minute)))
+	assert.False(t, entityTags.shouldRemove())
+	assert.True(t, entityTags.deleteExpired(expiryDate.Add(time.Minute)))
+	assert.True(t, entityTags.shouldRemove())
+
+	// tags set again without expiry date don't expire anymore
+	entityTags.setTagsForSource(testSource, sourceTags{
+		lowCardTags: []string{"l1:v1"},
+	})
+	assert.False(t, entityTags.deleteExpired(expiryDate.Add(time.Minute)))
+	assert.False(t, entityTags.shouldRemove())
+}
+
 func TestDeleteExpired(t *testing.T) {
 	expiryDate := time.Now()
 
@@ -0,0 +1,164 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tagstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// snapshotVersion is the version of the snapshot format. Snapshots of
+// another version are ignored.
+const snapshotVersion = 1
+
+// snapshot is the content of the tag store persisted on disk to warm-start
+// the tagger after a restart
+type snapshot struct {
+	Version   int             `json:"version"`
+	Timestamp time.Time       `json:"timestamp"`
+	Entries   []snapshotEntry `json:"entries"`
+}
+
+// snapshotEntry holds the tags of an entity collected from a single source
+type snapshotEntry struct {
+	EntityID             string     `json:"entity_id"`
+	Source               string     `json:"source"`
+	LowCardTags          []string   `json:"low_card_tags,omitempty"`
+	OrchestratorCardTags []string   `json:"orchestrator_card_tags,omitempty"`
+	HighCardTags         []string   `json:"high_card_tags,omitempty"`
+	StandardTags         []string   `json:"standard_tags,omitempty"`
+	ExpiryDate           *time.Time `json:"expiry_date,omitempty"`
+}
+
+// WriteSnapshot writes the content of the store to the given file. The file
+// is replaced atomically, so that a crash while writing it doesn't corrupt
+// the previous snapshot.
+func (s *TagStore) WriteSnapshot(path string) error {
+	snap := snapshot{
+		Version:   snapshotVersion,
+		Timestamp: s.clock.Now(),
+	}
+
+	s.RLock()
+	now := s.clock.Now()
+	s.store.ForEach(nil, func(eid types.EntityID, et EntityTags) {
+		for _, source := range et.sources() {
+			st := et.tagsForSource(source)
+			if st == nil || st.isEmpty() || st.isExpired(now) {
+				continue
+			}
+
+			entry := snapshotEntry{
+				EntityID:             eid.String(),
+				Source:               source,
+				LowCardTags:          st.lowCardTags,
+				OrchestratorCardTags: st.orchestratorCardTags,
+				HighCardTags:         st.highCardTags,
+				StandardTags:         st.standardTags,
+			}
+			if !st.expiryDate.IsZero() {
+				expiryDate := st.expiryDate
+				entry.ExpiryDate = &expiryDate
+			}
+			snap.Entries = append(snap.Entries, entry)
+		}
+	})
+	content, err := json.Marshal(snap)
+	s.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("unable to serialize the tagger snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("unable to create the tagger snapshot directory: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("unable to write the tagger snapshot: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("unable to write the tagger snapshot: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("unable to write the tagger snapshot: %w", err)
+	}
+
+	return os.Rename(tmpFile.Name(), path)
+}
+
+// RestoreSnapshot loads the tags of the snapshot written in the given file
+// into the store. The snapshot is ignored if it was written more than maxAge
+// ago. The restored tags expire after the grace period, unless their source
+// reconfirms them in the meantime, which clears their expiration date. It
+// returns the number of restored entries.
+func (s *TagStore) RestoreSnapshot(path string, gracePeriod, maxAge time.Duration) (int, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("unable to read the tagger snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(content, &snap); err != nil {
+		return 0, fmt.Errorf("unable to parse the tagger snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return 0, fmt.Errorf("unsupported tagger snapshot version %d", snap.Version)
+	}
+
+	now := s.clock.Now()
+	if maxAge > 0 && snap.Timestamp.Add(maxAge).Before(now) {
+		log.Infof("Ignoring the tagger snapshot written at %s, older than %s", snap.Timestamp, maxAge)
+		return 0, nil
+	}
+
+	graceExpiry := now.Add(gracePeriod)
+	tagInfos := make([]*types.TagInfo, 0, len(snap.Entries))
+	for _, entry := range snap.Entries {
+		expiryDate := graceExpiry
+		if entry.ExpiryDate != nil {
+			if entry.ExpiryDate.Before(now) {
+				continue
+			}
+			if entry.ExpiryDate.Before(expiryDate) {
+				expiryDate = *entry.ExpiryDate
+			}
+		}
+
+		entityID, err := types.NewEntityIDFromString(entry.EntityID)
+		if err != nil {
+			log.Debugf("Ignoring the tagger snapshot entry of %q: %s", entry.EntityID, err)
+			continue
+		}
+
+		tagInfos = append(tagInfos, &types.TagInfo{
+			Source:               entry.Source,
+			EntityID:             entityID,
+			LowCardTags:          entry.LowCardTags,
+			OrchestratorCardTags: entry.OrchestratorCardTags,
+			HighCardTags:         entry.HighCardTags,
+			StandardTags:         entry.StandardTags,
+			ExpiryDate:           expiryDate,
+		})
+	}
+
+	s.ProcessTagInfo(tagInfos)
+
+	return len(tagInfos), nil
+}
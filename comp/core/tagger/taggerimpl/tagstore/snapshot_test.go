@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tagstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	taggerTelemetry "github.com/DataDog/datadog-agent/comp/core/tagger/telemetry"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+	"github.com/DataDog/datadog-agent/comp/core/telemetry"
+	"github.com/DataDog/datadog-agent/comp/core/telemetry/telemetryimpl"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/util/flavor"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func newTestTagStore(t *testing.T, clock clock.Clock) *TagStore {
+	tel := fxutil.Test[telemetry.Component](t, telemetryimpl.MockModule())
+	return newTagStoreWithClock(configmock.New(t), clock, taggerTelemetry.NewStore(tel))
+}
+
+func TestSnapshot(t *testing.T) {
+	originalFlavor := flavor.GetFlavor()
+	defer flavor.SetFlavor(originalFlavor)
+
+	// entities have tags from multiple sources in the agent, and from a single
+	// source in the cluster agent
+	for _, agentFlavor := range []string{flavor.DefaultAgent, flavor.ClusterAgent} {
+		t.Run(agentFlavor, func(t *testing.T) {
+			flavor.SetFlavor(agentFlavor)
+			testSnapshot(t)
+		})
+	}
+}
+
+func testSnapshot(t *testing.T) {
+	clk := clock.NewMock()
+	clk.Add(time.Since(time.Unix(0, 0)))
+	path := filepath.Join(t.TempDir(), "tagger", "snapshot.json")
+
+	confirmedID := types.NewEntityID(types.ContainerID, "confirmed")
+	staleID := types.NewEntityID(types.ContainerID, "stale")
+	deletedID := types.NewEntityID(types.ContainerID, "deleted")
+
+	store := newTestTagStore(t, clk)
+	store.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:               "workloadmeta-container",
+			EntityID:             confirmedID,
+			LowCardTags:          []string{"image_name:redis"},
+			OrchestratorCardTags: []string{"pod_name:redis-0"},
+			HighCardTags:         []string{"container_id:confirmed"},
+			StandardTags:         []string{"service:redis"},
+		},
+		{
+			Source:      "workloadmeta-container",
+			EntityID:    staleID,
+			LowCardTags: []string{"image_name:nginx"},
+		},
+		{
+			Source:      "workloadmeta-container",
+			EntityID:    deletedID,
+			LowCardTags: []string{"image_name:mysql"},
+		},
+		{
+			Source:       "workloadmeta-container",
+			EntityID:     deletedID,
+			DeleteEntity: true,
+		},
+	})
+	require.NoError(t, store.WriteSnapshot(path))
+
+	// the entity deleted from the store expires before the restart
+	clk.Add(10 * time.Minute)
+
+	restoredStore := newTestTagStore(t, clk)
+	restored, err := restoredStore.RestoreSnapshot(path, 2*time.Minute, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2, restored)
+
+	entity, err := restoredStore.GetEntity(confirmedID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"image_name:redis"}, entity.LowCardinalityTags)
+	assert.Equal(t, []string{"pod_name:redis-0"}, entity.OrchestratorCardinalityTags)
+	assert.Equal(t, []string{"container_id:confirmed"}, entity.HighCardinalityTags)
+	assert.Equal(t, []string{"service:redis"}, entity.StandardTags)
+	assert.Equal(t, []string{"image_name:nginx"}, restoredStore.Lookup(staleID, types.LowCardinality))
+	assert.Empty(t, restoredStore.Lookup(deletedID, types.LowCardinality))
+
+	// the collector reconfirms one of the entities
+	restoredStore.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:      "workloadmeta-container",
+			EntityID:    confirmedID,
+			LowCardTags: []string{"image_name:redis"},
+		},
+	})
+
+	// the entity that wasn't reconfirmed is invalidated after the grace period
+	clk.Add(3 * time.Minute)
+	restoredStore.Prune()
+	assert.Equal(t, []string{"image_name:redis"}, restoredStore.Lookup(confirmedID, types.LowCardinality))
+	_, err = restoredStore.GetEntity(staleID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRestoreSnapshotErrors(t *testing.T) {
+	clk := clock.NewMock()
+	clk.Add(time.Since(time.Unix(0, 0)))
+	dir := t.TempDir()
+	store := newTestTagStore(t, clk)
+
+	// a missing snapshot isn't an error
+	restored, err := store.RestoreSnapshot(filepath.Join(dir, "missing.json"), time.Minute, time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, restored)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte("{"), 0o600))
+	_, err = store.RestoreSnapshot(invalid, time.Minute, time.Hour)
+	assert.Error(t, err)
+
+	unsupported := filepath.Join(dir, "unsupported.json")
+	require.NoError(t, os.WriteFile(unsupported, []byte(`{"version": 42}`), 0o600))
+	_, err = store.RestoreSnapshot(unsupported, time.Minute, time.Hour)
+	assert.ErrorContains(t, err, "unsupported tagger snapshot version")
+
+	// snapshots older than the max age are ignored
+	old := filepath.Join(dir, "old.json")
+	store.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:      "workloadmeta-container",
+			EntityID:    types.NewEntityID(types.ContainerID, "old"),
+			LowCardTags: []string{"image_name:redis"},
+		},
+	})
+	require.NoError(t, store.WriteSnapshot(old))
+	clk.Add(2 * time.Hour)
+	restored, err = newTestTagStore(t, clk).RestoreSnapshot(old, time.Minute, time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, restored)
+}
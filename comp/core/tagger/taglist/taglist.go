@@ -8,6 +8,7 @@ package taglist
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -94,6 +95,27 @@ func (l *TagList) Compute() ([]string, []string, []string, []string) {
 	return toSlice(l.lowCardTags), toSlice(l.orchestratorCardTags), toSlice(l.highCardTags), toSlice(l.standardTags)
 }
 
+// Values returns the sorted values of the tag with the given name, whatever
+// their cardinality.
+func (l *TagList) Values(name string) []string {
+	prefix := name + ":"
+	values := make(map[string]struct{})
+	for _, m := range []map[string]bool{l.lowCardTags, l.orchestratorCardTags, l.highCardTags} {
+		for tag := range m {
+			if strings.HasPrefix(tag, prefix) {
+				values[tag[len(prefix):]] = struct{}{}
+			}
+		}
+	}
+
+	s := make([]string, 0, len(values))
+	for value := range values {
+		s = append(s, value)
+	}
+	sort.Strings(s)
+	return s
+}
+
 func toSlice(m map[string]bool) []string {
 	s := make([]string, len(m))
 	index := 0
@@ -219,3 +219,21 @@ func TestCopy(t *testing.T) {
 	require.Contains(t, standard3, "env:dev")
 	require.Contains(t, standard3, "service:foo")
 }
+
+func TestValues(t *testing.T) {
+	list := NewTagList()
+	list.AddLow("image_name", "registry.example.com/payments/api")
+	list.AddOrchestrator("pod_name", "api-1")
+	list.AddHigh("container_name", "api")
+	list.AddHigh("team", "payments")
+	list.AddLow("team", "billing")
+	list.AddLow("team", "payments")
+	list.AddStandard("env", "prod")
+
+	require.Equal(t, []string{"registry.example.com/payments/api"}, list.Values("image_name"))
+	require.Equal(t, []string{"api-1"}, list.Values("pod_name"))
+	require.Equal(t, []string{"billing", "payments"}, list.Values("team"))
+	require.Equal(t, []string{"prod"}, list.Values("env"))
+	require.Empty(t, list.Values("image"))
+	require.Empty(t, list.Values("missing"))
+}
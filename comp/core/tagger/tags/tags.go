@@ -153,6 +153,9 @@ const (
 	// RemoteConfigRevision is the tag for the remote config revision
 	RemoteConfigRevision = "dd_remote_config_rev"
 
+	// SystemdUnit is the tag for the systemd unit name
+	SystemdUnit = "systemd_unit"
+
 	// ORCHESTRATOR CARDINALITY
 
 	// KubeOwnerRefName is the tag for the Kubernetes owner reference name
@@ -128,6 +128,8 @@ const (
 	KubernetesPodUID EntityIDPrefix = "kubernetes_pod_uid"
 	// Process is the prefix `process`
 	Process EntityIDPrefix = "process"
+	// SystemdUnit is the prefix `systemd_unit`
+	SystemdUnit EntityIDPrefix = "systemd_unit"
 )
 
 // AllPrefixesSet returns a set of all possible entity id prefixes that can be used in the tagger
@@ -141,5 +143,6 @@ func AllPrefixesSet() map[EntityIDPrefix]struct{} {
 		KubernetesMetadata:     {},
 		KubernetesPodUID:       {},
 		Process:                {},
+		SystemdUnit:            {},
 	}
 }
@@ -0,0 +1,307 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+
+// Package systemd implements the systemd Workloadmeta collector.
+package systemd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/coreos/go-systemd/v22/dbus"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	collectorID   = "systemd"
+	componentName = "workloadmeta-systemd"
+)
+
+// unitStates are the states of the units collected as entities. The units
+// in other states are removed from the store.
+var unitStates = []string{"active", "activating", "reloading", "deactivating"}
+
+type dependencies struct {
+	fx.In
+
+	Config config.Component
+}
+
+// systemdClient is the subset of the systemd D-Bus API used by the collector
+type systemdClient interface {
+	ListUnitsByPatternsContext(ctx context.Context, states []string, patterns []string) ([]dbus.UnitStatus, error)
+	GetUnitPropertiesContext(ctx context.Context, unit string) (map[string]interface{}, error)
+	GetUnitTypePropertiesContext(ctx context.Context, unit string, unitType string) (map[string]interface{}, error)
+	Close()
+}
+
+type collector struct {
+	store   workloadmeta.Component
+	catalog workloadmeta.AgentType
+	config  config.Component
+	client  systemdClient
+	clock   clock.Clock
+
+	patterns           []string
+	collectionInterval time.Duration
+	lastPull           time.Time
+	seen               map[workloadmeta.EntityID]struct{}
+}
+
+// NewCollector returns a new systemd collector provider and an error
+func NewCollector(deps dependencies) (workloadmeta.CollectorProvider, error) {
+	return workloadmeta.CollectorProvider{
+		Collector: &collector{
+			catalog: workloadmeta.NodeAgent | workloadmeta.ProcessAgent,
+			config:  deps.Config,
+			clock:   clock.New(),
+			seen:    make(map[workloadmeta.EntityID]struct{}),
+		},
+	}, nil
+}
+
+// GetFxOptions returns the FX framework options for the collector
+func GetFxOptions() fx.Option {
+	return fx.Provide(NewCollector)
+}
+
+// Start connects to systemd, directly through its private socket when
+// running as root, or through the system bus otherwise
+func (c *collector) Start(ctx context.Context, store workloadmeta.Component) error {
+	if !c.config.GetBool("workloadmeta.systemd_collector.enabled") {
+		return errors.NewDisabled(componentName, "the systemd collector is disabled")
+	}
+
+	if c.client == nil {
+		conn, err := dbus.NewSystemdConnectionContext(ctx)
+		if err != nil {
+			log.Debugf("Unable to connect to the systemd private socket, falling back to the system bus: %s", err)
+			conn, err = dbus.NewSystemConnectionContext(ctx)
+		}
+		if err != nil {
+			return errors.NewDisabled(componentName, fmt.Sprintf("unable to connect to systemd: %s", err))
+		}
+		c.client = conn
+	}
+
+	c.store = store
+	c.patterns = c.config.GetStringSlice("workloadmeta.systemd_collector.unit_patterns")
+	c.collectionInterval = c.config.GetDuration("workloadmeta.systemd_collector.collection_interval")
+
+	go func() {
+		<-ctx.Done()
+		c.client.Close()
+	}()
+
+	return nil
+}
+
+// Pull lists the units matching the configured patterns, at most once per
+// collection interval, and removes the ones that stopped from the store
+func (c *collector) Pull(ctx context.Context) error {
+	now := c.clock.Now()
+	if !c.lastPull.IsZero() && now.Sub(c.lastPull) < c.collectionInterval {
+		return nil
+	}
+	c.lastPull = now
+
+	units, err := c.client.ListUnitsByPatternsContext(ctx, unitStates, c.patterns)
+	if err != nil {
+		return fmt.Errorf("unable to list the systemd units: %w", err)
+	}
+
+	seen := make(map[workloadmeta.EntityID]struct{}, len(units))
+	events := make([]workloadmeta.CollectorEvent, 0, len(units))
+
+	for _, unit := range units {
+		entity, err := c.buildUnit(ctx, unit)
+		if err != nil {
+			log.Debugf("Unable to get the properties of the systemd unit %s: %s", unit.Name, err)
+			continue
+		}
+
+		seen[entity.EntityID] = struct{}{}
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceSystemd,
+			Entity: entity,
+		})
+	}
+
+	for seenID := range c.seen {
+		if _, ok := seen[seenID]; ok {
+			continue
+		}
+
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceSystemd,
+			Entity: &workloadmeta.SystemdUnit{
+				EntityID: seenID,
+			},
+		})
+	}
+
+	c.seen = seen
+
+	c.store.Notify(events)
+
+	return nil
+}
+
+func (c *collector) GetID() string {
+	return collectorID
+}
+
+func (c *collector) GetTargetCatalog() workloadmeta.AgentType {
+	return c.catalog
+}
+
+// buildUnit returns the entity of a unit from its properties. The
+// properties specific to services, like the main PID, are only available
+// for service units.
+func (c *collector) buildUnit(ctx context.Context, unit dbus.UnitStatus) (*workloadmeta.SystemdUnit, error) {
+	properties, err := c.client.GetUnitPropertiesContext(ctx, unit.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := &workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   unit.Name,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: unit.Name,
+		},
+		Description:   unit.Description,
+		LoadState:     unit.LoadState,
+		ActiveState:   unit.ActiveState,
+		SubState:      unit.SubState,
+		UnitFileState: stringProperty(properties, "UnitFileState"),
+		FragmentPath:  stringProperty(properties, "FragmentPath"),
+		StartedAt:     timestampProperty(properties, "ActiveEnterTimestamp"),
+	}
+
+	if !strings.HasSuffix(unit.Name, ".service") {
+		return entity, nil
+	}
+
+	serviceProperties, err := c.client.GetUnitTypePropertiesContext(ctx, unit.Name, "Service")
+	if err != nil {
+		return nil, err
+	}
+
+	entity.MainPID = int(uint32Property(serviceProperties, "MainPID"))
+	entity.ControlGroup = stringProperty(serviceProperties, "ControlGroup")
+	entity.User = stringProperty(serviceProperties, "User")
+	entity.EnvVars = envVars(serviceProperties)
+
+	return entity, nil
+}
+
+// envVars returns the variables of the Environment and EnvironmentFile
+// properties of a service included by the environment variables filter, like
+// DD_SERVICE and DD_ENV. Like in systemd, the variables of the files override
+// the ones of the Environment property.
+func envVars(properties map[string]interface{}) map[string]string {
+	environment, _ := properties["Environment"].([]string)
+
+	// EnvironmentFiles is a list of (path, ignore errors) pairs
+	files, _ := properties["EnvironmentFiles"].([][]interface{})
+	for _, file := range files {
+		if len(file) == 0 {
+			continue
+		}
+		path, _ := file[0].(string)
+		if path == "" {
+			continue
+		}
+		fileEnvironment, err := readEnvironmentFile(path)
+		if err != nil {
+			log.Debugf("Unable to read the environment file %s: %s", path, err)
+			continue
+		}
+		environment = append(environment, fileEnvironment...)
+	}
+
+	res := make(map[string]string)
+	filter := containers.EnvVarFilterFromConfig()
+	for _, env := range environment {
+		name, value, found := strings.Cut(env, "=")
+		if !found {
+			continue
+		}
+		if filter.IsIncluded(name) {
+			res[name] = value
+		}
+	}
+
+	return res
+}
+
+// readEnvironmentFile returns the NAME=VALUE assignments of an environment
+// file, skipping the empty lines and the comments starting with # or ;, and
+// removing the quotes around the values
+func readEnvironmentFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var environment []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		name, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		environment = append(environment, strings.TrimSpace(name)+"="+value)
+	}
+
+	return environment, scanner.Err()
+}
+
+func stringProperty(properties map[string]interface{}, name string) string {
+	value, _ := properties[name].(string)
+	return value
+}
+
+func uint32Property(properties map[string]interface{}, name string) uint32 {
+	value, _ := properties[name].(uint32)
+	return value
+}
+
+// timestampProperty returns the value of a timestamp property, which systemd
+// gives in microseconds since the epoch, 0 meaning unset
+func timestampProperty(properties map[string]interface{}, name string) time.Time {
+	value, _ := properties[name].(uint64)
+	if value == 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(int64(value))
+}
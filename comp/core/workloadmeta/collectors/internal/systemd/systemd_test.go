@@ -0,0 +1,181 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+
+package systemd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+)
+
+type fakeWorkloadmetaStore struct {
+	workloadmeta.Component
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+type fakeSystemdClient struct {
+	units              []dbus.UnitStatus
+	unitProperties     map[string]map[string]interface{}
+	serviceProperties  map[string]map[string]interface{}
+	listedPatterns     []string
+	listedUnitsCounter int
+}
+
+func (c *fakeSystemdClient) ListUnitsByPatternsContext(_ context.Context, _ []string, patterns []string) ([]dbus.UnitStatus, error) {
+	c.listedPatterns = patterns
+	c.listedUnitsCounter++
+	return c.units, nil
+}
+
+func (c *fakeSystemdClient) GetUnitPropertiesContext(_ context.Context, unit string) (map[string]interface{}, error) {
+	return c.unitProperties[unit], nil
+}
+
+func (c *fakeSystemdClient) GetUnitTypePropertiesContext(_ context.Context, unit string, _ string) (map[string]interface{}, error) {
+	return c.serviceProperties[unit], nil
+}
+
+func (c *fakeSystemdClient) Close() {}
+
+func TestPull(t *testing.T) {
+	cfg := configmock.New(t)
+	cfg.SetWithoutSource("workloadmeta.systemd_collector.enabled", true)
+	cfg.SetWithoutSource("workloadmeta.systemd_collector.unit_patterns", []string{"*.service", "*.socket"})
+	cfg.SetWithoutSource("workloadmeta.systemd_collector.collection_interval", time.Minute)
+
+	// the variables of the environment files override the Environment property
+	environmentFile := filepath.Join(t.TempDir(), "nginx.env")
+	require.NoError(t, os.WriteFile(environmentFile, []byte("# nginx\n\nDD_ENV=\"staging\"\n; version\nDD_VERSION='1.2.3'\nDB_PASSWORD=hunter2\n"), 0o600))
+
+	startedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	client := &fakeSystemdClient{
+		units: []dbus.UnitStatus{
+			{Name: "nginx.service", Description: "The nginx HTTP server", LoadState: "loaded", ActiveState: "active", SubState: "running"},
+			{Name: "docker.socket", Description: "Docker Socket for the API", LoadState: "loaded", ActiveState: "active", SubState: "listening"},
+		},
+		unitProperties: map[string]map[string]interface{}{
+			"nginx.service": {
+				"UnitFileState":        "enabled",
+				"FragmentPath":         "/lib/systemd/system/nginx.service",
+				"ActiveEnterTimestamp": uint64(startedAt.UnixMicro()),
+			},
+			"docker.socket": {
+				"UnitFileState": "enabled",
+				"FragmentPath":  "/lib/systemd/system/docker.socket",
+			},
+		},
+		serviceProperties: map[string]map[string]interface{}{
+			"nginx.service": {
+				"MainPID":      uint32(1234),
+				"ControlGroup": "/system.slice/nginx.service",
+				"User":         "www-data",
+				"Environment":  []string{"DD_SERVICE=web", "DD_ENV=prod", "SECRET_KEY=hunter2", "INVALID"},
+				"EnvironmentFiles": [][]interface{}{
+					{environmentFile, false},
+					{filepath.Join(t.TempDir(), "missing"), true},
+				},
+			},
+		},
+	}
+
+	clk := clock.NewMock()
+	store := &fakeWorkloadmetaStore{}
+	c := &collector{
+		config: cfg,
+		client: client,
+		clock:  clk,
+		seen:   make(map[workloadmeta.EntityID]struct{}),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, c.Start(ctx, store))
+
+	require.NoError(t, c.Pull(ctx))
+	assert.Equal(t, []string{"*.service", "*.socket"}, client.listedPatterns)
+	require.Len(t, store.notifiedEvents, 2)
+
+	assert.Equal(t, workloadmeta.CollectorEvent{
+		Type:   workloadmeta.EventTypeSet,
+		Source: workloadmeta.SourceSystemd,
+		Entity: &workloadmeta.SystemdUnit{
+			EntityID: workloadmeta.EntityID{
+				Kind: workloadmeta.KindSystemdUnit,
+				ID:   "nginx.service",
+			},
+			EntityMeta: workloadmeta.EntityMeta{
+				Name: "nginx.service",
+			},
+			Description:   "The nginx HTTP server",
+			LoadState:     "loaded",
+			ActiveState:   "active",
+			SubState:      "running",
+			UnitFileState: "enabled",
+			FragmentPath:  "/lib/systemd/system/nginx.service",
+			User:          "www-data",
+			MainPID:       1234,
+			ControlGroup:  "/system.slice/nginx.service",
+			StartedAt:     startedAt.Local(),
+			EnvVars: map[string]string{
+				"DD_SERVICE": "web",
+				"DD_ENV":     "staging",
+				"DD_VERSION": "1.2.3",
+			},
+		},
+	}, store.notifiedEvents[0])
+
+	// the service properties aren't fetched for the other kinds of units
+	socket := store.notifiedEvents[1].Entity.(*workloadmeta.SystemdUnit)
+	assert.Equal(t, "docker.socket", socket.ID)
+	assert.Zero(t, socket.MainPID)
+	assert.True(t, socket.StartedAt.IsZero())
+
+	// the units aren't listed again before the collection interval
+	require.NoError(t, c.Pull(ctx))
+	assert.Equal(t, 1, client.listedUnitsCounter)
+
+	// the units that stopped are removed from the store
+	client.units = client.units[:1]
+	store.notifiedEvents = nil
+	clk.Add(time.Minute)
+	require.NoError(t, c.Pull(ctx))
+	assert.Equal(t, 2, client.listedUnitsCounter)
+	require.Len(t, store.notifiedEvents, 2)
+	assert.Equal(t, workloadmeta.EventTypeSet, store.notifiedEvents[0].Type)
+	assert.Equal(t, workloadmeta.CollectorEvent{
+		Type:   workloadmeta.EventTypeUnset,
+		Source: workloadmeta.SourceSystemd,
+		Entity: &workloadmeta.SystemdUnit{
+			EntityID: workloadmeta.EntityID{
+				Kind: workloadmeta.KindSystemdUnit,
+				ID:   "docker.socket",
+			},
+		},
+	}, store.notifiedEvents[1])
+}
+
+func TestStartDisabled(t *testing.T) {
+	c := &collector{
+		config: configmock.New(t),
+		client: &fakeSystemdClient{},
+	}
+	assert.Error(t, c.Start(context.Background(), &fakeWorkloadmetaStore{}))
+}
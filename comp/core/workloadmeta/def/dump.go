@@ -8,6 +8,8 @@ package workloadmeta
 import (
 	"fmt"
 	"io"
+	"sort"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -37,3 +39,49 @@ func (wdr WorkloadDumpResponse) Write(writer io.Writer) {
 		}
 	}
 }
+
+// WorkloadSnapshot contains the containers and pods of the store with all
+// their fields, so that, unlike WorkloadDumpResponse, it can be loaded back.
+// It's used to run autodiscovery offline against the workloads of an agent.
+type WorkloadSnapshot struct {
+	Containers     []*Container     `json:"containers"`
+	KubernetesPods []*KubernetesPod `json:"kubernetes_pods"`
+}
+
+// WorkloadHistoryResponse is used to dump the last events received by the
+// store for some entities, including the entities that were since removed.
+type WorkloadHistoryResponse struct {
+	Entities map[string][]WorkloadEventRecord `json:"entities"`
+}
+
+// WorkloadEventRecord is an event received by the store for an entity.
+type WorkloadEventRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Source    Source    `json:"source"`
+	// Info is the dump of the entity sent by the source, for set events.
+	Info string `json:"info,omitempty"`
+}
+
+// Write writes the events of the entities, oldest first, in a given writer.
+// Useful for agent's CLI and Flare.
+func (whr WorkloadHistoryResponse) Write(writer io.Writer) {
+	if writer != color.Output {
+		color.NoColor = true
+	}
+
+	entities := make([]string, 0, len(whr.Entities))
+	for entity := range whr.Entities {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+
+	for _, entity := range entities {
+		fmt.Fprintf(writer, "\n=== Entity %s ===\n", color.GreenString(entity))
+		for _, record := range whr.Entities[entity] {
+			fmt.Fprintf(writer, "--- %s %s from %s ---\n", record.Timestamp.Format(time.RFC3339Nano), color.YellowString(record.Type), color.BlueString(string(record.Source)))
+			fmt.Fprint(writer, record.Info)
+		}
+		fmt.Fprintln(writer, "===")
+	}
+}
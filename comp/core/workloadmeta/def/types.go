@@ -48,6 +48,7 @@ const (
 	KindContainerImageMetadata Kind = "container_image_metadata"
 	KindProcess                Kind = "process"
 	KindHost                   Kind = "host"
+	KindSystemdUnit            Kind = "systemd_unit"
 )
 
 // Source is the source name of an entity.
@@ -92,6 +93,10 @@ const (
 	// SourceLocalProcessCollector reprents processes entities detected
 	// by the LocalProcessCollector.
 	SourceLocalProcessCollector Source = "local_process_collector"
+
+	// SourceSystemd represents entities detected by systemd, such as the
+	// units of the services that don't run in containers.
+	SourceSystemd Source = "systemd"
 )
 
 // ContainerRuntime is the container runtime used by a container.
@@ -1189,7 +1194,9 @@ func (p Process) String(_ bool) string {
 	_, _ = fmt.Fprintln(&sb, "Namespace PID:", p.NsPid)
 	_, _ = fmt.Fprintln(&sb, "Container ID:", p.ContainerID)
 	_, _ = fmt.Fprintln(&sb, "Creation time:", p.CreationTime)
-	_, _ = fmt.Fprintln(&sb, "Language:", p.Language.Name)
+	if p.Language != nil {
+		_, _ = fmt.Fprintln(&sb, "Language:", p.Language.Name)
+	}
 
 	return sb.String()
 }
@@ -1235,6 +1242,80 @@ func (p HostTags) String(verbose bool) string {
 	return sb.String()
 }
 
+// SystemdUnit is an Entity that represents a systemd unit. The tagger builds
+// the tags of its systemd_unit entity, but the data of the processes of the
+// unit isn't mapped to it yet, through MainPID or ControlGroup, during origin
+// detection. EntityID.ID and EntityMeta.Name are the name of the unit.
+type SystemdUnit struct {
+	EntityID
+	EntityMeta
+
+	Description   string
+	LoadState     string
+	ActiveState   string
+	SubState      string
+	UnitFileState string
+	FragmentPath  string
+	User          string
+	MainPID       int
+	ControlGroup  string
+	StartedAt     time.Time
+	// EnvVars are the variables of the Environment and EnvironmentFile
+	// properties of the unit, limited to the ones included in
+	// pkg/util/containers/env_vars_filter.go
+	EnvVars map[string]string
+}
+
+var _ Entity = &SystemdUnit{}
+
+// GetID implements Entity#GetID.
+func (u SystemdUnit) GetID() EntityID {
+	return u.EntityID
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (u SystemdUnit) DeepCopy() Entity {
+	cu := deepcopy.Copy(u).(SystemdUnit)
+	return &cu
+}
+
+// Merge implements Entity#Merge.
+func (u *SystemdUnit) Merge(e Entity) error {
+	otherUnit, ok := e.(*SystemdUnit)
+	if !ok {
+		return fmt.Errorf("cannot merge SystemdUnit with different kind %T", e)
+	}
+
+	return merge(u, otherUnit)
+}
+
+// String implements Entity#String.
+func (u SystemdUnit) String(verbose bool) string {
+	var sb strings.Builder
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, u.EntityID.String(verbose))
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, u.EntityMeta.String(verbose))
+	_, _ = fmt.Fprintln(&sb, "----------- Unit Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Description:", u.Description)
+	_, _ = fmt.Fprintln(&sb, "Active State:", u.ActiveState)
+	_, _ = fmt.Fprintln(&sb, "Sub State:", u.SubState)
+	_, _ = fmt.Fprintln(&sb, "Main PID:", u.MainPID)
+	_, _ = fmt.Fprintln(&sb, "Control Group:", u.ControlGroup)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Load State:", u.LoadState)
+		_, _ = fmt.Fprintln(&sb, "Unit File State:", u.UnitFileState)
+		_, _ = fmt.Fprintln(&sb, "Fragment Path:", u.FragmentPath)
+		_, _ = fmt.Fprintln(&sb, "User:", u.User)
+		_, _ = fmt.Fprintln(&sb, "Started At:", u.StartedAt)
+		_, _ = fmt.Fprintln(&sb, "Env Variables:", mapToScrubbedJSONString(u.EnvVars))
+	}
+
+	return sb.String()
+}
+
 // CollectorEvent is an event generated by a metadata collector, to be handled
 // by the metadata store.
 type CollectorEvent struct {
@@ -35,6 +35,8 @@ func (w *workloadmeta) Dump(verbose bool) wmdef.WorkloadDumpResponse {
 			info = e.String(verbose)
 		case *wmdef.KubernetesMetadata:
 			info = e.String(verbose)
+		case *wmdef.SystemdUnit:
+			info = e.String(verbose)
 		default:
 			return "", fmt.Errorf("unsupported type %T", e)
 		}
@@ -75,3 +77,16 @@ func (w *workloadmeta) Dump(verbose bool) wmdef.WorkloadDumpResponse {
 
 	return workloadList
 }
+
+// snapshot returns the containers and pods of the store.
+func (w *workloadmeta) snapshot() wmdef.WorkloadSnapshot {
+	snapshot := wmdef.WorkloadSnapshot{
+		Containers: w.ListContainers(),
+	}
+
+	for _, entity := range w.listEntitiesByKind(wmdef.KindKubernetesPod) {
+		snapshot.KubernetesPods = append(snapshot.KubernetesPods, entity.(*wmdef.KubernetesPod))
+	}
+
+	return snapshot
+}
@@ -190,3 +190,49 @@ Cgroup path: /default/ctr-id
 
 	assert.EqualValues(t, expectedVerbose, verboseDump)
 }
+
+func TestSnapshot(t *testing.T) {
+	s := newWorkloadmetaObject(t)
+
+	container := &wmdef.Container{
+		EntityID: wmdef.EntityID{
+			Kind: wmdef.KindContainer,
+			ID:   "ctr-id",
+		},
+		EntityMeta: wmdef.EntityMeta{
+			Name: "ctr-name",
+		},
+		Runtime: wmdef.ContainerRuntimeContainerd,
+	}
+
+	pod := &wmdef.KubernetesPod{
+		EntityID: wmdef.EntityID{
+			Kind: wmdef.KindKubernetesPod,
+			ID:   "pod-id",
+		},
+		EntityMeta: wmdef.EntityMeta{
+			Name:      "pod-name",
+			Namespace: "default",
+		},
+		Containers: []wmdef.OrchestratorContainer{
+			{ID: "ctr-id", Name: "ctr-name"},
+		},
+	}
+
+	s.handleEvents([]wmdef.CollectorEvent{
+		{
+			Type:   wmdef.EventTypeSet,
+			Source: "source1",
+			Entity: container,
+		},
+		{
+			Type:   wmdef.EventTypeSet,
+			Source: "source1",
+			Entity: pod,
+		},
+	})
+
+	snapshot := s.snapshot()
+	assert.Equal(t, []*wmdef.Container{container}, snapshot.Containers)
+	assert.Equal(t, []*wmdef.KubernetesPod{pod}, snapshot.KubernetesPods)
+}
@@ -6,8 +6,10 @@
 package workloadmetaimpl
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/samber/lo"
 
@@ -21,6 +23,15 @@ The flare providers are used to add additional data to the flare archive. They s
 with workloadmeta to dump its state.
 */
 
+// flareProvider adds the workloadmeta data to the flare archive.
+func (w *workloadmeta) flareProvider(fb flaretypes.FlareBuilder) error {
+	if err := w.sbomFlareProvider(fb); err != nil {
+		w.log.Warnf("Unable to add the SBOMs to the flare: %v", err)
+	}
+
+	return w.historyFlareProvider(fb)
+}
+
 // sbomFlareProvider will add the SBOMs of all the images in the flare archive.
 // Note that the generated file uncompressed can be very large
 func (w *workloadmeta) sbomFlareProvider(fb flaretypes.FlareBuilder) error {
@@ -41,3 +52,14 @@ func (w *workloadmeta) sbomFlareProvider(fb flaretypes.FlareBuilder) error {
 
 	return nil
 }
+
+// historyFlareProvider will add the last events of all the entities, from the
+// event journal, in the flare archive.
+func (w *workloadmeta) historyFlareProvider(fb flaretypes.FlareBuilder) error {
+	var buffer bytes.Buffer
+	w.journal.history("", "", time.Time{}).Write(&buffer)
+
+	_ = fb.AddFile("workload-history.log", buffer.Bytes())
+
+	return nil
+}
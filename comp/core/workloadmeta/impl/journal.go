@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package workloadmetaimpl
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	wmdef "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+)
+
+// eventJournal keeps the last events that changed the store for each entity,
+// including the entities that were since removed, so that the changes of an
+// entity can be investigated after the fact. It's bounded both in number of
+// events per entity and in number of entities of each kind, the entities
+// updated least recently being evicted first. The kinds are bounded
+// separately so that the churn of a kind, like processes, doesn't evict the
+// history of the others.
+type eventJournal struct {
+	mu sync.Mutex
+
+	maxEventsPerEntity int
+	maxEntitiesPerKind int
+
+	kinds map[wmdef.Kind]*journalKind
+}
+
+// journalKind holds the entities of a kind
+type journalKind struct {
+	entities map[string]*list.Element
+	lru      *list.List // of *journalEntity, least recently updated first
+}
+
+// journalEntity holds the events of an entity in a ring buffer
+type journalEntity struct {
+	id      string
+	records []wmdef.WorkloadEventRecord
+	next    int
+}
+
+// newEventJournal returns a new journal, or nil if maxEventsPerEntity or
+// maxEntitiesPerKind isn't positive, in which case the journal is disabled.
+func newEventJournal(maxEventsPerEntity, maxEntitiesPerKind int) *eventJournal {
+	if maxEventsPerEntity <= 0 || maxEntitiesPerKind <= 0 {
+		return nil
+	}
+
+	return &eventJournal{
+		maxEventsPerEntity: maxEventsPerEntity,
+		maxEntitiesPerKind: maxEntitiesPerKind,
+		kinds:              make(map[wmdef.Kind]*journalKind),
+	}
+}
+
+// record adds an event to the journal of its entity. It builds the
+// description of the entity, so it shouldn't be called with the store locked.
+func (j *eventJournal) record(ev wmdef.CollectorEvent, timestamp time.Time) {
+	if j == nil {
+		return
+	}
+
+	record := wmdef.WorkloadEventRecord{
+		Timestamp: timestamp,
+		Source:    ev.Source,
+	}
+	switch ev.Type {
+	case wmdef.EventTypeSet:
+		record.Type = "set"
+		record.Info = ev.Entity.String(false)
+	case wmdef.EventTypeUnset:
+		record.Type = "unset"
+	}
+
+	entityID := ev.Entity.GetID()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	kind, ok := j.kinds[entityID.Kind]
+	if !ok {
+		kind = &journalKind{
+			entities: make(map[string]*list.Element),
+			lru:      list.New(),
+		}
+		j.kinds[entityID.Kind] = kind
+	}
+
+	elem, ok := kind.entities[entityID.ID]
+	if ok {
+		kind.lru.MoveToBack(elem)
+	} else {
+		if kind.lru.Len() >= j.maxEntitiesPerKind {
+			oldest := kind.lru.Remove(kind.lru.Front()).(*journalEntity)
+			delete(kind.entities, oldest.id)
+		}
+		elem = kind.lru.PushBack(&journalEntity{id: entityID.ID})
+		kind.entities[entityID.ID] = elem
+	}
+
+	entity := elem.Value.(*journalEntity)
+	if len(entity.records) < j.maxEventsPerEntity {
+		entity.records = append(entity.records, record)
+		return
+	}
+	entity.records[entity.next] = record
+	entity.next = (entity.next + 1) % j.maxEventsPerEntity
+}
+
+// history returns the events of the entities with the given kind and ID
+// received until the given time, oldest first. An empty kind or ID matches
+// any kind or ID, and a zero time matches all the events.
+func (j *eventJournal) history(kind wmdef.Kind, id string, until time.Time) wmdef.WorkloadHistoryResponse {
+	response := wmdef.WorkloadHistoryResponse{
+		Entities: make(map[string][]wmdef.WorkloadEventRecord),
+	}
+
+	if j == nil {
+		return response
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for entityKind, journalKind := range j.kinds {
+		if kind != "" && entityKind != kind {
+			continue
+		}
+
+		for entityID, elem := range journalKind.entities {
+			if id != "" && entityID != id {
+				continue
+			}
+
+			entity := elem.Value.(*journalEntity)
+			records := make([]wmdef.WorkloadEventRecord, 0, len(entity.records))
+			for i := range entity.records {
+				record := entity.records[(entity.next+i)%len(entity.records)]
+				if !until.IsZero() && record.Timestamp.After(until) {
+					break
+				}
+				records = append(records, record)
+			}
+
+			if len(records) > 0 {
+				response.Entities[string(entityKind)+"/"+entityID] = records
+			}
+		}
+	}
+
+	return response
+}
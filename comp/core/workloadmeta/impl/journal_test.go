@@ -0,0 +1,207 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package workloadmetaimpl
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/flare/helpers"
+	wmdef "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+)
+
+func journalContainer(id string) *wmdef.Container {
+	return &wmdef.Container{
+		EntityID: wmdef.EntityID{
+			Kind: wmdef.KindContainer,
+			ID:   id,
+		},
+	}
+}
+
+func recordSources(records []wmdef.WorkloadEventRecord) []string {
+	sources := make([]string, 0, len(records))
+	for _, record := range records {
+		sources = append(sources, string(record.Source))
+	}
+	return sources
+}
+
+func TestEventJournal(t *testing.T) {
+	start := time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC)
+	journal := newEventJournal(3, 2)
+
+	// the oldest events of an entity are dropped past the limit
+	for i := 0; i < 5; i++ {
+		journal.record(wmdef.CollectorEvent{
+			Type:   wmdef.EventTypeSet,
+			Source: wmdef.Source(fmt.Sprintf("source-%d", i)),
+			Entity: journalContainer("ctr-1"),
+		}, start.Add(time.Duration(i)*time.Minute))
+	}
+
+	history := journal.history(wmdef.KindContainer, "ctr-1", time.Time{})
+	require.Contains(t, history.Entities, "container/ctr-1")
+	records := history.Entities["container/ctr-1"]
+	assert.Equal(t, []string{"source-2", "source-3", "source-4"}, recordSources(records))
+	assert.Equal(t, "set", records[0].Type)
+	assert.Equal(t, start.Add(2*time.Minute), records[0].Timestamp)
+	assert.Contains(t, records[0].Info, "ctr-1")
+
+	// the events received after the given time are left out
+	history = journal.history(wmdef.KindContainer, "ctr-1", start.Add(3*time.Minute))
+	assert.Equal(t, []string{"source-2", "source-3"}, recordSources(history.Entities["container/ctr-1"]))
+
+	// the entity updated least recently is evicted past the limit
+	journal.record(wmdef.CollectorEvent{
+		Type:   wmdef.EventTypeSet,
+		Source: "source-a",
+		Entity: journalContainer("ctr-2"),
+	}, start.Add(10*time.Minute))
+	journal.record(wmdef.CollectorEvent{
+		Type:   wmdef.EventTypeSet,
+		Source: "source-a",
+		Entity: journalContainer("ctr-1"),
+	}, start.Add(11*time.Minute))
+	journal.record(wmdef.CollectorEvent{
+		Type:   wmdef.EventTypeUnset,
+		Source: "source-a",
+		Entity: journalContainer("ctr-3"),
+	}, start.Add(12*time.Minute))
+
+	history = journal.history("", "", time.Time{})
+	assert.Len(t, history.Entities, 2)
+	assert.Contains(t, history.Entities, "container/ctr-1")
+	assert.Contains(t, history.Entities, "container/ctr-3")
+	assert.Equal(t, "unset", history.Entities["container/ctr-3"][0].Type)
+
+	// an empty kind matches any kind
+	history = journal.history("", "ctr-3", time.Time{})
+	assert.Len(t, history.Entities, 1)
+	assert.Empty(t, journal.history(wmdef.KindKubernetesPod, "ctr-3", time.Time{}).Entities)
+}
+
+func TestEventJournalPerKind(t *testing.T) {
+	start := time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC)
+	journal := newEventJournal(3, 2)
+
+	journal.record(wmdef.CollectorEvent{
+		Type:   wmdef.EventTypeSet,
+		Source: wmdef.SourceNodeOrchestrator,
+		Entity: &wmdef.KubernetesPod{
+			EntityID: wmdef.EntityID{
+				Kind: wmdef.KindKubernetesPod,
+				ID:   "pod-uid",
+			},
+		},
+	}, start)
+
+	// the churn of the processes doesn't evict the history of the pods
+	for i := 0; i < 5; i++ {
+		journal.record(wmdef.CollectorEvent{
+			Type:   wmdef.EventTypeUnset,
+			Source: wmdef.SourceLocalProcessCollector,
+			Entity: &wmdef.Process{
+				EntityID: wmdef.EntityID{
+					Kind: wmdef.KindProcess,
+					ID:   fmt.Sprintf("%d", i),
+				},
+			},
+		}, start.Add(time.Duration(i)*time.Minute))
+	}
+
+	history := journal.history("", "", time.Time{})
+	assert.Len(t, history.Entities, 3)
+	assert.Contains(t, history.Entities, "kubernetes_pod/pod-uid")
+	assert.Contains(t, history.Entities, "process/3")
+	assert.Contains(t, history.Entities, "process/4")
+}
+
+func TestEventJournalDisabled(t *testing.T) {
+	journal := newEventJournal(0, 1000)
+	assert.Nil(t, journal)
+
+	journal.record(wmdef.CollectorEvent{
+		Type:   wmdef.EventTypeSet,
+		Source: "source",
+		Entity: journalContainer("ctr-1"),
+	}, time.Now())
+	assert.Empty(t, journal.history("", "", time.Time{}).Entities)
+}
+
+func TestStoreEventJournal(t *testing.T) {
+	s := newWorkloadmetaObject(t)
+
+	container := journalContainer("ctr-1")
+	s.handleEvents([]wmdef.CollectorEvent{
+		{
+			Type:   wmdef.EventTypeSet,
+			Source: wmdef.SourceRuntime,
+			Entity: container,
+		},
+		// events that don't change the store aren't recorded
+		{
+			Type:   wmdef.EventTypeSet,
+			Source: wmdef.SourceRuntime,
+			Entity: container,
+		},
+		{
+			Type:   wmdef.EventTypeUnset,
+			Source: wmdef.SourceNodeOrchestrator,
+			Entity: container,
+		},
+		{
+			Type:   wmdef.EventTypeUnset,
+			Source: wmdef.SourceRuntime,
+			Entity: container,
+		},
+	})
+
+	records := s.journal.history(wmdef.KindContainer, "ctr-1", time.Time{}).Entities["container/ctr-1"]
+	require.Len(t, records, 2)
+	assert.Equal(t, "set", records[0].Type)
+	assert.Equal(t, wmdef.SourceRuntime, records[0].Source)
+	assert.Equal(t, "unset", records[1].Type)
+	assert.Equal(t, wmdef.SourceRuntime, records[1].Source)
+}
+
+func TestHistoryFlareProvider(t *testing.T) {
+	s := newWorkloadmetaObject(t)
+	s.handleEvents([]wmdef.CollectorEvent{
+		{
+			Type:   wmdef.EventTypeSet,
+			Source: wmdef.SourceRuntime,
+			Entity: journalContainer("ctr-1"),
+		},
+	})
+
+	fb := helpers.NewFlareBuilderMock(t, false)
+	require.NoError(t, s.historyFlareProvider(fb.Fb))
+	fb.AssertFileContentMatch("=== Entity container/ctr-1 ===\n--- .* set from runtime ---", "workload-history.log")
+}
+
+func TestParseHistoryEntity(t *testing.T) {
+	kind, id := parseHistoryEntity("kubernetes_pod/pod-uid")
+	assert.Equal(t, wmdef.KindKubernetesPod, kind)
+	assert.Equal(t, "pod-uid", id)
+
+	kind, id = parseHistoryEntity("kubernetes_metadata/namespaces//default")
+	assert.Equal(t, wmdef.KindKubernetesMetadata, kind)
+	assert.Equal(t, "namespaces//default", id)
+
+	kind, id = parseHistoryEntity("ctr-id")
+	assert.Empty(t, kind)
+	assert.Equal(t, "ctr-id", id)
+
+	// the IDs with slashes are matched as a whole when they don't start with a kind
+	kind, id = parseHistoryEntity("namespaces//default")
+	assert.Empty(t, kind)
+	assert.Equal(t, "namespaces//default", id)
+}
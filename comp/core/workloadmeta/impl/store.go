@@ -619,6 +619,11 @@ func (w *workloadmeta) handleEvents(evs []wmdef.CollectorEvent) {
 		filteredEvents[sub] = make([]wmdef.Event, 0, len(evs))
 	}
 
+	// the events that changed the store, recorded in the journal once the
+	// store is unlocked
+	var journalEvents []wmdef.CollectorEvent
+	now := time.Now()
+
 	for _, ev := range evs {
 		entityID := ev.Entity.GetID()
 
@@ -651,6 +656,8 @@ func (w *workloadmeta) handleEvents(evs []wmdef.CollectorEvent) {
 			if !changed {
 				continue
 			}
+
+			journalEvents = append(journalEvents, ev)
 		case wmdef.EventTypeUnset:
 			// if the entity we're trying to remove was not
 			// present in the store, skip generating any
@@ -683,6 +690,8 @@ func (w *workloadmeta) handleEvents(evs []wmdef.CollectorEvent) {
 			if len(c.sources) == 0 {
 				delete(entitiesOfKind, entityID.ID)
 			}
+
+			journalEvents = append(journalEvents, ev)
 		default:
 			w.log.Errorf("cannot handle event of type %d. event dump: %+v", ev.Type, ev)
 		}
@@ -741,6 +750,10 @@ func (w *workloadmeta) handleEvents(evs []wmdef.CollectorEvent) {
 	// process an event.
 	w.storeMut.Unlock()
 
+	for _, ev := range journalEvents {
+		w.journal.record(ev, now)
+	}
+
 	for _, sub := range w.subscribers {
 		if evs, found := filteredEvents[sub]; found {
 			if len(evs) == 0 {
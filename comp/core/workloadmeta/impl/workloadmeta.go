@@ -9,6 +9,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -45,6 +46,8 @@ type workloadmeta struct {
 
 	ongoingPullsMut sync.Mutex
 	ongoingPulls    map[string]time.Time // collector ID => time when last pull started
+
+	journal *eventJournal
 }
 
 // Dependencies defines the dependencies of the workloadmeta component.
@@ -83,6 +86,10 @@ func NewWorkloadMeta(deps Dependencies) Provider {
 		collectors:   make(map[string]wmdef.Collector),
 		eventCh:      make(chan []wmdef.CollectorEvent, eventChBufferSize),
 		ongoingPulls: make(map[string]time.Time),
+		journal: newEventJournal(
+			deps.Config.GetInt("workloadmeta.event_journal.max_events_per_entity"),
+			deps.Config.GetInt("workloadmeta.event_journal.max_entities_per_kind"),
+		),
 	}
 
 	deps.Lc.Append(compdef.Hook{OnStart: func(_ context.Context) error {
@@ -110,7 +117,7 @@ func NewWorkloadMeta(deps Dependencies) Provider {
 
 	return Provider{
 		Comp:          wm,
-		FlareProvider: flaretypes.NewProvider(wm.sbomFlareProvider),
+		FlareProvider: flaretypes.NewProvider(wm.flareProvider),
 		Endpoint:      api.NewAgentEndpointProvider(wm.writeResponse, "/workload-list", "GET"),
 	}
 }
@@ -124,7 +131,26 @@ func (w *workloadmeta) writeResponse(writer http.ResponseWriter, r *http.Request
 		}
 	}
 
-	response := w.Dump(verbose)
+	var response interface{}
+	if params.Has("history") {
+		kind, id := parseHistoryEntity(params.Get("history"))
+
+		var until time.Time
+		if v := params.Get("until"); v != "" {
+			var err error
+			until, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				httputils.SetJSONError(writer, w.log.Errorf("Invalid until parameter %q: %v", v, err), 400)
+				return
+			}
+		}
+
+		response = w.journal.history(kind, id, until)
+	} else if params.Get("snapshot") == "true" {
+		response = w.snapshot()
+	} else {
+		response = w.Dump(verbose)
+	}
 	jsonDump, err := json.Marshal(response)
 	if err != nil {
 		httputils.SetJSONError(writer, w.log.Errorf("Unable to marshal workload list response: %v", err), 500)
@@ -133,3 +159,32 @@ func (w *workloadmeta) writeResponse(writer http.ResponseWriter, r *http.Request
 
 	writer.Write(jsonDump)
 }
+
+// historyKinds are the kinds that can prefix the entities given to
+// parseHistoryEntity
+var historyKinds = map[wmdef.Kind]struct{}{
+	wmdef.KindContainer:              {},
+	wmdef.KindKubernetesPod:          {},
+	wmdef.KindKubernetesMetadata:     {},
+	wmdef.KindKubernetesDeployment:   {},
+	wmdef.KindECSTask:                {},
+	wmdef.KindContainerImageMetadata: {},
+	wmdef.KindProcess:                {},
+	wmdef.KindHost:                   {},
+	wmdef.KindSystemdUnit:            {},
+}
+
+// parseHistoryEntity parses an entity given as <kind>/<id>, or as <id> to
+// match the entities of any kind with that ID. IDs can contain slashes, like
+// the ones of the kubernetes metadata, so the entity is only split when it
+// starts with a known kind.
+func parseHistoryEntity(entity string) (wmdef.Kind, string) {
+	kind, id, found := strings.Cut(entity, "/")
+	if !found {
+		return "", entity
+	}
+	if _, ok := historyKinds[wmdef.Kind(kind)]; !ok {
+		return "", entity
+	}
+	return wmdef.Kind(kind), id
+}
@@ -209,8 +209,11 @@ type DefaultForwarder struct {
 	domainForwarders map[string]*domainForwarder
 	domainResolvers  map[string]resolver.DomainResolver
 	healthChecker    *forwarderHealth
-	internalState    *atomic.Uint32
-	m                sync.Mutex // To control Start/Stop races
+	// additionalAPIKeys holds the API keys of 'additional_endpoints' to replace them when they are updated at runtime
+	additionalAPIKeys     map[string][]string
+	additionalAPIKeysLock sync.Mutex
+	internalState         *atomic.Uint32
+	m                     sync.Mutex // To control Start/Stop races
 
 	completionHandler transaction.HTTPCompletionHandler
 
@@ -335,16 +338,20 @@ func NewDefaultForwarder(config config.Component, log log.Component, options *Op
 		}
 	}
 
+	f.additionalAPIKeys = config.GetStringMapStringSlice("additional_endpoints")
 	config.OnUpdate(func(setting string, oldValue, newValue any) {
-		if setting != "api_key" {
-			return
-		}
-		oldAPIKey, ok1 := oldValue.(string)
-		newAPIKey, ok2 := newValue.(string)
-		if ok1 && ok2 {
-			for _, dr := range f.domainResolvers {
-				dr.UpdateAPIKey(oldAPIKey, newAPIKey)
+		switch setting {
+		case "api_key":
+			oldAPIKey, ok1 := oldValue.(string)
+			newAPIKey, ok2 := newValue.(string)
+			if ok1 && ok2 {
+				for _, dr := range f.domainResolvers {
+					dr.UpdateAPIKey(oldAPIKey, newAPIKey)
+				}
 			}
+		case "additional_endpoints":
+			// the API keys of additional endpoints can be secrets that get refreshed
+			f.updateAdditionalAPIKeys(config.GetStringMapStringSlice("additional_endpoints"))
 		}
 	})
 
@@ -378,6 +385,28 @@ func getAgentName(options *Options) string {
 	return ""
 }
 
+// updateAdditionalAPIKeys replaces the API keys of the additional endpoints that changed in the configuration. Keys
+// are matched by their position, adding or removing endpoints still requires a restart.
+func (f *DefaultForwarder) updateAdditionalAPIKeys(additionalAPIKeys map[string][]string) {
+	f.additionalAPIKeysLock.Lock()
+	defer f.additionalAPIKeysLock.Unlock()
+	for domain, newKeys := range additionalAPIKeys {
+		oldKeys := f.additionalAPIKeys[domain]
+		versionedDomain, _ := utils.AddAgentVersionToDomain(domain, "app")
+		dr, ok := f.domainResolvers[versionedDomain]
+		if !ok || len(oldKeys) != len(newKeys) {
+			continue
+		}
+		for i := range newKeys {
+			if oldKeys[i] != newKeys[i] {
+				f.log.Infof("Updating an API key of the additional endpoint '%s'", domain)
+				dr.UpdateAPIKey(strings.TrimSpace(oldKeys[i]), strings.TrimSpace(newKeys[i]))
+			}
+		}
+	}
+	f.additionalAPIKeys = additionalAPIKeys
+}
+
 // Start initialize and runs the forwarder.
 func (f *DefaultForwarder) Start() error {
 	// Lock so we can't stop a Forwarder while is starting
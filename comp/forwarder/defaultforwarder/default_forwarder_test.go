@@ -55,3 +55,29 @@ func TestDefaultForwarderUpdateAPIKey(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, expectData, string(data))
 }
+
+func TestDefaultForwarderUpdateAdditionalEndpointsAPIKey(t *testing.T) {
+	mockConfig := config.NewMock(t)
+	mockConfig.Set("api_key", "api_key1", pkgconfigmodel.SourceAgentRuntime)
+	mockConfig.Set("additional_endpoints", map[string][]string{
+		"example2.com": {"api_key2", "api_key3"},
+	}, pkgconfigmodel.SourceAgentRuntime)
+	log := logmock.New(t)
+
+	keysPerDomains := map[string][]string{
+		"example1.com": {"api_key1"},
+		"example2.com": {"api_key2", "api_key3"},
+	}
+	forwarderOptions := NewOptions(mockConfig, log, keysPerDomains)
+	forwarder := NewDefaultForwarder(mockConfig, log, forwarderOptions)
+
+	// a secret of an additional endpoint is refreshed
+	mockConfig.Set("additional_endpoints", map[string][]string{
+		"example2.com": {"api_key2", "api_key4"},
+	}, pkgconfigmodel.SourceAgentRuntime)
+
+	expectData := `{"example1.com":["api_key1"],"example2.com":["api_key2","api_key4"]}`
+	data, err := json.Marshal(forwarder.domainAPIKeyMap())
+	require.NoError(t, err)
+	assert.Equal(t, expectData, string(data))
+}
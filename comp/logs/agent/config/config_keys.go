@@ -11,6 +11,7 @@ import (
 
 	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	pkgconfigutils "github.com/DataDog/datadog-agent/pkg/config/utils"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -136,6 +137,20 @@ func (l *LogsConfigKeys) getAPIKeyGetter() func() string {
 	}
 }
 
+// getAdditionalEndpointAPIKeyGetter returns a getter function to retrieve the API key of the additional endpoint at the
+// given index. Like getAPIKeyGetter, the value is refetched upon each call so that API keys coming from secrets can be
+// refreshed at runtime. The initial API key is returned if the endpoint is no longer in the configuration.
+func (l *LogsConfigKeys) getAdditionalEndpointAPIKeyGetter(index int, apiKey string) func() string {
+	apiKey = pkgconfigutils.SanitizeAPIKey(apiKey)
+	return func() string {
+		endpoints := l.getAdditionalEndpoints()
+		if index < len(endpoints) && endpoints[index].APIKey != "" {
+			return pkgconfigutils.SanitizeAPIKey(endpoints[index].APIKey)
+		}
+		return apiKey
+	}
+}
+
 func (l *LogsConfigKeys) connectionResetInterval() time.Duration {
 	return time.Duration(l.getConfig().GetInt(l.getConfigKey("connection_reset_interval"))) * time.Second
 
@@ -41,6 +41,26 @@ func TestGetAPIKeyGetter(t *testing.T) {
 	assert.Equal(t, "5678", l.getAPIKeyGetter()())
 }
 
+func TestGetAdditionalEndpointAPIKeyGetter(t *testing.T) {
+	configMock, l := getLogsConfigKeys(t)
+	configMock.SetWithoutSource("logs_config.additional_endpoints", []map[string]interface{}{
+		{"api_key": "apiKey2", "Host": "http://localhost1"},
+	})
+
+	getter := l.getAdditionalEndpointAPIKeyGetter(0, "apiKey2")
+	assert.Equal(t, "apiKey2", getter())
+
+	// the API key is refreshed, for example after a secret rotation
+	configMock.SetWithoutSource("logs_config.additional_endpoints", []map[string]interface{}{
+		{"api_key": "apiKey3", "Host": "http://localhost1"},
+	})
+	assert.Equal(t, "apiKey3", getter())
+
+	// the initial API key is kept when the endpoint is removed
+	configMock.SetWithoutSource("logs_config.additional_endpoints", []map[string]interface{}{})
+	assert.Equal(t, "apiKey2", getter())
+}
+
 func TestGetAdditionalEndpoints(t *testing.T) {
 	expected := []unmarshalEndpoint{
 		{
@@ -112,15 +112,16 @@ func NewHTTPEndpoint(logsConfig *LogsConfigKeys) Endpoint {
 }
 
 // The setting from 'logs_config.additional_endpoints' is directly unmarshalled from the configuration into a
-// []unmarshalEndpoint and do not use the constructors. In this case, apiKeyGetter is initialized to returned the API
-// key from the loaded data instead of 'api_key'/'logs_config.api_key'.
+// []unmarshalEndpoint and do not use the constructors. In this case, apiKeyGetter is initialized to return the API
+// key of the additional endpoint from the configuration instead of 'api_key'/'logs_config.api_key'.
 
 func loadTCPAdditionalEndpoints(main Endpoint, l *LogsConfigKeys) []Endpoint {
 	additionals := l.getAdditionalEndpoints()
 
 	newEndpoints := make([]Endpoint, 0, len(additionals))
-	for _, e := range additionals {
+	for i, e := range additionals {
 		newE := NewEndpoint(e.APIKey, e.Host, e.Port, false)
+		newE.apiKeyGetter = l.getAdditionalEndpointAPIKeyGetter(i, e.APIKey)
 
 		newE.UseCompression = e.UseCompression
 		newE.CompressionLevel = e.CompressionLevel
@@ -151,8 +152,9 @@ func loadHTTPAdditionalEndpoints(main Endpoint, l *LogsConfigKeys, intakeTrackTy
 	additionals := l.getAdditionalEndpoints()
 
 	newEndpoints := make([]Endpoint, 0, len(additionals))
-	for _, e := range additionals {
+	for i, e := range additionals {
 		newE := NewEndpoint(e.APIKey, e.Host, e.Port, false)
+		newE.apiKeyGetter = l.getAdditionalEndpointAPIKeyGetter(i, e.APIKey)
 
 		newE.UseCompression = main.UseCompression
 		newE.CompressionLevel = main.CompressionLevel
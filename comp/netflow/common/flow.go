@@ -72,6 +72,9 @@ type Flow struct {
 
 	NextHop []byte // FLOW KEY
 
+	// OtherBucket is set on flows aggregating the conversations that are not top talkers
+	OtherBucket bool
+
 	// Configured fields
 	AdditionalFields AdditionalFields
 }
@@ -28,6 +28,14 @@ type NetflowConfig struct {
 	AggregatorPortRollupThreshold int              `mapstructure:"aggregator_port_rollup_threshold"`
 	AggregatorPortRollupDisabled  bool             `mapstructure:"aggregator_port_rollup_disabled"`
 
+	// AggregatorTopTalkers is the number of flows with the most bytes kept per exporter at each flush,
+	// the other flows are folded into "other" flows. 0 disables the top talkers selection.
+	// Between two flushes, at most 10 times this number of flows are accumulated per exporter.
+	AggregatorTopTalkers int `mapstructure:"aggregator_top_talkers"`
+
+	// SamplingRateExtrapolationEnabled multiplies bytes and packets by the sampling rate advertised by the exporter
+	SamplingRateExtrapolationEnabled bool `mapstructure:"sampling_rate_extrapolation_enabled"`
+
 	// AggregatorRollupTrackerRefreshInterval is useful to speed up testing to avoid wait for 1h default
 	AggregatorRollupTrackerRefreshInterval uint `mapstructure:"aggregator_rollup_tracker_refresh_interval"`
 
@@ -35,6 +43,18 @@ type NetflowConfig struct {
 	PrometheusListenerEnabled bool   `mapstructure:"prometheus_listener_enabled"`
 
 	ReverseDNSEnrichmentEnabled bool `mapstructure:"reverse_dns_enrichment_enabled"`
+
+	Enrichment EnrichmentConfig `mapstructure:"enrichment"`
+}
+
+// EnrichmentConfig contains the location of the databases used to enrich flow endpoints
+type EnrichmentConfig struct {
+	// GeoIPDatabase is the path to a MaxMind-format (mmdb) country or city database
+	GeoIPDatabase string `mapstructure:"geoip_database"`
+	// ASNDatabase is the path to a MaxMind-format (mmdb) ASN database
+	ASNDatabase string `mapstructure:"asn_database"`
+	// CIDRLabelsFile is the path to a YAML file mapping CIDRs to site/team/env labels
+	CIDRLabelsFile string `mapstructure:"cidr_labels_file"`
 }
 
 // ListenerConfig contains configuration for a single flow listener
@@ -133,6 +153,10 @@ func (mainConfig *NetflowConfig) SetDefaults(namespace string, logger log.Compon
 		mainConfig.AggregatorRollupTrackerRefreshInterval = common.DefaultAggregatorRollupTrackerRefreshInterval
 	}
 
+	if mainConfig.AggregatorTopTalkers < 0 {
+		return fmt.Errorf("`aggregator_top_talkers` must be positive or zero, got %d", mainConfig.AggregatorTopTalkers)
+	}
+
 	if mainConfig.PrometheusListenerAddress == "" {
 		mainConfig.PrometheusListenerAddress = common.DefaultPrometheusListenerAddress
 	}
@@ -56,6 +56,11 @@ network_devices:
           my-ns2<abc
           zz
     reverse_dns_enrichment_enabled: true
+    aggregator_top_talkers: 500
+    sampling_rate_extrapolation_enabled: true
+    enrichment:
+      geoip_database: /etc/datadog-agent/GeoLite2-Country.mmdb
+      cidr_labels_file: /etc/datadog-agent/labels.yaml
 `,
 			expectedConfig: NetflowConfig{
 				Enabled:                                true,
@@ -84,7 +89,13 @@ network_devices:
 						Namespace: "my-ns2-abczz",
 					},
 				},
-				ReverseDNSEnrichmentEnabled: true,
+				ReverseDNSEnrichmentEnabled:      true,
+				AggregatorTopTalkers:             500,
+				SamplingRateExtrapolationEnabled: true,
+				Enrichment: EnrichmentConfig{
+					GeoIPDatabase:  "/etc/datadog-agent/GeoLite2-Country.mmdb",
+					CIDRLabelsFile: "/etc/datadog-agent/labels.yaml",
+				},
 			},
 		},
 		{
@@ -159,6 +170,16 @@ network_devices:
 `,
 			expectedError: "the provided flow type `invalidType` is not valid",
 		},
+		{
+			name: "invalid top talkers",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    aggregator_top_talkers: -1
+`,
+			expectedError: "`aggregator_top_talkers` must be positive or zero, got -1",
+		},
 		{
 			name: "invalid namespace with >100 chars",
 			configYaml: `
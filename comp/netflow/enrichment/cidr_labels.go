@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/payload"
+)
+
+// cidrLabelsFile is the format of the CIDR labels file, e.g.
+//
+//	networks:
+//	  - cidr: 10.1.0.0/16
+//	    site: paris
+//	    team: network
+//	    env: prod
+type cidrLabelsFile struct {
+	Networks []cidrLabelsEntry `yaml:"networks"`
+}
+
+type cidrLabelsEntry struct {
+	CIDR string `yaml:"cidr"`
+	Site string `yaml:"site"`
+	Team string `yaml:"team"`
+	Env  string `yaml:"env"`
+}
+
+type cidrLabel struct {
+	network *net.IPNet
+	labels  *payload.Labels
+}
+
+// cidrLabels matches IPs against the configured networks, the most specific network wins
+type cidrLabels struct {
+	// networks are sorted from the longest to the shortest prefix
+	networks []cidrLabel
+}
+
+func loadCIDRLabels(path string) (*cidrLabels, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CIDR labels file `%s`: %w", path, err)
+	}
+	return parseCIDRLabels(content)
+}
+
+func parseCIDRLabels(content []byte) (*cidrLabels, error) {
+	var file cidrLabelsFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("error parsing CIDR labels file: %w", err)
+	}
+
+	labels := &cidrLabels{}
+	for i, entry := range file.Networks {
+		_, network, err := net.ParseCIDR(entry.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("network %d: invalid cidr `%s`: %w", i, entry.CIDR, err)
+		}
+		if entry.Site == "" && entry.Team == "" && entry.Env == "" {
+			return nil, fmt.Errorf("network %d: `site`, `team` or `env` must be provided for `%s`", i, entry.CIDR)
+		}
+		labels.networks = append(labels.networks, cidrLabel{
+			network: network,
+			labels: &payload.Labels{
+				Site: entry.Site,
+				Team: entry.Team,
+				Env:  entry.Env,
+			},
+		})
+	}
+	sort.SliceStable(labels.networks, func(i, j int) bool {
+		onesI, _ := labels.networks[i].network.Mask.Size()
+		onesJ, _ := labels.networks[j].network.Mask.Size()
+		return onesI > onesJ
+	})
+	return labels, nil
+}
+
+// lookup returns the labels of the most specific network containing ip, or nil
+func (c *cidrLabels) lookup(ip net.IP) *payload.Labels {
+	if c == nil {
+		return nil
+	}
+	for _, entry := range c.networks {
+		if entry.network.Contains(ip) {
+			return entry.labels
+		}
+	}
+	return nil
+}
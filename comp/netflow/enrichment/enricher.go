@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package enrichment adds geolocation, autonomous system and user defined labels to flow endpoints.
+package enrichment
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/config"
+	"github.com/DataDog/datadog-agent/comp/netflow/payload"
+)
+
+// mmdbReader is the subset of maxminddb.Reader used by the enricher, it allows to fake databases in tests
+type mmdbReader interface {
+	Lookup(ip net.IP, result interface{}) error
+	Close() error
+}
+
+// countryRecord is the part of a MaxMind-format country or city record used for enrichment
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// asnRecord is the part of a MaxMind-format ASN record used for enrichment
+type asnRecord struct {
+	AutonomousSystemNumber       uint32 `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// Enricher looks up flow endpoints in the configured databases.
+// A nil *Enricher is valid and does not enrich anything.
+type Enricher struct {
+	geoIP      mmdbReader
+	asn        mmdbReader
+	cidrLabels *cidrLabels
+}
+
+// NewEnricher opens the databases listed in the config. It returns nil when no database is configured.
+func NewEnricher(conf config.EnrichmentConfig) (*Enricher, error) {
+	if conf.GeoIPDatabase == "" && conf.ASNDatabase == "" && conf.CIDRLabelsFile == "" {
+		return nil, nil
+	}
+
+	e := &Enricher{}
+	if conf.GeoIPDatabase != "" {
+		reader, err := maxminddb.Open(conf.GeoIPDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("error opening GeoIP database `%s`: %w", conf.GeoIPDatabase, err)
+		}
+		e.geoIP = reader
+	}
+	if conf.ASNDatabase != "" {
+		reader, err := maxminddb.Open(conf.ASNDatabase)
+		if err != nil {
+			e.Close() //nolint:errcheck
+			return nil, fmt.Errorf("error opening ASN database `%s`: %w", conf.ASNDatabase, err)
+		}
+		e.asn = reader
+	}
+	if conf.CIDRLabelsFile != "" {
+		labels, err := loadCIDRLabels(conf.CIDRLabelsFile)
+		if err != nil {
+			e.Close() //nolint:errcheck
+			return nil, err
+		}
+		e.cidrLabels = labels
+	}
+	return e, nil
+}
+
+// EnrichEndpoint adds the geolocation, autonomous system and labels of ipAddr to endpoint.
+// Lookup errors are returned once the endpoint has been enriched with what could be found.
+func (e *Enricher) EnrichEndpoint(ipAddr []byte, endpoint *payload.Endpoint) error {
+	if e == nil {
+		return nil
+	}
+	ip := net.IP(ipAddr)
+	if len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+		return nil
+	}
+
+	var errs []error
+	geo := payload.Geo{}
+	if e.geoIP != nil {
+		var record countryRecord
+		if err := e.geoIP.Lookup(ip, &record); err != nil {
+			errs = append(errs, fmt.Errorf("GeoIP lookup failed for %s: %w", ip, err))
+		}
+		geo.CountryISOCode = record.Country.ISOCode
+	}
+	if e.asn != nil {
+		var record asnRecord
+		if err := e.asn.Lookup(ip, &record); err != nil {
+			errs = append(errs, fmt.Errorf("ASN lookup failed for %s: %w", ip, err))
+		}
+		geo.ASNumber = record.AutonomousSystemNumber
+		geo.ASOrganization = record.AutonomousSystemOrganization
+	}
+	if geo != (payload.Geo{}) {
+		endpoint.Geo = &geo
+	}
+
+	if labels := e.cidrLabels.lookup(ip); labels != nil {
+		endpoint.Labels = labels
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the databases
+func (e *Enricher) Close() error {
+	if e == nil {
+		return nil
+	}
+	var errs []error
+	for _, reader := range []mmdbReader{e.geoIP, e.asn} {
+		if reader == nil {
+			continue
+		}
+		if err := reader.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,223 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/config"
+	"github.com/DataDog/datadog-agent/comp/netflow/payload"
+)
+
+// fakeReader returns the records registered for each IP
+type fakeReader struct {
+	countries map[string]string
+	asns      map[string]asnRecord
+	err       error
+	closed    bool
+}
+
+func (r *fakeReader) Lookup(ip net.IP, result interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	switch record := result.(type) {
+	case *countryRecord:
+		record.Country.ISOCode = r.countries[ip.String()]
+	case *asnRecord:
+		*record = r.asns[ip.String()]
+	}
+	return nil
+}
+
+func (r *fakeReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+const testCIDRLabels = `
+networks:
+  - cidr: 10.0.0.0/8
+    site: paris
+    env: prod
+  - cidr: 10.1.0.0/16
+    site: nyc
+    team: network
+  - cidr: 2001:db8::/32
+    env: staging
+`
+
+func TestEnricher_EnrichEndpoint(t *testing.T) {
+	labels, err := parseCIDRLabels([]byte(testCIDRLabels))
+	require.NoError(t, err)
+	geoIP := &fakeReader{countries: map[string]string{"8.8.8.8": "US"}}
+	asn := &fakeReader{asns: map[string]asnRecord{"8.8.8.8": {AutonomousSystemNumber: 15169, AutonomousSystemOrganization: "GOOGLE"}}}
+	enricher := &Enricher{geoIP: geoIP, asn: asn, cidrLabels: labels}
+
+	tests := []struct {
+		name           string
+		ip             net.IP
+		expectedGeo    *payload.Geo
+		expectedLabels *payload.Labels
+	}{
+		{
+			name:        "public ip",
+			ip:          net.ParseIP("8.8.8.8").To4(),
+			expectedGeo: &payload.Geo{CountryISOCode: "US", ASNumber: 15169, ASOrganization: "GOOGLE"},
+		},
+		{
+			name:           "most specific network wins",
+			ip:             net.ParseIP("10.1.2.3").To4(),
+			expectedLabels: &payload.Labels{Site: "nyc", Team: "network"},
+		},
+		{
+			name:           "broader network",
+			ip:             net.ParseIP("10.2.2.3").To4(),
+			expectedLabels: &payload.Labels{Site: "paris", Env: "prod"},
+		},
+		{
+			name:           "ipv6",
+			ip:             net.ParseIP("2001:db8::1"),
+			expectedLabels: &payload.Labels{Env: "staging"},
+		},
+		{
+			name: "unknown ip",
+			ip:   net.ParseIP("192.168.1.1").To4(),
+		},
+		{
+			name: "invalid ip",
+			ip:   net.IP{1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := payload.Endpoint{}
+			err := enricher.EnrichEndpoint(tt.ip, &endpoint)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedGeo, endpoint.Geo)
+			assert.Equal(t, tt.expectedLabels, endpoint.Labels)
+		})
+	}
+
+	require.NoError(t, enricher.Close())
+	assert.True(t, geoIP.closed)
+	assert.True(t, asn.closed)
+}
+
+func TestEnricher_EnrichEndpoint_lookupError(t *testing.T) {
+	labels, err := parseCIDRLabels([]byte(testCIDRLabels))
+	require.NoError(t, err)
+	enricher := &Enricher{
+		geoIP:      &fakeReader{err: errors.New("corrupted database")},
+		asn:        &fakeReader{asns: map[string]asnRecord{"10.1.2.3": {AutonomousSystemNumber: 64512}}},
+		cidrLabels: labels,
+	}
+
+	endpoint := payload.Endpoint{}
+	err = enricher.EnrichEndpoint(net.ParseIP("10.1.2.3").To4(), &endpoint)
+	assert.EqualError(t, err, "GeoIP lookup failed for 10.1.2.3: corrupted database")
+	assert.Equal(t, &payload.Geo{ASNumber: 64512}, endpoint.Geo)
+	assert.Equal(t, &payload.Labels{Site: "nyc", Team: "network"}, endpoint.Labels)
+}
+
+func TestEnricher_nil(t *testing.T) {
+	enricher, err := NewEnricher(config.EnrichmentConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, enricher)
+
+	endpoint := payload.Endpoint{IP: "10.1.2.3"}
+	assert.NoError(t, enricher.EnrichEndpoint(net.ParseIP("10.1.2.3").To4(), &endpoint))
+	assert.Equal(t, payload.Endpoint{IP: "10.1.2.3"}, endpoint)
+	assert.NoError(t, enricher.Close())
+}
+
+func TestNewEnricher(t *testing.T) {
+	dir := t.TempDir()
+	labelsFile := filepath.Join(dir, "labels.yaml")
+	require.NoError(t, os.WriteFile(labelsFile, []byte(testCIDRLabels), 0600))
+
+	enricher, err := NewEnricher(config.EnrichmentConfig{CIDRLabelsFile: labelsFile})
+	require.NoError(t, err)
+	require.NotNil(t, enricher)
+	assert.Len(t, enricher.cidrLabels.networks, 3)
+
+	_, err = NewEnricher(config.EnrichmentConfig{CIDRLabelsFile: filepath.Join(dir, "missing.yaml")})
+	assert.ErrorContains(t, err, "error reading CIDR labels file")
+
+	_, err = NewEnricher(config.EnrichmentConfig{GeoIPDatabase: labelsFile})
+	assert.ErrorContains(t, err, "error opening GeoIP database")
+
+	_, err = NewEnricher(config.EnrichmentConfig{ASNDatabase: filepath.Join(dir, "missing.mmdb"), CIDRLabelsFile: labelsFile})
+	assert.ErrorContains(t, err, "error opening ASN database")
+}
+
+func TestParseCIDRLabels_errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{
+			name:          "invalid yaml",
+			content:       "networks: [",
+			expectedError: "error parsing CIDR labels file",
+		},
+		{
+			name: "invalid cidr",
+			content: `
+networks:
+  - cidr: 10.0.0.0/33
+    site: paris
+`,
+			expectedError: "network 0: invalid cidr `10.0.0.0/33`",
+		},
+		{
+			name: "no labels",
+			content: `
+networks:
+  - cidr: 10.0.0.0/8
+`,
+			expectedError: "network 0: `site`, `team` or `env` must be provided for `10.0.0.0/8`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCIDRLabels([]byte(tt.content))
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestEndpoint_MarshalJSON(t *testing.T) {
+	labels, err := parseCIDRLabels([]byte(testCIDRLabels))
+	require.NoError(t, err)
+	enricher := &Enricher{
+		geoIP:      &fakeReader{countries: map[string]string{"10.1.2.3": "FR"}},
+		cidrLabels: labels,
+	}
+
+	endpoint := payload.Endpoint{IP: "10.1.2.3", Port: "443", Mac: "00:00:00:00:00:00", Mask: "10.1.0.0/16"}
+	require.NoError(t, enricher.EnrichEndpoint(net.ParseIP("10.1.2.3").To4(), &endpoint))
+
+	payloadBytes, err := json.Marshal(endpoint)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"ip": "10.1.2.3",
+		"port": "443",
+		"mac": "00:00:00:00:00:00",
+		"mask": "10.1.0.0/16",
+		"geo": {"country_iso_code": "FR"},
+		"labels": {"site": "nyc", "team": "network"}
+	}`, string(payloadBytes))
+}
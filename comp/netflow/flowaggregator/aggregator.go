@@ -28,6 +28,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/comp/netflow/common"
 	"github.com/DataDog/datadog-agent/comp/netflow/config"
+	"github.com/DataDog/datadog-agent/comp/netflow/enrichment"
 	"github.com/DataDog/datadog-agent/comp/netflow/goflowlib"
 )
 
@@ -49,6 +50,8 @@ type FlowAggregator struct {
 	flushedFlowCount             *atomic.Uint64
 	hostname                     string
 	goflowPrometheusGatherer     prometheus.Gatherer
+	enricher                     *enrichment.Enricher
+	topTalkers                   int
 	TimeNowFunction              func() time.Time // Allows to mock time in tests
 
 	lastSequencePerExporter   map[sequenceDeltaKey]uint32
@@ -82,9 +85,13 @@ func NewFlowAggregator(sender sender.Sender, epForwarder eventplatform.Forwarder
 	flushInterval := time.Duration(config.AggregatorFlushInterval) * time.Second
 	flowContextTTL := time.Duration(config.AggregatorFlowContextTTL) * time.Second
 	rollupTrackerRefreshInterval := time.Duration(config.AggregatorRollupTrackerRefreshInterval) * time.Second
+	enricher, err := enrichment.NewEnricher(config.Enrichment)
+	if err != nil {
+		logger.Errorf("Flow enrichment disabled: %s", err)
+	}
 	return &FlowAggregator{
 		flowIn:                       make(chan *common.Flow, config.AggregatorBufferSize),
-		flowAcc:                      newFlowAccumulator(flushInterval, flowContextTTL, config.AggregatorPortRollupThreshold, config.AggregatorPortRollupDisabled, logger, rdnsQuerier),
+		flowAcc:                      newFlowAccumulator(flushInterval, flowContextTTL, config.AggregatorPortRollupThreshold, config.AggregatorPortRollupDisabled, config.SamplingRateExtrapolationEnabled, config.AggregatorTopTalkers, logger, rdnsQuerier),
 		FlushFlowsToSendInterval:     flushFlowsToSendInterval,
 		rollupTrackerRefreshInterval: rollupTrackerRefreshInterval,
 		sender:                       sender,
@@ -96,6 +103,8 @@ func NewFlowAggregator(sender sender.Sender, epForwarder eventplatform.Forwarder
 		flushedFlowCount:             atomic.NewUint64(0),
 		hostname:                     hostname,
 		goflowPrometheusGatherer:     prometheus.DefaultGatherer,
+		enricher:                     enricher,
+		topTalkers:                   config.AggregatorTopTalkers,
 		TimeNowFunction:              time.Now,
 		lastSequencePerExporter:      make(map[sequenceDeltaKey]uint32),
 		logger:                       logger,
@@ -114,6 +123,9 @@ func (agg *FlowAggregator) Stop() {
 	close(agg.stopChan)
 	<-agg.flushLoopDone
 	<-agg.runDone
+	if err := agg.enricher.Close(); err != nil {
+		agg.logger.Errorf("Error closing flow enrichment databases: %s", err)
+	}
 }
 
 // GetFlowInChan returns flow input chan
@@ -138,6 +150,12 @@ func (agg *FlowAggregator) run() {
 func (agg *FlowAggregator) sendFlows(flows []*common.Flow, flushTime time.Time) {
 	for _, flow := range flows {
 		flowPayload := buildPayload(flow, agg.hostname, flushTime)
+		if err := agg.enricher.EnrichEndpoint(flow.SrcAddr, &flowPayload.Source); err != nil {
+			agg.logger.Debugf("Error enriching flow source: %s", err)
+		}
+		if err := agg.enricher.EnrichEndpoint(flow.DstAddr, &flowPayload.Destination); err != nil {
+			agg.logger.Debugf("Error enriching flow destination: %s", err)
+		}
 
 		// Calling MarshalJSON directly as it's faster than calling json.Marshall
 		payloadBytes, err := flowPayload.MarshalJSON()
@@ -268,6 +286,18 @@ func (agg *FlowAggregator) flush() int {
 		}
 	}
 
+	if agg.topTalkers > 0 {
+		var foldedFlowsPerExporter map[exporterKey]int
+		flowsToFlush, foldedFlowsPerExporter = selectTopTalkers(flowsToFlush, agg.topTalkers)
+		for key, foldedFlows := range agg.flowAcc.popFoldedFlows() {
+			foldedFlowsPerExporter[key] += foldedFlows
+		}
+		for key, foldedFlows := range foldedFlowsPerExporter {
+			tags := []string{"device_namespace:" + key.Namespace, "exporter_ip:" + key.ExporterIP, "flow_type:" + string(key.FlowType)}
+			agg.sender.Count("datadog.netflow.aggregator.top_talkers.folded_flows", float64(foldedFlows), "", tags)
+		}
+	}
+
 	// TODO: Add flush stats to agent telemetry e.g. aggregator newFlushCountStats()
 	if len(flowsToFlush) > 0 {
 		agg.sendFlows(flowsToFlush, flushTime)
@@ -61,6 +61,7 @@ func buildPayload(aggFlow *common.Flow, hostname string, flushTime time.Time) pa
 		NextHop: payload.NextHop{
 			IP: format.IPAddr(aggFlow.NextHop),
 		},
+		OtherBucket:      aggFlow.OtherBucket,
 		AdditionalFields: aggFlow.AdditionalFields,
 	}
 }
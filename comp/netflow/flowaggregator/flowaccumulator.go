@@ -39,6 +39,17 @@ type flowAccumulator struct {
 	portRollupThreshold int
 	portRollupDisabled  bool
 
+	// samplingRateExtrapolation multiplies bytes and packets by the sampling rate of the exporter
+	samplingRateExtrapolation bool
+
+	// topTalkersIngestLimit is the maximum number of flows accumulated per exporter between two
+	// flushes, 0 meaning unlimited. The flows of the new conversations beyond the limit are folded
+	// into otherFlows as they are received, foldedFlows counting them.
+	topTalkersIngestLimit int
+	flowsPerExporter      map[exporterKey]int
+	otherFlows            *otherFlows
+	foldedFlows           map[exporterKey]int
+
 	hashCollisionFlowCount *atomic.Uint64
 
 	logger      log.Component
@@ -53,17 +64,22 @@ func newFlowContext(flow *common.Flow) flowContext {
 	}
 }
 
-func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowContextTTL time.Duration, portRollupThreshold int, portRollupDisabled bool, logger log.Component, rdnsQuerier rdnsquerier.Component) *flowAccumulator {
+func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowContextTTL time.Duration, portRollupThreshold int, portRollupDisabled bool, samplingRateExtrapolation bool, topTalkers int, logger log.Component, rdnsQuerier rdnsquerier.Component) *flowAccumulator {
 	return &flowAccumulator{
-		flows:                  make(map[uint64]flowContext),
-		flowFlushInterval:      aggregatorFlushInterval,
-		flowContextTTL:         aggregatorFlowContextTTL,
-		portRollup:             portrollup.NewEndpointPairPortRollupStore(portRollupThreshold),
-		portRollupThreshold:    portRollupThreshold,
-		portRollupDisabled:     portRollupDisabled,
-		hashCollisionFlowCount: atomic.NewUint64(0),
-		logger:                 logger,
-		rdnsQuerier:            rdnsQuerier,
+		flows:                     make(map[uint64]flowContext),
+		flowFlushInterval:         aggregatorFlushInterval,
+		flowContextTTL:            aggregatorFlowContextTTL,
+		portRollup:                portrollup.NewEndpointPairPortRollupStore(portRollupThreshold),
+		portRollupThreshold:       portRollupThreshold,
+		portRollupDisabled:        portRollupDisabled,
+		samplingRateExtrapolation: samplingRateExtrapolation,
+		topTalkersIngestLimit:     topTalkers * topTalkersIngestFactor,
+		flowsPerExporter:          make(map[exporterKey]int),
+		otherFlows:                newOtherFlows(),
+		foldedFlows:               make(map[exporterKey]int),
+		hashCollisionFlowCount:    atomic.NewUint64(0),
+		logger:                    logger,
+		rdnsQuerier:               rdnsQuerier,
 	}
 }
 
@@ -95,18 +111,76 @@ func (f *flowAccumulator) flush() []*common.Flow {
 		}
 		if flowCtx.flow != nil {
 			flowsToFlush = append(flowsToFlush, flowCtx.flow)
+			f.releaseExporterFlow(flowCtx.flow)
 			flowCtx.lastSuccessfulFlush = now
 			flowCtx.flow = nil
 		}
 		flowCtx.nextFlush = flowCtx.nextFlush.Add(f.flowFlushInterval)
 		f.flows[key] = flowCtx
 	}
+
+	if len(f.otherFlows.keys) > 0 {
+		flowsToFlush = append(flowsToFlush, f.otherFlows.list()...)
+		f.otherFlows = newOtherFlows()
+	}
 	return flowsToFlush
 }
 
+// popFoldedFlows returns the number of flows folded per exporter as they were received since
+// the last call
+func (f *flowAccumulator) popFoldedFlows() map[exporterKey]int {
+	f.flowsMutex.Lock()
+	defer f.flowsMutex.Unlock()
+
+	foldedFlows := f.foldedFlows
+	f.foldedFlows = make(map[exporterKey]int)
+	return foldedFlows
+}
+
+// foldAtIngest folds the flow of a new conversation into the "other" flows of its exporter when
+// the exporter already has the maximum number of accumulated flows, it returns whether the flow
+// was folded. It must be called with flowsMutex held.
+func (f *flowAccumulator) foldAtIngest(flow *common.Flow) bool {
+	if f.topTalkersIngestLimit == 0 {
+		return false
+	}
+	exporter := newExporterKey(flow)
+	if f.flowsPerExporter[exporter] < f.topTalkersIngestLimit {
+		f.flowsPerExporter[exporter]++
+		return false
+	}
+	f.otherFlows.fold(exporter, flow)
+	f.foldedFlows[exporter]++
+	return true
+}
+
+// releaseExporterFlow frees the place of a flushed flow in the flows of its exporter.
+// It must be called with flowsMutex held.
+func (f *flowAccumulator) releaseExporterFlow(flow *common.Flow) {
+	if f.topTalkersIngestLimit == 0 {
+		return
+	}
+	exporter := newExporterKey(flow)
+	if f.flowsPerExporter[exporter] <= 1 {
+		delete(f.flowsPerExporter, exporter)
+		return
+	}
+	f.flowsPerExporter[exporter]--
+}
+
 func (f *flowAccumulator) add(flowToAdd *common.Flow) {
 	f.logger.Tracef("Add new flow: %+v", flowToAdd)
 
+	// Flows are extrapolated before being aggregated since the sampling rate
+	// can change between two flows having the same aggregation key. The
+	// sampling rate is then reset so that the flows aren't extrapolated again
+	// once sent.
+	if f.samplingRateExtrapolation && flowToAdd.SamplingRate > 1 {
+		flowToAdd.Bytes *= flowToAdd.SamplingRate
+		flowToAdd.Packets *= flowToAdd.SamplingRate
+		flowToAdd.SamplingRate = 1
+	}
+
 	if !f.portRollupDisabled {
 		// Handle port rollup
 		f.portRollup.Add(flowToAdd.SrcAddr, flowToAdd.DstAddr, uint16(flowToAdd.SrcPort), uint16(flowToAdd.DstPort))
@@ -124,6 +198,9 @@ func (f *flowAccumulator) add(flowToAdd *common.Flow) {
 
 	aggHash := flowToAdd.AggregationHash()
 	aggFlow, ok := f.flows[aggHash]
+	if (!ok || aggFlow.flow == nil) && f.foldAtIngest(flowToAdd) {
+		return
+	}
 	if !ok {
 		f.flows[aggHash] = newFlowContext(flowToAdd)
 		f.addRDNSEnrichment(aggHash, flowToAdd.SrcAddr, flowToAdd.DstAddr)
@@ -17,6 +17,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // MockTimeNow mocks time.Now
@@ -92,7 +93,7 @@ func Test_flowAccumulator_add(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier)
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, false, false, 0, logger, rdnsQuerier)
 	acc.add(flowA1)
 	acc.add(flowA2)
 	acc.add(flowB1)
@@ -115,6 +116,93 @@ func Test_flowAccumulator_add(t *testing.T) {
 	assert.Equal(t, []byte{10, 10, 10, 30}, wrappedFlowB.flow.DstAddr)
 }
 
+func Test_flowAccumulator_samplingRateExtrapolation(t *testing.T) {
+	logger := logmock.New(t)
+	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
+
+	newFlow := func(samplingRate uint64) *common.Flow {
+		return &common.Flow{
+			FlowType:     common.TypeSFlow5,
+			ExporterAddr: []byte{127, 0, 0, 1},
+			SamplingRate: samplingRate,
+			Bytes:        100,
+			Packets:      2,
+			SrcAddr:      []byte{10, 10, 10, 10},
+			DstAddr:      []byte{10, 10, 10, 20},
+			IPProtocol:   uint32(6),
+			SrcPort:      2000,
+			DstPort:      80,
+		}
+	}
+
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, false, true, 0, logger, rdnsQuerier)
+	flow := newFlow(1000)
+	acc.add(flow)
+	// the sampling rate changed on the exporter
+	acc.add(newFlow(10))
+	// unknown sampling rate
+	acc.add(newFlow(0))
+
+	assert.Equal(t, 1, len(acc.flows))
+	wrappedFlow := acc.flows[flow.AggregationHash()]
+	assert.Equal(t, uint64(100*1000+100*10+100), wrappedFlow.flow.Bytes)
+	assert.Equal(t, uint64(2*1000+2*10+2), wrappedFlow.flow.Packets)
+	// the flow is sent as extrapolated
+	assert.Equal(t, uint64(1), wrappedFlow.flow.SamplingRate)
+
+	acc = newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, false, false, 0, logger, rdnsQuerier)
+	acc.add(newFlow(1000))
+	wrappedFlow = acc.flows[flow.AggregationHash()]
+	assert.Equal(t, uint64(100), wrappedFlow.flow.Bytes)
+	assert.Equal(t, uint64(2), wrappedFlow.flow.Packets)
+}
+
+func Test_flowAccumulator_topTalkersIngestLimit(t *testing.T) {
+	logger := logmock.New(t)
+	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
+
+	newFlow := func(dst byte) *common.Flow {
+		return &common.Flow{
+			FlowType:     common.TypeNetFlow9,
+			ExporterAddr: []byte{127, 0, 0, 1},
+			Bytes:        100,
+			Packets:      2,
+			SrcAddr:      []byte{10, 10, 10, 10},
+			DstAddr:      []byte{10, 10, 10, dst},
+			IPProtocol:   uint32(6),
+			SrcPort:      2000,
+			DstPort:      80,
+		}
+	}
+
+	// 1 top talker keeps up to 10 flows per exporter between two flushes
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, true, false, 1, logger, rdnsQuerier)
+	for dst := byte(0); dst < 15; dst++ {
+		acc.add(newFlow(dst))
+	}
+	// the flows of the accumulated conversations are still aggregated
+	acc.add(newFlow(0))
+
+	assert.Equal(t, 10, len(acc.flows))
+	assert.Equal(t, uint64(200), acc.flows[newFlow(0).AggregationHash()].flow.Bytes)
+	exporter := exporterKey{ExporterIP: "127.0.0.1", FlowType: common.TypeNetFlow9}
+	assert.Equal(t, map[exporterKey]int{exporter: 5}, acc.popFoldedFlows())
+	assert.Empty(t, acc.popFoldedFlows())
+
+	flows := acc.flush()
+	require.Len(t, flows, 11)
+	otherFlow := flows[10]
+	assert.True(t, otherFlow.OtherBucket)
+	assert.Equal(t, uint64(500), otherFlow.Bytes)
+	assert.Equal(t, uint64(10), otherFlow.Packets)
+
+	// the flushed flows free their places
+	acc.add(newFlow(20))
+	assert.NotNil(t, acc.flows[newFlow(20).AggregationHash()].flow)
+	assert.Equal(t, 11, len(acc.flows))
+	assert.Empty(t, acc.popFoldedFlows())
+}
+
 func Test_flowAccumulator_portRollUp(t *testing.T) {
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
@@ -166,7 +254,7 @@ func Test_flowAccumulator_portRollUp(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, 3, false, logger, rdnsQuerier)
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, 3, false, false, 0, logger, rdnsQuerier)
 	acc.add(flowA1)
 	acc.add(flowA2)
 
@@ -243,7 +331,7 @@ func Test_flowAccumulator_flush(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(flushInterval, flowContextTTL, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier)
+	acc := newFlowAccumulator(flushInterval, flowContextTTL, common.DefaultAggregatorPortRollupThreshold, false, false, 0, logger, rdnsQuerier)
 	acc.add(flow)
 
 	// Then
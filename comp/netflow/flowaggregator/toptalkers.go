@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"net"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/portrollup"
+)
+
+// topTalkersIngestFactor bounds the flows accumulated per exporter between two flushes to this
+// factor times the number of top talkers, the flows of the new conversations beyond the bound are
+// folded into the "other" flows as they are received
+const topTalkersIngestFactor = 10
+
+// exporterKey identifies an exporter, top talkers are selected per exporter
+type exporterKey struct {
+	Namespace  string
+	ExporterIP string
+	FlowType   common.FlowType
+}
+
+func newExporterKey(flow *common.Flow) exporterKey {
+	return exporterKey{
+		Namespace:  flow.Namespace,
+		ExporterIP: net.IP(flow.ExporterAddr).String(),
+		FlowType:   flow.FlowType,
+	}
+}
+
+// otherBucketKey identifies the "other" flow the remaining flows of an exporter are folded into
+type otherBucketKey struct {
+	exporter   exporterKey
+	direction  uint32
+	etherType  uint32
+	ipProtocol uint32
+}
+
+// otherFlows folds flows into one "other" flow per exporter, direction, ether type and IP protocol
+type otherFlows struct {
+	keys  []otherBucketKey
+	flows map[otherBucketKey]*common.Flow
+}
+
+func newOtherFlows() *otherFlows {
+	return &otherFlows{
+		flows: make(map[otherBucketKey]*common.Flow),
+	}
+}
+
+// fold adds the counters of flow, which can itself be an "other" flow, to its "other" flow
+func (o *otherFlows) fold(exporter exporterKey, flow *common.Flow) {
+	key := otherBucketKey{
+		exporter:   exporter,
+		direction:  flow.Direction,
+		etherType:  flow.EtherType,
+		ipProtocol: flow.IPProtocol,
+	}
+	otherFlow, ok := o.flows[key]
+	if !ok {
+		o.keys = append(o.keys, key)
+		o.flows[key] = newOtherFlow(flow)
+		return
+	}
+	otherFlow.Bytes += flow.Bytes
+	otherFlow.Packets += flow.Packets
+	otherFlow.StartTimestamp = common.Min(otherFlow.StartTimestamp, flow.StartTimestamp)
+	otherFlow.EndTimestamp = common.Max(otherFlow.EndTimestamp, flow.EndTimestamp)
+	otherFlow.SequenceNum = common.Max(otherFlow.SequenceNum, flow.SequenceNum)
+	otherFlow.TCPFlags |= flow.TCPFlags
+}
+
+// list returns the "other" flows in the order they were created
+func (o *otherFlows) list() []*common.Flow {
+	flows := make([]*common.Flow, 0, len(o.keys))
+	for _, key := range o.keys {
+		flows = append(flows, o.flows[key])
+	}
+	return flows
+}
+
+// selectTopTalkers keeps the `limit` flows with the most bytes for each exporter, the other flows are
+// folded into one "other" flow per exporter, direction, ether type and IP protocol, along with the
+// "other" flows already folded by the accumulator.
+// It returns the flows to send and the number of folded flows per exporter.
+func selectTopTalkers(flows []*common.Flow, limit int) ([]*common.Flow, map[exporterKey]int) {
+	var exporters []exporterKey
+	flowsPerExporter := make(map[exporterKey][]*common.Flow)
+	otherFlowsPerExporter := make(map[exporterKey][]*common.Flow)
+	for _, flow := range flows {
+		key := newExporterKey(flow)
+		if _, ok := flowsPerExporter[key]; !ok {
+			exporters = append(exporters, key)
+			flowsPerExporter[key] = nil
+		}
+		if flow.OtherBucket {
+			otherFlowsPerExporter[key] = append(otherFlowsPerExporter[key], flow)
+			continue
+		}
+		flowsPerExporter[key] = append(flowsPerExporter[key], flow)
+	}
+
+	selectedFlows := make([]*common.Flow, 0, len(flows))
+	foldedFlows := make(map[exporterKey]int)
+	for _, exporter := range exporters {
+		exporterFlows := flowsPerExporter[exporter]
+		others := newOtherFlows()
+		if len(exporterFlows) > limit {
+			sort.SliceStable(exporterFlows, func(i, j int) bool {
+				if exporterFlows[i].Bytes != exporterFlows[j].Bytes {
+					return exporterFlows[i].Bytes > exporterFlows[j].Bytes
+				}
+				return exporterFlows[i].Packets > exporterFlows[j].Packets
+			})
+			for _, flow := range exporterFlows[limit:] {
+				others.fold(exporter, flow)
+			}
+			foldedFlows[exporter] = len(exporterFlows) - limit
+			exporterFlows = exporterFlows[:limit]
+		}
+		for _, flow := range otherFlowsPerExporter[exporter] {
+			others.fold(exporter, flow)
+		}
+		selectedFlows = append(selectedFlows, exporterFlows...)
+		selectedFlows = append(selectedFlows, others.list()...)
+	}
+	return selectedFlows, foldedFlows
+}
+
+// newOtherFlow returns an "other" flow initialized with the counters of flow,
+// endpoints are left empty since it aggregates several conversations.
+func newOtherFlow(flow *common.Flow) *common.Flow {
+	return &common.Flow{
+		Namespace:      flow.Namespace,
+		FlowType:       flow.FlowType,
+		SequenceNum:    flow.SequenceNum,
+		SamplingRate:   flow.SamplingRate,
+		Direction:      flow.Direction,
+		ExporterAddr:   flow.ExporterAddr,
+		StartTimestamp: flow.StartTimestamp,
+		EndTimestamp:   flow.EndTimestamp,
+		Bytes:          flow.Bytes,
+		Packets:        flow.Packets,
+		EtherType:      flow.EtherType,
+		IPProtocol:     flow.IPProtocol,
+		TCPFlags:       flow.TCPFlags,
+		SrcPort:        portrollup.EphemeralPort,
+		DstPort:        portrollup.EphemeralPort,
+		OtherBucket:    true,
+	}
+}
@@ -0,0 +1,140 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+func Test_selectTopTalkers(t *testing.T) {
+	newFlow := func(exporter byte, dst byte, ipProtocol uint32, bytes uint64) *common.Flow {
+		return &common.Flow{
+			Namespace:      "default",
+			FlowType:       common.TypeNetFlow9,
+			ExporterAddr:   []byte{127, 0, 0, exporter},
+			SequenceNum:    uint32(dst),
+			SamplingRate:   10,
+			StartTimestamp: 1000 + uint64(dst),
+			EndTimestamp:   2000 + uint64(dst),
+			Bytes:          bytes,
+			Packets:        bytes / 10,
+			SrcAddr:        []byte{10, 0, 0, 1},
+			DstAddr:        []byte{10, 0, 1, dst},
+			EtherType:      0x0800,
+			IPProtocol:     ipProtocol,
+			SrcPort:        2000,
+			DstPort:        443,
+			TCPFlags:       uint32(dst),
+		}
+	}
+
+	flows := []*common.Flow{
+		newFlow(1, 1, 6, 100),
+		newFlow(1, 2, 6, 500),
+		newFlow(1, 3, 17, 50),
+		newFlow(1, 4, 6, 300),
+		newFlow(1, 5, 6, 20),
+		newFlow(1, 6, 17, 10),
+		newFlow(2, 1, 6, 10),
+		newFlow(2, 2, 6, 20),
+	}
+
+	selectedFlows, foldedFlows := selectTopTalkers(flows, 2)
+
+	assert.Equal(t, []*common.Flow{
+		flows[1],
+		flows[3],
+		{
+			Namespace:      "default",
+			FlowType:       common.TypeNetFlow9,
+			ExporterAddr:   []byte{127, 0, 0, 1},
+			SequenceNum:    5,
+			SamplingRate:   10,
+			StartTimestamp: 1001,
+			EndTimestamp:   2005,
+			Bytes:          120,
+			Packets:        12,
+			EtherType:      0x0800,
+			IPProtocol:     6,
+			TCPFlags:       1 | 5,
+			SrcPort:        -1,
+			DstPort:        -1,
+			OtherBucket:    true,
+		},
+		{
+			Namespace:      "default",
+			FlowType:       common.TypeNetFlow9,
+			ExporterAddr:   []byte{127, 0, 0, 1},
+			SequenceNum:    6,
+			SamplingRate:   10,
+			StartTimestamp: 1003,
+			EndTimestamp:   2006,
+			Bytes:          60,
+			Packets:        6,
+			EtherType:      0x0800,
+			IPProtocol:     17,
+			TCPFlags:       3 | 6,
+			SrcPort:        -1,
+			DstPort:        -1,
+			OtherBucket:    true,
+		},
+		// the second exporter has no more than 2 flows
+		flows[6],
+		flows[7],
+	}, selectedFlows)
+	assert.Equal(t, map[exporterKey]int{
+		{Namespace: "default", ExporterIP: "127.0.0.1", FlowType: common.TypeNetFlow9}: 4,
+	}, foldedFlows)
+}
+
+func Test_selectTopTalkers_foldedAtIngest(t *testing.T) {
+	flow := &common.Flow{
+		FlowType:     common.TypeNetFlow9,
+		ExporterAddr: []byte{127, 0, 0, 1},
+		Bytes:        100,
+		Packets:      10,
+		DstAddr:      []byte{10, 0, 1, 1},
+		IPProtocol:   6,
+	}
+	// the "other" flow folded by the accumulator is merged into the "other" flow of the selection
+	// even when it has more bytes than the selected flows
+	otherFlow := newOtherFlow(&common.Flow{
+		FlowType:     common.TypeNetFlow9,
+		ExporterAddr: []byte{127, 0, 0, 1},
+		Bytes:        1000,
+		Packets:      100,
+		IPProtocol:   6,
+	})
+	smallFlow := &common.Flow{
+		FlowType:     common.TypeNetFlow9,
+		ExporterAddr: []byte{127, 0, 0, 1},
+		Bytes:        10,
+		Packets:      1,
+		DstAddr:      []byte{10, 0, 1, 2},
+		IPProtocol:   6,
+	}
+
+	selectedFlows, foldedFlows := selectTopTalkers([]*common.Flow{otherFlow, smallFlow, flow}, 1)
+
+	assert.Len(t, selectedFlows, 2)
+	assert.Equal(t, flow, selectedFlows[0])
+	assert.True(t, selectedFlows[1].OtherBucket)
+	assert.Equal(t, uint64(1010), selectedFlows[1].Bytes)
+	assert.Equal(t, uint64(101), selectedFlows[1].Packets)
+	assert.Equal(t, map[exporterKey]int{
+		{ExporterIP: "127.0.0.1", FlowType: common.TypeNetFlow9}: 1,
+	}, foldedFlows)
+}
+
+func Test_selectTopTalkers_noFlows(t *testing.T) {
+	selectedFlows, foldedFlows := selectTopTalkers(nil, 10)
+	assert.Empty(t, selectedFlows)
+	assert.Empty(t, foldedFlows)
+}
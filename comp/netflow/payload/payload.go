@@ -20,11 +20,27 @@ type Exporter struct {
 
 // Endpoint contains source or destination endpoint details
 type Endpoint struct {
-	IP                 string `json:"ip"`
-	Port               string `json:"port"` // Port number can be zero/positive or `*` (ephemeral port)
-	Mac                string `json:"mac"`
-	Mask               string `json:"mask"`
-	ReverseDNSHostname string `json:"reverse_dns_hostname,omitempty"`
+	IP                 string  `json:"ip"`
+	Port               string  `json:"port"` // Port number can be zero/positive or `*` (ephemeral port)
+	Mac                string  `json:"mac"`
+	Mask               string  `json:"mask"`
+	ReverseDNSHostname string  `json:"reverse_dns_hostname,omitempty"`
+	Geo                *Geo    `json:"geo,omitempty"`
+	Labels             *Labels `json:"labels,omitempty"`
+}
+
+// Geo contains the geolocation and autonomous system of an endpoint
+type Geo struct {
+	CountryISOCode string `json:"country_iso_code,omitempty"`
+	ASNumber       uint32 `json:"as_number,omitempty"`
+	ASOrganization string `json:"as_organization,omitempty"`
+}
+
+// Labels contains the user defined labels of the network an endpoint belongs to
+type Labels struct {
+	Site string `json:"site,omitempty"`
+	Team string `json:"team,omitempty"`
+	Env  string `json:"env,omitempty"`
 }
 
 // NextHop contains next hop details
@@ -66,6 +82,7 @@ type FlowPayload struct {
 	Host             string           `json:"host"`
 	TCPFlags         []string         `json:"tcp_flags,omitempty"`
 	NextHop          NextHop          `json:"next_hop,omitempty"`
+	OtherBucket      bool             `json:"other_bucket,omitempty"`
 	AdditionalFields AdditionalFields `json:"additional_fields,omitempty"`
 }
 
@@ -101,6 +118,11 @@ func (p FlowPayload) MarshalJSON() ([]byte, error) {
 		fields["tcp_flags"] = p.TCPFlags
 	}
 
+	// omit empty
+	if p.OtherBucket {
+		fields["other_bucket"] = p.OtherBucket
+	}
+
 	// Adding additional fields
 	for k, v := range p.AdditionalFields {
 		if _, ok := fields[k]; ok {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
 )
 
 type collectorConfigs struct {
@@ -23,9 +24,16 @@ type collectorConfigs struct {
 	pathtestInterval             time.Duration
 	flushInterval                time.Duration
 	networkDevicesNamespace      string
+	scheduledTests               []*scheduledTest
+	probesPerHop                 int
+	packetLossThreshold          float64
 }
 
-func newConfig(agentConfig config.Component) *collectorConfigs {
+func newConfig(agentConfig config.Component, logger log.Component) *collectorConfigs {
+	var scheduledTestConfigs []scheduledTestConfig
+	if err := agentConfig.UnmarshalKey("network_path.collector.scheduled_tests", &scheduledTestConfigs); err != nil {
+		logger.Errorf("Invalid network_path.collector.scheduled_tests: %s", err)
+	}
 
 	return &collectorConfigs{
 		connectionsMonitoringEnabled: agentConfig.GetBool("network_path.connections_monitoring.enabled"),
@@ -39,11 +47,14 @@ func newConfig(agentConfig config.Component) *collectorConfigs {
 		pathtestInterval:             agentConfig.GetDuration("network_path.collector.pathtest_interval"),
 		flushInterval:                agentConfig.GetDuration("network_path.collector.flush_interval"),
 		networkDevicesNamespace:      agentConfig.GetString("network_devices.namespace"),
+		scheduledTests:               newScheduledTests(scheduledTestConfigs, logger),
+		probesPerHop:                 agentConfig.GetInt("network_path.collector.probes_per_hop"),
+		packetLossThreshold:          agentConfig.GetFloat64("network_path.collector.packet_loss_threshold"),
 	}
 }
 
 // networkPathCollectorEnabled checks if Network Path Collector should be enabled
 // Network Path Collector is expected to be enabled if a feature depend on it.
 func (c *collectorConfigs) networkPathCollectorEnabled() bool {
-	return c.connectionsMonitoringEnabled
+	return c.connectionsMonitoringEnabled || len(c.scheduledTests) > 0
 }
@@ -48,6 +48,10 @@ type npCollectorImpl struct {
 	pathtestInputChan      chan *common.Pathtest
 	pathtestProcessingChan chan *pathteststore.PathtestContext
 
+	// Scheduled path tests
+	scheduledTestChan      chan *scheduledTest
+	scheduledTestsLoopDone chan struct{}
+
 	// Scheduling related
 	running       bool
 	workers       int
@@ -75,7 +79,7 @@ func newNoopNpCollectorImpl() *npCollectorImpl {
 }
 
 func newNpCollectorImpl(epForwarder eventplatform.Forwarder, collectorConfigs *collectorConfigs, logger log.Component, telemetrycomp telemetryComp.Component) *npCollectorImpl {
-	logger.Infof("New NpCollector (workers=%d timeout=%d max_ttl=%d input_chan_size=%d processing_chan_size=%d pathtest_contexts_limit=%d pathtest_ttl=%s pathtest_interval=%s flush_interval=%s)",
+	logger.Infof("New NpCollector (workers=%d timeout=%d max_ttl=%d input_chan_size=%d processing_chan_size=%d pathtest_contexts_limit=%d pathtest_ttl=%s pathtest_interval=%s flush_interval=%s scheduled_tests=%d probes_per_hop=%d)",
 		collectorConfigs.workers,
 		collectorConfigs.timeout,
 		collectorConfigs.maxTTL,
@@ -84,7 +88,9 @@ func newNpCollectorImpl(epForwarder eventplatform.Forwarder, collectorConfigs *c
 		collectorConfigs.pathtestContextsLimit,
 		collectorConfigs.pathtestTTL,
 		collectorConfigs.pathtestInterval,
-		collectorConfigs.flushInterval)
+		collectorConfigs.flushInterval,
+		len(collectorConfigs.scheduledTests),
+		collectorConfigs.probesPerHop)
 
 	return &npCollectorImpl{
 		epForwarder:      epForwarder,
@@ -94,6 +100,7 @@ func newNpCollectorImpl(epForwarder eventplatform.Forwarder, collectorConfigs *c
 		pathtestStore:          pathteststore.NewPathtestStore(collectorConfigs.pathtestTTL, collectorConfigs.pathtestInterval, collectorConfigs.pathtestContextsLimit, logger),
 		pathtestInputChan:      make(chan *common.Pathtest, collectorConfigs.pathtestInputChanSize),
 		pathtestProcessingChan: make(chan *pathteststore.PathtestContext, collectorConfigs.pathtestProcessingChanSize),
+		scheduledTestChan:      make(chan *scheduledTest, len(collectorConfigs.scheduledTests)),
 		flushInterval:          collectorConfigs.flushInterval,
 		workers:                collectorConfigs.workers,
 
@@ -109,6 +116,8 @@ func newNpCollectorImpl(epForwarder eventplatform.Forwarder, collectorConfigs *c
 		runDone:       make(chan struct{}),
 		flushLoopDone: make(chan struct{}),
 
+		scheduledTestsLoopDone: make(chan struct{}),
+
 		runTraceroute: runTraceroute,
 	}
 }
@@ -178,6 +187,9 @@ func (s *npCollectorImpl) start() error {
 
 	go s.listenPathtests()
 	go s.flushLoop()
+	if len(s.collectorConfigs.scheduledTests) > 0 {
+		go s.scheduledTestsLoop()
+	}
 	s.startWorkers()
 
 	return nil
@@ -191,6 +203,9 @@ func (s *npCollectorImpl) stop() {
 	close(s.stopChan)
 	<-s.flushLoopDone
 	<-s.runDone
+	if len(s.collectorConfigs.scheduledTests) > 0 {
+		<-s.scheduledTestsLoopDone
+	}
 	s.running = false
 }
 
@@ -232,7 +247,10 @@ func (s *npCollectorImpl) runTracerouteForPath(ptest *pathteststore.PathtestCont
 	path.Origin = payload.PathOriginNetworkTraffic
 
 	s.sendTelemetry(path, startTime, ptest)
+	s.sendPathEvent(path)
+}
 
+func (s *npCollectorImpl) sendPathEvent(path payload.NetworkPath) {
 	payloadBytes, err := json.Marshal(path)
 	if err != nil {
 		s.logger.Errorf("json marshall error: %s", err)
@@ -339,6 +357,9 @@ func (s *npCollectorImpl) startWorker(workerID int) {
 			s.logger.Debugf("[worker%d] Handling pathtest hostname=%s, port=%d", workerID, pathtestCtx.Pathtest.Hostname, pathtestCtx.Pathtest.Port)
 			s.runTracerouteForPath(pathtestCtx)
 			s.processedTracerouteCount.Inc()
+		case test := <-s.scheduledTestChan:
+			s.logger.Debugf("[worker%d] Handling scheduled path test hostname=%s, port=%d", workerID, test.pathtest.Hostname, test.pathtest.Port)
+			s.runScheduledTest(test)
 		}
 	}
 }
@@ -0,0 +1,203 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package npcollectorimpl
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/networkpath/npcollector/npcollectorimpl/common"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/utils"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/pathstats"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute"
+)
+
+const (
+	defaultScheduledTestInterval = time.Minute
+	scheduledTestsTickInterval   = time.Second
+)
+
+// scheduledTestConfig is a path test configured in `network_path.collector.scheduled_tests`
+type scheduledTestConfig struct {
+	Hostname string   `mapstructure:"hostname"`
+	Port     uint16   `mapstructure:"port"`
+	Protocol string   `mapstructure:"protocol"`
+	Interval int      `mapstructure:"interval"` // in seconds
+	Tags     []string `mapstructure:"tags"`
+}
+
+// scheduledTest is a path test run at a fixed interval, independently of the observed connections
+type scheduledTest struct {
+	pathtest common.Pathtest
+	interval time.Duration
+	tags     []string
+
+	// only accessed by the scheduling loop
+	nextRun time.Time
+}
+
+// newScheduledTests validates the configured path tests, invalid ones are logged and skipped
+func newScheduledTests(configs []scheduledTestConfig, logger log.Component) []*scheduledTest {
+	var tests []*scheduledTest
+	for _, conf := range configs {
+		if conf.Hostname == "" {
+			logger.Errorf("Invalid scheduled path test %+v: hostname is required", conf)
+			continue
+		}
+		protocol := payload.Protocol(strings.ToUpper(conf.Protocol))
+		switch protocol {
+		case "":
+			protocol = payload.ProtocolUDP
+		case payload.ProtocolUDP, payload.ProtocolTCP, payload.ProtocolICMP:
+		default:
+			logger.Errorf("Invalid scheduled path test %+v: unsupported protocol `%s`", conf, conf.Protocol)
+			continue
+		}
+		if conf.Interval < 0 {
+			logger.Errorf("Invalid scheduled path test %+v: interval must be > 0", conf)
+			continue
+		}
+		interval := time.Duration(conf.Interval) * time.Second
+		if interval == 0 {
+			interval = defaultScheduledTestInterval
+		}
+		tests = append(tests, &scheduledTest{
+			pathtest: common.Pathtest{
+				Hostname: conf.Hostname,
+				Port:     conf.Port,
+				Protocol: protocol,
+			},
+			interval: interval,
+			tags:     conf.Tags,
+		})
+	}
+	return tests
+}
+
+func (s *npCollectorImpl) scheduledTestsLoop() {
+	s.logger.Debugf("Starting scheduled path tests loop (%d tests)", len(s.collectorConfigs.scheduledTests))
+
+	ticker := time.NewTicker(scheduledTestsTickInterval)
+	for {
+		select {
+		case <-s.stopChan:
+			s.logger.Info("Stopped scheduled path tests loop")
+			ticker.Stop()
+			s.scheduledTestsLoopDone <- struct{}{}
+			return
+		case <-ticker.C:
+			s.scheduleDueTests(s.TimeNowFn())
+		}
+	}
+}
+
+// scheduleDueTests sends the tests whose interval elapsed to the workers.
+// It shouldn't block, if the channel is full the test is retried on the next tick.
+func (s *npCollectorImpl) scheduleDueTests(now time.Time) {
+	for _, test := range s.collectorConfigs.scheduledTests {
+		if now.Before(test.nextRun) {
+			continue
+		}
+		select {
+		case s.scheduledTestChan <- test:
+			test.nextRun = now.Add(test.interval)
+		default:
+			s.logger.Warnf("Scheduled path test channel is full, delaying test to %s", test.pathtest.Hostname)
+		}
+	}
+}
+
+// runScheduledTest runs one traceroute per probe so that each hop is probed probesPerHop times,
+// the first path is sent as an event and all of them are used to compute latency and loss metrics.
+// A traceroute that fails is counted as a probe lost at the destination, so that an unreachable
+// destination reports a 100% packet loss.
+func (s *npCollectorImpl) runScheduledTest(test *scheduledTest) {
+	s.logger.Debugf("Run scheduled path test: %+v", test.pathtest)
+
+	startTime := s.TimeNowFn()
+	cfg := traceroute.Config{
+		DestHostname: test.pathtest.Hostname,
+		DestPort:     test.pathtest.Port,
+		MaxTTL:       uint8(s.collectorConfigs.maxTTL),
+		Timeout:      s.collectorConfigs.timeout,
+		Protocol:     test.pathtest.Protocol,
+	}
+
+	probes := s.collectorConfigs.probesPerHop
+	if probes < 1 {
+		probes = 1
+	}
+	var paths []payload.NetworkPath
+	for i := 0; i < probes; i++ {
+		path, err := s.runTraceroute(cfg, s.telemetrycomp)
+		if err != nil {
+			s.logger.Errorf("%s", err)
+			continue
+		}
+		paths = append(paths, path)
+	}
+
+	stats := pathstats.Compute(paths)
+	stats.Destination.Sent += probes - len(paths)
+
+	if len(paths) == 0 {
+		// no path to send, only the loss of the destination is reported
+		path := payload.NetworkPath{
+			Protocol: test.pathtest.Protocol,
+			Destination: payload.NetworkPathDestination{
+				Hostname:  test.pathtest.Hostname,
+				IPAddress: s.scheduledTestDestinationIP(test.pathtest.Hostname),
+				Port:      test.pathtest.Port,
+			},
+		}
+		pathstats.Submit(s.metricSender, stats, s.collectorConfigs.packetLossThreshold, scheduledTestTags(path, test.tags))
+		return
+	}
+
+	path := paths[0]
+	path.Namespace = s.networkDevicesNamespace
+	path.Origin = payload.PathOriginNetworkPathIntegration
+	path.Tags = test.tags
+
+	telemetry.SubmitNetworkPathTelemetry(s.metricSender, path, s.TimeNowFn().Sub(startTime), test.interval, test.tags)
+	pathstats.Submit(s.metricSender, stats, s.collectorConfigs.packetLossThreshold, scheduledTestTags(path, test.tags))
+	s.sendPathEvent(path)
+}
+
+// scheduledTestDestinationIP returns the IP address of the destination of a test whose
+// traceroutes all failed, like the traceroute it resolves the hostname to an IPv4 address.
+// It's empty if the hostname can't be resolved.
+func (s *npCollectorImpl) scheduledTestDestinationIP(hostname string) string {
+	if ip := net.ParseIP(hostname); ip != nil {
+		return ip.String()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.collectorConfigs.timeout)
+	defer cancel()
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip4", hostname)
+	if err != nil || len(ips) == 0 {
+		return ""
+	}
+	return ips[0].String()
+}
+
+func scheduledTestTags(path payload.NetworkPath, tags []string) []string {
+	destPortTag := "unspecified"
+	if path.Destination.Port > 0 {
+		destPortTag = strconv.Itoa(int(path.Destination.Port))
+	}
+	return append(utils.CopyStrings(tags),
+		"protocol:"+string(path.Protocol),
+		"destination_ip:"+path.Destination.IPAddress,
+		"destination_hostname:"+path.Destination.Hostname,
+		"destination_port:"+destPortTag,
+	)
+}
@@ -0,0 +1,244 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+//go:build test
+
+package npcollectorimpl
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/telemetry"
+	"github.com/DataDog/datadog-agent/comp/forwarder/eventplatform"
+	"github.com/DataDog/datadog-agent/comp/forwarder/eventplatform/eventplatformimpl"
+	"github.com/DataDog/datadog-agent/comp/networkpath/npcollector/npcollectorimpl/common"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/metricsender"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute"
+)
+
+func Test_newNpCollectorImpl_scheduledTests(t *testing.T) {
+	agentConfigs := map[string]any{
+		"network_path.collector.scheduled_tests": []map[string]any{
+			{"hostname": "10.0.0.2", "port": 443, "protocol": "tcp", "interval": 30, "tags": []string{"team:net"}},
+			{"hostname": "10.0.0.3"},
+			{"port": 80},
+			{"hostname": "10.0.0.4", "protocol": "sctp"},
+			{"hostname": "10.0.0.5", "interval": -1},
+		},
+		"network_path.collector.probes_per_hop":        5,
+		"network_path.collector.packet_loss_threshold": 2.5,
+	}
+
+	_, npCollector := newTestNpCollector(t, agentConfigs)
+
+	assert.True(t, npCollector.collectorConfigs.networkPathCollectorEnabled())
+	assert.Equal(t, []*scheduledTest{
+		{
+			pathtest: common.Pathtest{Hostname: "10.0.0.2", Port: 443, Protocol: payload.ProtocolTCP},
+			interval: 30 * time.Second,
+			tags:     []string{"team:net"},
+		},
+		{
+			pathtest: common.Pathtest{Hostname: "10.0.0.3", Protocol: payload.ProtocolUDP},
+			interval: defaultScheduledTestInterval,
+		},
+	}, npCollector.collectorConfigs.scheduledTests)
+	assert.Equal(t, 5, npCollector.collectorConfigs.probesPerHop)
+	assert.Equal(t, 2.5, npCollector.collectorConfigs.packetLossThreshold)
+	assert.Equal(t, 2, cap(npCollector.scheduledTestChan))
+}
+
+func Test_npCollectorImpl_scheduleDueTests(t *testing.T) {
+	agentConfigs := map[string]any{
+		"network_path.collector.scheduled_tests": []map[string]any{
+			{"hostname": "10.0.0.2", "interval": 10},
+			{"hostname": "10.0.0.3", "interval": 60},
+		},
+	}
+	_, npCollector := newTestNpCollector(t, agentConfigs)
+	now := MockTimeNow()
+
+	// all tests are due on the first tick
+	npCollector.scheduleDueTests(now)
+	require.Len(t, npCollector.scheduledTestChan, 2)
+	assert.Equal(t, "10.0.0.2", (<-npCollector.scheduledTestChan).pathtest.Hostname)
+	assert.Equal(t, "10.0.0.3", (<-npCollector.scheduledTestChan).pathtest.Hostname)
+
+	npCollector.scheduleDueTests(now.Add(5 * time.Second))
+	assert.Len(t, npCollector.scheduledTestChan, 0)
+
+	npCollector.scheduleDueTests(now.Add(10 * time.Second))
+	require.Len(t, npCollector.scheduledTestChan, 1)
+	assert.Equal(t, "10.0.0.2", (<-npCollector.scheduledTestChan).pathtest.Hostname)
+}
+
+func Test_npCollectorImpl_runScheduledTest(t *testing.T) {
+	agentConfigs := map[string]any{
+		"network_path.collector.scheduled_tests": []map[string]any{
+			{"hostname": "10.0.0.2", "port": 80, "protocol": "icmp", "tags": []string{"team:net"}},
+		},
+		"network_path.collector.probes_per_hop":        2,
+		"network_path.collector.packet_loss_threshold": 10,
+	}
+	_, npCollector := newTestNpCollector(t, agentConfigs)
+
+	mockEpForwarder := eventplatformimpl.NewMockEventPlatformForwarder(gomock.NewController(t))
+	npCollector.epForwarder = mockEpForwarder
+	mockEpForwarder.EXPECT().SendEventPlatformEventBlocking(gomock.Any(), eventplatform.EventTypeNetworkPath).Return(nil).Times(1)
+
+	sender := &metricsender.MockMetricSender{}
+	npCollector.metricSender = sender
+	npCollector.TimeNowFn = MockTimeNow
+
+	var configs []traceroute.Config
+	destinationRTTs := []float64{10, 0}
+	npCollector.runTraceroute = func(cfg traceroute.Config, _ telemetry.Component) (payload.NetworkPath, error) {
+		run := len(configs)
+		configs = append(configs, cfg)
+		return payload.NetworkPath{
+			Protocol:    payload.ProtocolICMP,
+			Destination: payload.NetworkPathDestination{Hostname: "10.0.0.2", IPAddress: "10.0.0.2", Port: 80},
+			Hops: []payload.NetworkPathHop{
+				{TTL: 1, IPAddress: "10.0.0.1", Reachable: true, RTT: 2},
+				{TTL: 2, IPAddress: "10.0.0.2", Reachable: destinationRTTs[run] > 0, RTT: destinationRTTs[run]},
+			},
+		}, nil
+	}
+
+	npCollector.runScheduledTest(npCollector.collectorConfigs.scheduledTests[0])
+
+	require.Len(t, configs, 2)
+	assert.Equal(t, traceroute.Config{
+		DestHostname: "10.0.0.2",
+		DestPort:     80,
+		MaxTTL:       30,
+		Timeout:      1000 * time.Millisecond,
+		Protocol:     payload.ProtocolICMP,
+	}, configs[0])
+
+	pathTags := []string{
+		"team:net",
+		"protocol:ICMP",
+		"destination_ip:10.0.0.2",
+		"destination_hostname:10.0.0.2",
+		"destination_port:80",
+	}
+	hopTags := append(append([]string{}, pathTags...), "hop_ttl:1", "hop_ip_address:10.0.0.1")
+	assert.Contains(t, sender.Metrics, metricsender.MockReceivedMetric{MetricType: metrics.GaugeType, Name: "network_path.hop.packet_loss", Value: 0, Tags: hopTags})
+	assert.Contains(t, sender.Metrics, metricsender.MockReceivedMetric{MetricType: metrics.GaugeType, Name: "network_path.hop.latency.p50", Value: 2, Tags: hopTags})
+	assert.Contains(t, sender.Metrics, metricsender.MockReceivedMetric{MetricType: metrics.GaugeType, Name: "network_path.path.packet_loss", Value: 50, Tags: pathTags})
+	assert.Contains(t, sender.Metrics, metricsender.MockReceivedMetric{MetricType: metrics.GaugeType, Name: "network_path.path.latency.p95", Value: 10, Tags: pathTags})
+	assert.Equal(t, []metricsender.MockReceivedServiceCheck{
+		{
+			Name:    "network_path.packet_loss",
+			Status:  servicecheck.ServiceCheckCritical,
+			Tags:    pathTags,
+			Message: "packet loss 50.0% is above the threshold of 10.0%",
+		},
+	}, sender.ServiceChecks)
+}
+
+func Test_npCollectorImpl_runScheduledTest_unreachable(t *testing.T) {
+	agentConfigs := map[string]any{
+		"network_path.collector.scheduled_tests": []map[string]any{
+			{"hostname": "10.0.0.2", "port": 80, "protocol": "icmp", "tags": []string{"team:net"}},
+		},
+		"network_path.collector.probes_per_hop":        3,
+		"network_path.collector.packet_loss_threshold": 10,
+	}
+	_, npCollector := newTestNpCollector(t, agentConfigs)
+
+	// no path is sent when all the traceroutes fail
+	mockEpForwarder := eventplatformimpl.NewMockEventPlatformForwarder(gomock.NewController(t))
+	npCollector.epForwarder = mockEpForwarder
+
+	sender := &metricsender.MockMetricSender{}
+	npCollector.metricSender = sender
+	npCollector.TimeNowFn = MockTimeNow
+
+	runs := 0
+	npCollector.runTraceroute = func(_ traceroute.Config, _ telemetry.Component) (payload.NetworkPath, error) {
+		runs++
+		return payload.NetworkPath{}, errors.New("no route to host")
+	}
+
+	npCollector.runScheduledTest(npCollector.collectorConfigs.scheduledTests[0])
+
+	assert.Equal(t, 3, runs)
+	pathTags := []string{
+		"team:net",
+		"protocol:ICMP",
+		"destination_ip:10.0.0.2",
+		"destination_hostname:10.0.0.2",
+		"destination_port:80",
+	}
+	assert.Equal(t, []metricsender.MockReceivedMetric{
+		{MetricType: metrics.GaugeType, Name: "network_path.path.packet_loss", Value: 100, Tags: pathTags},
+	}, sender.Metrics)
+	assert.Equal(t, []metricsender.MockReceivedServiceCheck{
+		{
+			Name:    "network_path.packet_loss",
+			Status:  servicecheck.ServiceCheckCritical,
+			Tags:    pathTags,
+			Message: "packet loss 100.0% is above the threshold of 10.0%",
+		},
+	}, sender.ServiceChecks)
+}
+
+func Test_npCollectorImpl_runScheduledTest_failedRuns(t *testing.T) {
+	agentConfigs := map[string]any{
+		"network_path.collector.scheduled_tests": []map[string]any{
+			{"hostname": "10.0.0.2", "protocol": "icmp"},
+		},
+		"network_path.collector.probes_per_hop":        4,
+		"network_path.collector.packet_loss_threshold": 10,
+	}
+	_, npCollector := newTestNpCollector(t, agentConfigs)
+
+	mockEpForwarder := eventplatformimpl.NewMockEventPlatformForwarder(gomock.NewController(t))
+	npCollector.epForwarder = mockEpForwarder
+	mockEpForwarder.EXPECT().SendEventPlatformEventBlocking(gomock.Any(), eventplatform.EventTypeNetworkPath).Return(nil).Times(1)
+
+	sender := &metricsender.MockMetricSender{}
+	npCollector.metricSender = sender
+	npCollector.TimeNowFn = MockTimeNow
+
+	// the failed traceroutes are counted as lost probes
+	runs := 0
+	npCollector.runTraceroute = func(_ traceroute.Config, _ telemetry.Component) (payload.NetworkPath, error) {
+		runs++
+		if runs%2 == 0 {
+			return payload.NetworkPath{}, errors.New("timeout")
+		}
+		return payload.NetworkPath{
+			Protocol:    payload.ProtocolICMP,
+			Destination: payload.NetworkPathDestination{Hostname: "10.0.0.2", IPAddress: "10.0.0.2"},
+			Hops: []payload.NetworkPathHop{
+				{TTL: 1, IPAddress: "10.0.0.2", Reachable: true, RTT: 5},
+			},
+		}, nil
+	}
+
+	npCollector.runScheduledTest(npCollector.collectorConfigs.scheduledTests[0])
+
+	pathTags := []string{
+		"protocol:ICMP",
+		"destination_ip:10.0.0.2",
+		"destination_hostname:10.0.0.2",
+		"destination_port:unspecified",
+	}
+	assert.Contains(t, sender.Metrics, metricsender.MockReceivedMetric{MetricType: metrics.GaugeType, Name: "network_path.path.packet_loss", Value: 50, Tags: pathTags})
+	require.Len(t, sender.ServiceChecks, 1)
+	assert.Equal(t, servicecheck.ServiceCheckCritical, sender.ServiceChecks[0].Status)
+}
@@ -6,8 +6,11 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
 	"hash/fnv"
+	"strings"
+	"time"
 
 	"github.com/gosnmp/gosnmp"
 
@@ -33,19 +36,65 @@ type UserV3 struct {
 	AuthProtocol   string `mapstructure:"authProtocol" yaml:"authProtocol"`
 	PrivKey        string `mapstructure:"privKey" yaml:"privKey"`
 	PrivProtocol   string `mapstructure:"privProtocol" yaml:"privProtocol"`
+	// EngineIDs restricts the user to the devices with these authoritative engine IDs,
+	// in hexadecimal. Any engine ID is accepted when empty.
+	EngineIDs []string `mapstructure:"engineIDs" yaml:"engineIDs"`
+}
+
+// GetEngineIDs returns the decoded engine IDs the user is restricted to
+func (u *UserV3) GetEngineIDs() ([]string, error) {
+	var engineIDs []string
+	for _, engineID := range u.EngineIDs {
+		decoded, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(engineID), "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid engine ID `%s`: %w", engineID, err)
+		}
+		// RFC3411 section 5: an SnmpEngineID is between 5 and 32 bytes
+		if len(decoded) < 5 || len(decoded) > 32 {
+			return nil, fmt.Errorf("invalid engine ID `%s`: must be between 5 and 32 bytes", engineID)
+		}
+		engineIDs = append(engineIDs, string(decoded))
+	}
+	return engineIDs, nil
+}
+
+// Metric types supported by metric rules
+const (
+	MetricTypeCount = "count"
+	MetricTypeGauge = "gauge"
+)
+
+// MetricTag defines a tag added to the metric of a rule, its value is read from a trap variable.
+type MetricTag struct {
+	// Variable is the OID or the name of the variable, e.g. `ifDescr`
+	Variable string `mapstructure:"variable" yaml:"variable"`
+	Tag      string `mapstructure:"tag" yaml:"tag"`
+}
+
+// MetricRule converts the traps matching Trap into a metric.
+type MetricRule struct {
+	// Trap is the OID or the name of the trap, e.g. `linkDown`
+	Trap   string `mapstructure:"trap" yaml:"trap"`
+	Metric string `mapstructure:"metric" yaml:"metric"`
+	Type   string `mapstructure:"type" yaml:"type"`
+	// Value is the OID or the name of the variable holding the value of a gauge
+	Value string      `mapstructure:"value" yaml:"value"`
+	Tags  []MetricTag `mapstructure:"tags" yaml:"tags"`
 }
 
 // TrapsConfig contains configuration for SNMP trap listeners.
 // YAML field tags provided for test marshalling purposes.
 type TrapsConfig struct {
-	Enabled               bool     `mapstructure:"enabled" yaml:"enabled"`
-	Port                  uint16   `mapstructure:"port" yaml:"port"`
-	Users                 []UserV3 `mapstructure:"users" yaml:"users"`
-	CommunityStrings      []string `mapstructure:"community_strings" yaml:"community_strings"`
-	BindHost              string   `mapstructure:"bind_host" yaml:"bind_host"`
-	StopTimeout           int      `mapstructure:"stop_timeout" yaml:"stop_timeout"`
-	Namespace             string   `mapstructure:"namespace" yaml:"namespace"`
-	authoritativeEngineID string   `mapstructure:"-" yaml:"-"`
+	Enabled               bool         `mapstructure:"enabled" yaml:"enabled"`
+	Port                  uint16       `mapstructure:"port" yaml:"port"`
+	Users                 []UserV3     `mapstructure:"users" yaml:"users"`
+	CommunityStrings      []string     `mapstructure:"community_strings" yaml:"community_strings"`
+	BindHost              string       `mapstructure:"bind_host" yaml:"bind_host"`
+	StopTimeout           int          `mapstructure:"stop_timeout" yaml:"stop_timeout"`
+	Namespace             string       `mapstructure:"namespace" yaml:"namespace"`
+	MetricRules           []MetricRule `mapstructure:"metric_rules" yaml:"metric_rules"`
+	DedupWindow           int          `mapstructure:"dedup_window" yaml:"dedup_window"`
+	authoritativeEngineID string       `mapstructure:"-" yaml:"-"`
 }
 
 // ReadConfig builds the traps configuration from the Agent configuration.
@@ -97,9 +146,59 @@ func (c *TrapsConfig) SetDefaults(host string, namespace string) error {
 		return fmt.Errorf("invalid config: %w", err)
 	}
 
+	for _, user := range c.Users {
+		if _, err := user.GetEngineIDs(); err != nil {
+			username := user.Username
+			if username == "" {
+				username = user.UsernameLegacy
+			}
+			return fmt.Errorf("invalid config: user %s: %w", username, err)
+		}
+	}
+
+	if c.DedupWindow < 0 {
+		return fmt.Errorf("invalid config: dedup_window must be positive or zero, got %d", c.DedupWindow)
+	}
+	for i := range c.MetricRules {
+		if err := c.MetricRules[i].setDefaults(); err != nil {
+			return fmt.Errorf("invalid config: metric rule %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *MetricRule) setDefaults() error {
+	if r.Trap == "" {
+		return fmt.Errorf("`trap` is required")
+	}
+	if r.Metric == "" {
+		return fmt.Errorf("`metric` is required")
+	}
+	switch r.Type {
+	case "":
+		r.Type = MetricTypeCount
+	case MetricTypeCount:
+	case MetricTypeGauge:
+		if r.Value == "" {
+			return fmt.Errorf("`value` is required for gauge metric %s", r.Metric)
+		}
+	default:
+		return fmt.Errorf("unsupported metric type `%s`, must be `%s` or `%s`", r.Type, MetricTypeCount, MetricTypeGauge)
+	}
+	for _, tag := range r.Tags {
+		if tag.Variable == "" || tag.Tag == "" {
+			return fmt.Errorf("`variable` and `tag` are required for the tags of metric %s", r.Metric)
+		}
+	}
 	return nil
 }
 
+// GetDedupWindow returns the duration during which identical traps are only forwarded once, 0 disables deduplication
+func (c *TrapsConfig) GetDedupWindow() time.Duration {
+	return time.Duration(c.DedupWindow) * time.Second
+}
+
 // Addr returns the host:port address to listen on.
 func (c *TrapsConfig) Addr() string {
 	return fmt.Sprintf("%s:%d", c.BindHost, c.Port)
@@ -148,6 +247,17 @@ func (c *TrapsConfig) BuildSNMPParams(logger log.Component) (*gosnmp.GoSNMP, err
 		}
 	}
 
+	// Senders of v3 INFORMs discover the agent engine ID with an unauthenticated packet from an empty user,
+	// gosnmp answers with a report as long as the user is known (RFC3414 section 4). Packets of this user
+	// that aren't discovery requests are dropped by the listener.
+	err := usmTable.Add("", &gosnmp.UsmSecurityParameters{
+		AuthenticationProtocol: gosnmp.NoAuth,
+		PrivacyProtocol:        gosnmp.NoPriv,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return &gosnmp.GoSNMP{
 		Port:                        c.Port,
 		Transport:                   "udp",
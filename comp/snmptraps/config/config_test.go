@@ -9,6 +9,7 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	"github.com/DataDog/datadog-agent/comp/core/hostname"
@@ -290,3 +291,121 @@ func TestNamespaceSetBothGloballyAndLocally(t *testing.T) {
 
 	assert.Equal(t, "bar", config.Namespace)
 }
+
+func TestMetricRulesAndDedupWindow(t *testing.T) {
+	config := fxutil.Test[*TrapsConfig](t,
+		testOptions(t),
+		withConfig(t, &TrapsConfig{
+			DedupWindow: 30,
+			MetricRules: []MetricRule{
+				{
+					Trap:   "linkDown",
+					Metric: "snmp.interface.link_down",
+					Tags:   []MetricTag{{Variable: "ifIndex", Tag: "interface_index"}},
+				},
+				{
+					Trap:   "1.3.6.1.4.1.8072.2.3.0.1",
+					Metric: "snmp.heartbeat.rate",
+					Type:   "gauge",
+					Value:  "netSnmpExampleHeartbeatRate",
+				},
+			},
+		}, ""),
+	)
+	assert.Equal(t, 30*time.Second, config.GetDedupWindow())
+	assert.Equal(t, []MetricRule{
+		{
+			Trap:   "linkDown",
+			Metric: "snmp.interface.link_down",
+			Type:   MetricTypeCount,
+			Tags:   []MetricTag{{Variable: "ifIndex", Tag: "interface_index"}},
+		},
+		{
+			Trap:   "1.3.6.1.4.1.8072.2.3.0.1",
+			Metric: "snmp.heartbeat.rate",
+			Type:   MetricTypeGauge,
+			Value:  "netSnmpExampleHeartbeatRate",
+		},
+	}, config.MetricRules)
+}
+
+func TestInvalidMetricRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        TrapsConfig
+		expectedError string
+	}{
+		{
+			name:          "negative dedup window",
+			config:        TrapsConfig{DedupWindow: -1},
+			expectedError: "dedup_window must be positive or zero, got -1",
+		},
+		{
+			name:          "missing trap",
+			config:        TrapsConfig{MetricRules: []MetricRule{{Metric: "foo"}}},
+			expectedError: "metric rule 0: `trap` is required",
+		},
+		{
+			name:          "missing metric",
+			config:        TrapsConfig{MetricRules: []MetricRule{{Trap: "linkDown"}}},
+			expectedError: "metric rule 0: `metric` is required",
+		},
+		{
+			name:          "gauge without value",
+			config:        TrapsConfig{MetricRules: []MetricRule{{Trap: "linkDown", Metric: "foo", Type: "gauge"}}},
+			expectedError: "metric rule 0: `value` is required for gauge metric foo",
+		},
+		{
+			name:          "unsupported type",
+			config:        TrapsConfig{MetricRules: []MetricRule{{Trap: "linkDown", Metric: "foo", Type: "rate"}}},
+			expectedError: "metric rule 0: unsupported metric type `rate`, must be `count` or `gauge`",
+		},
+		{
+			name:          "incomplete tag",
+			config:        TrapsConfig{MetricRules: []MetricRule{{Trap: "linkDown", Metric: "foo", Tags: []MetricTag{{Variable: "ifIndex"}}}}},
+			expectedError: "metric rule 0: `variable` and `tag` are required for the tags of metric foo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.SetDefaults("host", "default")
+			assert.EqualError(t, err, "invalid config: "+tt.expectedError)
+		})
+	}
+}
+
+func TestUserEngineIDs(t *testing.T) {
+	user := UserV3{Username: "user", EngineIDs: []string{"0x8000000001020304", "80000009030000C0FFEE"}}
+	engineIDs, err := user.GetEngineIDs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"\x80\x00\x00\x00\x01\x02\x03\x04", "\x80\x00\x00\x09\x03\x00\x00\xc0\xff\xee"}, engineIDs)
+
+	user = UserV3{Username: "user"}
+	engineIDs, err = user.GetEngineIDs()
+	require.NoError(t, err)
+	assert.Empty(t, engineIDs)
+
+	tests := []struct {
+		name          string
+		user          UserV3
+		expectedError string
+	}{
+		{
+			name:          "not hexadecimal",
+			user:          UserV3{Username: "user", EngineIDs: []string{"engine"}},
+			expectedError: "user user: invalid engine ID `engine`: encoding/hex: invalid byte: U+006E 'n'",
+		},
+		{
+			name:          "too short",
+			user:          UserV3{UsernameLegacy: "legacy", EngineIDs: []string{"80000001"}},
+			expectedError: "user legacy: invalid engine ID `80000001`: must be between 5 and 32 bytes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := TrapsConfig{Users: []UserV3{tt.user}}
+			err := config.SetDefaults("host", "default")
+			assert.EqualError(t, err, "invalid config: "+tt.expectedError)
+		})
+	}
+}
@@ -31,8 +31,7 @@ func Module() fxutil.Module {
 }
 
 const (
-	ddsource       = "snmp-traps"
-	genericTrapOid = "1.3.6.1.6.3.1.1.5"
+	ddsource = "snmp-traps"
 )
 
 // JSONFormatter is a Formatter implementation that transforms Traps into JSON
@@ -117,14 +116,8 @@ func (f JSONFormatter) formatV1Trap(packet *packet.SnmpPacket) map[string]interf
 	enterpriseOid := oidresolver.NormalizeOID(content.Enterprise)
 	genericTrap := content.GenericTrap
 	specificTrap := content.SpecificTrap
-	var trapOID string
-	if genericTrap == 6 {
-		// Vendor-specific trap
-		trapOID = fmt.Sprintf("%s.0.%d", enterpriseOid, specificTrap)
-	} else {
-		// Generic trap
-		trapOID = fmt.Sprintf("%s.%d", genericTrapOid, genericTrap+1)
-	}
+	// SNMPv1 trap OIDs are always derived from the enterprise and trap numbers
+	trapOID, _, _ := packet.TrapOID()
 	data["snmpTrapOID"] = trapOID
 	trapMetadata, err := f.oidResolver.GetTrapMetadata(trapOID)
 	if err != nil {
@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package forwarderimpl
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
+)
+
+// dedupCache remembers the traps forwarded during the dedup window so that
+// identical traps sent by a flapping device are only forwarded once per window
+type dedupCache struct {
+	window time.Duration
+	expiry map[uint64]time.Time
+}
+
+func newDedupCache(window time.Duration) *dedupCache {
+	return &dedupCache{
+		window: window,
+		expiry: make(map[uint64]time.Time),
+	}
+}
+
+// isDuplicate returns true if an identical trap was forwarded less than window ago,
+// otherwise the trap is remembered until the end of the window
+func (d *dedupCache) isDuplicate(key uint64, now time.Time) bool {
+	if expiry, ok := d.expiry[key]; ok && now.Before(expiry) {
+		return true
+	}
+	d.expiry[key] = now.Add(d.window)
+	return false
+}
+
+// expire forgets about the traps whose window ended
+func (d *dedupCache) expire(now time.Time) {
+	for key, expiry := range d.expiry {
+		if !now.Before(expiry) {
+			delete(d.expiry, key)
+		}
+	}
+}
+
+// dedupKey identifies a trap by its sender, its OID and its variables.
+// The uptime of SNMPv1 traps is not part of the key since it changes with every trap.
+func dedupKey(p *packet.SnmpPacket, trapOID string, variables []gosnmp.SnmpPDU) uint64 {
+	h := fnv.New64a()
+	// Hash write never returns an error
+	fmt.Fprintf(h, "%s|%s|%s|", p.Namespace, p.Addr.IP.String(), trapOID) //nolint:errcheck
+	for _, variable := range variables {
+		fmt.Fprintf(h, "%s=%v|", oidresolver.NormalizeOID(variable.Name), variable.Value) //nolint:errcheck
+	}
+	return h.Sum64()
+}
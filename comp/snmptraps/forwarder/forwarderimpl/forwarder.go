@@ -19,6 +19,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/snmptraps/formatter"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/forwarder"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/listener"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
@@ -36,20 +37,27 @@ func Module() fxutil.Module {
 // to the minimum. The forwarder process payloads received by the listener via the trapsIn channel, formats them and finally
 // give them to the epforwarder for sending it to Datadog.
 type trapForwarder struct {
-	trapsIn   packet.PacketsChannel
-	formatter formatter.Component
-	sender    sender.Sender
-	stopChan  chan struct{}
-	logger    log.Component
+	trapsIn     packet.PacketsChannel
+	formatter   formatter.Component
+	oidResolver oidresolver.Component
+	sender      sender.Sender
+	stopChan    chan struct{}
+	logger      log.Component
+	metricRules []config.MetricRule
+	dedup       *dedupCache // nil when deduplication is disabled
 }
 
+// for testing
+var timeNow = time.Now
+
 type dependencies struct {
 	fx.In
-	Config    config.Component
-	Formatter formatter.Component
-	Demux     demultiplexer.Component
-	Listener  listener.Component
-	Logger    log.Component
+	Config      config.Component
+	Formatter   formatter.Component
+	OIDResolver oidresolver.Component
+	Demux       demultiplexer.Component
+	Listener    listener.Component
+	Logger      log.Component
 }
 
 // newTrapForwarder creates a simple TrapForwarder instance
@@ -58,14 +66,19 @@ func newTrapForwarder(lc fx.Lifecycle, dep dependencies) (forwarder.Component, e
 	if err != nil {
 		return nil, err
 	}
+	conf := dep.Config.Get()
 	tf := &trapForwarder{
-		trapsIn:   dep.Listener.Packets(),
-		formatter: dep.Formatter,
-		sender:    sender,
-		stopChan:  make(chan struct{}, 1),
-		logger:    dep.Logger,
+		trapsIn:     dep.Listener.Packets(),
+		formatter:   dep.Formatter,
+		oidResolver: dep.OIDResolver,
+		sender:      sender,
+		stopChan:    make(chan struct{}, 1),
+		logger:      dep.Logger,
+		metricRules: conf.MetricRules,
+	}
+	if window := conf.GetDedupWindow(); window > 0 {
+		tf.dedup = newDedupCache(window)
 	}
-	conf := dep.Config.Get()
 	if conf.Enabled {
 		lc.Append(fx.Hook{
 			OnStart: func(_ context.Context) error {
@@ -108,12 +121,25 @@ func (tf *trapForwarder) run() {
 		case packet := <-tf.trapsIn:
 			tf.sendTrap(packet)
 		case <-flushTicker.C:
+			if tf.dedup != nil {
+				tf.dedup.expire(timeNow())
+			}
 			tf.sender.Commit() // Commit metrics
 		}
 	}
 }
 
 func (tf *trapForwarder) sendTrap(packet *packet.SnmpPacket) {
+	// Invalid traps are reported by the formatter
+	if trapOID, variables, err := packet.TrapOID(); err == nil {
+		tf.sendMetrics(packet, &trapContent{trapOID: trapOID, variables: variables, resolver: tf.oidResolver})
+		if tf.dedup != nil && tf.dedup.isDuplicate(dedupKey(packet, trapOID, variables), timeNow()) {
+			tf.logger.Tracef("drop duplicate trap %s from %s", trapOID, packet.Addr.IP)
+			tf.sender.Count("datadog.snmp_traps.deduplicated", 1, "", packet.GetTags())
+			return
+		}
+	}
+
 	data, err := tf.formatter.FormatPacket(packet)
 	if err != nil {
 		tf.logger.Errorf("failed to format packet: %s", err)
@@ -11,16 +11,20 @@ import (
 	"time"
 
 	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/fx"
 
 	"github.com/DataDog/datadog-agent/comp/forwarder/eventplatform"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/config"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/config/configimpl"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/formatter"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/formatter/formatterimpl"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/forwarder"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/listener"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/listener/listenerimpl"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver/oidresolverimpl"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/senderhelper"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
@@ -38,11 +42,18 @@ type services struct {
 }
 
 func setUp(t *testing.T) *services {
+	t.Helper()
+	return setUpWithConfig(t, &config.TrapsConfig{Enabled: true})
+}
+
+func setUpWithConfig(t *testing.T, conf *config.TrapsConfig) *services {
 	t.Helper()
 	s := fxutil.Test[services](t,
 		configimpl.MockModule(),
+		fx.Replace(conf),
 		senderhelper.Opts,
 		formatterimpl.MockModule(),
+		oidresolverimpl.MockModule(),
 		listenerimpl.MockModule(),
 		Module(),
 	)
@@ -98,3 +109,115 @@ func TestForwarderTelemetry(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 	s.Sender.AssertMetric(t, "Count", "datadog.snmp_traps.forwarded", 1, "", []string{"snmp_device:1.1.1.1", "device_namespace:totoro", "snmp_version:2"})
 }
+
+var linkDownTrap = gosnmp.SnmpTrap{
+	Variables: []gosnmp.SnmpPDU{
+		// sysUpTimeInstance
+		{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(1000)},
+		// snmpTrapOID
+		{Name: "1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.OctetString, Value: "1.3.6.1.6.3.1.1.5.3"},
+		// ifIndex.2
+		{Name: "1.3.6.1.2.1.2.2.1.1.2", Type: gosnmp.Integer, Value: 2},
+		// ifOperStatus
+		{Name: "1.3.6.1.2.1.2.2.1.8", Type: gosnmp.Integer, Value: 2},
+	},
+}
+
+func TestMetricRules(t *testing.T) {
+	s := setUpWithConfig(t, &config.TrapsConfig{
+		Enabled: true,
+		MetricRules: []config.MetricRule{
+			{
+				Trap:   "ifDown",
+				Metric: "snmp.interface.down",
+				Type:   config.MetricTypeCount,
+				Tags: []config.MetricTag{
+					{Variable: "1.3.6.1.2.1.2.2.1.1", Tag: "interface_index"},
+					{Variable: "ifOperStatus", Tag: "oper_status"},
+					{Variable: "ifDescr", Tag: "interface"},
+				},
+			},
+			{
+				Trap:   "1.3.6.1.4.1.8072.2.3.0.1",
+				Metric: "snmp.heartbeat.rate",
+				Type:   config.MetricTypeGauge,
+				Value:  "netSnmpExampleHeartbeatRate",
+				Tags:   []config.MetricTag{{Variable: "1.3.6.1.4.1.8072.2.3.2.2", Tag: "heartbeat_name"}},
+			},
+			{
+				Trap:   "linkUp",
+				Metric: "snmp.interface.up",
+				Type:   config.MetricTypeCount,
+			},
+		},
+	})
+	s.Listener.Send(makeSnmpPacket(linkDownTrap))
+	s.Listener.Send(makeSnmpPacket(packet.NetSNMPExampleHeartbeatNotification))
+	time.Sleep(100 * time.Millisecond)
+
+	tags := []string{"snmp_version:2", "device_namespace:totoro", "snmp_device:1.1.1.1"}
+	s.Sender.AssertMetric(t, "Count", "snmp.interface.down", 1, "", append(tags, "interface_index:2", "oper_status:down"))
+	s.Sender.AssertMetric(t, "Gauge", "snmp.heartbeat.rate", 1024, "", append(tags, "heartbeat_name:test"))
+	s.Sender.AssertNotCalled(t, "Count", "snmp.interface.up", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDedupWindow(t *testing.T) {
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	// registered before the forwarder is started so that it runs after it is stopped
+	t.Cleanup(func() { timeNow = time.Now })
+
+	s := setUpWithConfig(t, &config.TrapsConfig{
+		Enabled:     true,
+		DedupWindow: 60,
+		MetricRules: []config.MetricRule{{Trap: "ifDown", Metric: "snmp.interface.down", Type: config.MetricTypeCount}},
+	})
+	trapPacket := makeSnmpPacket(linkDownTrap)
+	rawEvent, err := s.Formatter.FormatPacket(trapPacket)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		s.Listener.Send(makeSnmpPacket(linkDownTrap))
+	}
+	// a different trap from the same device is forwarded
+	s.Listener.Send(makeSnmpPacket(packet.NetSNMPExampleHeartbeatNotification))
+	time.Sleep(100 * time.Millisecond)
+
+	tags := []string{"snmp_version:2", "device_namespace:totoro", "snmp_device:1.1.1.1"}
+	s.Sender.AssertEventPlatformEvent(t, rawEvent, eventplatform.EventTypeSnmpTraps)
+	s.Sender.AssertNumberOfCalls(t, "EventPlatformEvent", 2)
+	s.Sender.AssertNumberOfCalls(t, "Count", 3+2+2) // metric rule, forwarded and deduplicated counts
+	s.Sender.AssertMetric(t, "Count", "datadog.snmp_traps.deduplicated", 1, "", tags)
+}
+
+func TestDedupCache(t *testing.T) {
+	now := time.Now()
+	cache := newDedupCache(time.Minute)
+
+	assert.False(t, cache.isDuplicate(1, now))
+	assert.True(t, cache.isDuplicate(1, now.Add(30*time.Second)))
+	assert.False(t, cache.isDuplicate(2, now.Add(30*time.Second)))
+	assert.False(t, cache.isDuplicate(1, now.Add(time.Minute)))
+
+	cache.expire(now.Add(90 * time.Second))
+	assert.Len(t, cache.expiry, 1)
+	cache.expire(now.Add(2 * time.Minute))
+	assert.Empty(t, cache.expiry)
+}
+
+func TestDedupKey(t *testing.T) {
+	p := makeSnmpPacket(linkDownTrap)
+	trapOID, variables, err := p.TrapOID()
+	require.NoError(t, err)
+	key := dedupKey(p, trapOID, variables)
+
+	other := makeSnmpPacket(linkDownTrap)
+	other.Content.Variables = append([]gosnmp.SnmpPDU{{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(2000)}}, linkDownTrap.Variables[1:]...)
+	trapOID, variables, err = other.TrapOID()
+	require.NoError(t, err)
+	assert.Equal(t, key, dedupKey(other, trapOID, variables), "the uptime should not be part of the key")
+
+	other = makeSnmpPacket(linkDownTrap)
+	other.Addr = &net.UDPAddr{IP: net.IPv4(1, 1, 1, 2), Port: 161}
+	assert.NotEqual(t, key, dedupKey(other, trapOID, variables))
+}
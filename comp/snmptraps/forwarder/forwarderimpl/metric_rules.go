@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package forwarderimpl
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/comp/snmptraps/config"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
+)
+
+// trapContent gives access to the variables of a trap by OID or by name,
+// names are resolved with the OID resolver
+type trapContent struct {
+	trapOID   string
+	variables []gosnmp.SnmpPDU
+	resolver  oidresolver.Component
+}
+
+// matches returns true if ref is the OID or the name of the trap
+func (t *trapContent) matches(ref string) bool {
+	if oidresolver.NormalizeOID(ref) == t.trapOID {
+		return true
+	}
+	trapMetadata, err := t.resolver.GetTrapMetadata(t.trapOID)
+	return err == nil && trapMetadata.Name == ref
+}
+
+// variable returns the first variable whose OID starts with ref, or whose name is ref
+func (t *trapContent) variable(ref string) (gosnmp.SnmpPDU, oidresolver.VariableMetadata, bool) {
+	refOID := oidresolver.NormalizeOID(ref)
+	isOID := oidresolver.IsValidOID(refOID)
+	for _, variable := range t.variables {
+		varOID := oidresolver.NormalizeOID(variable.Name)
+		varMetadata, err := t.resolver.GetVariableMetadata(t.trapOID, varOID)
+		if isOID && (varOID == refOID || strings.HasPrefix(varOID, refOID+".")) {
+			return variable, varMetadata, true
+		}
+		if !isOID && err == nil && varMetadata.Name == ref {
+			return variable, varMetadata, true
+		}
+	}
+	return gosnmp.SnmpPDU{}, oidresolver.VariableMetadata{}, false
+}
+
+// sendMetrics submits the metrics of the rules matching the trap
+func (tf *trapForwarder) sendMetrics(p *packet.SnmpPacket, trap *trapContent) {
+	for _, rule := range tf.metricRules {
+		if !trap.matches(rule.Trap) {
+			continue
+		}
+
+		tags := p.GetTags()
+		for _, metricTag := range rule.Tags {
+			variable, varMetadata, ok := trap.variable(metricTag.Variable)
+			if !ok {
+				tf.logger.Debugf("variable %s of tag %s not found in trap %s", metricTag.Variable, metricTag.Tag, trap.trapOID)
+				continue
+			}
+			tags = append(tags, metricTag.Tag+":"+tagValue(variable, varMetadata))
+		}
+
+		switch rule.Type {
+		case config.MetricTypeGauge:
+			variable, _, ok := trap.variable(rule.Value)
+			if !ok {
+				tf.logger.Debugf("variable %s of metric %s not found in trap %s", rule.Value, rule.Metric, trap.trapOID)
+				continue
+			}
+			value, err := gaugeValue(variable)
+			if err != nil {
+				tf.logger.Debugf("invalid value for metric %s: %s", rule.Metric, err)
+				continue
+			}
+			tf.sender.Gauge(rule.Metric, value, "", tags)
+		default:
+			tf.sender.Count(rule.Metric, 1, "", tags)
+		}
+	}
+}
+
+// tagValue formats the value of a variable, enums are replaced by their name
+func tagValue(variable gosnmp.SnmpPDU, varMetadata oidresolver.VariableMetadata) string {
+	switch value := variable.Value.(type) {
+	case int:
+		if name, ok := varMetadata.Enumeration[value]; ok {
+			return name
+		}
+		return strconv.Itoa(value)
+	case []byte:
+		return string(value)
+	case string:
+		if variable.Type == gosnmp.ObjectIdentifier {
+			return oidresolver.NormalizeOID(value)
+		}
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func gaugeValue(variable gosnmp.SnmpPDU) (float64, error) {
+	switch value := variable.Value.(type) {
+	case float32:
+		return float64(value), nil
+	case float64:
+		return value, nil
+	case []byte:
+		return strconv.ParseFloat(string(value), 64)
+	case string:
+		return strconv.ParseFloat(value, 64)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		// counter64 values may not fit in an int64
+		f, _ := new(big.Float).SetInt(gosnmp.ToBigInt(value)).Float64()
+		return f, nil
+	default:
+		return 0, fmt.Errorf("unsupported value %v of type %T", variable.Value, variable.Value)
+	}
+}
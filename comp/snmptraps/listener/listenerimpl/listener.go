@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/gosnmp/gosnmp"
@@ -24,7 +25,18 @@ import (
 	"github.com/DataDog/datadog-agent/comp/snmptraps/config"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/listener"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/snmplog"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/status"
+	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
+)
+
+const (
+	// unmarshalTrapErrorFormat is the format gosnmp uses to log the packets it couldn't decode.
+	// It isn't part of the gosnmp API, it's pinned to gosnmp v1.38.0 by TestGosnmpUnmarshalTrapErrors.
+	unmarshalTrapErrorFormat = "TrapListener: error in UnmarshalTrap %s\n"
+
+	reasonUnauthenticated = "unauthenticated"
+	reasonUnknownEngineID = "unknown_engine_id"
 )
 
 // Module defines the fx options for this component.
@@ -43,6 +55,17 @@ type trapListener struct {
 	errorsChannel chan error
 	logger        log.Component
 	status        status.Component
+	users         []v3User
+}
+
+// v3User holds the credentials of a configured user and the engine IDs it is restricted to
+type v3User struct {
+	username     string
+	authKey      string
+	authProtocol gosnmp.SnmpV3AuthProtocol
+	privKey      string
+	privProtocol gosnmp.SnmpV3PrivProtocol
+	engineIDs    map[string]struct{}
 }
 
 type dependencies struct {
@@ -65,6 +88,10 @@ func newTrapListener(lc fx.Lifecycle, dep dependencies) (listener.Component, err
 	if err != nil {
 		return nil, err
 	}
+	users, err := buildV3Users(config.Users)
+	if err != nil {
+		return nil, err
+	}
 	errorsChan := make(chan error, 1)
 	trapListener := &trapListener{
 		config:        config,
@@ -74,9 +101,15 @@ func newTrapListener(lc fx.Lifecycle, dep dependencies) (listener.Component, err
 		errorsChannel: errorsChan,
 		logger:        dep.Logger,
 		status:        dep.Status,
+		users:         users,
 	}
 
 	gosnmpListener.OnNewTrap = trapListener.receiveTrap
+	// gosnmp only logs the v3 packets it couldn't authenticate, wrap its logger to count them
+	gosnmpListener.Params.Logger = gosnmp.NewLogger(&unmarshalErrorsLogger{
+		LoggerInterface: snmplog.New(dep.Logger),
+		listener:        trapListener,
+	})
 	if config.Enabled {
 		lc.Append(fx.Hook{
 			OnStart: func(_ context.Context) error {
@@ -142,8 +175,14 @@ func (t *trapListener) stop() error {
 	return nil
 }
 
+// receiveTrap is called by gosnmp for each decoded packet. Once it returns, gosnmp answers
+// INFORMs by reusing the packet, so a copy is sent to the channel. Clearing the INFORM PDU type
+// of rejected packets prevents them from being acknowledged: gosnmp has no hook to validate a packet
+// before the ack, and as of gosnmp v1.38.0 TrapListener only acks packets whose PDU type is still
+// InformRequest after OnNewTrap returns. TestServerV2InformBadCredentials and
+// TestServerV3InformUnknownEngineID fail if that flow changes.
 func (t *trapListener) receiveTrap(p *gosnmp.SnmpPacket, u *net.UDPAddr) {
-	packet := &packet.SnmpPacket{Content: p, Addr: u, Timestamp: time.Now().UnixMilli(), Namespace: t.config.Namespace}
+	packet := &packet.SnmpPacket{Content: copyPacket(p), Addr: u, Timestamp: time.Now().UnixMilli(), Namespace: t.config.Namespace}
 	tags := packet.GetTags()
 
 	t.sender.Count("datadog.snmp_traps.received", 1, "", tags)
@@ -152,13 +191,140 @@ func (t *trapListener) receiveTrap(p *gosnmp.SnmpPacket, u *net.UDPAddr) {
 		t.logger.Debugf("Invalid credentials from %s on listener %s, dropping traps", u.String(), t.config.Addr())
 		t.status.AddTrapsPacketsUnknownCommunityString(1)
 		t.sender.Count("datadog.snmp_traps.invalid_packet", 1, "", append(tags, "reason:unknown_community_string"))
+		p.PDUType = gosnmp.SNMPv2Trap
+		return
+	}
+	if reason, err := t.validateV3Packet(p); err != nil {
+		t.logger.Debugf("%s from %s on listener %s, dropping traps", err, u.String(), t.config.Addr())
+		if reason == reasonUnknownEngineID {
+			t.status.AddTrapsPacketsUnknownEngineID(1)
+		} else {
+			t.status.AddTrapsPacketsUnauthenticated(1)
+		}
+		t.sender.Count("datadog.snmp_traps.invalid_packet", 1, "", append(tags, "reason:"+reason))
+		p.PDUType = gosnmp.SNMPv2Trap
 		return
 	}
-	t.logger.Debugf("Packet received from %s on listener %s", u.String(), t.config.Addr())
+	if p.PDUType == gosnmp.InformRequest {
+		t.logger.Debugf("Inform received from %s on listener %s, sending response", u.String(), t.config.Addr())
+	} else {
+		t.logger.Debugf("Packet received from %s on listener %s", u.String(), t.config.Addr())
+	}
 	t.status.AddTrapsPackets(1)
 	t.packets <- packet
 }
 
+// copyPacket returns a copy of the packet that isn't modified when gosnmp answers an INFORM
+func copyPacket(p *gosnmp.SnmpPacket) *gosnmp.SnmpPacket {
+	packetCopy := *p
+	packetCopy.Variables = append([]gosnmp.SnmpPDU(nil), p.Variables...)
+	if p.SecurityParameters != nil {
+		packetCopy.SecurityParameters = p.SecurityParameters.Copy()
+	}
+	return &packetCopy
+}
+
+// validateV3Packet returns an error and the reason to report when the packet wasn't authenticated
+// by a configured user, or when the user is restricted to engine IDs that don't include the one of the device
+func (t *trapListener) validateV3Packet(p *gosnmp.SnmpPacket) (string, error) {
+	if p.Version != gosnmp.Version3 {
+		return "", nil
+	}
+	params, ok := p.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok || params.UserName == "" {
+		// Only engine ID discovery requests are expected from the empty user, gosnmp already answered them
+		return reasonUnauthenticated, errors.New("unauthenticated packet")
+	}
+	// The authoritative engine of a trap is the device, while it's the agent for an INFORM
+	engineID := params.AuthoritativeEngineID
+	if p.PDUType == gosnmp.InformRequest {
+		engineID = p.ContextEngineID
+	}
+	for _, user := range t.users {
+		if !user.matches(params) {
+			continue
+		}
+		if len(user.engineIDs) == 0 {
+			return "", nil
+		}
+		if _, ok := user.engineIDs[engineID]; ok {
+			return "", nil
+		}
+	}
+	return reasonUnknownEngineID, fmt.Errorf("unknown engine ID %x for user %s", engineID, params.UserName)
+}
+
+// onUnmarshalError is called with the errors of the packets gosnmp couldn't decode
+func (t *trapListener) onUnmarshalError(err string) {
+	// Errors returned by gosnmp v1.38.0 when no configured user matches the packet or when its authentication
+	// or decryption failed, pinned by TestGosnmpUnmarshalTrapErrors
+	if !strings.Contains(err, "no security parameters found") && !strings.Contains(err, "no credentials successfully unmarshaled") {
+		return
+	}
+	t.logger.Debugf("Unauthenticated packet on listener %s, dropping traps: %s", t.config.Addr(), err)
+	t.status.AddTrapsPacketsUnauthenticated(1)
+	tags := []string{"device_namespace:" + t.config.Namespace, "snmp_version:3", "reason:" + reasonUnauthenticated}
+	t.sender.Count("datadog.snmp_traps.invalid_packet", 1, "", tags)
+}
+
+func buildV3Users(users []config.UserV3) ([]v3User, error) {
+	var v3Users []v3User
+	for _, user := range users {
+		// Backward compatibility
+		if user.Username == "" {
+			user.Username = user.UsernameLegacy
+		}
+		authProtocol, err := gosnmplib.GetAuthProtocol(user.AuthProtocol)
+		if err != nil {
+			return nil, err
+		}
+		privProtocol, err := gosnmplib.GetPrivProtocol(user.PrivProtocol)
+		if err != nil {
+			return nil, err
+		}
+		engineIDs, err := user.GetEngineIDs()
+		if err != nil {
+			return nil, err
+		}
+		v3User := v3User{
+			username:     user.Username,
+			authKey:      user.AuthKey,
+			authProtocol: authProtocol,
+			privKey:      user.PrivKey,
+			privProtocol: privProtocol,
+			engineIDs:    make(map[string]struct{}, len(engineIDs)),
+		}
+		for _, engineID := range engineIDs {
+			v3User.engineIDs[engineID] = struct{}{}
+		}
+		v3Users = append(v3Users, v3User)
+	}
+	return v3Users, nil
+}
+
+// matches returns true if the packet was authenticated with the credentials of the user
+func (u *v3User) matches(params *gosnmp.UsmSecurityParameters) bool {
+	return u.username == params.UserName &&
+		u.authKey == params.AuthenticationPassphrase &&
+		u.authProtocol == params.AuthenticationProtocol &&
+		u.privKey == params.PrivacyPassphrase &&
+		u.privProtocol == params.PrivacyProtocol
+}
+
+// unmarshalErrorsLogger is a gosnmp logger reporting the packets that couldn't be decoded to the listener
+type unmarshalErrorsLogger struct {
+	gosnmp.LoggerInterface
+	listener *trapListener
+}
+
+// Printf implements gosnmp.LoggerInterface#Printf
+func (l *unmarshalErrorsLogger) Printf(format string, v ...interface{}) {
+	if format == unmarshalTrapErrorFormat && len(v) == 1 {
+		l.listener.onUnmarshalError(fmt.Sprint(v[0]))
+	}
+	l.LoggerInterface.Printf(format, v...)
+}
+
 func validatePacket(p *gosnmp.SnmpPacket, c *config.TrapsConfig) error {
 	if p.Version == gosnmp.Version3 {
 		// v3 Packets are already decrypted and validated by gosnmp
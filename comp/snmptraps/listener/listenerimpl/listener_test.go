@@ -7,6 +7,9 @@ package listenerimpl
 
 import (
 	"errors"
+	"fmt"
+	"net"
+	"sync"
 	"testing"
 	"time"
 
@@ -231,6 +234,185 @@ func TestServerV3BadCredentials(t *testing.T) {
 		PrivacyProtocol:          gosnmp.AES,
 	})
 	assertNoPacketReceived(t, s.Listener)
+	assert.Eventually(t, func() bool {
+		return s.Status.GetTrapsPacketsUnauthenticated() == 1
+	}, defaultTimeout, 20*time.Millisecond)
+	s.Sender.AssertMetric(t, "Count", "datadog.snmp_traps.invalid_packet", 1, "", []string{"device_namespace:default", "snmp_version:3", "reason:unauthenticated"})
+}
+
+func TestServerV3UnknownUser(t *testing.T) {
+	serverPort, err := ndmtestutils.GetFreePort()
+	require.NoError(t, err)
+	config := &config.TrapsConfig{Port: serverPort, Users: users}
+	s := listenerTestSetup(t, config)
+
+	sendTestV3Trap(t, config, gosnmp.AuthPriv, &gosnmp.UsmSecurityParameters{
+		UserName:                 "unknown_user",
+		AuthoritativeEngineID:    "foobarbaz",
+		AuthenticationPassphrase: "password",
+		AuthenticationProtocol:   gosnmp.SHA,
+		PrivacyPassphrase:        "password",
+		PrivacyProtocol:          gosnmp.AES,
+	})
+	assertNoPacketReceived(t, s.Listener)
+	assert.Eventually(t, func() bool {
+		return s.Status.GetTrapsPacketsUnauthenticated() == 1
+	}, defaultTimeout, 20*time.Millisecond)
+}
+
+var engineIDsUser = config.UserV3{
+	Username:     "user",
+	AuthKey:      "password",
+	AuthProtocol: "sha",
+	PrivKey:      "password",
+	PrivProtocol: "aes",
+	EngineIDs:    []string{"0x8000000001020304"},
+}
+
+func engineIDsSecurityParams(engineID string) *gosnmp.UsmSecurityParameters {
+	return &gosnmp.UsmSecurityParameters{
+		UserName:                 "user",
+		AuthoritativeEngineID:    engineID,
+		AuthenticationPassphrase: "password",
+		AuthenticationProtocol:   gosnmp.SHA,
+		PrivacyPassphrase:        "password",
+		PrivacyProtocol:          gosnmp.AES,
+	}
+}
+
+func TestServerV3EngineIDs(t *testing.T) {
+	serverPort, err := ndmtestutils.GetFreePort()
+	require.NoError(t, err)
+	config := &config.TrapsConfig{Port: serverPort, Users: []config.UserV3{engineIDsUser}, Namespace: "totoro"}
+	s := listenerTestSetup(t, config)
+
+	sendTestV3Trap(t, config, gosnmp.AuthPriv, engineIDsSecurityParams("\x80\x00\x00\x00\x01\x02\x03\x04"))
+	packet, err := receivePacket(s, defaultTimeout)
+	require.NoError(t, err)
+	assertVariables(t, packet)
+
+	sendTestV3Trap(t, config, gosnmp.AuthPriv, engineIDsSecurityParams("\x80\x00\x00\x00\x01\x02\x03\x05"))
+	assertNoPacketReceived(t, s.Listener)
+	assert.Eventually(t, func() bool {
+		return s.Status.GetTrapsPacketsUnknownEngineID() == 1
+	}, defaultTimeout, 20*time.Millisecond)
+	s.Sender.AssertMetric(t, "Count", "datadog.snmp_traps.invalid_packet", 1, "", []string{"snmp_device:127.0.0.1", "device_namespace:totoro", "snmp_version:3", "reason:unknown_engine_id"})
+}
+
+func TestServerV3Inform(t *testing.T) {
+	serverPort, err := ndmtestutils.GetFreePort()
+	require.NoError(t, err)
+	config := &config.TrapsConfig{Port: serverPort, Users: []config.UserV3{engineIDsUser}}
+	s := listenerTestSetup(t, config)
+
+	// the engine ID of the agent is discovered by the sender
+	response, err := sendTestV3Inform(t, config, "\x80\x00\x00\x00\x01\x02\x03\x04", engineIDsSecurityParams(""))
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.GetResponse, response.PDUType)
+	assert.Equal(t, gosnmp.NoError, response.Error)
+
+	packet, err := receivePacket(s, defaultTimeout)
+	require.NoError(t, err)
+	// the response sent by gosnmp doesn't modify the forwarded packet
+	assert.Equal(t, gosnmp.InformRequest, packet.Content.PDUType)
+	assertVariables(t, packet)
+}
+
+func TestServerV3InformUnknownEngineID(t *testing.T) {
+	serverPort, err := ndmtestutils.GetFreePort()
+	require.NoError(t, err)
+	config := &config.TrapsConfig{Port: serverPort, Users: []config.UserV3{engineIDsUser}}
+	s := listenerTestSetup(t, config)
+
+	// rejected INFORMs aren't acknowledged
+	_, err = sendTestV3Inform(t, config, "\x80\x00\x00\x00\x01\x02\x03\x05", engineIDsSecurityParams(""))
+	require.Error(t, err)
+	assertNoPacketReceived(t, s.Listener)
+	assert.Equal(t, int64(2), s.Status.GetTrapsPacketsUnknownEngineID())
+}
+
+func TestServerV2InformBadCredentials(t *testing.T) {
+	serverPort, err := ndmtestutils.GetFreePort()
+	require.NoError(t, err)
+	config := &config.TrapsConfig{Port: serverPort, CommunityStrings: []string{"public"}}
+	s := listenerTestSetup(t, config)
+
+	// rejected INFORMs aren't acknowledged
+	_, err = sendTestV2Inform(t, config, "wrong-community")
+	require.Error(t, err)
+	assertNoPacketReceived(t, s.Listener)
+
+	response, err := sendTestV2Inform(t, config, "public")
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.GetResponse, response.PDUType)
+	packet, err := receivePacket(s, defaultTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.InformRequest, packet.Content.PDUType)
+}
+
+// recordingLogger records the errors logged by gosnmp for the packets it couldn't decode
+type recordingLogger struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+func (l *recordingLogger) Print(...interface{}) {}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if format == unmarshalTrapErrorFormat && len(v) == 1 {
+		l.errors = append(l.errors, fmt.Sprint(v[0]))
+	}
+}
+
+func (l *recordingLogger) recorded() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.errors...)
+}
+
+// TestGosnmpUnmarshalTrapErrors fails if gosnmp changes the way it logs the v3 packets it couldn't
+// authenticate, that unmarshalErrorsLogger relies on to count them
+func TestGosnmpUnmarshalTrapErrors(t *testing.T) {
+	serverPort, err := ndmtestutils.GetFreePort()
+	require.NoError(t, err)
+	userV3 := config.UserV3{Username: "user", AuthKey: "password", AuthProtocol: "sha", PrivKey: "password", PrivProtocol: "aes"}
+	config := &config.TrapsConfig{Port: serverPort, Users: []config.UserV3{userV3}}
+
+	logger := &recordingLogger{}
+	gosnmpListener := gosnmp.NewTrapListener()
+	gosnmpListener.Params, err = config.BuildSNMPParams(nil)
+	require.NoError(t, err)
+	gosnmpListener.Params.Logger = gosnmp.NewLogger(logger)
+	gosnmpListener.OnNewTrap = func(*gosnmp.SnmpPacket, *net.UDPAddr) {}
+	go gosnmpListener.Listen(config.Addr()) //nolint:errcheck
+	defer gosnmpListener.Close()
+	select {
+	case <-gosnmpListener.Listening():
+	case <-time.After(defaultTimeout):
+		require.FailNow(t, "the gosnmp listener didn't start")
+	}
+
+	securityParams := func(userName, privacyPassphrase string) *gosnmp.UsmSecurityParameters {
+		return &gosnmp.UsmSecurityParameters{
+			UserName:                 userName,
+			AuthoritativeEngineID:    "foobarbaz",
+			AuthenticationPassphrase: "password",
+			AuthenticationProtocol:   gosnmp.SHA,
+			PrivacyPassphrase:        privacyPassphrase,
+			PrivacyProtocol:          gosnmp.AES,
+		}
+	}
+	sendTestV3Trap(t, config, gosnmp.AuthPriv, securityParams("unknown_user", "password"))
+	sendTestV3Trap(t, config, gosnmp.AuthPriv, securityParams("user", "wrong_password"))
+
+	require.Eventually(t, func() bool {
+		return len(logger.recorded()) == 2
+	}, defaultTimeout, 20*time.Millisecond, "gosnmp no longer logs the packets it couldn't decode with %q", unmarshalTrapErrorFormat)
+	errors := logger.recorded()
+	assert.Contains(t, errors[0], "no security parameters found")
+	assert.Contains(t, errors[1], "no credentials successfully unmarshaled")
 }
 
 func TestListenerTrapsReceivedTelemetry(t *testing.T) {
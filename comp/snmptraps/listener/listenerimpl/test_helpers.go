@@ -91,6 +91,40 @@ func sendTestV3Trap(t *testing.T, trapConfig *config.TrapsConfig, msgFlags gosnm
 	return params
 }
 
+func sendTestV3Inform(t *testing.T, trapConfig *config.TrapsConfig, contextEngineID string, securityParams *gosnmp.UsmSecurityParameters) (*gosnmp.SnmpPacket, error) {
+	params, err := trapConfig.BuildSNMPParams(nil)
+	require.NoError(t, err)
+	params.MsgFlags = gosnmp.AuthPriv
+	params.SecurityParameters = securityParams
+	params.ContextEngineID = contextEngineID
+	params.Timeout = 500 * time.Millisecond // Must be non-zero when sending traps.
+	params.Retries = 1                      // Must be non-zero when sending traps.
+
+	err = params.Connect()
+	require.NoError(t, err)
+	defer params.Conn.Close()
+
+	trap := packet.NetSNMPExampleHeartbeatNotification
+	trap.IsInform = true
+	return params.SendTrap(trap)
+}
+
+func sendTestV2Inform(t *testing.T, trapConfig *config.TrapsConfig, community string) (*gosnmp.SnmpPacket, error) {
+	params, err := trapConfig.BuildSNMPParams(nil)
+	require.NoError(t, err)
+	params.Community = community
+	params.Timeout = 500 * time.Millisecond // Must be non-zero when sending traps.
+	params.Retries = 1                      // Must be non-zero when sending traps.
+
+	err = params.Connect()
+	require.NoError(t, err)
+	defer params.Conn.Close()
+
+	trap := packet.NetSNMPExampleHeartbeatNotification
+	trap.IsInform = true
+	return params.SendTrap(trap)
+}
+
 func assertIsValidV2Packet(t *testing.T, packet *packet.SnmpPacket, trapConfig *config.TrapsConfig) {
 	require.Equal(t, gosnmp.Version2c, packet.Content.Version)
 	communityValid := false
@@ -7,9 +7,17 @@
 package packet
 
 import (
+	"fmt"
 	"net"
 
 	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+)
+
+const (
+	genericTrapOID = "1.3.6.1.6.3.1.1.5"
+	snmpTrapOID    = "1.3.6.1.6.3.1.1.4.1.0"
 )
 
 // SnmpPacket is the type of packets yielded by server listeners.
@@ -32,6 +40,36 @@ func (p *SnmpPacket) GetTags() []string {
 	}
 }
 
+// TrapOID returns the OID of the trap and the variables it carries.
+// For SNMPv2+ traps, the sysUpTime.0 and snmpTrapOID.0 variables are not part of the returned variables.
+func (p *SnmpPacket) TrapOID() (string, []gosnmp.SnmpPDU, error) {
+	content := p.Content
+	if content.Version == gosnmp.Version1 {
+		if content.GenericTrap == 6 {
+			// Vendor-specific trap
+			return fmt.Sprintf("%s.0.%d", oidresolver.NormalizeOID(content.Enterprise), content.SpecificTrap), content.Variables, nil
+		}
+		// Generic trap
+		return fmt.Sprintf("%s.%d", genericTrapOID, content.GenericTrap+1), content.Variables, nil
+	}
+
+	if len(content.Variables) < 2 {
+		return "", nil, fmt.Errorf("expected at least 2 variables, got %d", len(content.Variables))
+	}
+	variable := content.Variables[1]
+	if name := oidresolver.NormalizeOID(variable.Name); name != snmpTrapOID {
+		return "", nil, fmt.Errorf("expected OID %s, got %s", snmpTrapOID, name)
+	}
+	switch value := variable.Value.(type) {
+	case string:
+		return oidresolver.NormalizeOID(value), content.Variables[2:], nil
+	case []byte:
+		return oidresolver.NormalizeOID(string(value)), content.Variables[2:], nil
+	default:
+		return "", nil, fmt.Errorf("expected snmpTrapOID to be a string (got %v of type %T)", variable.Value, variable.Value)
+	}
+}
+
 func formatVersion(packet *gosnmp.SnmpPacket) string {
 	switch packet.Version {
 	case gosnmp.Version3:
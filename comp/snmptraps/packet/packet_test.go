@@ -8,6 +8,8 @@ package packet
 import (
 	"testing"
 
+	"github.com/gosnmp/gosnmp"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,3 +40,34 @@ func TestGetTagsForUnsupportedVersionShouldStillSucceed(t *testing.T) {
 		"snmp_device:127.0.0.1",
 	})
 }
+
+func TestTrapOID(t *testing.T) {
+	packet := CreateTestPacket(NetSNMPExampleHeartbeatNotification)
+	trapOID, variables, err := packet.TrapOID()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.4.1.8072.2.3.0.1", trapOID)
+	assert.Equal(t, NetSNMPExampleHeartbeatNotification.Variables[2:], variables)
+}
+
+func TestTrapOIDSNMPV1(t *testing.T) {
+	trapOID, variables, err := CreateTestV1GenericPacket().TrapOID()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.6.3.1.1.5.3", trapOID)
+	assert.Equal(t, LinkDownv1GenericTrap.Variables, variables)
+
+	trapOID, _, err = CreateTestV1SpecificPacket().TrapOID()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.2.1.118.0.2", trapOID)
+}
+
+func TestTrapOIDInvalidVariables(t *testing.T) {
+	packet := CreateTestPacket(NetSNMPExampleHeartbeatNotification)
+	packet.Content.Variables = packet.Content.Variables[:1]
+	_, _, err := packet.TrapOID()
+	assert.EqualError(t, err, "expected at least 2 variables, got 1")
+
+	packet = CreateTestPacket(NetSNMPExampleHeartbeatNotification)
+	packet.Content.Variables = []gosnmp.SnmpPDU{packet.Content.Variables[0], packet.Content.Variables[2]}
+	_, _, err = packet.TrapOID()
+	assert.EqualError(t, err, "expected OID 1.3.6.1.6.3.1.1.4.1.0, got 1.3.6.1.4.1.8072.2.3.2.1")
+}
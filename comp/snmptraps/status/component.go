@@ -15,6 +15,10 @@ type Component interface {
 	GetTrapsPackets() int64
 	AddTrapsPacketsUnknownCommunityString(int64)
 	GetTrapsPacketsUnknownCommunityString() int64
+	AddTrapsPacketsUnknownEngineID(int64)
+	GetTrapsPacketsUnknownEngineID() int64
+	AddTrapsPacketsUnauthenticated(int64)
+	GetTrapsPacketsUnauthenticated() int64
 	SetStartError(error)
 	GetStartError() error
 }
@@ -29,6 +29,8 @@ func newMock() status.Component {
 // mockManager mocks a manager using plain values (not expvars)
 type mockManager struct {
 	trapsPackets, trapsPacketsUnknownCommunityString int64
+	trapsPacketsUnknownEngineID                      int64
+	trapsPacketsUnauthenticated                      int64
 	lock                                             sync.Mutex
 	err                                              error
 }
@@ -57,6 +59,30 @@ func (s *mockManager) GetTrapsPacketsUnknownCommunityString() int64 {
 	return s.trapsPacketsUnknownCommunityString
 }
 
+func (s *mockManager) AddTrapsPacketsUnknownEngineID(i int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.trapsPacketsUnknownEngineID += i
+}
+
+func (s *mockManager) GetTrapsPacketsUnknownEngineID() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.trapsPacketsUnknownEngineID
+}
+
+func (s *mockManager) AddTrapsPacketsUnauthenticated(i int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.trapsPacketsUnauthenticated += i
+}
+
+func (s *mockManager) GetTrapsPacketsUnauthenticated() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.trapsPacketsUnauthenticated
+}
+
 func (s *mockManager) SetStartError(err error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -31,6 +31,8 @@ var (
 	trapsExpvars                       = expvar.NewMap("snmp_traps")
 	trapsPackets                       = expvar.Int{}
 	trapsPacketsUnknownCommunityString = expvar.Int{}
+	trapsPacketsUnknownEngineID        = expvar.Int{}
+	trapsPacketsUnauthenticated        = expvar.Int{}
 	// startError stores the error we report to GetStatus()
 	startError error
 )
@@ -38,6 +40,8 @@ var (
 func init() {
 	trapsExpvars.Set("Packets", &trapsPackets)
 	trapsExpvars.Set("PacketsUnknownCommunityString", &trapsPacketsUnknownCommunityString)
+	trapsExpvars.Set("PacketsUnknownEngineId", &trapsPacketsUnknownEngineID)
+	trapsExpvars.Set("PacketsUnauthenticated", &trapsPacketsUnauthenticated)
 }
 
 // New creates a new status manager component
@@ -64,6 +68,22 @@ func (s *manager) GetTrapsPacketsUnknownCommunityString() int64 {
 	return trapsPacketsUnknownCommunityString.Value()
 }
 
+func (s *manager) AddTrapsPacketsUnknownEngineID(i int64) {
+	trapsPacketsUnknownEngineID.Add(i)
+}
+
+func (s *manager) GetTrapsPacketsUnknownEngineID() int64 {
+	return trapsPacketsUnknownEngineID.Value()
+}
+
+func (s *manager) AddTrapsPacketsUnauthenticated(i int64) {
+	trapsPacketsUnauthenticated.Add(i)
+}
+
+func (s *manager) GetTrapsPacketsUnauthenticated() int64 {
+	return trapsPacketsUnauthenticated.Value()
+}
+
 func (s *manager) GetStartError() error {
 	return startError
 }
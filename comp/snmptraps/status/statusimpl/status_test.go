@@ -50,6 +50,10 @@ func TestStatusProvider(t *testing.T) {
 			_ = metrics["PacketsDropped"].(float64)
 			// assert PacketsUnknownCommunityString is float64
 			_ = metrics["PacketsUnknownCommunityString"].(float64)
+			// assert PacketsUnknownEngineId is float64
+			_ = metrics["PacketsUnknownEngineId"].(float64)
+			// assert PacketsUnauthenticated is float64
+			_ = metrics["PacketsUnauthenticated"].(float64)
 		}},
 		{"Text", func(t *testing.T) {
 			b := new(bytes.Buffer)
@@ -60,7 +64,9 @@ func TestStatusProvider(t *testing.T) {
 			expectedOutput := `
   Packets: 0
   Packets Dropped: 42
+  Packets Unauthenticated: 0
   Packets Unknown Community String: 0
+  Packets Unknown Engine Id: 0
 `
 
 			// We replace windows line break by linux so the tests pass on every OS
@@ -81,7 +87,9 @@ func TestStatusProvider(t *testing.T) {
     <span class="stat_data">
           Packets: 0<br>
           Packets Dropped: 42<br>
+          Packets Unauthenticated: 0<br>
           Packets Unknown Community String: 0<br>
+          Packets Unknown Engine Id: 0<br>
     </span>
   </div>
 `
@@ -7,8 +7,11 @@
 package workloadlist
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"time"
 
 	"go.uber.org/fx"
 
@@ -30,6 +33,9 @@ type cliParams struct {
 	GlobalParams
 
 	verboseList bool
+	snapshot    bool
+	history     string
+	until       string
 }
 
 // GlobalParams contains the values of agent-global Cobra flags.
@@ -73,6 +79,9 @@ func MakeCommand(globalParamsGetter func() GlobalParams) *cobra.Command {
 	}
 
 	workloadListCommand.Flags().BoolVarP(&cliParams.verboseList, "verbose", "v", false, "print out a full dump of the workload store")
+	workloadListCommand.Flags().BoolVar(&cliParams.snapshot, "snapshot", false, "print out a JSON snapshot of the containers and pods of the workload store, as used by 'autodiscovery simulate'")
+	workloadListCommand.Flags().StringVar(&cliParams.history, "history", "", "print out the last events received for an entity, given as <kind>/<id> or <id>, even if it was since removed")
+	workloadListCommand.Flags().StringVar(&cliParams.until, "until", "", "with --history, only print out the events received until the given RFC3339 time")
 
 	return workloadListCommand
 }
@@ -86,7 +95,7 @@ func workloadList(_ log.Component, config config.Component, cliParams *cliParams
 		return err
 	}
 
-	url, err := workloadURL(cliParams.verboseList)
+	url, err := workloadURL(cliParams)
 	if err != nil {
 		return err
 	}
@@ -100,6 +109,28 @@ func workloadList(_ log.Component, config config.Component, cliParams *cliParams
 		}
 	}
 
+	if cliParams.snapshot {
+		var out bytes.Buffer
+		if err := json.Indent(&out, r, "", "  "); err != nil {
+			return err
+		}
+		fmt.Fprintln(color.Output, out.String())
+		return nil
+	}
+
+	if cliParams.history != "" {
+		history := workloadmeta.WorkloadHistoryResponse{}
+		if err := json.Unmarshal(r, &history); err != nil {
+			return err
+		}
+		if len(history.Entities) == 0 {
+			fmt.Fprintf(color.Output, "No event received for entity %s\n", cliParams.history)
+			return nil
+		}
+		history.Write(color.Output)
+		return nil
+	}
+
 	workload := workloadmeta.WorkloadDumpResponse{}
 	err = json.Unmarshal(r, &workload)
 	if err != nil {
@@ -111,7 +142,7 @@ func workloadList(_ log.Component, config config.Component, cliParams *cliParams
 	return nil
 }
 
-func workloadURL(verbose bool) (string, error) {
+func workloadURL(cliParams *cliParams) (string, error) {
 	ipcAddress, err := pkgconfig.GetIPCAddress()
 	if err != nil {
 		return "", err
@@ -124,7 +155,23 @@ func workloadURL(verbose bool) (string, error) {
 		prefix = fmt.Sprintf("https://%v:%v/agent/workload-list", ipcAddress, pkgconfig.Datadog().GetInt("cmd_port"))
 	}
 
-	if verbose {
+	if cliParams.history != "" {
+		params := url.Values{}
+		params.Set("history", cliParams.history)
+		if cliParams.until != "" {
+			if _, err := time.Parse(time.RFC3339, cliParams.until); err != nil {
+				return "", fmt.Errorf("invalid --until time: %w", err)
+			}
+			params.Set("until", cliParams.until)
+		}
+		return prefix + "?" + params.Encode(), nil
+	}
+
+	if cliParams.snapshot {
+		return prefix + "?snapshot=true", nil
+	}
+
+	if cliParams.verboseList {
 		return prefix + "?verbose=true", nil
 	}
 
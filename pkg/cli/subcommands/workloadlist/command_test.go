@@ -32,3 +32,20 @@ func TestCommand(t *testing.T) {
 			require.Equal(t, false, secretParams.Enabled)
 		})
 }
+
+func TestCommandHistory(t *testing.T) {
+	commands := []*cobra.Command{
+		MakeCommand(func() GlobalParams {
+			return GlobalParams{}
+		}),
+	}
+
+	fxutil.TestOneShotSubcommand(t,
+		commands,
+		[]string{"workload-list", "--history", "kubernetes_pod/pod-uid", "--until", "2024-03-01T03:12:00Z"},
+		workloadList,
+		func(cliParams *cliParams, _ core.BundleParams, _ secrets.Params) {
+			require.Equal(t, "kubernetes_pod/pod-uid", cliParams.history)
+			require.Equal(t, "2024-03-01T03:12:00Z", cliParams.until)
+		})
+}
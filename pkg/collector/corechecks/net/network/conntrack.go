@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// conntrackStats holds the nf_conntrack table usage and the per-CPU statistics
+// from /proc/net/stat/nf_conntrack
+type conntrackStats struct {
+	Count  int64
+	Max    int64
+	PerCPU []map[string]int64
+}
+
+func readConntrackStats(procfsPath string) (*conntrackStats, error) {
+	stats := &conntrackStats{}
+
+	var err error
+	if stats.Count, err = readIntFile(filepath.Join(procfsPath, "sys/net/netfilter/nf_conntrack_count")); err != nil {
+		return nil, err
+	}
+	if stats.Max, err = readIntFile(filepath.Join(procfsPath, "sys/net/netfilter/nf_conntrack_max")); err != nil {
+		return nil, err
+	}
+
+	// The per-CPU statistics are not exposed in all network namespaces, they are optional
+	if stats.PerCPU, err = readConntrackPerCPUStats(filepath.Join(procfsPath, "net/stat/nf_conntrack")); err != nil {
+		stats.PerCPU = nil
+	}
+
+	return stats, nil
+}
+
+// readConntrackPerCPUStats parses the conntrack statistics file: a header with the counter names
+// followed by one line of hexadecimal values per CPU. Counters depend on the kernel version.
+func readConntrackPerCPUStats(path string) ([]map[string]int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+	names := strings.Fields(scanner.Text())
+
+	var perCPU []map[string]int64
+	for scanner.Scan() {
+		values := strings.Fields(scanner.Text())
+		if len(values) != len(names) {
+			return nil, fmt.Errorf("%s is not formatted correctly, expected %d columns, got %d", path, len(names), len(values))
+		}
+
+		counters := make(map[string]int64, len(names))
+		for i, name := range names {
+			value, err := strconv.ParseInt(values[i], 16, 64)
+			if err != nil {
+				return nil, err
+			}
+			counters[name] = value
+		}
+		perCPU = append(perCPU, counters)
+	}
+
+	return perCPU, scanner.Err()
+}
+
+func readIntFile(path string) (int64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+}
@@ -18,12 +18,14 @@ import (
 	"strings"
 
 	"github.com/shirou/gopsutil/v3/net"
+	"github.com/vishvananda/netlink"
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/util/optional"
 )
@@ -71,6 +73,19 @@ var (
 	udpStateMetricsSuffixMapping = map[string]string{
 		"NONE": "connections",
 	}
+
+	// per-CPU counters from /proc/net/stat/nf_conntrack reported as system.net.conntrack.<name>
+	conntrackPerCPUCounters = []string{
+		"found",
+		"invalid",
+		"ignore",
+		"insert",
+		"insert_failed",
+		"drop",
+		"early_drop",
+		"icmp_error",
+		"search_restart",
+	}
 )
 
 // NetworkCheck represent a network check
@@ -85,6 +100,9 @@ type networkInstanceConfig struct {
 	ExcludedInterfaces       []string `yaml:"excluded_interfaces"`
 	ExcludedInterfaceRe      string   `yaml:"excluded_interface_re"`
 	ExcludedInterfacePattern *regexp.Regexp
+	CollectQdiscStats        bool `yaml:"collect_qdisc_stats"`
+	CollectConntrackMetrics  bool `yaml:"collect_conntrack_metrics"`
+	CollectSockstatMetrics   bool `yaml:"collect_sockstat_metrics"`
 }
 
 type networkInitConfig struct{}
@@ -99,6 +117,9 @@ type networkStats interface {
 	ProtoCounters(protocols []string) ([]net.ProtoCountersStat, error)
 	Connections(kind string) ([]net.ConnectionStat, error)
 	NetstatTCPExtCounters() (map[string]int64, error)
+	QdiscStats() ([]qdiscStats, error)
+	ConntrackStats() (*conntrackStats, error)
+	SockstatStats() (sockstatStats, error)
 }
 
 type defaultNetworkStats struct{}
@@ -119,6 +140,25 @@ func (n defaultNetworkStats) NetstatTCPExtCounters() (map[string]int64, error) {
 	return netstatTCPExtCounters()
 }
 
+func (n defaultNetworkStats) QdiscStats() ([]qdiscStats, error) {
+	return listQdiscStats()
+}
+
+func (n defaultNetworkStats) ConntrackStats() (*conntrackStats, error) {
+	return readConntrackStats(procfsPath())
+}
+
+func (n defaultNetworkStats) SockstatStats() (sockstatStats, error) {
+	return readSockstatStats(procfsPath())
+}
+
+func procfsPath() string {
+	if config.Datadog().IsSet("procfs_path") {
+		return config.Datadog().GetString("procfs_path")
+	}
+	return "/proc"
+}
+
 // Run executes the check
 func (c *NetworkCheck) Run() error {
 	sender, err := c.GetSender()
@@ -182,6 +222,37 @@ func (c *NetworkCheck) Run() error {
 		submitConnectionsMetrics(sender, "tcp6", tcpStateMetricsSuffixMapping, connectionsStats)
 	}
 
+	// The following metrics are optional, failing to collect them does not fail the check
+	if c.config.instance.CollectQdiscStats {
+		qdiscs, err := c.net.QdiscStats()
+		if err != nil {
+			log.Warnf("Unable to collect qdisc statistics: %s", err)
+		}
+		for _, qdisc := range qdiscs {
+			if !c.isDeviceExcluded(qdisc.Interface) {
+				submitQdiscMetrics(sender, qdisc)
+			}
+		}
+	}
+
+	if c.config.instance.CollectConntrackMetrics {
+		conntrack, err := c.net.ConntrackStats()
+		if err != nil {
+			log.Warnf("Unable to collect conntrack statistics (is the nf_conntrack module loaded?): %s", err)
+		} else {
+			submitConntrackMetrics(sender, conntrack)
+		}
+	}
+
+	if c.config.instance.CollectSockstatMetrics {
+		sockstat, err := c.net.SockstatStats()
+		if err != nil {
+			log.Warnf("Unable to collect socket statistics: %s", err)
+		} else {
+			submitSockstatMetrics(sender, sockstat)
+		}
+	}
+
 	sender.Commit()
 	return nil
 }
@@ -236,6 +307,48 @@ func submitConnectionsMetrics(sender sender.Sender, protocolName string, stateMe
 	}
 }
 
+func submitQdiscMetrics(sender sender.Sender, qdisc qdiscStats) {
+	tags := []string{
+		fmt.Sprintf("device:%s", qdisc.Interface),
+		fmt.Sprintf("device_name:%s", qdisc.Interface),
+		fmt.Sprintf("qdisc:%s", qdisc.Kind),
+		fmt.Sprintf("qdisc_handle:%s", netlink.HandleStr(qdisc.Handle)),
+		fmt.Sprintf("qdisc_parent:%s", netlink.HandleStr(qdisc.Parent)),
+	}
+	sender.MonotonicCount("system.net.qdisc.bytes", float64(qdisc.Bytes), "", tags)
+	sender.MonotonicCount("system.net.qdisc.packets", float64(qdisc.Packets), "", tags)
+	sender.MonotonicCount("system.net.qdisc.drops", float64(qdisc.Drops), "", tags)
+	sender.MonotonicCount("system.net.qdisc.overlimits", float64(qdisc.Overlimits), "", tags)
+	sender.MonotonicCount("system.net.qdisc.requeues", float64(qdisc.Requeues), "", tags)
+	sender.Gauge("system.net.qdisc.backlog", float64(qdisc.Backlog), "", tags)
+	sender.Gauge("system.net.qdisc.qlen", float64(qdisc.Qlen), "", tags)
+}
+
+func submitConntrackMetrics(sender sender.Sender, conntrack *conntrackStats) {
+	sender.Gauge("system.net.conntrack.count", float64(conntrack.Count), "", nil)
+	sender.Gauge("system.net.conntrack.max", float64(conntrack.Max), "", nil)
+	if conntrack.Max > 0 {
+		sender.Gauge("system.net.conntrack.usage", float64(conntrack.Count)/float64(conntrack.Max), "", nil)
+	}
+
+	for cpu, counters := range conntrack.PerCPU {
+		tags := []string{fmt.Sprintf("cpu:%d", cpu)}
+		for _, name := range conntrackPerCPUCounters {
+			if value, ok := counters[name]; ok {
+				sender.MonotonicCount(fmt.Sprintf("system.net.conntrack.%s", name), float64(value), "", tags)
+			}
+		}
+	}
+}
+
+func submitSockstatMetrics(sender sender.Sender, sockstat sockstatStats) {
+	for protocol, counters := range sockstat {
+		for name, value := range counters {
+			sender.Gauge(fmt.Sprintf("system.net.sockstat.%s.%s", protocol, name), float64(value), "", nil)
+		}
+	}
+}
+
 func netstatTCPExtCounters() (map[string]int64, error) {
 	f, err := os.Open("/proc/net/netstat")
 	if err != nil {
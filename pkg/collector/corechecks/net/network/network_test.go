@@ -8,11 +8,14 @@
 package network
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/shirou/gopsutil/v3/net"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/vishvananda/netlink/nl"
 
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
@@ -34,6 +37,12 @@ type fakeNetworkStats struct {
 	connectionStatsTCP6Error    error
 	netstatTCPExtCountersValues map[string]int64
 	netstatTCPExtCountersError  error
+	qdiscStatsValues            []qdiscStats
+	qdiscStatsError             error
+	conntrackStatsValues        *conntrackStats
+	conntrackStatsError         error
+	sockstatStatsValues         sockstatStats
+	sockstatStatsError          error
 }
 
 // IOCounters returns the inner values of counterStats and counterStatsError
@@ -69,6 +78,18 @@ func (n *fakeNetworkStats) NetstatTCPExtCounters() (map[string]int64, error) {
 	return n.netstatTCPExtCountersValues, n.netstatTCPExtCountersError
 }
 
+func (n *fakeNetworkStats) QdiscStats() ([]qdiscStats, error) {
+	return n.qdiscStatsValues, n.qdiscStatsError
+}
+
+func (n *fakeNetworkStats) ConntrackStats() (*conntrackStats, error) {
+	return n.conntrackStatsValues, n.conntrackStatsError
+}
+
+func (n *fakeNetworkStats) SockstatStats() (sockstatStats, error) {
+	return n.sockstatStatsValues, n.sockstatStatsError
+}
+
 func TestDefaultConfiguration(t *testing.T) {
 	check := NetworkCheck{}
 	check.Configure(aggregator.NewNoOpSenderManager(), integration.FakeConfigHash, []byte(``), []byte(``), "test")
@@ -76,6 +97,9 @@ func TestDefaultConfiguration(t *testing.T) {
 	assert.Equal(t, false, check.config.instance.CollectConnectionState)
 	assert.Equal(t, []string(nil), check.config.instance.ExcludedInterfaces)
 	assert.Equal(t, "", check.config.instance.ExcludedInterfaceRe)
+	assert.Equal(t, false, check.config.instance.CollectQdiscStats)
+	assert.Equal(t, false, check.config.instance.CollectConntrackMetrics)
+	assert.Equal(t, false, check.config.instance.CollectSockstatMetrics)
 }
 
 func TestConfiguration(t *testing.T) {
@@ -516,3 +540,164 @@ excluded_interface_re: "eth[0-9]"
 	mockSender.AssertCalled(t, "Rate", "system.net.packets_out.drop", float64(32), "", lo0Tags)
 	mockSender.AssertCalled(t, "Rate", "system.net.packets_out.error", float64(33), "", lo0Tags)
 }
+
+func TestQdiscConntrackSockstatMetrics(t *testing.T) {
+	net := &fakeNetworkStats{
+		qdiscStatsValues: []qdiscStats{
+			{
+				Interface:  "eth0",
+				Kind:       "fq_codel",
+				Handle:     0,
+				Parent:     0x10001,
+				Bytes:      1000,
+				Packets:    10,
+				Qlen:       1,
+				Backlog:    150,
+				Drops:      3,
+				Requeues:   4,
+				Overlimits: 5,
+			},
+			{
+				Interface: "lo0",
+				Kind:      "noqueue",
+			},
+		},
+		conntrackStatsValues: &conntrackStats{
+			Count: 250,
+			Max:   1000,
+			PerCPU: []map[string]int64{
+				{"found": 1, "drop": 2, "early_drop": 3, "entries": 250},
+				{"found": 4, "drop": 5, "insert_failed": 6, "entries": 250},
+			},
+		},
+		sockstatStatsValues: sockstatStats{
+			"sockets": {"used": 100},
+			"tcp":     {"inuse": 12, "mem": 3, "mem_pressure": 4096},
+		},
+	}
+
+	networkCheck := NetworkCheck{
+		net: net,
+	}
+
+	rawInstanceConfig := []byte(`
+collect_qdisc_stats: true
+collect_conntrack_metrics: true
+collect_sockstat_metrics: true
+excluded_interfaces:
+    - lo0
+`)
+
+	mockSender := mocksender.NewMockSender(networkCheck.ID())
+	err := networkCheck.Configure(mockSender.GetSenderManager(), integration.FakeConfigHash, rawInstanceConfig, []byte(``), "test")
+	assert.Nil(t, err)
+	mockSender.SetupAcceptAll()
+
+	err = networkCheck.Run()
+	assert.Nil(t, err)
+
+	qdiscTags := []string{"device:eth0", "device_name:eth0", "qdisc:fq_codel", "qdisc_handle:none", "qdisc_parent:1:1"}
+	mockSender.AssertCalled(t, "MonotonicCount", "system.net.qdisc.bytes", float64(1000), "", qdiscTags)
+	mockSender.AssertCalled(t, "MonotonicCount", "system.net.qdisc.packets", float64(10), "", qdiscTags)
+	mockSender.AssertCalled(t, "MonotonicCount", "system.net.qdisc.drops", float64(3), "", qdiscTags)
+	mockSender.AssertCalled(t, "MonotonicCount", "system.net.qdisc.requeues", float64(4), "", qdiscTags)
+	mockSender.AssertCalled(t, "MonotonicCount", "system.net.qdisc.overlimits", float64(5), "", qdiscTags)
+	mockSender.AssertCalled(t, "Gauge", "system.net.qdisc.backlog", float64(150), "", qdiscTags)
+	mockSender.AssertCalled(t, "Gauge", "system.net.qdisc.qlen", float64(1), "", qdiscTags)
+	mockSender.AssertMetricNotTaggedWith(t, "Gauge", "system.net.qdisc.backlog", []string{"device:lo0"})
+
+	mockSender.AssertCalled(t, "Gauge", "system.net.conntrack.count", float64(250), "", []string(nil))
+	mockSender.AssertCalled(t, "Gauge", "system.net.conntrack.max", float64(1000), "", []string(nil))
+	mockSender.AssertCalled(t, "Gauge", "system.net.conntrack.usage", float64(0.25), "", []string(nil))
+	mockSender.AssertCalled(t, "MonotonicCount", "system.net.conntrack.found", float64(1), "", []string{"cpu:0"})
+	mockSender.AssertCalled(t, "MonotonicCount", "system.net.conntrack.drop", float64(2), "", []string{"cpu:0"})
+	mockSender.AssertCalled(t, "MonotonicCount", "system.net.conntrack.early_drop", float64(3), "", []string{"cpu:0"})
+	mockSender.AssertCalled(t, "MonotonicCount", "system.net.conntrack.found", float64(4), "", []string{"cpu:1"})
+	mockSender.AssertCalled(t, "MonotonicCount", "system.net.conntrack.drop", float64(5), "", []string{"cpu:1"})
+	mockSender.AssertCalled(t, "MonotonicCount", "system.net.conntrack.insert_failed", float64(6), "", []string{"cpu:1"})
+	mockSender.AssertNotCalled(t, "MonotonicCount", "system.net.conntrack.entries", mock.Anything, mock.Anything, mock.Anything)
+
+	mockSender.AssertCalled(t, "Gauge", "system.net.sockstat.sockets.used", float64(100), "", []string(nil))
+	mockSender.AssertCalled(t, "Gauge", "system.net.sockstat.tcp.inuse", float64(12), "", []string(nil))
+	mockSender.AssertCalled(t, "Gauge", "system.net.sockstat.tcp.mem", float64(3), "", []string(nil))
+	mockSender.AssertCalled(t, "Gauge", "system.net.sockstat.tcp.mem_pressure", float64(4096), "", []string(nil))
+}
+
+func TestReadConntrackAndSockstatFiles(t *testing.T) {
+	procfs := t.TempDir()
+	writeFile := func(path, content string) {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(procfs, path)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(procfs, path), []byte(content), 0644))
+	}
+	writeFile("sys/net/netfilter/nf_conntrack_count", "42\n")
+	writeFile("sys/net/netfilter/nf_conntrack_max", "262144\n")
+	writeFile("net/stat/nf_conntrack", `entries  clashres found new invalid ignore delete chainlength insert insert_failed drop early_drop icmp_error  expect_new expect_create expect_delete search_restart
+0000002a  00000000 00000000 00000000 00000010 00000000 00000000 00000000 00000000 00000000 0000000a 00000000 00000000  00000000 00000000 00000000 00000001
+0000002a  00000000 00000002 00000000 00000000 00000000 00000000 00000000 00000000 00000003 00000000 00000000 00000000  00000000 00000000 00000000 00000000
+`)
+	writeFile("net/sockstat", `sockets: used 221
+TCP: inuse 12 orphan 1 tw 4 alloc 15 mem 3
+UDP: inuse 5 mem 4
+FRAG: inuse 0 memory 0
+`)
+	writeFile("sys/net/ipv4/tcp_mem", "22200\t29600\t44400\n")
+
+	conntrack, err := readConntrackStats(procfs)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), conntrack.Count)
+	assert.Equal(t, int64(262144), conntrack.Max)
+	assert.Len(t, conntrack.PerCPU, 2)
+	assert.Equal(t, int64(16), conntrack.PerCPU[0]["invalid"])
+	assert.Equal(t, int64(10), conntrack.PerCPU[0]["drop"])
+	assert.Equal(t, int64(1), conntrack.PerCPU[0]["search_restart"])
+	assert.Equal(t, int64(2), conntrack.PerCPU[1]["found"])
+	assert.Equal(t, int64(3), conntrack.PerCPU[1]["insert_failed"])
+
+	sockstat, err := readSockstatStats(procfs)
+	assert.NoError(t, err)
+	assert.Equal(t, sockstatStats{
+		"sockets": {"used": 221},
+		"tcp":     {"inuse": 12, "orphan": 1, "tw": 4, "alloc": 15, "mem": 3, "mem_min": 22200, "mem_pressure": 29600, "mem_max": 44400},
+		"udp":     {"inuse": 5, "mem": 4},
+		"frag":    {"inuse": 0, "memory": 0},
+	}, sockstat)
+}
+
+func TestParseQdiscMessage(t *testing.T) {
+	msg := &nl.TcMsg{
+		Family:  nl.FAMILY_ALL,
+		Ifindex: 2,
+		Handle:  0x80000000,
+		Parent:  0xffffffff,
+	}
+	data := msg.Serialize()
+	data = append(data, nl.NewRtAttr(nl.TCA_KIND, nl.ZeroTerminated("fq_codel")).Serialize()...)
+
+	stats := nl.NewRtAttr(nl.TCA_STATS2, nil)
+	basic := make([]byte, 16)
+	nl.NativeEndian().PutUint64(basic[0:], 123456)
+	nl.NativeEndian().PutUint32(basic[8:], 789)
+	stats.AddRtAttr(nl.TCA_STATS_BASIC, basic[:12])
+	queue := make([]byte, 20)
+	for i, value := range []uint32{1, 2, 3, 4, 5} {
+		nl.NativeEndian().PutUint32(queue[i*4:], value)
+	}
+	stats.AddRtAttr(nl.TCA_STATS_QUEUE, queue)
+	data = append(data, stats.Serialize()...)
+
+	qdisc, err := parseQdiscMessage(data)
+	assert.NoError(t, err)
+	assert.Equal(t, &qdiscStats{
+		LinkIndex:  2,
+		Kind:       "fq_codel",
+		Handle:     0x80000000,
+		Parent:     0xffffffff,
+		Bytes:      123456,
+		Packets:    789,
+		Qlen:       1,
+		Backlog:    2,
+		Drops:      3,
+		Requeues:   4,
+		Overlimits: 5,
+	}, qdisc)
+}
@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// qdiscStats holds the statistics of a single queueing discipline, as reported by `tc -s qdisc show`
+type qdiscStats struct {
+	LinkIndex  int
+	Interface  string
+	Kind       string
+	Handle     uint32
+	Parent     uint32
+	Bytes      uint64
+	Packets    uint32
+	Qlen       uint32
+	Backlog    uint32
+	Drops      uint32
+	Requeues   uint32
+	Overlimits uint32
+}
+
+// listQdiscStats dumps all the qdiscs of the host network namespace through netlink.
+// netlink.QdiscList does not expose statistics, so the dump is parsed here.
+func listQdiscStats() ([]qdiscStats, error) {
+	req := nl.NewNetlinkRequest(unix.RTM_GETQDISC, unix.NLM_F_DUMP)
+	req.AddData(&nl.TcMsg{Family: nl.FAMILY_ALL})
+
+	msgs, err := req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWQDISC)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list qdiscs: %w", err)
+	}
+
+	interfaceNames := make(map[int]string)
+	stats := make([]qdiscStats, 0, len(msgs))
+	for _, m := range msgs {
+		qdisc, err := parseQdiscMessage(m)
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := interfaceNames[qdisc.LinkIndex]
+		if !ok {
+			if iface, err := net.InterfaceByIndex(qdisc.LinkIndex); err == nil {
+				name = iface.Name
+			}
+			interfaceNames[qdisc.LinkIndex] = name
+		}
+		if name == "" {
+			continue
+		}
+		qdisc.Interface = name
+
+		stats = append(stats, *qdisc)
+	}
+
+	return stats, nil
+}
+
+func parseQdiscMessage(m []byte) (*qdiscStats, error) {
+	msg := nl.DeserializeTcMsg(m)
+	attrs, err := nl.ParseRouteAttr(m[msg.Len():])
+	if err != nil {
+		return nil, err
+	}
+
+	qdisc := &qdiscStats{
+		LinkIndex: int(msg.Ifindex),
+		Handle:    msg.Handle,
+		Parent:    msg.Parent,
+	}
+
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case nl.TCA_KIND:
+			qdisc.Kind = string(bytes.TrimRight(attr.Value, "\x00"))
+		case nl.TCA_STATS2:
+			statsAttrs, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return nil, err
+			}
+			for _, statsAttr := range statsAttrs {
+				switch statsAttr.Attr.Type {
+				case nl.TCA_STATS_BASIC:
+					basic := netlink.GnetStatsBasic{}
+					if err := binary.Read(bytes.NewReader(statsAttr.Value), nl.NativeEndian(), &basic); err != nil {
+						return nil, fmt.Errorf("unable to parse qdisc basic stats: %w", err)
+					}
+					qdisc.Bytes = basic.Bytes
+					qdisc.Packets = basic.Packets
+				case nl.TCA_STATS_QUEUE:
+					queue := netlink.GnetStatsQueue{}
+					if err := binary.Read(bytes.NewReader(statsAttr.Value), nl.NativeEndian(), &queue); err != nil {
+						return nil, fmt.Errorf("unable to parse qdisc queue stats: %w", err)
+					}
+					qdisc.Qlen = queue.Qlen
+					qdisc.Backlog = queue.Backlog
+					qdisc.Drops = queue.Drops
+					qdisc.Requeues = queue.Requeues
+					qdisc.Overlimits = queue.Overlimits
+				}
+			}
+		}
+	}
+
+	return qdisc, nil
+}
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sockstatStats holds the socket usage from /proc/net/sockstat, indexed by protocol then counter
+// (e.g. "tcp" -> "inuse"). Memory counters are in pages. The tcp_mem and udp_mem limits are
+// stored as "mem_min", "mem_pressure" and "mem_max" under their protocol.
+type sockstatStats map[string]map[string]int64
+
+func readSockstatStats(procfsPath string) (sockstatStats, error) {
+	stats, err := parseSockstatFile(filepath.Join(procfsPath, "net/sockstat"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, protocol := range []string{"tcp", "udp"} {
+		limits, err := os.ReadFile(filepath.Join(procfsPath, "sys/net/ipv4", protocol+"_mem"))
+		if err != nil {
+			continue
+		}
+		fields := strings.Fields(string(limits))
+		if len(fields) != 3 {
+			continue
+		}
+		if stats[protocol] == nil {
+			stats[protocol] = map[string]int64{}
+		}
+		for i, name := range []string{"mem_min", "mem_pressure", "mem_max"} {
+			if value, err := strconv.ParseInt(fields[i], 10, 64); err == nil {
+				stats[protocol][name] = value
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+// parseSockstatFile parses lines such as `TCP: inuse 5 orphan 0 tw 2 alloc 7 mem 1`
+func parseSockstatFile(path string) (sockstatStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stats := sockstatStats{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		protocol, counters, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			return nil, fmt.Errorf("%s is not formatted correctly, expected ':'", path)
+		}
+
+		fields := strings.Fields(counters)
+		if len(fields)%2 != 0 {
+			return nil, fmt.Errorf("%s is not formatted correctly, expected key/value pairs", path)
+		}
+
+		values := make(map[string]int64, len(fields)/2)
+		for i := 0; i < len(fields); i += 2 {
+			value, err := strconv.ParseInt(fields[i+1], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			values[fields[i]] = value
+		}
+		stats[strings.ToLower(protocol)] = values
+	}
+
+	return stats, scanner.Err()
+}
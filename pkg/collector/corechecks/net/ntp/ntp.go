@@ -61,6 +61,11 @@ type ntpInstanceConfig struct {
 	Timeout                int      `yaml:"timeout"`
 	Version                int      `yaml:"version"`
 	UseLocalDefinedServers bool     `yaml:"use_local_defined_servers"`
+	DisableServerQueries   bool     `yaml:"disable_server_queries"`
+	CollectPerServer       bool     `yaml:"collect_per_server_metrics"`
+	OutlierThreshold       float64  `yaml:"outlier_threshold"`
+	LocalDaemon            string   `yaml:"local_daemon"`
+	LocalDaemonAddress     string   `yaml:"local_daemon_address"`
 }
 
 type ntpInitConfig struct{}
@@ -84,6 +89,7 @@ func (c *ntpConfig) parse(data []byte, initData []byte, getLocalServers func() (
 	defaultTimeout := 5
 	defaultPort := 123
 	defaultOffsetThreshold := 60
+	defaultOutlierThreshold := 1.0
 
 	defaultHosts := getCloudProviderNTPHosts(context.TODO())
 
@@ -142,6 +148,27 @@ func (c *ntpConfig) parse(data []byte, initData []byte, getLocalServers func() (
 	if c.instance.OffsetThreshold == 0 {
 		c.instance.OffsetThreshold = defaultOffsetThreshold
 	}
+	if c.instance.OutlierThreshold == 0 {
+		c.instance.OutlierThreshold = defaultOutlierThreshold
+	}
+
+	switch c.instance.LocalDaemon {
+	case "":
+	case localDaemonChrony:
+		if c.instance.LocalDaemonAddress == "" {
+			c.instance.LocalDaemonAddress = defaultChronyAddress
+		}
+	case localDaemonNtpd:
+		if c.instance.LocalDaemonAddress == "" {
+			c.instance.LocalDaemonAddress = defaultNtpdAddress
+		}
+	default:
+		return fmt.Errorf("invalid local_daemon %q, supported values are %q and %q", c.instance.LocalDaemon, localDaemonChrony, localDaemonNtpd)
+	}
+
+	if c.instance.DisableServerQueries && c.instance.LocalDaemon == "" {
+		return fmt.Errorf("local_daemon must be set when disable_server_queries is enabled")
+	}
 	c.initConf = initConf
 
 	return nil
@@ -174,20 +201,35 @@ func (c *NTPCheck) Run() error {
 		return err
 	}
 
+	if c.cfg.instance.LocalDaemon != "" {
+		c.reportLocalDaemonState(sender)
+	}
+
+	if !c.cfg.instance.DisableServerQueries {
+		err = c.reportServersOffset(sender)
+	}
+
+	c.lastCollection = time.Now()
+	sender.Commit()
+
+	return err
+}
+
+func (c *NTPCheck) reportServersOffset(sender sender.Sender) error {
 	var serviceCheckStatus servicecheck.ServiceCheckStatus
 	serviceCheckMessage := ""
 	offsetThreshold := c.cfg.instance.OffsetThreshold
 
-	clockOffset, err := c.queryOffset()
+	responses, err := c.queryServers()
 	if err != nil {
 		log.Error(err)
 
 		sender.ServiceCheck("ntp.in_sync", servicecheck.ServiceCheckUnknown, "", nil, serviceCheckMessage)
-		c.lastCollection = time.Now()
-		sender.Commit()
 
 		return err
 	}
+
+	clockOffset := medianOffset(responses)
 	if int(math.Abs(clockOffset)) > offsetThreshold {
 		serviceCheckStatus = servicecheck.ServiceCheckCritical
 		serviceCheckMessage = fmt.Sprintf("Offset %v is higher than offset threshold (%v secs)", clockOffset, offsetThreshold)
@@ -200,15 +242,74 @@ func (c *NTPCheck) Run() error {
 	tlmNtpOffset.Set(clockOffset)
 	sender.ServiceCheck("ntp.in_sync", serviceCheckStatus, "", nil, serviceCheckMessage)
 
-	c.lastCollection = time.Now()
+	outliers := findOutliers(responses, clockOffset, c.cfg.instance.OutlierThreshold)
+	if len(outliers) > 0 {
+		log.Infof("NTP servers disagreeing with the median offset %v by more than %v secs: [ %s ]", clockOffset, c.cfg.instance.OutlierThreshold, strings.Join(outliers, ", "))
+	}
 
-	sender.Commit()
+	if c.cfg.instance.CollectPerServer {
+		isOutlier := make(map[string]bool, len(outliers))
+		for _, host := range outliers {
+			isOutlier[host] = true
+		}
+
+		for _, response := range responses {
+			tags := []string{"ntp_server:" + response.host}
+			outlier := 0.0
+			if isOutlier[response.host] {
+				outlier = 1.0
+			}
+			sender.Gauge("ntp.server.offset", response.offset, "", tags)
+			sender.Gauge("ntp.server.stratum", float64(response.stratum), "", tags)
+			sender.Gauge("ntp.server.outlier", outlier, "", tags)
+		}
+		sender.Gauge("ntp.servers.responding", float64(len(responses)), "", nil)
+		sender.Gauge("ntp.servers.outliers", float64(len(outliers)), "", nil)
+	}
 
 	return nil
 }
 
-func (c *NTPCheck) queryOffset() (float64, error) {
-	offsets := []float64{}
+func (c *NTPCheck) reportLocalDaemonState(sender sender.Sender) {
+	daemon := c.cfg.instance.LocalDaemon
+	tags := []string{"ntp_daemon:" + daemon}
+
+	state, err := queryLocalDaemon(daemon, c.cfg.instance.LocalDaemonAddress, time.Duration(c.cfg.instance.Timeout)*time.Second)
+	if err != nil {
+		log.Warnf("Unable to get the local %s state: %s", daemon, err)
+		sender.ServiceCheck("ntp.local.in_sync", servicecheck.ServiceCheckUnknown, "", tags, err.Error())
+		return
+	}
+
+	synchronized := 0.0
+	if state.Synchronized {
+		synchronized = 1.0
+	}
+	sender.Gauge("ntp.local.offset", state.Offset, "", tags)
+	sender.Gauge("ntp.local.stratum", float64(state.Stratum), "", tags)
+	sender.Gauge("ntp.local.root_delay", state.RootDelay, "", tags)
+	sender.Gauge("ntp.local.root_dispersion", state.RootDispersion, "", tags)
+	sender.Gauge("ntp.local.synchronized", synchronized, "", tags)
+
+	offsetThreshold := c.cfg.instance.OffsetThreshold
+	switch {
+	case !state.Synchronized:
+		sender.ServiceCheck("ntp.local.in_sync", servicecheck.ServiceCheckCritical, "", tags, fmt.Sprintf("The local %s daemon is not synchronized", daemon))
+	case int(math.Abs(state.Offset)) > offsetThreshold:
+		sender.ServiceCheck("ntp.local.in_sync", servicecheck.ServiceCheckCritical, "", tags, fmt.Sprintf("Offset %v is higher than offset threshold (%v secs)", state.Offset, offsetThreshold))
+	default:
+		sender.ServiceCheck("ntp.local.in_sync", servicecheck.ServiceCheckOK, "", tags, "")
+	}
+}
+
+type serverResponse struct {
+	host    string
+	offset  float64
+	stratum uint8
+}
+
+func (c *NTPCheck) queryServers() ([]serverResponse, error) {
+	responses := []serverResponse{}
 
 	for _, host := range c.cfg.instance.Hosts {
 		response, err := ntpQuery(host, ntp.QueryOptions{Version: c.cfg.instance.Version, Port: c.cfg.instance.Port, Timeout: time.Duration(c.cfg.instance.Timeout) * time.Second})
@@ -221,11 +322,24 @@ func (c *NTPCheck) queryOffset() (float64, error) {
 			log.Infof("The ntp response is not valid for host %s: %s", host, err)
 			continue
 		}
-		offsets = append(offsets, response.ClockOffset.Seconds())
+		responses = append(responses, serverResponse{
+			host:    host,
+			offset:  response.ClockOffset.Seconds(),
+			stratum: response.Stratum,
+		})
 	}
 
-	if len(offsets) == 0 {
-		return .0, fmt.Errorf("failed to get clock offset from any ntp host: [ %s ]", strings.Join(c.cfg.instance.Hosts, ", "))
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("failed to get clock offset from any ntp host: [ %s ]", strings.Join(c.cfg.instance.Hosts, ", "))
+	}
+
+	return responses, nil
+}
+
+func medianOffset(responses []serverResponse) float64 {
+	offsets := make([]float64, 0, len(responses))
+	for _, response := range responses {
+		offsets = append(offsets, response.offset)
 	}
 
 	var median float64
@@ -238,7 +352,18 @@ func (c *NTPCheck) queryOffset() (float64, error) {
 		median = offsets[length/2]
 	}
 
-	return median, nil
+	return median
+}
+
+// findOutliers returns the servers whose offset differs from the median by more than threshold seconds
+func findOutliers(responses []serverResponse, median float64, threshold float64) []string {
+	var outliers []string
+	for _, response := range responses {
+		if math.Abs(response.offset-median) > threshold {
+			outliers = append(outliers, response.host)
+		}
+	}
+	return outliers
 }
 
 // Factory creates a new check factory
@@ -0,0 +1,355 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package ntp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	localDaemonChrony = "chrony"
+	localDaemonNtpd   = "ntpd"
+
+	defaultChronyAddress = "127.0.0.1:323"
+	defaultNtpdAddress   = "127.0.0.1:123"
+)
+
+// localDaemonState is the synchronization state reported by the NTP daemon running on the host
+type localDaemonState struct {
+	// Offset is the estimated offset of the system clock against the reference, in seconds.
+	// As for ntp.offset, a positive value means the system clock is behind.
+	Offset         float64
+	Stratum        int
+	RootDelay      float64 // seconds
+	RootDispersion float64 // seconds
+	Synchronized   bool
+}
+
+// for testing purpose
+var (
+	queryChronyTracking = queryChronyTrackingUDP
+	queryNtpdSystemVars = queryNtpdSystemVarsUDP
+)
+
+func queryLocalDaemon(daemon string, address string, timeout time.Duration) (*localDaemonState, error) {
+	switch daemon {
+	case localDaemonChrony:
+		return queryChronyTracking(address, timeout)
+	case localDaemonNtpd:
+		return queryNtpdSystemVars(address, timeout)
+	default:
+		return nil, fmt.Errorf("unsupported local daemon %q, supported values are %q and %q", daemon, localDaemonChrony, localDaemonNtpd)
+	}
+}
+
+func exchangeUDP(address string, timeout time.Duration, request []byte, handle func([]byte) (done bool, err error)) error {
+	conn, err := net.DialTimeout("udp", address, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 2048)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return err
+		}
+		done, err := handle(buf[:n])
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// Chrony command protocol, see candm.h in the chrony sources.
+// Only the `tracking` request is implemented, it is allowed from localhost
+// on the UDP command port without authentication.
+const (
+	chronyProtoVersion  = 6
+	chronyPktTypeReq    = 1
+	chronyPktTypeReply  = 2
+	chronyReqTracking   = 33
+	chronyRpyTracking   = 5
+	chronyStatusSuccess = 0
+	chronyLeapUnsync    = 3
+
+	// requests are padded to the size of the reply to prevent amplification
+	chronyRequestHeaderLen = 20
+	chronyMaxDataLen       = 396
+	chronyReplyHeaderLen   = 28
+	chronyTrackingLen      = 4 + 20 + 2 + 2 + 12 + 9*4
+)
+
+type chronyRequestHead struct {
+	Version  uint8
+	PktType  uint8
+	Res1     uint8
+	Res2     uint8
+	Command  uint16
+	Attempt  uint16
+	Sequence uint32
+	Pad1     uint32
+	Pad2     uint32
+}
+
+type chronyReplyHead struct {
+	Version  uint8
+	PktType  uint8
+	Res1     uint8
+	Res2     uint8
+	Command  uint16
+	Reply    uint16
+	Status   uint16
+	Pad1     uint16
+	Pad2     uint16
+	Pad3     uint16
+	Sequence uint32
+	Pad4     uint32
+	Pad5     uint32
+}
+
+type chronyTrackingContent struct {
+	RefID              uint32
+	IPAddr             [16]uint8
+	IPFamily           uint16
+	IPPad              uint16
+	Stratum            uint16
+	LeapStatus         uint16
+	RefTimeSecHigh     uint32
+	RefTimeSecLow      uint32
+	RefTimeNsec        uint32
+	CurrentCorrection  int32
+	LastOffset         int32
+	RMSOffset          int32
+	FreqPPM            int32
+	ResidFreqPPM       int32
+	SkewPPM            int32
+	RootDelay          int32
+	RootDispersion     int32
+	LastUpdateInterval int32
+}
+
+// chronyFloat decodes the custom floating point format used by chrony on the wire:
+// a 7-bit signed exponent followed by a 25-bit signed coefficient.
+func chronyFloat(v int32) float64 {
+	const expBits = 7
+	const coefBits = 32 - expBits
+
+	x := uint32(v)
+	exp := int32(x >> coefBits)
+	if exp >= 1<<(expBits-1) {
+		exp -= 1 << expBits
+	}
+	exp -= coefBits
+
+	coef := int32(x % (1 << coefBits))
+	if coef >= 1<<(coefBits-1) {
+		coef -= 1 << coefBits
+	}
+
+	return float64(coef) * math.Pow(2, float64(exp))
+}
+
+func buildChronyTrackingRequest(sequence uint32) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, chronyRequestHeaderLen+chronyMaxDataLen))
+	// Writes to a bytes.Buffer cannot fail
+	_ = binary.Write(buf, binary.BigEndian, chronyRequestHead{
+		Version:  chronyProtoVersion,
+		PktType:  chronyPktTypeReq,
+		Command:  chronyReqTracking,
+		Sequence: sequence,
+	})
+	buf.Write(make([]byte, chronyMaxDataLen))
+	return buf.Bytes()
+}
+
+func parseChronyTrackingReply(data []byte, sequence uint32) (*localDaemonState, error) {
+	if len(data) < chronyReplyHeaderLen+chronyTrackingLen {
+		return nil, fmt.Errorf("chrony reply too short: %d bytes", len(data))
+	}
+
+	reader := bytes.NewReader(data)
+	var head chronyReplyHead
+	if err := binary.Read(reader, binary.BigEndian, &head); err != nil {
+		return nil, err
+	}
+
+	if head.PktType != chronyPktTypeReply || head.Sequence != sequence {
+		return nil, errors.New("unexpected chrony reply")
+	}
+	if head.Status != chronyStatusSuccess {
+		return nil, fmt.Errorf("chrony returned status %d", head.Status)
+	}
+	if head.Reply != chronyRpyTracking {
+		return nil, fmt.Errorf("unexpected chrony reply type %d", head.Reply)
+	}
+
+	var tracking chronyTrackingContent
+	if err := binary.Read(reader, binary.BigEndian, &tracking); err != nil {
+		return nil, err
+	}
+
+	return &localDaemonState{
+		Offset:         chronyFloat(tracking.CurrentCorrection),
+		Stratum:        int(tracking.Stratum),
+		RootDelay:      chronyFloat(tracking.RootDelay),
+		RootDispersion: chronyFloat(tracking.RootDispersion),
+		Synchronized:   tracking.LeapStatus != chronyLeapUnsync,
+	}, nil
+}
+
+func queryChronyTrackingUDP(address string, timeout time.Duration) (*localDaemonState, error) {
+	sequence := rand.Uint32()
+
+	var state *localDaemonState
+	err := exchangeUDP(address, timeout, buildChronyTrackingRequest(sequence), func(data []byte) (bool, error) {
+		var err error
+		state, err = parseChronyTrackingReply(data, sequence)
+		return true, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to query chrony tracking on %s: %w", address, err)
+	}
+
+	return state, nil
+}
+
+// NTP control messages (mode 6), see RFC 1305 appendix B.
+// Only the READVAR request on the system association is implemented.
+const (
+	ntpControlVersion   = 2
+	ntpControlMode      = 6
+	ntpControlReadVar   = 2
+	ntpControlHeaderLen = 12
+
+	ntpControlResponseBit = 0x80
+	ntpControlErrorBit    = 0x40
+	ntpControlMoreBit     = 0x20
+	ntpControlOpcodeMask  = 0x1f
+)
+
+func buildNtpdReadVarRequest(sequence uint16) []byte {
+	request := make([]byte, ntpControlHeaderLen)
+	request[0] = ntpControlVersion<<3 | ntpControlMode
+	request[1] = ntpControlReadVar
+	binary.BigEndian.PutUint16(request[2:], sequence)
+	// status, association ID (0: system), offset and count are left to 0
+	return request
+}
+
+type ntpdFragment struct {
+	offset uint16
+	data   []byte
+}
+
+func queryNtpdSystemVarsUDP(address string, timeout time.Duration) (*localDaemonState, error) {
+	sequence := uint16(rand.Uint32())
+
+	var fragments []ntpdFragment
+	// Fragments may arrive out of order, the response is complete once the last fragment
+	// has been received and all the bytes before it are there
+	received, expected := 0, -1
+	err := exchangeUDP(address, timeout, buildNtpdReadVarRequest(sequence), func(data []byte) (bool, error) {
+		if len(data) < ntpControlHeaderLen {
+			return false, fmt.Errorf("ntpd reply too short: %d bytes", len(data))
+		}
+		if data[1]&ntpControlOpcodeMask != ntpControlReadVar || data[1]&ntpControlResponseBit == 0 || binary.BigEndian.Uint16(data[2:]) != sequence {
+			// Not an answer to our request, keep waiting
+			return false, nil
+		}
+		if data[1]&ntpControlErrorBit != 0 {
+			return false, fmt.Errorf("ntpd returned error status %d", binary.BigEndian.Uint16(data[4:])>>8)
+		}
+
+		offset := binary.BigEndian.Uint16(data[8:])
+		count := int(binary.BigEndian.Uint16(data[10:]))
+		if ntpControlHeaderLen+count > len(data) {
+			return false, errors.New("ntpd reply truncated")
+		}
+		// data is backed by the read buffer, it has to be copied before reading the next fragment
+		fragment := ntpdFragment{offset: offset, data: make([]byte, count)}
+		copy(fragment.data, data[ntpControlHeaderLen:])
+		fragments = append(fragments, fragment)
+		received += count
+
+		if data[1]&ntpControlMoreBit == 0 {
+			expected = int(offset) + count
+		}
+		return received == expected, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read ntpd system variables on %s: %w", address, err)
+	}
+
+	sort.Slice(fragments, func(i, j int) bool { return fragments[i].offset < fragments[j].offset })
+	var payload bytes.Buffer
+	for _, f := range fragments {
+		payload.Write(f.data)
+	}
+
+	return parseNtpdSystemVars(payload.String())
+}
+
+// parseNtpdSystemVars parses the `key=value, key="value"` list returned by ntpd
+func parseNtpdSystemVars(payload string) (*localDaemonState, error) {
+	vars := make(map[string]string)
+	for _, field := range strings.Split(payload, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found {
+			continue
+		}
+		vars[key] = strings.Trim(value, `"`)
+	}
+
+	state := &localDaemonState{}
+
+	leap, ok := vars["leap"]
+	if !ok {
+		return nil, errors.New("missing leap indicator in ntpd system variables")
+	}
+	// leap=11 (3) means the clock is not synchronized
+	state.Synchronized = leap != "11" && leap != "3"
+
+	var err error
+	if state.Stratum, err = strconv.Atoi(vars["stratum"]); err != nil {
+		return nil, fmt.Errorf("invalid stratum in ntpd system variables: %w", err)
+	}
+
+	// ntpd reports offset, rootdelay and rootdisp in milliseconds
+	millisecondFields := map[string]*float64{
+		"offset":    &state.Offset,
+		"rootdelay": &state.RootDelay,
+		"rootdisp":  &state.RootDispersion,
+	}
+	for key, dest := range millisecondFields {
+		value, err := strconv.ParseFloat(vars[key], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in ntpd system variables: %w", key, err)
+		}
+		*dest = value / 1000
+	}
+
+	return state, nil
+}
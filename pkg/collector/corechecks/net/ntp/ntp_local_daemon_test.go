@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package ntp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startUDPServer answers every request received with the packets returned by respond
+func startUDPServer(t *testing.T, respond func(request []byte) [][]byte) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			for _, packet := range respond(buf[:n]) {
+				conn.WriteTo(packet, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// encodeChronyFloat is the reverse of chronyFloat, for exact powers of two only
+func encodeChronyFloat(coef int32, exp int32) int32 {
+	exp += 25
+	return int32(uint32(exp&0x7f)<<25 | uint32(coef)&(1<<25-1))
+}
+
+func TestChronyFloat(t *testing.T) {
+	assert.Equal(t, 0.0, chronyFloat(0))
+	assert.Equal(t, 1.0, chronyFloat(encodeChronyFloat(1, 0)))
+	assert.Equal(t, -0.5, chronyFloat(encodeChronyFloat(-1, -1)))
+	assert.Equal(t, 0.0009765625, chronyFloat(encodeChronyFloat(1, -10)))
+}
+
+func TestQueryChronyTracking(t *testing.T) {
+	address := startUDPServer(t, func(request []byte) [][]byte {
+		var head chronyRequestHead
+		require.NoError(t, binary.Read(bytes.NewReader(request), binary.BigEndian, &head))
+		assert.Len(t, request, chronyRequestHeaderLen+chronyMaxDataLen)
+		assert.Equal(t, uint8(chronyProtoVersion), head.Version)
+		assert.Equal(t, uint16(chronyReqTracking), head.Command)
+
+		var reply bytes.Buffer
+		binary.Write(&reply, binary.BigEndian, chronyReplyHead{
+			Version:  chronyProtoVersion,
+			PktType:  chronyPktTypeReply,
+			Command:  chronyReqTracking,
+			Reply:    chronyRpyTracking,
+			Status:   chronyStatusSuccess,
+			Sequence: head.Sequence,
+		})
+		binary.Write(&reply, binary.BigEndian, chronyTrackingContent{
+			Stratum:           2,
+			CurrentCorrection: encodeChronyFloat(-1, -1),
+			RootDelay:         encodeChronyFloat(1, -10),
+			RootDispersion:    encodeChronyFloat(1, -9),
+		})
+		return [][]byte{reply.Bytes()}
+	})
+
+	state, err := queryChronyTrackingUDP(address, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, &localDaemonState{
+		Offset:         -0.5,
+		Stratum:        2,
+		RootDelay:      0.0009765625,
+		RootDispersion: 0.001953125,
+		Synchronized:   true,
+	}, state)
+}
+
+func TestQueryChronyTrackingUnauthorized(t *testing.T) {
+	address := startUDPServer(t, func(request []byte) [][]byte {
+		var head chronyRequestHead
+		binary.Read(bytes.NewReader(request), binary.BigEndian, &head)
+
+		var reply bytes.Buffer
+		binary.Write(&reply, binary.BigEndian, chronyReplyHead{
+			Version:  chronyProtoVersion,
+			PktType:  chronyPktTypeReply,
+			Reply:    chronyRpyTracking,
+			Status:   2,
+			Sequence: head.Sequence,
+		})
+		binary.Write(&reply, binary.BigEndian, chronyTrackingContent{})
+		return [][]byte{reply.Bytes()}
+	})
+
+	_, err := queryChronyTrackingUDP(address, time.Second)
+	assert.ErrorContains(t, err, "chrony returned status 2")
+}
+
+func ntpdControlResponse(request []byte, offset int, more bool, payload string) []byte {
+	response := make([]byte, ntpControlHeaderLen+len(payload))
+	copy(response, request[:ntpControlHeaderLen])
+	response[1] = ntpControlResponseBit | ntpControlReadVar
+	if more {
+		response[1] |= ntpControlMoreBit
+	}
+	binary.BigEndian.PutUint16(response[8:], uint16(offset))
+	binary.BigEndian.PutUint16(response[10:], uint16(len(payload)))
+	copy(response[ntpControlHeaderLen:], payload)
+	return response
+}
+
+func TestQueryNtpdSystemVars(t *testing.T) {
+	part1 := `version="ntpd 4.2.8p15", processor="x86_64", leap=00, stratum=3, precision=-23, rootdelay=12.5, `
+	part2 := `rootdisp=40.25, refid=10.0.0.1, offset=-1.5, sys_jitter=0.1`
+
+	address := startUDPServer(t, func(request []byte) [][]byte {
+		assert.Equal(t, byte(ntpControlVersion<<3|ntpControlMode), request[0])
+		assert.Equal(t, byte(ntpControlReadVar), request[1])
+		// Send the fragments out of order
+		return [][]byte{
+			ntpdControlResponse(request, len(part1), false, part2),
+			ntpdControlResponse(request, 0, true, part1),
+		}
+	})
+
+	state, err := queryNtpdSystemVarsUDP(address, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, &localDaemonState{
+		Offset:         -0.0015,
+		Stratum:        3,
+		RootDelay:      0.0125,
+		RootDispersion: 0.04025,
+		Synchronized:   true,
+	}, state)
+}
+
+func TestParseNtpdSystemVarsUnsynchronized(t *testing.T) {
+	state, err := parseNtpdSystemVars("leap=11, stratum=16, rootdelay=0.000, rootdisp=1.5, offset=0.000")
+	require.NoError(t, err)
+	assert.False(t, state.Synchronized)
+	assert.Equal(t, 16, state.Stratum)
+
+	_, err = parseNtpdSystemVars("stratum=16")
+	assert.EqualError(t, err, "missing leap indicator in ntpd system variables")
+}
@@ -423,3 +423,137 @@ func TestNTPUseLocalDefinedServers(t *testing.T) {
 	assert.False(t, defaultConfig.instance.UseLocalDefinedServers)
 	assert.NotEqual(t, configUseLocalServer.instance.Hosts, defaultConfig.instance.Hosts)
 }
+
+func TestNTPPerServerMetrics(t *testing.T) {
+	ntpCfg := []byte(`
+collect_per_server_metrics: true
+outlier_threshold: 5
+hosts:
+  - 1
+  - 2
+  - 400
+`)
+	ntpInitCfg := []byte("")
+
+	ntpQuery = func(host string, _ ntp.QueryOptions) (*ntp.Response, error) {
+		o, _ := strconv.Atoi(host)
+		return &ntp.Response{
+			ClockOffset: time.Duration(o) * time.Second,
+			Stratum:     uint8(o%10 + 1),
+		}, nil
+	}
+	defer func() { ntpQuery = ntp.QueryWithOptions }()
+
+	ntpCheck := new(NTPCheck)
+	senderManager := mocksender.CreateDefaultDemultiplexer()
+	ntpCheck.Configure(senderManager, integration.FakeConfigHash, ntpCfg, ntpInitCfg, "test")
+
+	mockSender := mocksender.NewMockSenderWithSenderManager(ntpCheck.ID(), senderManager)
+	mockSender.SetupAcceptAll()
+
+	err := ntpCheck.Run()
+	assert.NoError(t, err)
+
+	mockSender.AssertMetric(t, "Gauge", "ntp.offset", 2, "", nil)
+	mockSender.AssertServiceCheck(t, "ntp.in_sync", servicecheck.ServiceCheckOK, "", nil, "")
+	for _, server := range []struct {
+		host    string
+		offset  float64
+		stratum float64
+		outlier float64
+	}{
+		{"1", 1, 2, 0},
+		{"2", 2, 3, 0},
+		{"400", 400, 1, 1},
+	} {
+		tags := []string{"ntp_server:" + server.host}
+		mockSender.AssertMetric(t, "Gauge", "ntp.server.offset", server.offset, "", tags)
+		mockSender.AssertMetric(t, "Gauge", "ntp.server.stratum", server.stratum, "", tags)
+		mockSender.AssertMetric(t, "Gauge", "ntp.server.outlier", server.outlier, "", tags)
+	}
+	mockSender.AssertMetric(t, "Gauge", "ntp.servers.responding", 3, "", nil)
+	mockSender.AssertMetric(t, "Gauge", "ntp.servers.outliers", 1, "", nil)
+	mockSender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestNTPLocalDaemonOnly(t *testing.T) {
+	ntpCfg := []byte(`
+disable_server_queries: true
+local_daemon: chrony
+`)
+	ntpInitCfg := []byte("")
+
+	ntpQuery = func(string, ntp.QueryOptions) (*ntp.Response, error) {
+		t.Fatal("remote servers should not be queried")
+		return nil, nil
+	}
+	defer func() { ntpQuery = ntp.QueryWithOptions }()
+
+	var queriedAddress string
+	queryChronyTracking = func(address string, _ time.Duration) (*localDaemonState, error) {
+		queriedAddress = address
+		return &localDaemonState{Offset: -0.5, Stratum: 3, RootDelay: 0.01, RootDispersion: 0.002, Synchronized: true}, nil
+	}
+	defer func() { queryChronyTracking = queryChronyTrackingUDP }()
+
+	ntpCheck := new(NTPCheck)
+	senderManager := mocksender.CreateDefaultDemultiplexer()
+	err := ntpCheck.Configure(senderManager, integration.FakeConfigHash, ntpCfg, ntpInitCfg, "test")
+	assert.NoError(t, err)
+
+	mockSender := mocksender.NewMockSenderWithSenderManager(ntpCheck.ID(), senderManager)
+	mockSender.SetupAcceptAll()
+
+	err = ntpCheck.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, defaultChronyAddress, queriedAddress)
+
+	tags := []string{"ntp_daemon:chrony"}
+	mockSender.AssertMetric(t, "Gauge", "ntp.local.offset", -0.5, "", tags)
+	mockSender.AssertMetric(t, "Gauge", "ntp.local.stratum", 3, "", tags)
+	mockSender.AssertMetric(t, "Gauge", "ntp.local.root_delay", 0.01, "", tags)
+	mockSender.AssertMetric(t, "Gauge", "ntp.local.root_dispersion", 0.002, "", tags)
+	mockSender.AssertMetric(t, "Gauge", "ntp.local.synchronized", 1, "", tags)
+	mockSender.AssertServiceCheck(t, "ntp.local.in_sync", servicecheck.ServiceCheckOK, "", tags, "")
+	mockSender.AssertNotCalled(t, "Gauge", "ntp.offset", mock.Anything, "", []string(nil))
+	mockSender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestNTPLocalDaemonUnsynchronized(t *testing.T) {
+	ntpCfg := []byte(`
+disable_server_queries: true
+local_daemon: ntpd
+local_daemon_address: 127.0.0.1:1123
+`)
+
+	queryNtpdSystemVars = func(address string, _ time.Duration) (*localDaemonState, error) {
+		assert.Equal(t, "127.0.0.1:1123", address)
+		return &localDaemonState{Stratum: 16}, nil
+	}
+	defer func() { queryNtpdSystemVars = queryNtpdSystemVarsUDP }()
+
+	ntpCheck := new(NTPCheck)
+	senderManager := mocksender.CreateDefaultDemultiplexer()
+	err := ntpCheck.Configure(senderManager, integration.FakeConfigHash, ntpCfg, []byte(""), "test")
+	assert.NoError(t, err)
+
+	mockSender := mocksender.NewMockSenderWithSenderManager(ntpCheck.ID(), senderManager)
+	mockSender.SetupAcceptAll()
+
+	err = ntpCheck.Run()
+	assert.NoError(t, err)
+
+	tags := []string{"ntp_daemon:ntpd"}
+	mockSender.AssertMetric(t, "Gauge", "ntp.local.synchronized", 0, "", tags)
+	mockSender.AssertServiceCheck(t, "ntp.local.in_sync", servicecheck.ServiceCheckCritical, "", tags, "The local ntpd daemon is not synchronized")
+}
+
+func TestNTPLocalDaemonConfig(t *testing.T) {
+	ntpCheck := new(NTPCheck)
+
+	err := ntpCheck.Configure(aggregator.NewNoOpSenderManager(), integration.FakeConfigHash, []byte("local_daemon: timesyncd"), []byte(""), "test")
+	assert.EqualError(t, err, `invalid local_daemon "timesyncd", supported values are "chrony" and "ntpd"`)
+
+	err = ntpCheck.Configure(aggregator.NewNoOpSenderManager(), integration.FakeConfigHash, []byte("disable_server_queries: true"), []byte(""), "test")
+	assert.EqualError(t, err, "local_daemon must be set when disable_server_queries is enabled")
+}
@@ -0,0 +1,164 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package client implements the SSH client used to retrieve the configuration of network devices
+package client
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Client runs show commands on a network device over SSH
+type Client struct {
+	address string
+	config  *ssh.ClientConfig
+	timeout time.Duration
+}
+
+// ClientOptions are the functional options for the SSH client
+type ClientOptions func(*ssh.ClientConfig) error
+
+// NewClient creates a new SSH client, the timeout applies both to the connection and to each
+// command. The host key must be verified with WithKnownHostsFile unless WithInsecureHostKey is used
+func NewClient(address string, username string, timeout time.Duration, options ...ClientOptions) (*Client, error) {
+	if address == "" {
+		return nil, errors.New("invalid address")
+	}
+	config := &ssh.ClientConfig{
+		User:    username,
+		Timeout: timeout,
+	}
+	for _, option := range options {
+		if err := option(config); err != nil {
+			return nil, err
+		}
+	}
+	if config.HostKeyCallback == nil {
+		return nil, errors.New("no host key verification configured")
+	}
+	if len(config.Auth) == 0 {
+		return nil, errors.New("no authentication method configured")
+	}
+	return &Client{
+		address: address,
+		config:  config,
+		timeout: timeout,
+	}, nil
+}
+
+// WithPassword authenticates with a password, keyboard-interactive authentication
+// used by many network devices is answered with the password as well
+func WithPassword(password string) ClientOptions {
+	return func(config *ssh.ClientConfig) error {
+		config.Auth = append(config.Auth,
+			ssh.Password(password),
+			ssh.KeyboardInteractive(func(_ string, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = password
+				}
+				return answers, nil
+			}),
+		)
+		return nil
+	}
+}
+
+// WithPrivateKeyFile authenticates with a private key, the passphrase is optional
+func WithPrivateKeyFile(keyFile string, passphrase string) ClientOptions {
+	return func(config *ssh.ClientConfig) error {
+		key, err := os.ReadFile(keyFile)
+		if err != nil {
+			return fmt.Errorf("unable to read private key: %w", err)
+		}
+		var signer ssh.Signer
+		if passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(key)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid private key: %w", err)
+		}
+		// the key is tried first, password authentication is the fallback
+		config.Auth = append([]ssh.AuthMethod{ssh.PublicKeys(signer)}, config.Auth...)
+		return nil
+	}
+}
+
+// WithKnownHostsFile verifies the host key of the device with a known_hosts file
+func WithKnownHostsFile(knownHostsFile string) ClientOptions {
+	return func(config *ssh.ClientConfig) error {
+		callback, err := knownhosts.New(knownHostsFile)
+		if err != nil {
+			return fmt.Errorf("invalid known hosts file: %w", err)
+		}
+		config.HostKeyCallback = callback
+		return nil
+	}
+}
+
+// WithInsecureHostKey disables the verification of the host key of the device
+func WithInsecureHostKey() ClientOptions {
+	return func(config *ssh.ClientConfig) error {
+		config.HostKeyCallback = ssh.InsecureIgnoreHostKey() //nolint:gosec // explicitly enabled by the user
+		return nil
+	}
+}
+
+// RunCommands runs each command in its own session and returns the concatenated outputs
+func (c *Client) RunCommands(commands []string) (string, error) {
+	conn, err := ssh.Dial("tcp", c.address, c.config)
+	if err != nil {
+		return "", fmt.Errorf("unable to connect to %s: %w", c.address, err)
+	}
+	defer conn.Close()
+
+	var outputs []string
+	for _, command := range commands {
+		output, err := runCommand(conn, command, c.timeout)
+		if err != nil {
+			return "", fmt.Errorf("command `%s` failed: %w", command, err)
+		}
+		outputs = append(outputs, output)
+	}
+	return strings.Join(outputs, "\n"), nil
+}
+
+// runCommand runs a command in a new session, the connection is closed if the command
+// doesn't complete within the timeout since the dial timeout doesn't apply to commands
+func runCommand(conn *ssh.Client, command string, timeout time.Duration) (string, error) {
+	session, err := conn.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
+	var timedOut atomic.Bool
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			timedOut.Store(true)
+			conn.Close()
+		})
+		defer timer.Stop()
+	}
+
+	output, err := session.Output(command)
+	if timedOut.Load() {
+		return "", fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
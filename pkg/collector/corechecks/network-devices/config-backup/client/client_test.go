@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+//go:build test
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestRunCommands(t *testing.T) {
+	server, err := SetupMockServer("admin", "secret", map[string]string{
+		"show version":        "Cisco IOS Software\n",
+		"show running-config": "hostname router-1\n",
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	client, err := NewClient(server.Addr, "admin", time.Second, WithPassword("secret"), WithInsecureHostKey())
+	require.NoError(t, err)
+
+	output, err := client.RunCommands([]string{"show version", "show running-config"})
+	require.NoError(t, err)
+	assert.Equal(t, "Cisco IOS Software\n\nhostname router-1\n", output)
+	assert.Equal(t, []string{"show version", "show running-config"}, server.Commands())
+
+	_, err = client.RunCommands([]string{"show foo"})
+	assert.ErrorContains(t, err, "command `show foo` failed")
+
+	client, err = NewClient(server.Addr, "admin", time.Second, WithPassword("wrong"), WithInsecureHostKey())
+	require.NoError(t, err)
+	_, err = client.RunCommands([]string{"show version"})
+	assert.ErrorContains(t, err, "unable to connect to "+server.Addr)
+}
+
+func TestRunCommandsTimeout(t *testing.T) {
+	server, err := SetupMockServer("admin", "secret", map[string]string{"show running-config": "hostname router-1\n"})
+	require.NoError(t, err)
+	defer server.Close()
+	server.SetHanging("show running-config")
+
+	client, err := NewClient(server.Addr, "admin", 100*time.Millisecond, WithPassword("secret"), WithInsecureHostKey())
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = client.RunCommands([]string{"show running-config"})
+	assert.EqualError(t, err, "command `show running-config` failed: timed out after 100ms")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestKnownHostsFile(t *testing.T) {
+	server, err := SetupMockServer("admin", "secret", map[string]string{"show running-config": "hostname router-1\n"})
+	require.NoError(t, err)
+	defer server.Close()
+
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	require.NoError(t, os.WriteFile(knownHostsFile, []byte(knownhosts.Line([]string{server.Addr}, server.PublicKey)+"\n"), 0600))
+
+	client, err := NewClient(server.Addr, "admin", time.Second, WithPassword("secret"), WithKnownHostsFile(knownHostsFile))
+	require.NoError(t, err)
+	_, err = client.RunCommands([]string{"show running-config"})
+	assert.NoError(t, err)
+
+	// unknown host key
+	otherServer, err := SetupMockServer("admin", "secret", nil)
+	require.NoError(t, err)
+	defer otherServer.Close()
+	require.NoError(t, os.WriteFile(knownHostsFile, []byte(knownhosts.Line([]string{server.Addr}, otherServer.PublicKey)+"\n"), 0600))
+	client, err = NewClient(server.Addr, "admin", time.Second, WithPassword("secret"), WithKnownHostsFile(knownHostsFile))
+	require.NoError(t, err)
+	_, err = client.RunCommands([]string{"show running-config"})
+	var keyErr *knownhosts.KeyError
+	assert.ErrorAs(t, err, &keyErr)
+}
+
+func TestNewClientErrors(t *testing.T) {
+	_, err := NewClient("", "admin", time.Second)
+	assert.EqualError(t, err, "invalid address")
+
+	_, err = NewClient("127.0.0.1:22", "admin", time.Second, WithPassword("secret"))
+	assert.EqualError(t, err, "no host key verification configured")
+
+	_, err = NewClient("127.0.0.1:22", "admin", time.Second, WithInsecureHostKey())
+	assert.EqualError(t, err, "no authentication method configured")
+
+	_, err = NewClient("127.0.0.1:22", "admin", time.Second, WithInsecureHostKey(), WithPrivateKeyFile("/does/not/exist", ""))
+	assert.ErrorContains(t, err, "unable to read private key")
+
+	_, err = NewClient("127.0.0.1:22", "admin", time.Second, WithKnownHostsFile("/does/not/exist"))
+	assert.ErrorContains(t, err, "invalid known hosts file")
+}
@@ -0,0 +1,171 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+//go:build test
+
+package client
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// MockServer is a local SSH server answering commands with canned outputs
+type MockServer struct {
+	Addr      string
+	PublicKey ssh.PublicKey
+
+	listener net.Listener
+	mu       sync.Mutex
+	outputs  map[string]string
+	hanging  map[string]bool
+	commands []string
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// SetupMockServer starts a mock SSH server accepting the username and password
+func SetupMockServer(username string, password string, outputs map[string]string) (*MockServer, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if conn.User() == username && string(pass) == password {
+				return nil, nil
+			}
+			return nil, errors.New("invalid credentials")
+		},
+	}
+	config.AddHostKey(signer)
+
+	if outputs == nil {
+		outputs = make(map[string]string)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	server := &MockServer{
+		Addr:      listener.Addr().String(),
+		PublicKey: signer.PublicKey(),
+		listener:  listener,
+		outputs:   outputs,
+		hanging:   make(map[string]bool),
+		done:      make(chan struct{}),
+	}
+	server.wg.Add(1)
+	go func() {
+		defer server.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.wg.Add(1)
+			go func() {
+				defer server.wg.Done()
+				server.handleConn(conn, config)
+			}()
+		}
+	}()
+	return server, nil
+}
+
+// SetOutput sets the output of a command
+func (s *MockServer) SetOutput(command string, output string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outputs[command] = output
+}
+
+// SetHanging makes a command never complete, until the server is closed
+func (s *MockServer) SetHanging(command string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hanging[command] = true
+}
+
+// Commands returns the commands received by the server
+func (s *MockServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.commands...)
+}
+
+// Close stops the server, it can be called several times
+func (s *MockServer) Close() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+		s.listener.Close()
+	})
+	s.wg.Wait()
+}
+
+func (s *MockServer) handleConn(netConn net.Conn, config *ssh.ServerConfig) {
+	defer netConn.Close()
+	_, channels, requests, err := ssh.NewServerConn(netConn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type") //nolint:errcheck
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		s.handleSession(channel, channelRequests)
+	}
+}
+
+func (s *MockServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for request := range requests {
+		if request.Type != "exec" || len(request.Payload) < 4 {
+			request.Reply(false, nil) //nolint:errcheck
+			continue
+		}
+		command := string(request.Payload[4 : 4+binary.BigEndian.Uint32(request.Payload)])
+		request.Reply(true, nil) //nolint:errcheck
+
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		output, ok := s.outputs[command]
+		hanging := s.hanging[command]
+		s.mu.Unlock()
+
+		if hanging {
+			<-s.done
+			return
+		}
+
+		status := uint32(0)
+		if ok {
+			channel.Write([]byte(output)) //nolint:errcheck
+		} else {
+			fmt.Fprintf(channel.Stderr(), "%% Invalid input detected\n")
+			status = 1
+		}
+		channel.SendRequest("exit-status", false, binary.BigEndian.AppendUint32(nil, status)) //nolint:errcheck
+		return
+	}
+}
@@ -0,0 +1,293 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package configbackup implements NDM network device configuration backup corecheck
+package configbackup
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/config-backup/client"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/persistentcache"
+	"github.com/DataDog/datadog-agent/pkg/snmp/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/optional"
+)
+
+const (
+	// CheckName is the name of the check
+	CheckName            = "network_config_backup"
+	defaultCheckInterval = 15 * time.Minute
+	defaultPort          = 22
+	defaultTimeout       = 10
+	metricPrefix         = "network_config."
+	// maxDiffLength keeps the diff events under the size limit of the event text
+	maxDiffLength = 3500
+)
+
+// TimeNow useful for mocking
+var TimeNow = time.Now
+
+// Configuration for the config backup check
+type checkCfg struct {
+	IPAddress                 string   `yaml:"ip_address"`
+	Port                      int      `yaml:"port"`
+	Username                  string   `yaml:"username"`
+	Password                  string   `yaml:"password"`
+	PrivateKeyFile            string   `yaml:"private_key_file"`
+	PrivateKeyPassphrase      string   `yaml:"private_key_passphrase"`
+	KnownHostsFile            string   `yaml:"known_hosts_file"`
+	InsecureSkipHostKeyVerify bool     `yaml:"insecure_skip_host_key_verify"`
+	Vendor                    string   `yaml:"vendor"`
+	Commands                  []string `yaml:"commands"`
+	IgnoredLines              []string `yaml:"ignored_lines"`
+	Timeout                   int      `yaml:"timeout"`
+	Namespace                 string   `yaml:"namespace"`
+	MinCollectionInterval     int      `yaml:"min_collection_interval"`
+}
+
+// ConfigBackupCheck retrieves the running configuration of a device over SSH
+// and sends an event with the diff when it changes
+type ConfigBackupCheck struct {
+	core.CheckBase
+	interval      time.Duration
+	config        checkCfg
+	client        *client.Client
+	commands      []string
+	volatileLines []*regexp.Regexp
+	secretLines   []*regexp.Regexp
+}
+
+// Run retrieves the configuration and compares it with the last backup
+func (c *ConfigBackupCheck) Run() error {
+	sender, err := c.GetSender()
+	if err != nil {
+		return err
+	}
+	tags := c.deviceTags()
+
+	output, err := c.client.RunCommands(c.commands)
+	if err != nil {
+		sender.Gauge(metricPrefix+"device.reachable", 0, "", tags)
+		sender.Gauge(metricPrefix+"device.unreachable", 1, "", tags)
+		sender.Commit()
+		return err
+	}
+	sender.Gauge(metricPrefix+"device.reachable", 1, "", tags)
+	sender.Gauge(metricPrefix+"device.unreachable", 0, "", tags)
+
+	current := normalizeConfig(output, c.volatileLines, c.secretLines)
+	sender.Gauge(metricPrefix+"lines", float64(strings.Count(current, "\n")), "", tags)
+
+	backup, err := persistentcache.Read(c.cacheKey())
+	if err != nil {
+		log.Warnf("Unable to read the configuration backup of device %s: %s", c.config.IPAddress, err)
+	}
+	// the backups written before the secrets were redacted are redacted too, so
+	// that the secrets don't show up as removed lines in the diff
+	previous := backup
+	if previous != "" {
+		previous = normalizeConfig(previous, nil, c.secretLines)
+	}
+
+	changed := 0.0
+	if previous != "" && previous != current {
+		changed = 1
+		diff, err := diffConfigs(previous, current)
+		if err != nil {
+			log.Warnf("Unable to diff the configuration of device %s: %s", c.config.IPAddress, err)
+		} else {
+			sender.Event(c.buildDiffEvent(diff, tags))
+		}
+	}
+	sender.Gauge(metricPrefix+"changed", changed, "", tags)
+
+	if backup != current {
+		if err := persistentcache.Write(c.cacheKey(), current); err != nil {
+			log.Warnf("Unable to write the configuration backup of device %s: %s", c.config.IPAddress, err)
+		}
+	}
+
+	sender.Commit()
+	return nil
+}
+
+func (c *ConfigBackupCheck) buildDiffEvent(diff string, tags []string) event.Event {
+	added, removed := countChanges(diff)
+	if len(diff) > maxDiffLength {
+		// cut at a rune boundary to keep the text valid UTF-8
+		end := maxDiffLength
+		for end > 0 && !utf8.RuneStart(diff[end]) {
+			end--
+		}
+		diff = diff[:end] + "\n... (diff truncated)\n"
+	}
+	return event.Event{
+		Title:          fmt.Sprintf("Configuration of network device %s changed", c.config.IPAddress),
+		Text:           fmt.Sprintf("%%%%%% \n%d lines added, %d lines removed\n```diff\n%s```\n %%%%%%", added, removed, diff),
+		Ts:             TimeNow().Unix(),
+		Priority:       event.PriorityNormal,
+		AlertType:      event.AlertTypeInfo,
+		AggregationKey: c.deviceID(),
+		SourceTypeName: CheckName,
+		EventType:      CheckName,
+		Tags:           tags,
+	}
+}
+
+// Configure the config backup check
+func (c *ConfigBackupCheck) Configure(senderManager sender.SenderManager, integrationConfigDigest uint64, rawInstance integration.Data, rawInitConfig integration.Data, source string) error {
+	// Must be called before c.CommonConfigure
+	c.BuildID(integrationConfigDigest, rawInstance, rawInitConfig)
+
+	err := c.CommonConfigure(senderManager, rawInitConfig, rawInstance, source)
+	if err != nil {
+		return err
+	}
+
+	var instanceConfig checkCfg
+
+	// Set defaults before unmarshalling
+	instanceConfig.Port = defaultPort
+	instanceConfig.Timeout = defaultTimeout
+
+	err = yaml.Unmarshal(rawInstance, &instanceConfig)
+	if err != nil {
+		return err
+	}
+	c.config = instanceConfig
+
+	if c.config.IPAddress == "" {
+		return errors.New("ip_address is required")
+	}
+	if c.config.Username == "" {
+		return errors.New("username is required")
+	}
+
+	if c.config.Namespace == "" {
+		c.config.Namespace = "default"
+	} else {
+		namespace, err := utils.NormalizeNamespace(c.config.Namespace)
+		if err != nil {
+			return err
+		}
+		c.config.Namespace = namespace
+	}
+
+	if c.config.MinCollectionInterval != 0 {
+		c.interval = time.Second * time.Duration(c.config.MinCollectionInterval)
+	}
+
+	var vendorVolatileLines []string
+	if c.config.Vendor != "" {
+		vendor, ok := vendors[c.config.Vendor]
+		if !ok {
+			return fmt.Errorf("unknown vendor `%s`, expected one of %s", c.config.Vendor, strings.Join(vendorNames(), ", "))
+		}
+		c.commands = vendor.commands
+		vendorVolatileLines = vendor.volatileLines
+	}
+	if len(c.config.Commands) > 0 {
+		c.commands = c.config.Commands
+	}
+	if len(c.commands) == 0 {
+		return errors.New("vendor or commands is required")
+	}
+
+	c.volatileLines, err = compileVolatileLines(vendorVolatileLines, c.config.IgnoredLines)
+	if err != nil {
+		return err
+	}
+	c.secretLines = compileSecretLines(c.config.Vendor)
+
+	clientOptions, err := c.buildClientOptions()
+	if err != nil {
+		return err
+	}
+	address := net.JoinHostPort(c.config.IPAddress, strconv.Itoa(c.config.Port))
+	c.client, err = client.NewClient(address, c.config.Username, time.Duration(c.config.Timeout)*time.Second, clientOptions...)
+	return err
+}
+
+func (c *ConfigBackupCheck) buildClientOptions() ([]client.ClientOptions, error) {
+	var options []client.ClientOptions
+	if c.config.Password != "" {
+		options = append(options, client.WithPassword(c.config.Password))
+	}
+	if c.config.PrivateKeyFile != "" {
+		options = append(options, client.WithPrivateKeyFile(c.config.PrivateKeyFile, c.config.PrivateKeyPassphrase))
+	}
+	switch {
+	case c.config.KnownHostsFile != "":
+		options = append(options, client.WithKnownHostsFile(c.config.KnownHostsFile))
+	case c.config.InsecureSkipHostKeyVerify:
+		options = append(options, client.WithInsecureHostKey())
+	default:
+		return nil, errors.New("known_hosts_file is required to verify the host key of the device, unless insecure_skip_host_key_verify is enabled")
+	}
+	return options, nil
+}
+
+func (c *ConfigBackupCheck) deviceID() string {
+	return c.config.Namespace + ":" + c.config.IPAddress
+}
+
+func (c *ConfigBackupCheck) deviceTags() []string {
+	tags := []string{
+		"device_namespace:" + c.config.Namespace,
+		"device_ip:" + c.config.IPAddress,
+		"device_id:" + c.deviceID(),
+	}
+	if c.config.Vendor != "" {
+		tags = append(tags, "device_vendor:"+c.config.Vendor)
+	}
+	return tags
+}
+
+// cacheKey returns the persistent cache key of the last configuration of the device
+func (c *ConfigBackupCheck) cacheKey() string {
+	return CheckName + ":" + strings.NewReplacer(":", "_", ".", "_").Replace(c.deviceID())
+}
+
+// Interval returns the scheduling time for the check
+func (c *ConfigBackupCheck) Interval() time.Duration {
+	return c.interval
+}
+
+func vendorNames() []string {
+	names := make([]string, 0, len(vendors))
+	for name := range vendors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Factory creates a new check factory
+func Factory() optional.Option[func() check.Check] {
+	return optional.NewOption(newCheck)
+}
+
+func newCheck() check.Check {
+	return &ConfigBackupCheck{
+		CheckBase: core.NewCheckBase(CheckName),
+		interval:  defaultCheckInterval,
+	}
+}
@@ -0,0 +1,189 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+//go:build test
+
+package configbackup
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/config-backup/client"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/persistentcache"
+)
+
+const initialConfig = `Building configuration...
+
+Current configuration : 120 bytes
+!
+! Last configuration change at 10:01:02 UTC Mon Jan 1 2024
+hostname router-1
+interface Gi0/1
+ description uplink
+`
+
+func TestConfigBackupCheck(t *testing.T) {
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("run_path", t.TempDir())
+	now := time.Unix(1700000000, 0)
+	TimeNow = func() time.Time { return now }
+	t.Cleanup(func() { TimeNow = time.Now })
+
+	server, err := client.SetupMockServer("admin", "secret", map[string]string{"show running-config": initialConfig})
+	require.NoError(t, err)
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Addr)
+	require.NoError(t, err)
+
+	chk := newCheck()
+	senderManager := mocksender.CreateDefaultDemultiplexer()
+	// language=yaml
+	rawInstanceConfig := []byte(`
+ip_address: ` + host + `
+port: ` + port + `
+username: admin
+password: secret
+insecure_skip_host_key_verify: true
+vendor: cisco_ios
+namespace: test
+min_collection_interval: 600
+`)
+	err = chk.Configure(senderManager, integration.FakeConfigHash, rawInstanceConfig, []byte(``), "test")
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, chk.Interval())
+
+	sender := mocksender.NewMockSenderWithSenderManager(chk.ID(), senderManager)
+	sender.SetupAcceptAll()
+	tags := []string{"device_namespace:test", "device_ip:" + host, "device_id:test:" + host, "device_vendor:cisco_ios"}
+
+	// first run is the initial backup
+	require.NoError(t, chk.Run())
+	sender.AssertMetric(t, "Gauge", "network_config.device.reachable", 1, "", tags)
+	sender.AssertMetric(t, "Gauge", "network_config.lines", 4, "", tags)
+	sender.AssertMetric(t, "Gauge", "network_config.changed", 0, "", tags)
+	sender.AssertNotCalled(t, "Event", mock.Anything)
+	backup, err := persistentcache.Read(chk.(*ConfigBackupCheck).cacheKey())
+	require.NoError(t, err)
+	assert.Equal(t, "!\nhostname router-1\ninterface Gi0/1\n description uplink\n", backup)
+	assert.Equal(t, []string{"show running-config"}, server.Commands())
+
+	// volatile lines are ignored
+	server.SetOutput("show running-config", "Building configuration...\n\nCurrent configuration : 121 bytes\n!\n! Last configuration change at 11:00:00 UTC Mon Jan 1 2024\nhostname router-1\ninterface Gi0/1\n description uplink\n")
+	sender.ResetCalls()
+	require.NoError(t, chk.Run())
+	sender.AssertMetric(t, "Gauge", "network_config.changed", 0, "", tags)
+	sender.AssertNotCalled(t, "Event", mock.Anything)
+
+	// configuration change
+	server.SetOutput("show running-config", "Building configuration...\n!\nhostname router-1\ninterface Gi0/1\n description core uplink\n")
+	sender.ResetCalls()
+	require.NoError(t, chk.Run())
+	sender.AssertMetric(t, "Gauge", "network_config.changed", 1, "", tags)
+	sender.AssertEvent(t, event.Event{
+		Title: "Configuration of network device " + host + " changed",
+		Text: "%%% \n1 lines added, 1 lines removed\n```diff\n" + `--- previous
++++ current
+@@ -1,4 +1,4 @@
+ !
+ hostname router-1
+ interface Gi0/1
+- description uplink
++ description core uplink
+` + "```\n %%%",
+		Ts:             now.Unix(),
+		Priority:       event.PriorityNormal,
+		AlertType:      event.AlertTypeInfo,
+		AggregationKey: "test:" + host,
+		SourceTypeName: "network_config_backup",
+		EventType:      "network_config_backup",
+		Tags:           tags,
+	}, time.Second)
+	backup, err = persistentcache.Read(chk.(*ConfigBackupCheck).cacheKey())
+	require.NoError(t, err)
+	assert.Equal(t, "!\nhostname router-1\ninterface Gi0/1\n description core uplink\n", backup)
+
+	// the secrets are redacted, including in the backups written before they were
+	require.NoError(t, persistentcache.Write(chk.(*ConfigBackupCheck).cacheKey(), "!\nhostname router-1\nsnmp-server community public RO\ninterface Gi0/1\n description core uplink\n"))
+	server.SetOutput("show running-config", "!\nhostname router-1\nsnmp-server community public RO\nsnmp-server community private RW\ninterface Gi0/1\n description core uplink\n")
+	sender.ResetCalls()
+	require.NoError(t, chk.Run())
+	sender.AssertMetric(t, "Gauge", "network_config.changed", 1, "", tags)
+	sender.AssertEvent(t, event.Event{
+		Title: "Configuration of network device " + host + " changed",
+		Text: "%%% \n1 lines added, 0 lines removed\n```diff\n" + `--- previous
++++ current
+@@ -1,5 +1,6 @@
+ !
+ hostname router-1
+ snmp-server community <redacted>
++snmp-server community <redacted>
+ interface Gi0/1
+  description core uplink
+` + "```\n %%%",
+		Ts:             now.Unix(),
+		Priority:       event.PriorityNormal,
+		AlertType:      event.AlertTypeInfo,
+		AggregationKey: "test:" + host,
+		SourceTypeName: "network_config_backup",
+		EventType:      "network_config_backup",
+		Tags:           tags,
+	}, time.Second)
+	backup, err = persistentcache.Read(chk.(*ConfigBackupCheck).cacheKey())
+	require.NoError(t, err)
+	assert.Equal(t, "!\nhostname router-1\nsnmp-server community <redacted>\nsnmp-server community <redacted>\ninterface Gi0/1\n description core uplink\n", backup)
+
+	// unreachable device
+	server.Close()
+	sender.ResetCalls()
+	assert.ErrorContains(t, chk.Run(), "unable to connect to "+server.Addr)
+	sender.AssertMetric(t, "Gauge", "network_config.device.unreachable", 1, "", tags)
+}
+
+func TestConfigBackupCheckConfigureErrors(t *testing.T) {
+	senderManager := mocksender.CreateDefaultDemultiplexer()
+
+	for _, tt := range []struct {
+		name          string
+		config        string
+		expectedError string
+	}{
+		{name: "missing ip address", config: `username: admin`, expectedError: "ip_address is required"},
+		{name: "missing username", config: `ip_address: 1.2.3.4`, expectedError: "username is required"},
+		{name: "unknown vendor", config: "ip_address: 1.2.3.4\nusername: admin\nvendor: foo", expectedError: "unknown vendor `foo`, expected one of arista_eos, cisco_ios, cisco_nxos, juniper_junos"},
+		{name: "missing commands", config: "ip_address: 1.2.3.4\nusername: admin", expectedError: "vendor or commands is required"},
+		{name: "invalid ignored lines", config: "ip_address: 1.2.3.4\nusername: admin\nvendor: cisco_ios\nignored_lines: ['^(']", expectedError: "invalid ignored line pattern `^(`: error parsing regexp: missing closing ): `^(`"},
+		{name: "missing host key verification", config: "ip_address: 1.2.3.4\nusername: admin\npassword: secret\ncommands: [show config]", expectedError: "known_hosts_file is required to verify the host key of the device, unless insecure_skip_host_key_verify is enabled"},
+		{name: "missing credentials", config: "ip_address: 1.2.3.4\nusername: admin\ncommands: [show config]\ninsecure_skip_host_key_verify: true", expectedError: "no authentication method configured"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			chk := newCheck()
+			err := chk.Configure(senderManager, integration.FakeConfigHash, []byte(tt.config), []byte(``), "test")
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestBuildDiffEventTruncatesAtRuneBoundary(t *testing.T) {
+	chk := newCheck().(*ConfigBackupCheck)
+	// the 2 bytes rune "é" spans over the maximum length
+	diff := strings.Repeat("+", maxDiffLength-1) + "é" + strings.Repeat("+", 10)
+
+	e := chk.buildDiffEvent(diff, nil)
+
+	assert.True(t, utf8.ValidString(e.Text))
+	assert.Contains(t, e.Text, strings.Repeat("+", maxDiffLength-1)+"\n... (diff truncated)\n")
+}
@@ -0,0 +1,192 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package configbackup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// redactedValue replaces the secrets of the configurations
+const redactedValue = "<redacted>"
+
+// vendorConfig contains the commands returning the configuration of a vendor,
+// the lines of the configuration that change without any configuration change
+// and the lines holding secrets
+type vendorConfig struct {
+	commands      []string
+	volatileLines []string
+	// secretLines match the lines holding secrets, the end of the line after
+	// the first group is redacted
+	secretLines []string
+}
+
+// iosSecretLines match the secrets of the IOS-like configurations
+var iosSecretLines = []string{
+	`^(\s*snmp-server community\s+)`,
+	`^(\s*snmp-server host\s+\S+\s+)`,
+	`^(\s*snmp-server user\s+.*?\s(?:auth|priv)\s+)`,
+	`^(\s*(?:tacacs-server|radius-server)\s+.*?\bkey\s+)`,
+	`^(\s*key-string\s+)`,
+	// the keys of the tacacs and radius server blocks
+	`^(\s+key\s+)`,
+	`^(\s*crypto isakmp key\s+)`,
+	`^((?:.*?\s)?(?:password|secret|md5|authentication-key)\s+)`,
+}
+
+// junosSecretLines match the secrets of the Junos configurations, in both the
+// hierarchical and the set formats
+var junosSecretLines = []string{
+	`^(.*?\s)"[^"]*"; ## SECRET-DATA$`,
+	`^(\s*(?:set\s+.*?\s)?community\s+)`,
+	`^((?:.*?\s)?(?:encrypted-password|secret|authentication-key|simple-password|pre-shared-key|ascii-text|hexadecimal-text)\s+)`,
+}
+
+var vendors = map[string]vendorConfig{
+	"cisco_ios": {
+		commands: []string{"show running-config"},
+		volatileLines: []string{
+			`^Building configuration\.\.\.`,
+			`^Current configuration : \d+ bytes`,
+			`^! Last configuration change at `,
+			`^! NVRAM config last updated at `,
+			`^! No configuration change since last restart`,
+			`^ntp clock-period `,
+		},
+		secretLines: iosSecretLines,
+	},
+	"cisco_nxos": {
+		commands: []string{"show running-config"},
+		volatileLines: []string{
+			`^!Command: show running-config`,
+			`^!Running configuration last done at: `,
+			`^!Time: `,
+		},
+		secretLines: iosSecretLines,
+	},
+	"arista_eos": {
+		commands: []string{"show running-config"},
+		volatileLines: []string{
+			`^! Command: show running-config`,
+			`^! Time: `,
+		},
+		secretLines: iosSecretLines,
+	},
+	"juniper_junos": {
+		commands: []string{"show configuration"},
+		volatileLines: []string{
+			`^## Last commit: `,
+			`^## Last changed: `,
+		},
+		secretLines: junosSecretLines,
+	},
+}
+
+// compileVolatileLines compiles the volatile lines of a vendor and the user defined ones
+func compileVolatileLines(patterns ...[]string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, group := range patterns {
+		for _, pattern := range group {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ignored line pattern `%s`: %w", pattern, err)
+			}
+			compiled = append(compiled, re)
+		}
+	}
+	return compiled, nil
+}
+
+// compileSecretLines compiles the patterns of the lines holding secrets of a
+// vendor, or of all the vendors if none is given
+func compileSecretLines(vendor string) []*regexp.Regexp {
+	var patterns []string
+	if config, ok := vendors[vendor]; ok {
+		patterns = config.secretLines
+	} else {
+		patterns = append(append(patterns, iosSecretLines...), junosSecretLines...)
+	}
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		compiled = append(compiled, regexp.MustCompile(pattern))
+	}
+	return compiled
+}
+
+// normalizeConfig removes the volatile lines, the trailing spaces and the leading and trailing empty lines,
+// and redacts the secrets so that they are neither sent in the diffs nor stored in the backups
+func normalizeConfig(config string, volatileLines []*regexp.Regexp, secretLines []*regexp.Regexp) string {
+	var lines []string
+	for _, line := range strings.Split(config, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if matchesAny(line, volatileLines) {
+			continue
+		}
+		lines = append(lines, redactLine(line, secretLines))
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// redactLine replaces the end of the line after the first group of the first matching secret pattern
+func redactLine(line string, secretLines []*regexp.Regexp) string {
+	for _, pattern := range secretLines {
+		if loc := pattern.FindStringSubmatchIndex(line); loc != nil {
+			return line[:loc[3]] + redactedValue
+		}
+	}
+	return line
+}
+
+func matchesAny(line string, patterns []*regexp.Regexp) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// diffConfigs returns the unified diff between two configurations
+func diffConfigs(previous string, current string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(previous),
+		B:        splitLines(current),
+		FromFile: "previous",
+		ToFile:   "current",
+		Context:  3,
+	})
+}
+
+// splitLines splits a configuration in lines keeping the line endings
+func splitLines(config string) []string {
+	lines := strings.SplitAfter(config, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// countChanges returns the number of added and removed lines of a unified diff
+func countChanges(diff string) (int, int) {
+	var added, removed int
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			// the file headers are before the first hunk
+			inHunk = true
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,164 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package configbackup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeConfig(t *testing.T) {
+	volatileLines, err := compileVolatileLines(vendors["cisco_ios"].volatileLines, []string{`^! Generated by `})
+	require.NoError(t, err)
+
+	config := "Building configuration...\r\n\r\nCurrent configuration : 1234 bytes\r\n!\r\n! Last configuration change at 10:01:02 UTC Mon Jan 1 2024\r\n! Generated by automation   \r\nhostname router-1   \r\ninterface Gi0/1\r\n description uplink\r\n\r\n"
+
+	assert.Equal(t, "!\nhostname router-1\ninterface Gi0/1\n description uplink\n", normalizeConfig(config, volatileLines, nil))
+
+	_, err = compileVolatileLines([]string{`^(`})
+	assert.ErrorContains(t, err, "invalid ignored line pattern `^(`")
+}
+
+func TestRedactSecrets(t *testing.T) {
+	for _, tt := range []struct {
+		vendor   string
+		config   string
+		expected string
+	}{
+		{
+			vendor: "cisco_ios",
+			config: `hostname router-1
+enable secret 9 $9$nhEmQVczB7dqsO$X.HsgL6x1il0RxkOSSvyQYwucySCt7qFm4v7pqCxkKM
+service password-encryption
+username admin privilege 15 secret 5 $1$mERr$hx5rVt7rPNoS4wqbXKX7m0
+snmp-server community public RO
+snmp-server host 10.0.0.1 version 2c private
+snmp-server user monitor monitoring v3 auth sha authpass priv aes 128 privpass
+tacacs-server host 10.0.0.2 key 7 0822455D0A16
+crypto isakmp key cisco123 address 10.0.0.3
+interface Gi0/1
+ description uplink
+ ip ospf message-digest-key 1 md5 7 0822455D0A16
+line vty 0 4
+ password 7 0822455D0A16
+key chain routing
+ key 1
+  key-string 7 0822455D0A16
+router bgp 65000
+ neighbor 10.0.0.4 password 7 0822455D0A16
+`,
+			expected: `hostname router-1
+enable secret <redacted>
+service password-encryption
+username admin privilege 15 secret <redacted>
+snmp-server community <redacted>
+snmp-server host 10.0.0.1 <redacted>
+snmp-server user monitor monitoring v3 auth <redacted>
+tacacs-server host 10.0.0.2 key <redacted>
+crypto isakmp key <redacted>
+interface Gi0/1
+ description uplink
+ ip ospf message-digest-key 1 md5 <redacted>
+line vty 0 4
+ password <redacted>
+key chain routing
+ key <redacted>
+  key-string <redacted>
+router bgp 65000
+ neighbor 10.0.0.4 password <redacted>
+`,
+		},
+		{
+			vendor: "cisco_nxos",
+			config: `username admin password 5 $5$JIHOHP$9b0KbKd2wZlI8yMhs0Ms0nOSSeCIz8PlSI2G1i2SwfC  role network-admin
+snmp-server user admin network-admin auth md5 0x0fb0ba6ab3f6bd5b0ac4b1fb3b9c5a39 priv 0x0fb0ba6ab3f6bd5b0ac4b1fb3b9c5a39 localizedkey
+feature tacacs+
+`,
+			expected: `username admin password <redacted>
+snmp-server user admin network-admin auth <redacted>
+feature tacacs+
+`,
+		},
+		{
+			vendor: "arista_eos",
+			config: `username admin privilege 15 role network-admin secret sha512 $6$Qf3sXOTEWDHvDZmP$1dv4tW
+snmp-server community public ro
+ntp authentication-key 1 md5 7 0822455D0A16
+`,
+			expected: `username admin privilege 15 role network-admin secret <redacted>
+snmp-server community <redacted>
+ntp authentication-key <redacted>
+`,
+		},
+		{
+			vendor: "juniper_junos",
+			config: `system {
+    root-authentication {
+        encrypted-password "$6$vOte1Kz3$rHdt1bdt"; ## SECRET-DATA
+    }
+    tacplus-server {
+        10.0.0.2 secret "$9$dKbwgoJUk.mT"; ## SECRET-DATA
+    }
+}
+snmp {
+    community public {
+        authorization read-only;
+    }
+}
+set security ike policy ike-pol pre-shared-key ascii-text "$9$H.fz9A0hSe"
+set snmp community private authorization read-write
+`,
+			expected: `system {
+    root-authentication {
+        encrypted-password <redacted>
+    }
+    tacplus-server {
+        10.0.0.2 secret <redacted>
+    }
+}
+snmp {
+    community <redacted>
+        authorization read-only;
+    }
+}
+set security ike policy ike-pol pre-shared-key <redacted>
+set snmp community <redacted>
+`,
+		},
+		{
+			// all the vendor patterns are used when the vendor isn't set
+			config:   "snmp-server community public RO\nset snmp community private\n",
+			expected: "snmp-server community <redacted>\nset snmp community <redacted>\n",
+		},
+	} {
+		t.Run(tt.vendor, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalizeConfig(tt.config, nil, compileSecretLines(tt.vendor)))
+		})
+	}
+}
+
+func TestDiffConfigs(t *testing.T) {
+	previous := "hostname router-1\ninterface Gi0/1\n description uplink\n shutdown\n"
+	current := "hostname router-1\ninterface Gi0/1\n description core uplink\n"
+
+	diff, err := diffConfigs(previous, current)
+	require.NoError(t, err)
+	assert.Equal(t, `--- previous
++++ current
+@@ -1,4 +1,3 @@
+ hostname router-1
+ interface Gi0/1
+- description uplink
+- shutdown
++ description core uplink
+`, diff)
+
+	added, removed := countChanges(diff)
+	assert.Equal(t, 1, added)
+	assert.Equal(t, 2, removed)
+}
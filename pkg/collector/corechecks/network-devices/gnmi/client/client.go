@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package client implements a gNMI dial-in client subscribing to the telemetry of network devices
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// Client is a gNMI client
+type Client struct {
+	target      string
+	username    string
+	password    string
+	credentials credentials.TransportCredentials
+}
+
+// ClientOptions are the functional options for the gNMI client
+type ClientOptions func(*Client)
+
+// NewClient creates a new gNMI client, connections use TLS unless WithoutTLS is used
+func NewClient(target, username, password string, options ...ClientOptions) (*Client, error) {
+	if target == "" {
+		return nil, fmt.Errorf("invalid target")
+	}
+	client := &Client{
+		target:      target,
+		username:    username,
+		password:    password,
+		credentials: credentials.NewTLS(&tls.Config{}),
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client, nil
+}
+
+// WithTLSConfig is a functional option to set the TLS config of the connections
+func WithTLSConfig(insecureSkipVerify bool, caFile, certFile, keyFile, serverName string) (ClientOptions, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: insecureSkipVerify,
+		ServerName:         serverName,
+	}
+	if caFile != "" {
+		caCert, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in %s", caFile)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return func(c *Client) {
+		c.credentials = credentials.NewTLS(tlsConfig)
+	}, nil
+}
+
+// WithoutTLS is a functional option to connect without TLS
+func WithoutTLS() ClientOptions {
+	return func(c *Client) {
+		c.credentials = insecure.NewCredentials()
+	}
+}
+
+// Subscribe subscribes to the paths in STREAM mode and calls handler with each response,
+// it blocks until the context is canceled or the stream fails
+func (c *Client) Subscribe(ctx context.Context, prefix Path, subscriptions []Subscription, encoding Encoding, handler func(SubscribeResponse)) error {
+	conn, err := grpc.NewClient(c.target, grpc.WithTransportCredentials(c.credentials))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if c.username != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "username", c.username, "password", c.password)
+	}
+
+	stream, err := gnmi.NewGNMIClient(conn).Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open subscribe stream to %s: %w", c.target, err)
+	}
+	if err := stream.Send(newSubscribeRequest(prefix, subscriptions, encoding)); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", c.target, err)
+	}
+	for {
+		message, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("subscribe stream closed by %s", c.target)
+			}
+			return fmt.Errorf("subscribe stream to %s failed: %w", c.target, err)
+		}
+		response, err := fromSubscribeResponse(message)
+		if err != nil {
+			return fmt.Errorf("invalid response from %s: %w", c.target, err)
+		}
+		handler(response)
+	}
+}
@@ -0,0 +1,287 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+//go:build test
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func mustParsePath(t *testing.T, str string) Path {
+	path, err := ParsePath(str)
+	require.NoError(t, err)
+	return path
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		expectedPath  Path
+		expectedError string
+	}{
+		{
+			name: "simple path",
+			path: "/system/state/hostname",
+			expectedPath: Path{Elems: []PathElem{
+				{Name: "system"}, {Name: "state"}, {Name: "hostname"},
+			}},
+		},
+		{
+			name: "keys and origin",
+			path: "openconfig:/interfaces/interface[name=Ethernet1/1]/subinterfaces/subinterface[index=0][vlan=*]",
+			expectedPath: Path{Origin: "openconfig", Elems: []PathElem{
+				{Name: "interfaces"},
+				{Name: "interface", Keys: map[string]string{"name": "Ethernet1/1"}},
+				{Name: "subinterfaces"},
+				{Name: "subinterface", Keys: map[string]string{"index": "0", "vlan": "*"}},
+			}},
+		},
+		{
+			name:         "root",
+			path:         "/",
+			expectedPath: Path{},
+		},
+		{
+			name:          "missing bracket",
+			path:          "/interfaces/interface[name=eth0",
+			expectedError: "missing `]` in path element `interface`",
+		},
+		{
+			name:          "invalid key",
+			path:          "/interfaces/interface[name]",
+			expectedError: "invalid key `name` in path element `interface`",
+		},
+		{
+			name:          "empty element",
+			path:          "/interfaces//interface",
+			expectedError: "empty element in path",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := ParsePath(tt.path)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPath, path)
+		})
+	}
+
+	assert.Equal(t, "openconfig:/interfaces/interface[name=eth0]/state", mustParsePath(t, "openconfig:/interfaces/interface[name=eth0]/state").String())
+}
+
+func TestSubscribeRequestConversion(t *testing.T) {
+	subscriptions := []Subscription{
+		{Path: mustParsePath(t, "/interfaces/interface[name=*]/state/counters"), Mode: Sample, SampleInterval: 10 * time.Second},
+		{Path: mustParsePath(t, "/system/state/hostname"), Mode: OnChange},
+	}
+	prefix := mustParsePath(t, "openconfig:/")
+
+	request := newSubscribeRequest(prefix, subscriptions, EncodingJSONIETF)
+	assert.Equal(t, gnmi.SubscriptionList_STREAM, request.GetSubscribe().GetMode())
+	assert.Equal(t, gnmi.Encoding_JSON_IETF, request.GetSubscribe().GetEncoding())
+	assert.Equal(t, uint64(10*time.Second), request.GetSubscribe().GetSubscription()[0].GetSampleInterval())
+
+	decodedPrefix, decodedSubscriptions, encoding, err := fromSubscribeRequest(request)
+	require.NoError(t, err)
+	assert.Equal(t, prefix, decodedPrefix)
+	assert.Equal(t, subscriptions, decodedSubscriptions)
+	assert.Equal(t, EncodingJSONIETF, encoding)
+
+	_, _, _, err = fromSubscribeRequest(&gnmi.SubscribeRequest{})
+	assert.Error(t, err)
+}
+
+func TestSubscribeResponseConversion(t *testing.T) {
+	notification := Notification{
+		Timestamp: time.Unix(1700000000, 123),
+		Prefix:    mustParsePath(t, "/interfaces/interface[name=eth0]"),
+		Updates: []Update{
+			{Path: mustParsePath(t, "state/counters/in-octets"), Value: uint64(1234)},
+			{Path: mustParsePath(t, "state/mtu"), Value: int64(-1)},
+			{Path: mustParsePath(t, "state/description"), Value: "uplink"},
+			{Path: mustParsePath(t, "state/enabled"), Value: true},
+			{Path: mustParsePath(t, "state/rate"), Value: 1.5},
+			{Path: mustParsePath(t, "state/raw"), Value: []byte{1, 2}},
+			{Path: mustParsePath(t, "state/json"), Value: json.RawMessage(`{"a":"1"}`)},
+		},
+		Deletes: []Path{mustParsePath(t, "state/old")},
+	}
+	message, err := toNotificationResponse(notification)
+	require.NoError(t, err)
+
+	response, err := fromSubscribeResponse(message)
+	require.NoError(t, err)
+	assert.False(t, response.SyncResponse)
+	require.NotNil(t, response.Notification)
+	assert.Equal(t, notification.Timestamp, response.Notification.Timestamp)
+	assert.Equal(t, notification.Prefix, response.Notification.Prefix)
+	assert.Equal(t, notification.Deletes, response.Notification.Deletes)
+
+	var values []interface{}
+	for _, update := range response.Notification.Updates {
+		values = append(values, update.Value)
+	}
+	assert.Equal(t, []interface{}{uint64(1234), int64(-1), "uplink", true, 1.5, []byte{1, 2}, map[string]interface{}{"a": "1"}}, values)
+
+	response, err = fromSubscribeResponse(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}})
+	require.NoError(t, err)
+	assert.True(t, response.SyncResponse)
+	assert.Nil(t, response.Notification)
+
+	_, err = fromSubscribeResponse(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Error{Error: &gnmi.Error{Code: 3, Message: "bad path"}}})
+	assert.EqualError(t, err, "target error 3: bad path")
+}
+
+func TestDecodeTypedValue(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         *gnmi.TypedValue
+		expectedValue interface{}
+		expectedError string
+	}{
+		{
+			name:          "decimal",
+			value:         &gnmi.TypedValue{Value: &gnmi.TypedValue_DecimalVal{DecimalVal: &gnmi.Decimal64{Digits: 12345, Precision: 2}}},
+			expectedValue: 123.45,
+		},
+		{
+			name:          "float",
+			value:         &gnmi.TypedValue{Value: &gnmi.TypedValue_FloatVal{FloatVal: 0.5}},
+			expectedValue: 0.5,
+		},
+		{
+			name:          "ascii",
+			value:         &gnmi.TypedValue{Value: &gnmi.TypedValue_AsciiVal{AsciiVal: "up"}},
+			expectedValue: "up",
+		},
+		{
+			name: "leaf list",
+			value: &gnmi.TypedValue{Value: &gnmi.TypedValue_LeaflistVal{LeaflistVal: &gnmi.ScalarArray{Element: []*gnmi.TypedValue{
+				{Value: &gnmi.TypedValue_StringVal{StringVal: "a"}},
+				{Value: &gnmi.TypedValue_UintVal{UintVal: 2}},
+			}}}},
+			expectedValue: []interface{}{"a", uint64(2)},
+		},
+		{
+			name:          "any",
+			value:         &gnmi.TypedValue{Value: &gnmi.TypedValue_AnyVal{AnyVal: &anypb.Any{}}},
+			expectedError: "unsupported value of type *gnmi.TypedValue_AnyVal",
+		},
+		{
+			name: "leaf list with unsupported element",
+			value: &gnmi.TypedValue{Value: &gnmi.TypedValue_LeaflistVal{LeaflistVal: &gnmi.ScalarArray{Element: []*gnmi.TypedValue{
+				{Value: &gnmi.TypedValue_AnyVal{AnyVal: &anypb.Any{}}},
+			}}}},
+			expectedError: "leaf list element: unsupported value of type *gnmi.TypedValue_AnyVal",
+		},
+		{
+			name:          "missing value",
+			value:         nil,
+			expectedError: "missing value",
+		},
+		{
+			name:          "invalid json",
+			value:         &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: []byte("{")}},
+			expectedError: "invalid json value: unexpected end of JSON input",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := decodeTypedValue(tt.value)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedValue, value)
+		})
+	}
+}
+
+func TestClientSubscribeUnsupportedValue(t *testing.T) {
+	server, err := SetupMockServer(Notification{
+		Updates: []Update{
+			{Path: mustParsePath(t, "/system/state/boot-info"), Value: &gnmi.TypedValue{Value: &gnmi.TypedValue_AnyVal{AnyVal: &anypb.Any{}}}},
+		},
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	client, err := NewClient(server.Addr, "", "", WithoutTLS())
+	require.NoError(t, err)
+
+	err = client.Subscribe(context.Background(), Path{}, nil, EncodingProto, func(SubscribeResponse) {})
+	assert.ErrorContains(t, err, "path /system/state/boot-info: unsupported value of type *gnmi.TypedValue_AnyVal")
+}
+
+func TestClientSubscribe(t *testing.T) {
+	server, err := SetupMockServer(Notification{
+		Timestamp: time.Unix(1700000000, 0),
+		Updates: []Update{
+			{Path: mustParsePath(t, "/system/state/hostname"), Value: "router-1"},
+		},
+	})
+	require.NoError(t, err)
+	defer server.Close()
+
+	client, err := NewClient(server.Addr, "admin", "secret", WithoutTLS())
+	require.NoError(t, err)
+
+	subscriptions := []Subscription{{Path: mustParsePath(t, "/system/state/hostname"), Mode: OnChange}}
+	responses := make(chan SubscribeResponse, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- client.Subscribe(ctx, Path{}, subscriptions, EncodingProto, func(response SubscribeResponse) {
+			responses <- response
+		})
+	}()
+
+	response := <-responses
+	require.NotNil(t, response.Notification)
+	assert.Equal(t, "router-1", response.Notification.Updates[0].Value)
+	response = <-responses
+	assert.True(t, response.SyncResponse)
+
+	require.NoError(t, server.Send(Notification{
+		Timestamp: time.Unix(1700000010, 0),
+		Updates: []Update{
+			{Path: mustParsePath(t, "/system/state/hostname"), Value: "router-2"},
+		},
+	}))
+	response = <-responses
+	require.NotNil(t, response.Notification)
+	assert.Equal(t, "router-2", response.Notification.Updates[0].Value)
+
+	receivedSubscriptions, encoding := server.Subscriptions()
+	assert.Equal(t, subscriptions, receivedSubscriptions)
+	assert.Equal(t, EncodingProto, encoding)
+	assert.Equal(t, "admin", server.Username())
+
+	cancel()
+	assert.Error(t, <-done)
+}
+
+func TestNewClientErrors(t *testing.T) {
+	_, err := NewClient("", "", "")
+	assert.EqualError(t, err, "invalid target")
+
+	_, err = WithTLSConfig(false, "/does/not/exist.pem", "", "", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,161 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// newSubscribeRequest returns a SubscribeRequest subscribing to the paths in STREAM mode
+func newSubscribeRequest(prefix Path, subscriptions []Subscription, encoding Encoding) *gnmi.SubscribeRequest {
+	list := &gnmi.SubscriptionList{
+		Prefix:   toProtoPath(prefix),
+		Mode:     gnmi.SubscriptionList_STREAM,
+		Encoding: gnmi.Encoding(encoding),
+	}
+	for _, subscription := range subscriptions {
+		list.Subscription = append(list.Subscription, &gnmi.Subscription{
+			Path:           toProtoPath(subscription.Path),
+			Mode:           gnmi.SubscriptionMode(subscription.Mode),
+			SampleInterval: uint64(subscription.SampleInterval.Nanoseconds()),
+		})
+	}
+	return &gnmi.SubscribeRequest{Request: &gnmi.SubscribeRequest_Subscribe{Subscribe: list}}
+}
+
+// fromSubscribeRequest returns the prefix, subscriptions and encoding of a SubscribeRequest
+func fromSubscribeRequest(request *gnmi.SubscribeRequest) (Path, []Subscription, Encoding, error) {
+	list := request.GetSubscribe()
+	if list == nil {
+		return Path{}, nil, 0, fmt.Errorf("subscribe request without subscription list")
+	}
+	subscriptions := make([]Subscription, 0, len(list.GetSubscription()))
+	for _, subscription := range list.GetSubscription() {
+		subscriptions = append(subscriptions, Subscription{
+			Path:           fromProtoPath(subscription.GetPath()),
+			Mode:           SubscriptionMode(subscription.GetMode()),
+			SampleInterval: time.Duration(subscription.GetSampleInterval()),
+		})
+	}
+	return fromProtoPath(list.GetPrefix()), subscriptions, Encoding(list.GetEncoding()), nil
+}
+
+// fromSubscribeResponse converts a SubscribeResponse, values are decoded with decodeTypedValue
+func fromSubscribeResponse(response *gnmi.SubscribeResponse) (SubscribeResponse, error) {
+	switch r := response.GetResponse().(type) {
+	case *gnmi.SubscribeResponse_Update:
+		notification, err := fromProtoNotification(r.Update)
+		if err != nil {
+			return SubscribeResponse{}, err
+		}
+		return SubscribeResponse{Notification: &notification}, nil
+	case *gnmi.SubscribeResponse_SyncResponse:
+		return SubscribeResponse{SyncResponse: r.SyncResponse}, nil
+	case *gnmi.SubscribeResponse_Error:
+		targetErr := r.Error //nolint:staticcheck // deprecated, still sent by older targets
+		return SubscribeResponse{}, fmt.Errorf("target error %d: %s", targetErr.GetCode(), targetErr.GetMessage())
+	default:
+		return SubscribeResponse{}, fmt.Errorf("unsupported response %T", r)
+	}
+}
+
+func fromProtoNotification(notification *gnmi.Notification) (Notification, error) {
+	converted := Notification{
+		Timestamp: time.Unix(0, notification.GetTimestamp()),
+		Prefix:    fromProtoPath(notification.GetPrefix()),
+	}
+	for _, update := range notification.GetUpdate() {
+		path := fromProtoPath(update.GetPath())
+		value, err := decodeTypedValue(update.GetVal())
+		if err != nil {
+			return Notification{}, fmt.Errorf("path %s: %w", converted.Prefix.Join(path), err)
+		}
+		converted.Updates = append(converted.Updates, Update{Path: path, Value: value})
+	}
+	for _, path := range notification.GetDelete() {
+		converted.Deletes = append(converted.Deletes, fromProtoPath(path))
+	}
+	return converted, nil
+}
+
+func toProtoPath(path Path) *gnmi.Path {
+	converted := &gnmi.Path{Origin: path.Origin, Target: path.Target}
+	for _, elem := range path.Elems {
+		converted.Elem = append(converted.Elem, &gnmi.PathElem{Name: elem.Name, Key: elem.Keys})
+	}
+	return converted
+}
+
+func fromProtoPath(path *gnmi.Path) Path {
+	converted := Path{Origin: path.GetOrigin(), Target: path.GetTarget()}
+	// deprecated string elements
+	for _, name := range path.GetElement() { //nolint:staticcheck // deprecated, still sent by older targets
+		converted.Elems = append(converted.Elems, PathElem{Name: name})
+	}
+	for _, elem := range path.GetElem() {
+		converted.Elems = append(converted.Elems, PathElem{Name: elem.GetName(), Keys: elem.GetKey()})
+	}
+	return converted
+}
+
+// decodeTypedValue decodes a value to string, int64, uint64, bool, float64, []byte,
+// []interface{} for leaf lists, or to the types of encoding/json for JSON values
+func decodeTypedValue(value *gnmi.TypedValue) (interface{}, error) {
+	switch v := value.GetValue().(type) {
+	case *gnmi.TypedValue_StringVal:
+		return v.StringVal, nil
+	case *gnmi.TypedValue_AsciiVal:
+		return v.AsciiVal, nil
+	case *gnmi.TypedValue_IntVal:
+		return v.IntVal, nil
+	case *gnmi.TypedValue_UintVal:
+		return v.UintVal, nil
+	case *gnmi.TypedValue_BoolVal:
+		return v.BoolVal, nil
+	case *gnmi.TypedValue_BytesVal:
+		return v.BytesVal, nil
+	case *gnmi.TypedValue_ProtoBytes:
+		return v.ProtoBytes, nil
+	case *gnmi.TypedValue_FloatVal:
+		return float64(v.FloatVal), nil //nolint:staticcheck // deprecated, still sent by older targets
+	case *gnmi.TypedValue_DoubleVal:
+		return v.DoubleVal, nil
+	case *gnmi.TypedValue_DecimalVal:
+		decimal := v.DecimalVal //nolint:staticcheck // deprecated, still sent by older targets
+		return float64(decimal.GetDigits()) / math.Pow10(int(decimal.GetPrecision())), nil
+	case *gnmi.TypedValue_LeaflistVal:
+		elements := make([]interface{}, 0, len(v.LeaflistVal.GetElement()))
+		for _, element := range v.LeaflistVal.GetElement() {
+			decoded, err := decodeTypedValue(element)
+			if err != nil {
+				return nil, fmt.Errorf("leaf list element: %w", err)
+			}
+			elements = append(elements, decoded)
+		}
+		return elements, nil
+	case *gnmi.TypedValue_JsonVal:
+		return decodeJSON(v.JsonVal)
+	case *gnmi.TypedValue_JsonIetfVal:
+		return decodeJSON(v.JsonIetfVal)
+	case nil:
+		return nil, fmt.Errorf("missing value")
+	default:
+		return nil, fmt.Errorf("unsupported value of type %T", v)
+	}
+}
+
+func decodeJSON(b []byte) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(b, &value); err != nil {
+		return nil, fmt.Errorf("invalid json value: %w", err)
+	}
+	return value, nil
+}
@@ -0,0 +1,164 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+//go:build test
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MockServer is a gNMI target sending notifications to its subscribers
+type MockServer struct {
+	gnmi.UnimplementedGNMIServer
+	Addr string
+
+	server        *grpc.Server
+	notifications []Notification
+
+	mu            sync.Mutex
+	subscriptions []Subscription
+	encoding      Encoding
+	username      string
+	streams       chan func(Notification) error
+}
+
+// SetupMockServer starts a gNMI target sending the notifications followed by a sync response
+// when a client subscribes, later notifications are sent with MockServer.Send
+func SetupMockServer(notifications ...Notification) (*MockServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &MockServer{
+		Addr:          listener.Addr().String(),
+		notifications: notifications,
+		streams:       make(chan func(Notification) error, 10),
+	}
+	s.server = grpc.NewServer()
+	gnmi.RegisterGNMIServer(s.server, s)
+	go s.server.Serve(listener) //nolint:errcheck
+	return s, nil
+}
+
+// Close stops the target
+func (s *MockServer) Close() {
+	s.server.Stop()
+}
+
+// Subscriptions returns the subscriptions of the last subscriber
+func (s *MockServer) Subscriptions() ([]Subscription, Encoding) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.subscriptions, s.encoding
+}
+
+// Username returns the username sent by the last subscriber
+func (s *MockServer) Username() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.username
+}
+
+// Send sends a notification to the next subscriber waiting for notifications
+func (s *MockServer) Send(notification Notification) error {
+	send := <-s.streams
+	err := send(notification)
+	s.streams <- send
+	return err
+}
+
+// Subscribe implements the gNMI Subscribe RPC
+func (s *MockServer) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	request, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	_, subscriptions, encoding, err := fromSubscribeRequest(request)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	s.mu.Lock()
+	s.subscriptions = subscriptions
+	s.encoding = encoding
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok && len(md.Get("username")) > 0 {
+		s.username = md.Get("username")[0]
+	}
+	s.mu.Unlock()
+
+	send := func(notification Notification) error {
+		response, err := toNotificationResponse(notification)
+		if err != nil {
+			return err
+		}
+		return stream.Send(response)
+	}
+	for _, notification := range s.notifications {
+		if err := send(notification); err != nil {
+			return err
+		}
+	}
+	syncResponse := &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}}
+	if err := stream.Send(syncResponse); err != nil {
+		return err
+	}
+	s.streams <- send
+	<-stream.Context().Done()
+	return nil
+}
+
+// toNotificationResponse returns a SubscribeResponse holding the notification
+func toNotificationResponse(notification Notification) (*gnmi.SubscribeResponse, error) {
+	converted := &gnmi.Notification{
+		Timestamp: notification.Timestamp.UnixNano(),
+		Prefix:    toProtoPath(notification.Prefix),
+	}
+	for _, update := range notification.Updates {
+		value, err := toTypedValue(update.Value)
+		if err != nil {
+			return nil, err
+		}
+		converted.Update = append(converted.Update, &gnmi.Update{Path: toProtoPath(update.Path), Val: value})
+	}
+	for _, path := range notification.Deletes {
+		converted.Delete = append(converted.Delete, toProtoPath(path))
+	}
+	return &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: converted}}, nil
+}
+
+func toTypedValue(value interface{}) (*gnmi.TypedValue, error) {
+	switch v := value.(type) {
+	case *gnmi.TypedValue:
+		return v, nil
+	case string:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: v}}, nil
+	case int64:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: v}}, nil
+	case int:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: int64(v)}}, nil
+	case uint64:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: v}}, nil
+	case bool:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_BoolVal{BoolVal: v}}, nil
+	case []byte:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_BytesVal{BytesVal: v}}, nil
+	case float64:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_DoubleVal{DoubleVal: v}}, nil
+	case json.RawMessage:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: v}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v of type %T", value, value)
+	}
+}
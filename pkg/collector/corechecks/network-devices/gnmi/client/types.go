@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// SubscriptionMode is the mode of a gNMI subscription
+type SubscriptionMode int
+
+// SubscriptionMode enums
+const (
+	TargetDefined = SubscriptionMode(gnmi.SubscriptionMode_TARGET_DEFINED)
+	OnChange      = SubscriptionMode(gnmi.SubscriptionMode_ON_CHANGE)
+	Sample        = SubscriptionMode(gnmi.SubscriptionMode_SAMPLE)
+)
+
+// Encoding is the encoding of the values sent by the target
+type Encoding int
+
+// Encoding enums
+const (
+	EncodingJSON     = Encoding(gnmi.Encoding_JSON)
+	EncodingBytes    = Encoding(gnmi.Encoding_BYTES)
+	EncodingProto    = Encoding(gnmi.Encoding_PROTO)
+	EncodingASCII    = Encoding(gnmi.Encoding_ASCII)
+	EncodingJSONIETF = Encoding(gnmi.Encoding_JSON_IETF)
+)
+
+var encodingNames = map[string]Encoding{
+	"json":      EncodingJSON,
+	"bytes":     EncodingBytes,
+	"proto":     EncodingProto,
+	"ascii":     EncodingASCII,
+	"json_ietf": EncodingJSONIETF,
+}
+
+// ParseEncoding returns the encoding with the given name
+func ParseEncoding(name string) (Encoding, error) {
+	encoding, ok := encodingNames[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown encoding `%s`", name)
+	}
+	return encoding, nil
+}
+
+// PathElem is an element of a gNMI path, with its keys
+type PathElem struct {
+	Name string
+	Keys map[string]string
+}
+
+// Path is a gNMI path
+type Path struct {
+	Origin string
+	Elems  []PathElem
+	Target string
+}
+
+// Subscription is a path to subscribe to
+type Subscription struct {
+	Path           Path
+	Mode           SubscriptionMode
+	SampleInterval time.Duration
+}
+
+// Update is the value of a leaf, values are decoded to string, int64, uint64,
+// bool, float64, []byte, or to the types of encoding/json for JSON values
+type Update struct {
+	Path  Path
+	Value interface{}
+}
+
+// Notification is a set of updates and deletes sent by the target
+type Notification struct {
+	Timestamp time.Time
+	Prefix    Path
+	Updates   []Update
+	Deletes   []Path
+}
+
+// SubscribeResponse is a notification, or the signal that the target sent
+// all the values of the subscriptions once
+type SubscribeResponse struct {
+	Notification *Notification
+	SyncResponse bool
+}
+
+// ParsePath parses a path in the `/elem/elem[key=value]` format, `origin:` may prefix the path
+func ParsePath(str string) (Path, error) {
+	var path Path
+	if i := strings.Index(str, ":"); i > 0 && !strings.ContainsAny(str[:i], "/[") {
+		path.Origin = str[:i]
+		str = str[i+1:]
+	}
+	str = strings.TrimPrefix(str, "/")
+	for str != "" {
+		end := strings.IndexAny(str, "/[")
+		if end == -1 {
+			end = len(str)
+		}
+		elem := PathElem{Name: str[:end]}
+		if elem.Name == "" {
+			return Path{}, fmt.Errorf("empty element in path")
+		}
+		str = str[end:]
+		for strings.HasPrefix(str, "[") {
+			closing := strings.Index(str, "]")
+			if closing == -1 {
+				return Path{}, fmt.Errorf("missing `]` in path element `%s`", elem.Name)
+			}
+			key, value, ok := strings.Cut(str[1:closing], "=")
+			if !ok || key == "" {
+				return Path{}, fmt.Errorf("invalid key `%s` in path element `%s`", str[1:closing], elem.Name)
+			}
+			if elem.Keys == nil {
+				elem.Keys = make(map[string]string)
+			}
+			elem.Keys[key] = value
+			str = str[closing+1:]
+		}
+		if str != "" && !strings.HasPrefix(str, "/") {
+			return Path{}, fmt.Errorf("unexpected `%s` after path element `%s`", str, elem.Name)
+		}
+		str = strings.TrimPrefix(str, "/")
+		path.Elems = append(path.Elems, elem)
+	}
+	return path, nil
+}
+
+// String formats the path in the `/elem/elem[key=value]` format
+func (p Path) String() string {
+	var b strings.Builder
+	if p.Origin != "" {
+		b.WriteString(p.Origin + ":")
+	}
+	for _, elem := range p.Elems {
+		b.WriteString("/" + elem.Name)
+		for _, key := range sortedKeys(elem.Keys) {
+			b.WriteString("[" + key + "=" + elem.Keys[key] + "]")
+		}
+	}
+	if len(p.Elems) == 0 {
+		b.WriteString("/")
+	}
+	return b.String()
+}
+
+// Join returns the path with the elements of other appended
+func (p Path) Join(other Path) Path {
+	joined := Path{Origin: p.Origin, Target: p.Target}
+	if joined.Origin == "" {
+		joined.Origin = other.Origin
+	}
+	joined.Elems = make([]PathElem, 0, len(p.Elems)+len(other.Elems))
+	joined.Elems = append(joined.Elems, p.Elems...)
+	joined.Elems = append(joined.Elems, other.Elems...)
+	return joined
+}
+
+func sortedKeys(keys map[string]string) []string {
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
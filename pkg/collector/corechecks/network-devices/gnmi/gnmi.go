@@ -0,0 +1,278 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package gnmi implements NDM gNMI streaming telemetry corecheck
+package gnmi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/client"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/profile"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/report"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/snmp/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/optional"
+)
+
+const (
+	// CheckName is the name of the check
+	CheckName            = "gnmi"
+	defaultCheckInterval = 15 * time.Second
+	defaultPort          = 9339
+	defaultEncoding      = "proto"
+	minReconnectDelay    = 5 * time.Second
+	maxReconnectDelay    = 2 * time.Minute
+)
+
+// Configuration for the gNMI check
+type checkCfg struct {
+	IPAddress             string                    `yaml:"ip_address"`
+	Port                  int                       `yaml:"port"`
+	Username              string                    `yaml:"username"`
+	Password              string                    `yaml:"password"`
+	Namespace             string                    `yaml:"namespace"`
+	UsePlaintext          bool                      `yaml:"use_plaintext"`
+	Insecure              bool                      `yaml:"insecure"`
+	CAFile                string                    `yaml:"ca_file"`
+	CertFile              string                    `yaml:"cert_file"`
+	KeyFile               string                    `yaml:"key_file"`
+	TLSServerName         string                    `yaml:"tls_server_name"`
+	Encoding              string                    `yaml:"encoding"`
+	Profile               string                    `yaml:"profile"`
+	Metrics               []profile.MetricsConfig   `yaml:"metrics"`
+	MetricTags            []profile.MetricTagConfig `yaml:"metric_tags"`
+	Tags                  []string                  `yaml:"tags"`
+	SendNDMMetadata       *bool                     `yaml:"send_ndm_metadata"`
+	MinCollectionInterval int                       `yaml:"min_collection_interval"`
+}
+
+// GNMICheck subscribes to the telemetry of a device and reports the last values on each run
+type GNMICheck struct {
+	core.CheckBase
+	interval      time.Duration
+	config        checkCfg
+	client        *client.Client
+	encoding      client.Encoding
+	matcher       *profile.Matcher
+	store         *report.Store
+	metricsSender *report.GNMISender
+
+	mu               sync.Mutex
+	cancel           context.CancelFunc
+	subscriptionErr  error
+	subscriptionDone chan struct{}
+}
+
+// Run reports the last values sent by the device, the subscription is started on the first run
+func (c *GNMICheck) Run() error {
+	c.startSubscription()
+
+	reachable := c.store.Synced()
+	deviceTags := c.metricsSender.GetDeviceTags(c.store.DeviceTags())
+	c.metricsSender.SendDeviceStatus(reachable, deviceTags)
+	if reachable {
+		c.metricsSender.SendMetrics(c.store.MetricSamples(), deviceTags)
+	}
+	if *c.config.SendNDMMetadata {
+		c.metricsSender.SendMetadata(c.store.DeviceFields(), c.store.Interfaces(), deviceTags, reachable)
+	}
+	c.metricsSender.Commit()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !reachable && c.subscriptionErr != nil {
+		return c.subscriptionErr
+	}
+	return nil
+}
+
+// Configure the gNMI check
+func (c *GNMICheck) Configure(senderManager sender.SenderManager, integrationConfigDigest uint64, rawInstance integration.Data, rawInitConfig integration.Data, source string) error {
+	// Must be called before c.CommonConfigure
+	c.BuildID(integrationConfigDigest, rawInstance, rawInitConfig)
+
+	err := c.CommonConfigure(senderManager, rawInitConfig, rawInstance, source)
+	if err != nil {
+		return err
+	}
+
+	sender, err := c.GetSender()
+	if err != nil {
+		return err
+	}
+
+	var instanceConfig checkCfg
+
+	// Set defaults before unmarshalling
+	instanceConfig.Port = defaultPort
+	instanceConfig.Encoding = defaultEncoding
+	instanceConfig.Profile = profile.DefaultProfile
+	instanceConfig.SendNDMMetadata = boolPointer(true)
+
+	err = yaml.Unmarshal(rawInstance, &instanceConfig)
+	if err != nil {
+		return err
+	}
+	c.config = instanceConfig
+
+	if c.config.IPAddress == "" {
+		return errors.New("ip_address is required")
+	}
+
+	if c.config.Namespace == "" {
+		c.config.Namespace = "default"
+	} else {
+		namespace, err := utils.NormalizeNamespace(c.config.Namespace)
+		if err != nil {
+			return err
+		}
+		c.config.Namespace = namespace
+	}
+
+	if c.config.MinCollectionInterval != 0 {
+		c.interval = time.Second * time.Duration(c.config.MinCollectionInterval)
+	}
+
+	c.encoding, err = client.ParseEncoding(c.config.Encoding)
+	if err != nil {
+		return err
+	}
+
+	definition, err := profile.GetProfile(config.Datadog().GetString("confd_path"), c.config.Profile)
+	if err != nil {
+		return err
+	}
+	definition.Metrics = append(definition.Metrics, c.config.Metrics...)
+	definition.MetricTags = append(definition.MetricTags, c.config.MetricTags...)
+	c.matcher, err = definition.Compile()
+	if err != nil {
+		return fmt.Errorf("invalid profile `%s`: %w", c.config.Profile, err)
+	}
+
+	clientOptions, err := c.buildClientOptions()
+	if err != nil {
+		return err
+	}
+	target := net.JoinHostPort(c.config.IPAddress, strconv.Itoa(c.config.Port))
+	c.client, err = client.NewClient(target, c.config.Username, c.config.Password, clientOptions...)
+	if err != nil {
+		return err
+	}
+
+	c.store = report.NewStore(c.matcher)
+	c.metricsSender = report.NewGNMISender(sender, c.config.Namespace, c.config.IPAddress, c.config.Tags)
+
+	return nil
+}
+
+func (c *GNMICheck) buildClientOptions() ([]client.ClientOptions, error) {
+	if c.config.UsePlaintext {
+		return []client.ClientOptions{client.WithoutTLS()}, nil
+	}
+	options, err := client.WithTLSConfig(c.config.Insecure, c.config.CAFile, c.config.CertFile, c.config.KeyFile, c.config.TLSServerName)
+	if err != nil {
+		return nil, err
+	}
+	return []client.ClientOptions{options}, nil
+}
+
+// startSubscription starts the subscription to the device if it isn't running
+func (c *GNMICheck) startSubscription() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancel != nil {
+		return
+	}
+	// values of a previous subscription are not reported
+	c.store.Reset()
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.subscriptionDone = make(chan struct{})
+	go c.subscribe(ctx, c.subscriptionDone)
+}
+
+// subscribe subscribes to the device until the context is canceled, reconnecting with a backoff
+func (c *GNMICheck) subscribe(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	delay := minReconnectDelay
+	for {
+		c.store.Reset()
+		start := time.Now()
+		err := c.client.Subscribe(ctx, client.Path{}, c.matcher.Subscriptions(), c.encoding, c.handleResponse)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warnf("gNMI device %s: %s, reconnecting in %s", c.config.IPAddress, err, delay)
+		c.mu.Lock()
+		c.subscriptionErr = err
+		c.mu.Unlock()
+
+		if time.Since(start) > maxReconnectDelay {
+			delay = minReconnectDelay
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxReconnectDelay)
+	}
+}
+
+func (c *GNMICheck) handleResponse(response client.SubscribeResponse) {
+	c.store.Handle(response)
+	if response.SyncResponse {
+		c.mu.Lock()
+		c.subscriptionErr = nil
+		c.mu.Unlock()
+	}
+}
+
+// Cancel stops the subscription
+func (c *GNMICheck) Cancel() {
+	c.mu.Lock()
+	cancel, done := c.cancel, c.subscriptionDone
+	c.cancel = nil
+	c.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Interval returns the scheduling time for the check
+func (c *GNMICheck) Interval() time.Duration {
+	return c.interval
+}
+
+func boolPointer(b bool) *bool {
+	return &b
+}
+
+// Factory creates a new check factory
+func Factory() optional.Option[func() check.Check] {
+	return optional.NewOption(newCheck)
+}
+
+func newCheck() check.Check {
+	return &GNMICheck{
+		CheckBase: core.NewCheckBase(CheckName),
+		interval:  defaultCheckInterval,
+	}
+}
@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+//go:build test
+
+package gnmi
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/comp/aggregator/demultiplexer"
+	"github.com/DataDog/datadog-agent/comp/aggregator/demultiplexer/demultiplexerimpl"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/client"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+type deps struct {
+	fx.In
+	Demultiplexer demultiplexer.Mock
+}
+
+func createDeps(t *testing.T) deps {
+	return fxutil.Test[deps](t, demultiplexerimpl.MockModule(), defaultforwarder.MockModule(), core.MockBundle())
+}
+
+func mustParsePath(t *testing.T, str string) client.Path {
+	path, err := client.ParsePath(str)
+	require.NoError(t, err)
+	return path
+}
+
+func TestGNMICheck(t *testing.T) {
+	server, err := client.SetupMockServer(client.Notification{
+		Timestamp: time.Now(),
+		Prefix:    mustParsePath(t, "/interfaces/interface[name=Ethernet1]"),
+		Updates: []client.Update{
+			{Path: mustParsePath(t, "state/counters/in-octets"), Value: uint64(1000)},
+			{Path: mustParsePath(t, "state/oper-status"), Value: "UP"},
+			{Path: mustParsePath(t, "state/admin-status"), Value: "UP"},
+			{Path: mustParsePath(t, "state/ifindex"), Value: uint64(1)},
+		},
+	}, client.Notification{
+		Timestamp: time.Now(),
+		Updates: []client.Update{
+			{Path: mustParsePath(t, "/system/state/hostname"), Value: "router-1"},
+			{Path: mustParsePath(t, "/system/state/software-version"), Value: "4.30"},
+		},
+	})
+	require.NoError(t, err)
+	defer server.Close()
+	host, port, err := net.SplitHostPort(server.Addr)
+	require.NoError(t, err)
+
+	deps := createDeps(t)
+	chk := newCheck()
+	senderManager := deps.Demultiplexer
+
+	// language=yaml
+	rawInstanceConfig := []byte(`
+ip_address: ` + host + `
+port: ` + port + `
+username: admin
+password: 'test-password'
+use_plaintext: true
+namespace: test
+min_collection_interval: 10
+tags:
+  - env:test
+metrics:
+  - path: /system/memory/state/used
+    symbols:
+      - name: memory.used
+`)
+
+	// Use ID to ensure the mock sender gets registered
+	id := checkid.BuildID(CheckName, integration.FakeConfigHash, rawInstanceConfig, []byte(``))
+	sender := mocksender.NewMockSenderWithSenderManager(id, senderManager)
+	sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("MonotonicCount", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("Rate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("EventPlatformEvent", mock.Anything, mock.Anything).Return()
+	sender.On("Commit").Return()
+	sender.On("SetCheckCustomTags", []string{"env:test"}).Return()
+
+	err = chk.Configure(senderManager, integration.FakeConfigHash, rawInstanceConfig, []byte(``), "test")
+	require.NoError(t, err)
+	defer chk.Cancel()
+
+	assert.Equal(t, 10*time.Second, chk.Interval())
+
+	gnmiCheck := chk.(*GNMICheck)
+	require.NoError(t, chk.Run())
+	require.Eventually(t, gnmiCheck.store.Synced, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, chk.Run())
+
+	subscriptions, encoding := server.Subscriptions()
+	assert.Equal(t, client.EncodingProto, encoding)
+	assert.Contains(t, subscriptions, client.Subscription{Path: mustParsePath(t, "/system/memory/state/used"), Mode: client.Sample, SampleInterval: 10 * time.Second})
+	assert.Contains(t, subscriptions, client.Subscription{Path: mustParsePath(t, "/system/state/hostname"), Mode: client.OnChange})
+	assert.Equal(t, "admin", server.Username())
+
+	deviceTags := []string{"device_namespace:test", "device_ip:" + host, "device_id:test:" + host, "hostname:router-1"}
+	interfaceTags := append(append([]string{}, deviceTags...), "interface:Ethernet1")
+	sender.AssertMetric(t, "Gauge", "gnmi.device.reachable", 1, "", deviceTags)
+	sender.AssertMetric(t, "MonotonicCount", "gnmi.interface.in_octets", 1000, "", interfaceTags)
+	sender.AssertMetric(t, "Rate", "gnmi.interface.in_octets.rate", 1000, "", interfaceTags)
+	sender.AssertMetric(t, "Gauge", "gnmi.interface.oper_status", 1, "", interfaceTags)
+	sender.AssertCalled(t, "EventPlatformEvent", mock.Anything, "network-devices-metadata")
+
+	// updates streamed after the first sync are reported on the next run
+	require.NoError(t, server.Send(client.Notification{
+		Timestamp: time.Now(),
+		Updates: []client.Update{
+			{Path: mustParsePath(t, "/interfaces/interface[name=Ethernet1]/state/counters/in-octets"), Value: uint64(3000)},
+		},
+	}))
+	require.Eventually(t, func() bool {
+		for _, sample := range gnmiCheck.store.MetricSamples() {
+			if sample.Name == "interface.in_octets" {
+				return sample.Value == 3000
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, chk.Run())
+	sender.AssertMetric(t, "MonotonicCount", "gnmi.interface.in_octets", 3000, "", interfaceTags)
+
+	// the device is reported unreachable when the subscription fails
+	chk.Cancel()
+	server.Close()
+	chk.Run() //nolint:errcheck
+	sender.AssertMetric(t, "Gauge", "gnmi.device.unreachable", 1, "", []string{"device_namespace:test", "device_ip:" + host, "device_id:test:" + host})
+}
+
+func TestGNMICheckConfigureErrors(t *testing.T) {
+	deps := createDeps(t)
+
+	for _, tt := range []struct {
+		name          string
+		config        string
+		expectedError string
+	}{
+		{name: "missing ip address", config: `port: 1`, expectedError: "ip_address is required"},
+		{name: "invalid encoding", config: "ip_address: 1.2.3.4\nencoding: xml", expectedError: "unknown encoding `xml`"},
+		{name: "unknown profile", config: "ip_address: 1.2.3.4\nprofile: foo", expectedError: "unknown profile `foo`"},
+		{name: "invalid metrics", config: "ip_address: 1.2.3.4\nmetrics:\n  - path: /a", expectedError: "invalid profile `openconfig`: no symbol for path `/a`"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			chk := newCheck()
+			err := chk.Configure(deps.Demultiplexer, integration.FakeConfigHash, []byte(tt.config), []byte(``), "test")
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
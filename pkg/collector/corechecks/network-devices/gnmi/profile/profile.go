@@ -0,0 +1,361 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package profile implements the gNMI profiles mapping the paths of a device to metrics, tags and metadata
+package profile
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/client"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+)
+
+//go:embed default_profiles/*.yaml
+var defaultProfiles embed.FS
+
+// DefaultProfile is the profile used when no profile is configured
+const DefaultProfile = "openconfig"
+
+// Subscription modes of metrics
+const (
+	ModeSample   = "sample"
+	ModeOnChange = "on_change"
+)
+
+// DefaultSampleInterval is the sample interval of metrics that don't set one
+const DefaultSampleInterval = 10 * time.Second
+
+// Device metadata fields
+var deviceFields = map[string]struct{}{
+	"name": {}, "description": {}, "vendor": {}, "model": {}, "serial_number": {}, "version": {},
+	"product_name": {}, "os_name": {}, "os_version": {}, "os_hostname": {}, "location": {},
+}
+
+// Interface metadata fields
+var interfaceFields = map[string]struct{}{
+	"index": {}, "alias": {}, "description": {}, "mac_address": {}, "admin_status": {}, "oper_status": {},
+}
+
+// ProfileDefinition maps the gNMI paths of a device to metrics, tags and metadata
+type ProfileDefinition struct {
+	Metrics    []MetricsConfig   `yaml:"metrics"`
+	MetricTags []MetricTagConfig `yaml:"metric_tags"`
+	Metadata   MetadataConfig    `yaml:"metadata"`
+}
+
+// MetricsConfig is a subscription to a path, the leaves under the path are submitted as metrics
+type MetricsConfig struct {
+	Path string `yaml:"path"`
+	// Mode is `sample` (default) or `on_change`
+	Mode string `yaml:"mode"`
+	// SampleInterval is the sample interval in seconds of the `sample` mode
+	SampleInterval int               `yaml:"sample_interval"`
+	Symbols        []SymbolConfig    `yaml:"symbols"`
+	MetricTags     []MetricTagConfig `yaml:"metric_tags"`
+}
+
+// SymbolConfig maps a leaf to a metric
+type SymbolConfig struct {
+	// Path of the leaf, relative to the path of the metrics, empty if the path of the metrics is the leaf
+	Path        string                              `yaml:"path"`
+	Name        string                              `yaml:"name"`
+	MetricType  profiledefinition.ProfileMetricType `yaml:"metric_type"`
+	ScaleFactor float64                             `yaml:"scale_factor"`
+	// Mapping maps string values, e.g. enums, to numbers
+	Mapping map[string]float64 `yaml:"mapping"`
+}
+
+// MetricTagConfig is a tag taken from a path key of the metrics, or from the value of a leaf for device tags
+type MetricTagConfig struct {
+	Tag string `yaml:"tag"`
+	// Key is the name of a key of the path of the metrics
+	Key string `yaml:"key"`
+	// Path is the path of a leaf holding the value of a device tag
+	Path string `yaml:"path"`
+}
+
+// MetadataConfig maps leaves to the fields of the device and interface metadata
+type MetadataConfig struct {
+	Device    DeviceMetadataConfig    `yaml:"device"`
+	Interface InterfaceMetadataConfig `yaml:"interface"`
+}
+
+// DeviceMetadataConfig maps device metadata fields to leaf paths
+type DeviceMetadataConfig struct {
+	Fields map[string]string `yaml:"fields"`
+}
+
+// InterfaceMetadataConfig maps interface metadata fields to leaf paths relative to the path of the interfaces
+type InterfaceMetadataConfig struct {
+	// Path of the interfaces list, e.g. `/interfaces/interface`
+	Path string `yaml:"path"`
+	// Key of the interfaces list holding the interface name
+	Key    string            `yaml:"key"`
+	Fields map[string]string `yaml:"fields"`
+}
+
+// GetProfile loads a profile from `conf.d/gnmi.d/profiles/<name>.yaml`, or from the default profiles
+func GetProfile(confdPath string, name string) (*ProfileDefinition, error) {
+	buf, err := os.ReadFile(filepath.Join(confdPath, "gnmi.d", "profiles", name+".yaml"))
+	if os.IsNotExist(err) {
+		buf, err = defaultProfiles.ReadFile("default_profiles/" + name + ".yaml")
+		if err != nil {
+			return nil, fmt.Errorf("unknown profile `%s`", name)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read profile `%s`: %w", name, err)
+	}
+	definition := &ProfileDefinition{}
+	if err := yaml.UnmarshalStrict(buf, definition); err != nil {
+		return nil, fmt.Errorf("invalid profile `%s`: %w", name, err)
+	}
+	return definition, nil
+}
+
+// LeafKind is the kind of data held by a leaf
+type LeafKind int
+
+// LeafKind enums
+const (
+	LeafMetric LeafKind = iota
+	LeafDeviceTag
+	LeafDeviceField
+	LeafInterfaceField
+)
+
+// Leaf is a leaf path pattern of the profile
+type Leaf struct {
+	Kind LeafKind
+	Path client.Path
+	Mode string
+
+	// metrics
+	Symbol         SymbolConfig
+	MetricTags     []MetricTagConfig
+	SampleInterval time.Duration
+
+	// device tags
+	Tag string
+
+	// device and interface metadata fields
+	Field string
+	// InterfaceKey is the key of the interface name in the path, for interface fields
+	InterfaceKey string
+}
+
+// Matcher matches the paths sent by a device with the leaves of a profile
+type Matcher struct {
+	leaves        []*Leaf
+	subscriptions []client.Subscription
+	interfaceKey  string
+}
+
+// Compile validates the profile and builds the matcher of its leaves
+func (p *ProfileDefinition) Compile() (*Matcher, error) {
+	m := &Matcher{}
+	for _, metric := range p.Metrics {
+		path, err := client.ParsePath(metric.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metrics path `%s`: %w", metric.Path, err)
+		}
+		mode := metric.Mode
+		if mode == "" {
+			mode = ModeSample
+		}
+		if mode != ModeSample && mode != ModeOnChange {
+			return nil, fmt.Errorf("invalid mode `%s` for path `%s`, expected `%s` or `%s`", mode, metric.Path, ModeSample, ModeOnChange)
+		}
+		sampleInterval := DefaultSampleInterval
+		if metric.SampleInterval > 0 {
+			sampleInterval = time.Duration(metric.SampleInterval) * time.Second
+		}
+		if len(metric.Symbols) == 0 {
+			return nil, fmt.Errorf("no symbol for path `%s`", metric.Path)
+		}
+		for _, tag := range metric.MetricTags {
+			if tag.Tag == "" || tag.Key == "" {
+				return nil, fmt.Errorf("metric tags of path `%s` must have a `tag` and a `key`", metric.Path)
+			}
+		}
+		for _, symbol := range metric.Symbols {
+			if symbol.Name == "" {
+				return nil, fmt.Errorf("symbol of path `%s` has no name", metric.Path)
+			}
+			switch symbol.MetricType {
+			case "", profiledefinition.ProfileMetricTypeGauge, profiledefinition.ProfileMetricTypeRate,
+				profiledefinition.ProfileMetricTypeMonotonicCount, profiledefinition.ProfileMetricTypeMonotonicCountAndRate:
+			default:
+				return nil, fmt.Errorf("unsupported metric type `%s` for symbol `%s`", symbol.MetricType, symbol.Name)
+			}
+			leafPath, err := joinRelativePath(path, symbol.Path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path `%s` of symbol `%s`: %w", symbol.Path, symbol.Name, err)
+			}
+			m.leaves = append(m.leaves, &Leaf{
+				Kind:           LeafMetric,
+				Path:           leafPath,
+				Mode:           mode,
+				Symbol:         symbol,
+				MetricTags:     metric.MetricTags,
+				SampleInterval: sampleInterval,
+			})
+		}
+		subscription := client.Subscription{Path: path, Mode: client.OnChange}
+		if mode == ModeSample {
+			subscription = client.Subscription{Path: path, Mode: client.Sample, SampleInterval: sampleInterval}
+		}
+		m.addSubscription(subscription)
+	}
+
+	for _, tag := range p.MetricTags {
+		if tag.Tag == "" || tag.Path == "" {
+			return nil, fmt.Errorf("device tags must have a `tag` and a `path`")
+		}
+		if err := m.addOnChangeLeaf(&Leaf{Kind: LeafDeviceTag, Tag: tag.Tag}, tag.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, field := range sortedKeys(p.Metadata.Device.Fields) {
+		if _, ok := deviceFields[field]; !ok {
+			return nil, fmt.Errorf("unknown device metadata field `%s`", field)
+		}
+		if err := m.addOnChangeLeaf(&Leaf{Kind: LeafDeviceField, Field: field}, p.Metadata.Device.Fields[field]); err != nil {
+			return nil, err
+		}
+	}
+
+	interfaces := p.Metadata.Interface
+	if len(interfaces.Fields) > 0 {
+		if interfaces.Path == "" || interfaces.Key == "" {
+			return nil, fmt.Errorf("interface metadata must have a `path` and a `key`")
+		}
+		interfacesPath, err := client.ParsePath(interfaces.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interface metadata path `%s`: %w", interfaces.Path, err)
+		}
+		m.interfaceKey = interfaces.Key
+		for _, field := range sortedKeys(interfaces.Fields) {
+			if _, ok := interfaceFields[field]; !ok {
+				return nil, fmt.Errorf("unknown interface metadata field `%s`", field)
+			}
+			leafPath, err := joinRelativePath(interfacesPath, interfaces.Fields[field])
+			if err != nil {
+				return nil, fmt.Errorf("invalid path of interface metadata field `%s`: %w", field, err)
+			}
+			if err := m.addOnChangeLeaf(&Leaf{Kind: LeafInterfaceField, Field: field, InterfaceKey: interfaces.Key}, leafPath.String()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m *Matcher) addOnChangeLeaf(leaf *Leaf, path string) error {
+	leafPath, err := client.ParsePath(path)
+	if err != nil {
+		return fmt.Errorf("invalid path `%s`: %w", path, err)
+	}
+	leaf.Path = leafPath
+	leaf.Mode = ModeOnChange
+	m.leaves = append(m.leaves, leaf)
+	m.addSubscription(client.Subscription{Path: leafPath, Mode: client.OnChange})
+	return nil
+}
+
+func (m *Matcher) addSubscription(subscription client.Subscription) {
+	for _, existing := range m.subscriptions {
+		if existing.Path.String() == subscription.Path.String() && existing.Mode == subscription.Mode && existing.SampleInterval == subscription.SampleInterval {
+			return
+		}
+	}
+	m.subscriptions = append(m.subscriptions, subscription)
+}
+
+// Subscriptions returns the subscriptions needed by the profile
+func (m *Matcher) Subscriptions() []client.Subscription {
+	return m.subscriptions
+}
+
+// InterfaceKey returns the key of the interface name in the interface paths
+func (m *Matcher) InterfaceKey() string {
+	return m.interfaceKey
+}
+
+// Match returns the leaves matching a path sent by the device
+func (m *Matcher) Match(path client.Path) []*Leaf {
+	var leaves []*Leaf
+	for _, leaf := range m.leaves {
+		if matchPath(leaf.Path, path) {
+			leaves = append(leaves, leaf)
+		}
+	}
+	return leaves
+}
+
+// matchPath matches the element names, and the keys of the pattern with glob patterns
+func matchPath(pattern client.Path, path client.Path) bool {
+	if len(pattern.Elems) != len(path.Elems) {
+		return false
+	}
+	for i, elem := range pattern.Elems {
+		if elem.Name != path.Elems[i].Name {
+			return false
+		}
+		for key, keyPattern := range elem.Keys {
+			value, ok := path.Elems[i].Keys[key]
+			if !ok {
+				return false
+			}
+			if matched, err := filepath.Match(keyPattern, value); err != nil || !matched {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// KeyValue returns the value of the first key with the given name, from the leaf to the root
+func KeyValue(path client.Path, key string) (string, bool) {
+	for i := len(path.Elems) - 1; i >= 0; i-- {
+		if value, ok := path.Elems[i].Keys[key]; ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func joinRelativePath(path client.Path, relative string) (client.Path, error) {
+	if relative == "" {
+		return path, nil
+	}
+	if strings.HasPrefix(relative, "/") {
+		return client.Path{}, fmt.Errorf("path must be relative")
+	}
+	relativePath, err := client.ParsePath(relative)
+	if err != nil {
+		return client.Path{}, err
+	}
+	return path.Join(relativePath), nil
+}
+
+func sortedKeys(fields map[string]string) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,184 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/client"
+)
+
+func mustParsePath(t *testing.T, str string) client.Path {
+	path, err := client.ParsePath(str)
+	require.NoError(t, err)
+	return path
+}
+
+func TestGetProfile(t *testing.T) {
+	confdPath := t.TempDir()
+
+	definition, err := GetProfile(confdPath, DefaultProfile)
+	require.NoError(t, err)
+	_, err = definition.Compile()
+	require.NoError(t, err)
+
+	// user profiles take precedence over the default profiles
+	require.NoError(t, os.MkdirAll(filepath.Join(confdPath, "gnmi.d", "profiles"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(confdPath, "gnmi.d", "profiles", "openconfig.yaml"), []byte(`
+metric_tags:
+  - tag: hostname
+    path: /system/state/hostname
+`), 0644))
+	definition, err = GetProfile(confdPath, DefaultProfile)
+	require.NoError(t, err)
+	assert.Equal(t, &ProfileDefinition{MetricTags: []MetricTagConfig{{Tag: "hostname", Path: "/system/state/hostname"}}}, definition)
+
+	require.NoError(t, os.WriteFile(filepath.Join(confdPath, "gnmi.d", "profiles", "invalid.yaml"), []byte(`
+unknown_field: true
+`), 0644))
+	_, err = GetProfile(confdPath, "invalid")
+	assert.ErrorContains(t, err, "invalid profile `invalid`")
+
+	_, err = GetProfile(confdPath, "does-not-exist")
+	assert.EqualError(t, err, "unknown profile `does-not-exist`")
+}
+
+func TestCompile(t *testing.T) {
+	definition := ProfileDefinition{
+		Metrics: []MetricsConfig{
+			{
+				Path:           "/interfaces/interface[name=eth*]/state/counters",
+				SampleInterval: 5,
+				Symbols: []SymbolConfig{
+					{Path: "in-octets", Name: "interface.in_octets", MetricType: "monotonic_count"},
+				},
+				MetricTags: []MetricTagConfig{{Tag: "interface", Key: "name"}},
+			},
+			{
+				Path:    "/interfaces/interface/state/oper-status",
+				Mode:    ModeOnChange,
+				Symbols: []SymbolConfig{{Name: "interface.oper_status"}},
+			},
+		},
+		MetricTags: []MetricTagConfig{{Tag: "hostname", Path: "/system/state/hostname"}},
+		Metadata: MetadataConfig{
+			Device: DeviceMetadataConfig{Fields: map[string]string{"name": "/system/state/hostname"}},
+			Interface: InterfaceMetadataConfig{
+				Path:   "/interfaces/interface",
+				Key:    "name",
+				Fields: map[string]string{"oper_status": "state/oper-status"},
+			},
+		},
+	}
+	matcher, err := definition.Compile()
+	require.NoError(t, err)
+
+	assert.Equal(t, []client.Subscription{
+		{Path: mustParsePath(t, "/interfaces/interface[name=eth*]/state/counters"), Mode: client.Sample, SampleInterval: 5 * time.Second},
+		{Path: mustParsePath(t, "/interfaces/interface/state/oper-status"), Mode: client.OnChange},
+		{Path: mustParsePath(t, "/system/state/hostname"), Mode: client.OnChange},
+	}, matcher.Subscriptions())
+
+	leaves := matcher.Match(mustParsePath(t, "/interfaces/interface[name=eth0]/state/counters/in-octets"))
+	require.Len(t, leaves, 1)
+	assert.Equal(t, LeafMetric, leaves[0].Kind)
+	assert.Equal(t, "interface.in_octets", leaves[0].Symbol.Name)
+	assert.Equal(t, 5*time.Second, leaves[0].SampleInterval)
+
+	assert.Empty(t, matcher.Match(mustParsePath(t, "/interfaces/interface[name=lo0]/state/counters/in-octets")))
+	assert.Empty(t, matcher.Match(mustParsePath(t, "/interfaces/interface[name=eth0]/state/counters/out-octets")))
+
+	leaves = matcher.Match(mustParsePath(t, "/interfaces/interface[name=lo0]/state/oper-status"))
+	require.Len(t, leaves, 2)
+	assert.Equal(t, LeafMetric, leaves[0].Kind)
+	assert.Equal(t, LeafInterfaceField, leaves[1].Kind)
+	assert.Equal(t, "oper_status", leaves[1].Field)
+
+	leaves = matcher.Match(mustParsePath(t, "/system/state/hostname"))
+	require.Len(t, leaves, 2)
+	assert.Equal(t, LeafDeviceTag, leaves[0].Kind)
+	assert.Equal(t, LeafDeviceField, leaves[1].Kind)
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		definition    ProfileDefinition
+		expectedError string
+	}{
+		{
+			name: "invalid mode",
+			definition: ProfileDefinition{Metrics: []MetricsConfig{
+				{Path: "/a", Mode: "poll", Symbols: []SymbolConfig{{Name: "a"}}},
+			}},
+			expectedError: "invalid mode `poll` for path `/a`, expected `sample` or `on_change`",
+		},
+		{
+			name: "no symbol",
+			definition: ProfileDefinition{Metrics: []MetricsConfig{
+				{Path: "/a"},
+			}},
+			expectedError: "no symbol for path `/a`",
+		},
+		{
+			name: "unsupported metric type",
+			definition: ProfileDefinition{Metrics: []MetricsConfig{
+				{Path: "/a", Symbols: []SymbolConfig{{Name: "a", MetricType: "flag_stream"}}},
+			}},
+			expectedError: "unsupported metric type `flag_stream` for symbol `a`",
+		},
+		{
+			name: "absolute symbol path",
+			definition: ProfileDefinition{Metrics: []MetricsConfig{
+				{Path: "/a", Symbols: []SymbolConfig{{Name: "a", Path: "/b"}}},
+			}},
+			expectedError: "invalid path `/b` of symbol `a`: path must be relative",
+		},
+		{
+			name: "metric tag without key",
+			definition: ProfileDefinition{Metrics: []MetricsConfig{
+				{Path: "/a", Symbols: []SymbolConfig{{Name: "a"}}, MetricTags: []MetricTagConfig{{Tag: "a"}}},
+			}},
+			expectedError: "metric tags of path `/a` must have a `tag` and a `key`",
+		},
+		{
+			name: "unknown device field",
+			definition: ProfileDefinition{Metadata: MetadataConfig{
+				Device: DeviceMetadataConfig{Fields: map[string]string{"firmware": "/a"}},
+			}},
+			expectedError: "unknown device metadata field `firmware`",
+		},
+		{
+			name: "interface metadata without key",
+			definition: ProfileDefinition{Metadata: MetadataConfig{
+				Interface: InterfaceMetadataConfig{Path: "/interfaces/interface", Fields: map[string]string{"alias": "state/description"}},
+			}},
+			expectedError: "interface metadata must have a `path` and a `key`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.definition.Compile()
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestKeyValue(t *testing.T) {
+	path := mustParsePath(t, "/interfaces/interface[name=eth0]/subinterfaces/subinterface[index=1][name=eth0.1]/state")
+	value, ok := KeyValue(path, "name")
+	assert.True(t, ok)
+	assert.Equal(t, "eth0.1", value)
+
+	_, ok = KeyValue(path, "vlan")
+	assert.False(t, ok)
+}
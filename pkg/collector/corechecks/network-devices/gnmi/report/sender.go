@@ -0,0 +1,192 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package report
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/forwarder/eventplatform"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const gnmiMetricPrefix = "gnmi."
+
+// TimeNow useful for mocking
+var TimeNow = time.Now
+
+// OpenConfig interface status enums
+var adminStatusMap = map[string]devicemetadata.IfAdminStatus{
+	"UP":      devicemetadata.AdminStatusUp,
+	"DOWN":    devicemetadata.AdminStatusDown,
+	"TESTING": devicemetadata.AdminStatusTesting,
+}
+
+var operStatusMap = map[string]devicemetadata.IfOperStatus{
+	"UP":               devicemetadata.OperStatusUp,
+	"DOWN":             devicemetadata.OperStatusDown,
+	"TESTING":          devicemetadata.OperStatusTesting,
+	"UNKNOWN":          devicemetadata.OperStatusUnknown,
+	"DORMANT":          devicemetadata.OperStatusDormant,
+	"NOT_PRESENT":      devicemetadata.OperStatusNotPresent,
+	"LOWER_LAYER_DOWN": devicemetadata.OperStatusLowerLayerDown,
+}
+
+// GNMISender implements methods for sending gNMI metrics and metadata
+type GNMISender struct {
+	sender    sender.Sender
+	namespace string
+	ipAddress string
+	// instanceTags are added to the metadata, metrics get them from the check custom tags
+	instanceTags []string
+}
+
+// NewGNMISender returns a new GNMISender for a device
+func NewGNMISender(sender sender.Sender, namespace string, ipAddress string, instanceTags []string) *GNMISender {
+	return &GNMISender{
+		sender:       sender,
+		namespace:    namespace,
+		ipAddress:    ipAddress,
+		instanceTags: instanceTags,
+	}
+}
+
+// DeviceID returns the ID of the device
+func (s *GNMISender) DeviceID() string {
+	return s.namespace + ":" + s.ipAddress
+}
+
+// GetDeviceTags returns the tags of the device metrics
+func (s *GNMISender) GetDeviceTags(profileTags []string) []string {
+	tags := []string{
+		"device_namespace:" + s.namespace,
+		"device_ip:" + s.ipAddress,
+		"device_id:" + s.DeviceID(),
+	}
+	return append(tags, profileTags...)
+}
+
+// SendMetrics sends the metric samples with the device tags
+func (s *GNMISender) SendMetrics(samples []MetricSample, deviceTags []string) {
+	for _, sample := range samples {
+		name := gnmiMetricPrefix + sample.Name
+		tags := append(append([]string{}, deviceTags...), sample.Tags...)
+		switch sample.MetricType {
+		case profiledefinition.ProfileMetricTypeRate:
+			s.sender.Rate(name, sample.Value, "", tags)
+		case profiledefinition.ProfileMetricTypeMonotonicCount:
+			s.sender.MonotonicCount(name, sample.Value, "", tags)
+		case profiledefinition.ProfileMetricTypeMonotonicCountAndRate:
+			s.sender.MonotonicCount(name, sample.Value, "", tags)
+			s.sender.Rate(name+".rate", sample.Value, "", tags)
+		default:
+			s.sender.Gauge(name, sample.Value, "", tags)
+		}
+	}
+}
+
+// SendDeviceStatus sends the reachability of the device
+func (s *GNMISender) SendDeviceStatus(reachable bool, deviceTags []string) {
+	var reachableValue, unreachableValue float64
+	if reachable {
+		reachableValue = 1
+	} else {
+		unreachableValue = 1
+	}
+	s.sender.Gauge(gnmiMetricPrefix+"device.reachable", reachableValue, "", deviceTags)
+	s.sender.Gauge(gnmiMetricPrefix+"device.unreachable", unreachableValue, "", deviceTags)
+}
+
+// SendMetadata sends the device and interface metadata
+func (s *GNMISender) SendMetadata(deviceFields map[string]string, interfaces map[string]map[string]string, deviceTags []string, reachable bool) {
+	collectionTime := TimeNow()
+	devices := []devicemetadata.DeviceMetadata{s.buildDeviceMetadata(deviceFields, deviceTags, reachable)}
+	interfacesMetadata := s.buildInterfacesMetadata(interfaces)
+
+	metadataPayloads := devicemetadata.BatchPayloads(s.namespace, "", collectionTime, devicemetadata.PayloadMetadataBatchSize, devices, interfacesMetadata, nil, nil, nil, nil)
+	for _, payload := range metadataPayloads {
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			log.Errorf("Error marshalling gNMI metadata : %s", err)
+			continue
+		}
+		s.sender.EventPlatformEvent(payloadBytes, eventplatform.EventTypeNetworkDevicesMetadata)
+	}
+}
+
+// Commit commits the metrics
+func (s *GNMISender) Commit() {
+	s.sender.Commit()
+}
+
+func (s *GNMISender) buildDeviceMetadata(fields map[string]string, deviceTags []string, reachable bool) devicemetadata.DeviceMetadata {
+	status := devicemetadata.DeviceStatusReachable
+	if !reachable {
+		status = devicemetadata.DeviceStatusUnreachable
+	}
+	tags := append(append([]string{}, deviceTags...), s.instanceTags...)
+	tags = append(tags, "source:gnmi")
+	sort.Strings(tags)
+	return devicemetadata.DeviceMetadata{
+		ID:           s.DeviceID(),
+		IDTags:       []string{"device_namespace:" + s.namespace, "device_ip:" + s.ipAddress},
+		Tags:         tags,
+		IPAddress:    s.ipAddress,
+		Status:       status,
+		Name:         fields["name"],
+		Description:  fields["description"],
+		Location:     fields["location"],
+		Vendor:       fields["vendor"],
+		SerialNumber: fields["serial_number"],
+		Version:      fields["version"],
+		ProductName:  fields["product_name"],
+		Model:        fields["model"],
+		OsName:       fields["os_name"],
+		OsVersion:    fields["os_version"],
+		OsHostname:   fields["os_hostname"],
+		Integration:  "gnmi",
+	}
+}
+
+func (s *GNMISender) buildInterfacesMetadata(interfaces map[string]map[string]string) []devicemetadata.InterfaceMetadata {
+	names := make([]string, 0, len(interfaces))
+	for name := range interfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	interfacesMetadata := make([]devicemetadata.InterfaceMetadata, 0, len(names))
+	for _, name := range names {
+		fields := interfaces[name]
+		var index int32
+		if fields["index"] != "" {
+			parsed, err := strconv.ParseInt(fields["index"], 10, 32)
+			if err != nil {
+				log.Debugf("invalid index `%s` of interface %s: %s", fields["index"], name, err)
+			}
+			index = int32(parsed)
+		}
+		interfacesMetadata = append(interfacesMetadata, devicemetadata.InterfaceMetadata{
+			DeviceID:    s.DeviceID(),
+			IDTags:      []string{"interface:" + name},
+			Index:       index,
+			RawID:       name,
+			Name:        name,
+			Alias:       fields["alias"],
+			Description: fields["description"],
+			MacAddress:  fields["mac_address"],
+			AdminStatus: adminStatusMap[strings.ToUpper(fields["admin_status"])],
+			OperStatus:  operStatusMap[strings.ToUpper(fields["oper_status"])],
+		})
+	}
+	return interfacesMetadata
+}
@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+)
+
+func TestSendMetrics(t *testing.T) {
+	sender := mocksender.NewMockSender("testID") // required to initiate aggregator
+	sender.SetupAcceptAll()
+	ms := NewGNMISender(sender, "my-ns", "10.0.0.1", []string{"env:prod"})
+
+	deviceTags := ms.GetDeviceTags([]string{"hostname:router-1"})
+	assert.Equal(t, []string{"device_namespace:my-ns", "device_ip:10.0.0.1", "device_id:my-ns:10.0.0.1", "hostname:router-1"}, deviceTags)
+
+	ms.SendMetrics([]MetricSample{
+		{Name: "cpu.usage", MetricType: "gauge", Value: 20},
+		{Name: "interface.in_octets", MetricType: "monotonic_count_and_rate", Value: 1000, Tags: []string{"interface:eth0"}},
+		{Name: "interface.in_errors", MetricType: "monotonic_count", Value: 3, Tags: []string{"interface:eth0"}},
+		{Name: "interface.speed", MetricType: "rate", Value: 10, Tags: []string{"interface:eth0"}},
+	}, deviceTags)
+	ms.SendDeviceStatus(true, deviceTags)
+
+	interfaceTags := append(append([]string{}, deviceTags...), "interface:eth0")
+	sender.AssertMetric(t, "Gauge", "gnmi.cpu.usage", 20, "", deviceTags)
+	sender.AssertMetric(t, "MonotonicCount", "gnmi.interface.in_octets", 1000, "", interfaceTags)
+	sender.AssertMetric(t, "Rate", "gnmi.interface.in_octets.rate", 1000, "", interfaceTags)
+	sender.AssertMetric(t, "MonotonicCount", "gnmi.interface.in_errors", 3, "", interfaceTags)
+	sender.AssertMetric(t, "Rate", "gnmi.interface.speed", 10, "", interfaceTags)
+	sender.AssertMetric(t, "Gauge", "gnmi.device.reachable", 1, "", deviceTags)
+	sender.AssertMetric(t, "Gauge", "gnmi.device.unreachable", 0, "", deviceTags)
+}
+
+func TestSendMetadata(t *testing.T) {
+	mockTimeNow(t, time.Unix(946684800, 0))
+
+	sender := mocksender.NewMockSender("testID") // required to initiate aggregator
+	sender.On("EventPlatformEvent", mock.Anything, mock.Anything).Return()
+	ms := NewGNMISender(sender, "my-ns", "10.0.0.1", []string{"env:prod"})
+
+	ms.SendMetadata(
+		map[string]string{"name": "router-1", "os_version": "4.30", "vendor": "arista"},
+		map[string]map[string]string{
+			"eth1": {"index": "2", "description": "uplink", "admin_status": "UP", "oper_status": "LOWER_LAYER_DOWN"},
+			"eth0": {"index": "1", "admin_status": "DOWN", "oper_status": "DOWN"},
+		},
+		ms.GetDeviceTags([]string{"hostname:router-1"}),
+		true,
+	)
+
+	// language=json
+	event := []byte(`
+{
+  "namespace": "my-ns",
+  "devices": [
+    {
+      "id": "my-ns:10.0.0.1",
+      "id_tags": [
+        "device_namespace:my-ns",
+        "device_ip:10.0.0.1"
+      ],
+      "tags": [
+        "device_id:my-ns:10.0.0.1",
+        "device_ip:10.0.0.1",
+        "device_namespace:my-ns",
+        "env:prod",
+        "hostname:router-1",
+        "source:gnmi"
+      ],
+      "ip_address": "10.0.0.1",
+      "status": 1,
+      "name": "router-1",
+      "vendor": "arista",
+      "os_version": "4.30",
+      "integration": "gnmi"
+    }
+  ],
+  "interfaces": [
+    {
+      "device_id": "my-ns:10.0.0.1",
+      "id_tags": [
+        "interface:eth0"
+      ],
+      "index": 1,
+      "raw_id": "eth0",
+      "name": "eth0",
+      "admin_status": 2,
+      "oper_status": 2
+    },
+    {
+      "device_id": "my-ns:10.0.0.1",
+      "id_tags": [
+        "interface:eth1"
+      ],
+      "index": 2,
+      "raw_id": "eth1",
+      "name": "eth1",
+      "description": "uplink",
+      "admin_status": 1,
+      "oper_status": 7
+    }
+  ],
+  "collect_timestamp": 946684800
+}
+`)
+	compactEvent := new(bytes.Buffer)
+	err := json.Compact(compactEvent, event)
+	assert.NoError(t, err)
+
+	sender.AssertEventPlatformEvent(t, compactEvent.Bytes(), "network-devices-metadata")
+}
@@ -0,0 +1,277 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package report implements gNMI metadata and metrics reporting
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/client"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/profile"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// sampleExpirationFactor is the number of sample intervals after which a sampled value
+// that wasn't updated is no longer reported
+const sampleExpirationFactor = 3
+
+// MetricSample is the last value of a leaf mapped to a metric
+type MetricSample struct {
+	Name       string
+	MetricType profiledefinition.ProfileMetricType
+	Value      float64
+	Tags       []string
+	Timestamp  time.Time
+
+	path       string
+	received   time.Time
+	expiration time.Duration
+}
+
+// Store keeps the last values sent by a device for the leaves of a profile
+type Store struct {
+	mu           sync.Mutex
+	matcher      *profile.Matcher
+	synced       bool
+	samples      map[string]MetricSample
+	deviceTags   map[string]string
+	deviceFields map[string]string
+	interfaces   map[string]map[string]string
+}
+
+// NewStore returns a new Store for the leaves of the matcher
+func NewStore(matcher *profile.Matcher) *Store {
+	s := &Store{matcher: matcher}
+	s.Reset()
+	return s
+}
+
+// Reset forgets all the values, targets send all the values again when subscribing
+func (s *Store) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.synced = false
+	s.samples = make(map[string]MetricSample)
+	s.deviceTags = make(map[string]string)
+	s.deviceFields = make(map[string]string)
+	s.interfaces = make(map[string]map[string]string)
+}
+
+// Synced returns true once the device sent all the values of the subscriptions
+func (s *Store) Synced() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.synced
+}
+
+// Handle stores the values of a subscribe response
+func (s *Store) Handle(response client.SubscribeResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if response.SyncResponse {
+		s.synced = true
+	}
+	notification := response.Notification
+	if notification == nil {
+		return
+	}
+	timestamp := notification.Timestamp
+	if timestamp.IsZero() || timestamp.UnixNano() == 0 {
+		timestamp = TimeNow()
+	}
+	for _, deleted := range notification.Deletes {
+		s.delete(notification.Prefix.Join(deleted))
+	}
+	for _, update := range notification.Updates {
+		path := notification.Prefix.Join(update.Path)
+		for _, leaf := range s.matcher.Match(path) {
+			s.update(leaf, path, update.Value, timestamp)
+		}
+	}
+}
+
+func (s *Store) update(leaf *profile.Leaf, path client.Path, value interface{}, timestamp time.Time) {
+	switch leaf.Kind {
+	case profile.LeafMetric:
+		floatValue, err := metricValue(leaf.Symbol, value)
+		if err != nil {
+			log.Debugf("gNMI path %s: %s", path, err)
+			return
+		}
+		tags := make([]string, 0, len(leaf.MetricTags))
+		for _, tag := range leaf.MetricTags {
+			if keyValue, ok := profile.KeyValue(path, tag.Key); ok {
+				tags = append(tags, tag.Tag+":"+keyValue)
+			}
+		}
+		metricType := leaf.Symbol.MetricType
+		if metricType == "" {
+			metricType = profiledefinition.ProfileMetricTypeGauge
+		}
+		var expiration time.Duration
+		if leaf.Mode == profile.ModeSample {
+			expiration = sampleExpirationFactor * leaf.SampleInterval
+		}
+		key := path.String() + "|" + leaf.Symbol.Name
+		s.samples[key] = MetricSample{
+			Name:       leaf.Symbol.Name,
+			MetricType: metricType,
+			Value:      floatValue,
+			Tags:       tags,
+			Timestamp:  timestamp,
+			path:       path.String(),
+			received:   TimeNow(),
+			expiration: expiration,
+		}
+	case profile.LeafDeviceTag:
+		s.deviceTags[leaf.Tag] = stringValue(value)
+	case profile.LeafDeviceField:
+		s.deviceFields[leaf.Field] = stringValue(value)
+	case profile.LeafInterfaceField:
+		name, ok := profile.KeyValue(path, leaf.InterfaceKey)
+		if !ok {
+			return
+		}
+		if _, ok := s.interfaces[name]; !ok {
+			s.interfaces[name] = make(map[string]string)
+		}
+		s.interfaces[name][leaf.Field] = stringValue(value)
+	}
+}
+
+// delete removes the values of the leaves under the path
+func (s *Store) delete(path client.Path) {
+	deleted := path.String()
+	for key, sample := range s.samples {
+		if sample.path == deleted || strings.HasPrefix(sample.path, deleted+"/") {
+			delete(s.samples, key)
+		}
+	}
+	for _, leaf := range s.matcher.Match(path) {
+		switch leaf.Kind {
+		case profile.LeafDeviceTag:
+			delete(s.deviceTags, leaf.Tag)
+		case profile.LeafDeviceField:
+			delete(s.deviceFields, leaf.Field)
+		}
+	}
+	// a deleted interface is a path ending with the interface key, e.g. /interfaces/interface[name=eth0]
+	if interfaceKey := s.matcher.InterfaceKey(); interfaceKey != "" && len(path.Elems) > 0 {
+		if name, ok := path.Elems[len(path.Elems)-1].Keys[interfaceKey]; ok {
+			delete(s.interfaces, name)
+		}
+	}
+}
+
+// MetricSamples returns the last value of each metric, expired sampled values are removed
+func (s *Store) MetricSamples() []MetricSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := TimeNow()
+	keys := make([]string, 0, len(s.samples))
+	for key, sample := range s.samples {
+		// the device clock may not be in sync with the agent, the reception time is used to expire values
+		if sample.expiration > 0 && now.Sub(sample.received) > sample.expiration {
+			delete(s.samples, key)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	samples := make([]MetricSample, 0, len(keys))
+	for _, key := range keys {
+		samples = append(samples, s.samples[key])
+	}
+	return samples
+}
+
+// DeviceTags returns the device tags
+func (s *Store) DeviceTags() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tags := make([]string, 0, len(s.deviceTags))
+	for tag, value := range s.deviceTags {
+		tags = append(tags, tag+":"+value)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// DeviceFields returns the device metadata fields
+func (s *Store) DeviceFields() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fields := make(map[string]string, len(s.deviceFields))
+	for field, value := range s.deviceFields {
+		fields[field] = value
+	}
+	return fields
+}
+
+// Interfaces returns the interface metadata fields by interface name
+func (s *Store) Interfaces() map[string]map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	interfaces := make(map[string]map[string]string, len(s.interfaces))
+	for name, fields := range s.interfaces {
+		interfaces[name] = make(map[string]string, len(fields))
+		for field, value := range fields {
+			interfaces[name][field] = value
+		}
+	}
+	return interfaces
+}
+
+// metricValue converts a value to a number, strings are mapped with the symbol mapping
+func metricValue(symbol profile.SymbolConfig, value interface{}) (float64, error) {
+	var floatValue float64
+	switch v := value.(type) {
+	case int64:
+		floatValue = float64(v)
+	case uint64:
+		floatValue = float64(v)
+	case float64:
+		floatValue = v
+	case bool:
+		if v {
+			floatValue = 1
+		}
+	case string:
+		if mapped, ok := symbol.Mapping[v]; ok {
+			floatValue = mapped
+			break
+		}
+		// JSON_IETF encodes 64 bits integers as strings
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value `%s` of symbol `%s` is not a number and has no mapping", v, symbol.Name)
+		}
+		floatValue = parsed
+	default:
+		return 0, fmt.Errorf("unsupported value %v of type %T for symbol `%s`", value, value, symbol.Name)
+	}
+	if symbol.ScaleFactor != 0 {
+		floatValue *= symbol.ScaleFactor
+	}
+	return floatValue, nil
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,173 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/client"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/network-devices/gnmi/profile"
+)
+
+var testProfile = profile.ProfileDefinition{
+	Metrics: []profile.MetricsConfig{
+		{
+			Path: "/interfaces/interface/state/counters",
+			Symbols: []profile.SymbolConfig{
+				{Path: "in-octets", Name: "interface.in_octets", MetricType: "monotonic_count"},
+				{Path: "in-errors", Name: "interface.in_errors", MetricType: "monotonic_count"},
+			},
+			MetricTags: []profile.MetricTagConfig{{Tag: "interface", Key: "name"}},
+		},
+		{
+			Path:    "/interfaces/interface/state/oper-status",
+			Mode:    profile.ModeOnChange,
+			Symbols: []profile.SymbolConfig{{Name: "interface.oper_status", Mapping: map[string]float64{"UP": 1, "DOWN": 2}}},
+		},
+		{
+			Path:    "/system/cpu/state/utilization",
+			Symbols: []profile.SymbolConfig{{Name: "cpu.usage", ScaleFactor: 0.5}},
+		},
+	},
+	MetricTags: []profile.MetricTagConfig{{Tag: "hostname", Path: "/system/state/hostname"}},
+	Metadata: profile.MetadataConfig{
+		Device: profile.DeviceMetadataConfig{Fields: map[string]string{
+			"name":       "/system/state/hostname",
+			"os_version": "/system/state/software-version",
+		}},
+		Interface: profile.InterfaceMetadataConfig{
+			Path: "/interfaces/interface",
+			Key:  "name",
+			Fields: map[string]string{
+				"index":       "state/ifindex",
+				"oper_status": "state/oper-status",
+			},
+		},
+	},
+}
+
+func mustParsePath(t *testing.T, str string) client.Path {
+	path, err := client.ParsePath(str)
+	require.NoError(t, err)
+	return path
+}
+
+func newTestStore(t *testing.T) *Store {
+	matcher, err := testProfile.Compile()
+	require.NoError(t, err)
+	return NewStore(matcher)
+}
+
+func mockTimeNow(t *testing.T, now time.Time) {
+	TimeNow = func() time.Time { return now }
+	t.Cleanup(func() { TimeNow = time.Now })
+}
+
+func TestStoreHandle(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	mockTimeNow(t, now)
+	store := newTestStore(t)
+
+	store.Handle(client.SubscribeResponse{Notification: &client.Notification{
+		Timestamp: now,
+		Prefix:    mustParsePath(t, "/interfaces/interface[name=eth0]"),
+		Updates: []client.Update{
+			{Path: mustParsePath(t, "state/counters/in-octets"), Value: uint64(1000)},
+			// JSON_IETF integers
+			{Path: mustParsePath(t, "state/counters/in-errors"), Value: "3"},
+			{Path: mustParsePath(t, "state/counters/out-octets"), Value: uint64(5)},
+			{Path: mustParsePath(t, "state/oper-status"), Value: "UP"},
+			{Path: mustParsePath(t, "state/ifindex"), Value: uint64(1)},
+		},
+	}})
+	store.Handle(client.SubscribeResponse{Notification: &client.Notification{
+		Timestamp: now,
+		Updates: []client.Update{
+			{Path: mustParsePath(t, "/system/state/hostname"), Value: "router-1"},
+			{Path: mustParsePath(t, "/system/state/software-version"), Value: "4.30"},
+			{Path: mustParsePath(t, "/system/cpu/state/utilization"), Value: int64(40)},
+			{Path: mustParsePath(t, "/interfaces/interface[name=eth1]/state/oper-status"), Value: "TESTING"},
+		},
+	}})
+	assert.False(t, store.Synced())
+	store.Handle(client.SubscribeResponse{SyncResponse: true})
+	assert.True(t, store.Synced())
+
+	assert.Equal(t, []MetricSample{
+		{Name: "interface.in_errors", MetricType: "monotonic_count", Value: 3, Tags: []string{"interface:eth0"}, Timestamp: now, path: "/interfaces/interface[name=eth0]/state/counters/in-errors", received: now, expiration: 30 * time.Second},
+		{Name: "interface.in_octets", MetricType: "monotonic_count", Value: 1000, Tags: []string{"interface:eth0"}, Timestamp: now, path: "/interfaces/interface[name=eth0]/state/counters/in-octets", received: now, expiration: 30 * time.Second},
+		{Name: "interface.oper_status", MetricType: "gauge", Value: 1, Tags: []string{}, Timestamp: now, path: "/interfaces/interface[name=eth0]/state/oper-status", received: now},
+		{Name: "cpu.usage", MetricType: "gauge", Value: 20, Tags: []string{}, Timestamp: now, path: "/system/cpu/state/utilization", received: now, expiration: 30 * time.Second},
+	}, store.MetricSamples())
+	assert.Equal(t, []string{"hostname:router-1"}, store.DeviceTags())
+	assert.Equal(t, map[string]string{"name": "router-1", "os_version": "4.30"}, store.DeviceFields())
+	assert.Equal(t, map[string]map[string]string{
+		"eth0": {"index": "1", "oper_status": "UP"},
+		"eth1": {"oper_status": "TESTING"},
+	}, store.Interfaces())
+
+	// deletes
+	store.Handle(client.SubscribeResponse{Notification: &client.Notification{
+		Timestamp: now,
+		Deletes: []client.Path{
+			mustParsePath(t, "/interfaces/interface[name=eth0]"),
+			mustParsePath(t, "/system/state/hostname"),
+		},
+	}})
+	assert.Len(t, store.MetricSamples(), 1)
+	assert.Empty(t, store.DeviceTags())
+	assert.Equal(t, map[string]string{"os_version": "4.30"}, store.DeviceFields())
+	assert.Equal(t, map[string]map[string]string{"eth1": {"oper_status": "TESTING"}}, store.Interfaces())
+
+	// sampled values expire, on change values don't
+	store.Handle(client.SubscribeResponse{Notification: &client.Notification{
+		Timestamp: now,
+		Updates: []client.Update{
+			{Path: mustParsePath(t, "/interfaces/interface[name=eth1]/state/oper-status"), Value: "DOWN"},
+		},
+	}})
+	mockTimeNow(t, now.Add(time.Minute))
+	samples := store.MetricSamples()
+	require.Len(t, samples, 1)
+	assert.Equal(t, "interface.oper_status", samples[0].Name)
+	assert.Equal(t, float64(2), samples[0].Value)
+
+	store.Reset()
+	assert.False(t, store.Synced())
+	assert.Empty(t, store.MetricSamples())
+	assert.Empty(t, store.Interfaces())
+}
+
+func TestMetricValue(t *testing.T) {
+	symbol := profile.SymbolConfig{Name: "a", Mapping: map[string]float64{"UP": 1}}
+	for _, tt := range []struct {
+		value         interface{}
+		expected      float64
+		expectedError string
+	}{
+		{value: int64(-2), expected: -2},
+		{value: uint64(2), expected: 2},
+		{value: 2.5, expected: 2.5},
+		{value: true, expected: 1},
+		{value: false, expected: 0},
+		{value: "UP", expected: 1},
+		{value: "12.5", expected: 12.5},
+		{value: "DOWN", expectedError: "value `DOWN` of symbol `a` is not a number and has no mapping"},
+		{value: []byte{1}, expectedError: "unsupported value [1] of type []uint8 for symbol `a`"},
+	} {
+		value, err := metricValue(symbol, tt.value)
+		if tt.expectedError != "" {
+			assert.EqualError(t, err, tt.expectedError)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, value)
+	}
+}
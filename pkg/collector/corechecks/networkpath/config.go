@@ -14,6 +14,7 @@ import (
 	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/paris"
 	"gopkg.in/yaml.v2"
 )
 
@@ -24,14 +25,15 @@ const (
 // Number is a type that is used to make a generic version
 // of the firstNonZero function
 type Number interface {
-	~int | ~int64 | ~uint8
+	~int | ~int64 | ~uint8 | ~uint16
 }
 
 // InitConfig is used to deserialize integration init config
 type InitConfig struct {
-	MinCollectionInterval int64 `yaml:"min_collection_interval"`
-	TimeoutMs             int64 `yaml:"timeout"`
-	MaxTTL                uint8 `yaml:"max_ttl"`
+	MinCollectionInterval int64  `yaml:"min_collection_interval"`
+	TimeoutMs             int64  `yaml:"timeout"`
+	MaxTTL                uint8  `yaml:"max_ttl"`
+	NumPaths              uint16 `yaml:"num_paths"`
 }
 
 // InstanceConfig is used to deserialize integration instance config
@@ -47,6 +49,8 @@ type InstanceConfig struct {
 
 	MaxTTL uint8 `yaml:"max_ttl"`
 
+	NumPaths uint16 `yaml:"num_paths"`
+
 	TimeoutMs int64 `yaml:"timeout"`
 
 	MinCollectionInterval int `yaml:"min_collection_interval"`
@@ -62,6 +66,7 @@ type CheckConfig struct {
 	SourceService         string
 	DestinationService    string
 	MaxTTL                uint8
+	NumPaths              uint16
 	Protocol              payload.Protocol
 	Timeout               time.Duration
 	MinCollectionInterval time.Duration
@@ -116,6 +121,14 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 		setup.DefaultNetworkPathMaxTTL,
 	)
 
+	c.NumPaths = firstNonZero(
+		instance.NumPaths,
+		initConfig.NumPaths,
+	)
+	if c.NumPaths > paris.MaxNumPaths {
+		return nil, fmt.Errorf("num_paths must be <= %d", paris.MaxNumPaths)
+	}
+
 	c.Tags = instance.Tags
 	c.Namespace = coreconfig.Datadog().GetString("network_devices.namespace")
 
@@ -309,6 +309,51 @@ max_ttl: 64
 				MaxTTL:                64,
 			},
 		},
+		{
+			name: "numPaths from instance config preferred over init config",
+			rawInstance: []byte(`
+hostname: 1.2.3.4
+protocol: icmp
+num_paths: 8
+`),
+			rawInitConfig: []byte(`
+num_paths: 4
+`),
+			expectedConfig: &CheckConfig{
+				DestHostname:          "1.2.3.4",
+				MinCollectionInterval: time.Duration(60) * time.Second,
+				Namespace:             "my-namespace",
+				Protocol:              payload.ProtocolICMP,
+				Timeout:               setup.DefaultNetworkPathTimeout * time.Millisecond,
+				MaxTTL:                setup.DefaultNetworkPathMaxTTL,
+				NumPaths:              8,
+			},
+		},
+		{
+			name: "numPaths from init config",
+			rawInstance: []byte(`
+hostname: 1.2.3.4
+`),
+			rawInitConfig: []byte(`
+num_paths: 4
+`),
+			expectedConfig: &CheckConfig{
+				DestHostname:          "1.2.3.4",
+				MinCollectionInterval: time.Duration(60) * time.Second,
+				Namespace:             "my-namespace",
+				Timeout:               setup.DefaultNetworkPathTimeout * time.Millisecond,
+				MaxTTL:                setup.DefaultNetworkPathMaxTTL,
+				NumPaths:              4,
+			},
+		},
+		{
+			name: "numPaths greater than the maximum returns an error",
+			rawInstance: []byte(`
+hostname: 1.2.3.4
+num_paths: 33
+`),
+			expectedError: "num_paths must be <= 32",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
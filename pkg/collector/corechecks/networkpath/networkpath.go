@@ -54,6 +54,7 @@ func (c *Check) Run() error {
 		DestHostname: c.config.DestHostname,
 		DestPort:     c.config.DestPort,
 		MaxTTL:       c.config.MaxTTL,
+		NumPaths:     c.config.NumPaths,
 		Timeout:      c.config.Timeout,
 		Protocol:     c.config.Protocol,
 	}
@@ -79,6 +79,40 @@ var uptimeMetricConfig = profiledefinition.MetricsConfig{Symbol: profiledefiniti
 // DeviceDigest is the digest of a minimal config used for autodiscovery
 type DeviceDigest string
 
+// Authentication is a set of credentials that can be used to connect to a device.
+// Credentials can be secret handles (`ENC[...]`), they are resolved by the secrets backend
+// like the rest of the instance configuration.
+type Authentication struct {
+	SnmpVersion     string `yaml:"snmp_version"`
+	CommunityString string `yaml:"community_string"`
+	User            string `yaml:"user"`
+	AuthProtocol    string `yaml:"authProtocol"`
+	AuthKey         string `yaml:"authKey"`
+	PrivProtocol    string `yaml:"privProtocol"`
+	PrivKey         string `yaml:"privKey"`
+	ContextName     string `yaml:"context_name"`
+}
+
+// Digest returns a hash identifying the credentials, used to remember which
+// credentials work for a device without storing them
+func (a Authentication) Digest() string {
+	h := fnv.New64()
+	// Hash write never returns an error
+	h.Write([]byte(a.SnmpVersion))     //nolint:errcheck
+	h.Write([]byte(a.CommunityString)) //nolint:errcheck
+	h.Write([]byte(a.User))            //nolint:errcheck
+	h.Write([]byte(a.AuthProtocol))    //nolint:errcheck
+	h.Write([]byte(a.AuthKey))         //nolint:errcheck
+	h.Write([]byte(a.PrivProtocol))    //nolint:errcheck
+	h.Write([]byte(a.PrivKey))         //nolint:errcheck
+	h.Write([]byte(a.ContextName))     //nolint:errcheck
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+func (a Authentication) isEmpty() bool {
+	return a.CommunityString == "" && a.User == ""
+}
+
 // InitConfig is used to deserialize integration init config
 type InitConfig struct {
 	Profiles                     profile.ProfileConfigMap          `yaml:"profiles"`
@@ -110,6 +144,7 @@ type InstanceConfig struct {
 	PrivProtocol          string                              `yaml:"privProtocol"`
 	PrivKey               string                              `yaml:"privKey"`
 	ContextName           string                              `yaml:"context_name"`
+	Authentications       []Authentication                    `yaml:"authentications"`
 	Metrics               []profiledefinition.MetricsConfig   `yaml:"metrics"`     // SNMP metrics definition
 	MetricTags            []profiledefinition.MetricTagConfig `yaml:"metric_tags"` // SNMP metric tags definition
 	Profile               string                              `yaml:"profile"`
@@ -173,6 +208,9 @@ type CheckConfig struct {
 	PrivProtocol    string
 	PrivKey         string
 	ContextName     string
+	// Authentications are additional credentials tried during autodiscovery
+	// when the device doesn't answer to the ones above
+	Authentications []Authentication
 	OidConfig       OidConfig
 	// RequestedMetrics are the metrics explicitly requested by config.
 	RequestedMetrics []profiledefinition.MetricsConfig
@@ -214,6 +252,10 @@ type CheckConfig struct {
 
 	PingEnabled bool
 	PingConfig  pinger.Config
+
+	// initConfigProfiles and profilesVersion are used to reload Profiles when the user profiles change
+	initConfigProfiles profile.ProfileConfigMap
+	profilesVersion    uint64
 }
 
 // SetProfile refreshes config based on profile
@@ -241,6 +283,32 @@ func (c *CheckConfig) SetProfile(profile string) error {
 	return nil
 }
 
+// RefreshProfiles reloads the profiles if the user profiles changed since they were loaded.
+// A profile set in the configuration is applied again, an autodetected profile is detected
+// again on the next run.
+func (c *CheckConfig) RefreshProfiles() error {
+	version := profile.GetProfilesVersion()
+	if version == c.profilesVersion {
+		return nil
+	}
+	profiles, err := profile.GetProfiles(c.initConfigProfiles)
+	if err != nil {
+		return fmt.Errorf("failed to reload profiles: %w", err)
+	}
+	log.Debugf("Reloaded profiles (version %d)", version)
+	c.Profiles = profiles
+	c.profilesVersion = version
+
+	if c.DetectMetricsEnabled || c.Profile == "" {
+		return nil
+	}
+	if c.AutodetectProfile {
+		c.Profile = ""
+		return nil
+	}
+	return c.SetProfile(c.Profile)
+}
+
 // SetAutodetectProfile sets the profile to the provided auto-detected metrics
 // and tags. This overwrites any preexisting profile but does not affect
 // RequestedMetrics or RequestedMetricTags, which will still be queried.
@@ -486,6 +554,18 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 	c.PrivKey = instance.PrivKey
 	c.ContextName = instance.ContextName
 
+	if len(instance.Authentications) > 0 {
+		if c.Network == "" {
+			return nil, fmt.Errorf("`authentications` can only be used with `network_address`")
+		}
+		for i, auth := range instance.Authentications {
+			if auth.isEmpty() {
+				return nil, fmt.Errorf("authentication %d: `community_string` or `user` must be provided", i)
+			}
+		}
+		c.Authentications = instance.Authentications
+	}
+
 	if instance.OidBatchSize != 0 {
 		c.OidBatchSize = int(instance.OidBatchSize)
 	} else if initConfig.OidBatchSize != 0 {
@@ -520,6 +600,9 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 		return nil, err
 	}
 
+	// the version is read first so that profiles reloaded meanwhile are refreshed on the next run
+	c.profilesVersion = profile.GetProfilesVersion()
+	c.initConfigProfiles = initConfig.Profiles
 	profiles, err := profile.GetProfiles(initConfig.Profiles)
 	if err != nil {
 		return nil, err
@@ -635,6 +718,9 @@ func (c *CheckConfig) DeviceDigest(address string) DeviceDigest {
 	h.Write([]byte(c.PrivKey))                 //nolint:errcheck
 	h.Write([]byte(c.PrivProtocol))            //nolint:errcheck
 	h.Write([]byte(c.ContextName))             //nolint:errcheck
+	for _, auth := range c.Authentications {
+		h.Write([]byte(auth.Digest())) //nolint:errcheck
+	}
 
 	// Sort the addresses to get a stable digest
 	addresses := make([]string, 0, len(c.IgnoredIPAddresses))
@@ -673,6 +759,10 @@ func (c *CheckConfig) Copy() *CheckConfig {
 	newConfig.PrivKey = c.PrivKey
 	newConfig.ContextName = c.ContextName
 	newConfig.ContextName = c.ContextName
+	if c.Authentications != nil {
+		newConfig.Authentications = make([]Authentication, len(c.Authentications))
+		copy(newConfig.Authentications, c.Authentications)
+	}
 	newConfig.OidConfig = c.OidConfig
 	newConfig.RequestedMetrics = make([]profiledefinition.MetricsConfig, len(c.RequestedMetrics))
 	copy(newConfig.RequestedMetrics, c.RequestedMetrics)
@@ -690,6 +780,8 @@ func (c *CheckConfig) Copy() *CheckConfig {
 	newConfig.OidBatchSize = c.OidBatchSize
 	newConfig.BulkMaxRepetitions = c.BulkMaxRepetitions
 	newConfig.Profiles = c.Profiles
+	newConfig.initConfigProfiles = c.initConfigProfiles
+	newConfig.profilesVersion = c.profilesVersion
 	newConfig.ProfileTags = netutils.CopyStrings(c.ProfileTags)
 	newConfig.Profile = c.Profile
 	newConfig.ProfileDef = c.ProfileDef
@@ -726,6 +818,38 @@ func (c *CheckConfig) CopyWithNewIP(ipAddress string) *CheckConfig {
 	return newConfig
 }
 
+// GetAuthentications returns the credentials to try, in order, to connect to a device:
+// the ones set at the instance level followed by `authentications`
+func (c *CheckConfig) GetAuthentications() []Authentication {
+	auths := make([]Authentication, 0, len(c.Authentications)+1)
+	instanceAuth := Authentication{
+		SnmpVersion:     c.SnmpVersion,
+		CommunityString: c.CommunityString,
+		User:            c.User,
+		AuthProtocol:    c.AuthProtocol,
+		AuthKey:         c.AuthKey,
+		PrivProtocol:    c.PrivProtocol,
+		PrivKey:         c.PrivKey,
+		ContextName:     c.ContextName,
+	}
+	if !instanceAuth.isEmpty() {
+		auths = append(auths, instanceAuth)
+	}
+	return append(auths, c.Authentications...)
+}
+
+// SetAuthentication replaces the credentials used to connect to the device
+func (c *CheckConfig) SetAuthentication(auth Authentication) {
+	c.SnmpVersion = auth.SnmpVersion
+	c.CommunityString = auth.CommunityString
+	c.User = auth.User
+	c.AuthProtocol = auth.AuthProtocol
+	c.AuthKey = auth.AuthKey
+	c.PrivProtocol = auth.PrivProtocol
+	c.PrivKey = auth.PrivKey
+	c.ContextName = auth.ContextName
+}
+
 // IsDiscovery return weather it's a network/autodiscovery config or not
 func (c *CheckConfig) IsDiscovery() bool {
 	return c.Network != ""
@@ -285,6 +285,45 @@ workers: 30
 	}, config.IgnoredIPAddresses)
 }
 
+func TestDiscoveryAuthentications(t *testing.T) {
+	// language=yaml
+	rawInstanceConfig := []byte(`
+network_address: 127.0.0.0/24
+community_string: public
+authentications:
+  - community_string: private
+  - snmp_version: 3
+    user: monitor
+    authProtocol: sha
+    authKey: my-auth-key
+    privProtocol: aes
+    privKey: my-priv-key
+`)
+	config, err := NewCheckConfig(rawInstanceConfig, []byte(``))
+	assert.Nil(t, err)
+
+	expectedAuths := []Authentication{
+		{CommunityString: "public"},
+		{CommunityString: "private"},
+		{SnmpVersion: "3", User: "monitor", AuthProtocol: "sha", AuthKey: "my-auth-key", PrivProtocol: "aes", PrivKey: "my-priv-key"},
+	}
+	assert.Equal(t, expectedAuths, config.GetAuthentications())
+	assert.NotEqual(t, expectedAuths[0].Digest(), expectedAuths[1].Digest())
+
+	// the candidates are part of the discovery digest
+	withoutCandidates := config.Copy()
+	withoutCandidates.Authentications = nil
+	assert.NotEqual(t, config.DeviceDigest("127.0.0.1"), withoutCandidates.DeviceDigest("127.0.0.1"))
+
+	deviceConfig := config.CopyWithNewIP("127.0.0.1")
+	deviceConfig.SetAuthentication(expectedAuths[2])
+	assert.Equal(t, "3", deviceConfig.SnmpVersion)
+	assert.Equal(t, "", deviceConfig.CommunityString)
+	assert.Equal(t, "monitor", deviceConfig.User)
+	assert.Equal(t, "my-priv-key", deviceConfig.PrivKey)
+	assert.Equal(t, "public", config.CommunityString)
+}
+
 func TestProfileNormalizeMetrics(t *testing.T) {
 	profile.SetConfdPathAndCleanProfiles()
 
@@ -690,6 +729,35 @@ network_address: 10.0.0.0/xx
 				"couldn't parse SNMP network: invalid CIDR address: 10.0.0.0/xx",
 			},
 		},
+		{
+			name: "authentications without network",
+			// language=yaml
+			rawInstanceConfig: []byte(`
+ip_address: 1.2.3.4
+authentications:
+  - community_string: public
+`),
+			// language=yaml
+			rawInitConfig: []byte(``),
+			expectedErrors: []string{
+				"`authentications` can only be used with `network_address`",
+			},
+		},
+		{
+			name: "authentication without credentials",
+			// language=yaml
+			rawInstanceConfig: []byte(`
+network_address: 10.0.0.0/24
+authentications:
+  - community_string: public
+  - snmp_version: 3
+`),
+			// language=yaml
+			rawInitConfig: []byte(``),
+			expectedErrors: []string{
+				"authentication 1: `community_string` or `user` must be provided",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1695,6 +1763,46 @@ func TestSetAutodetectPreservesRequests(t *testing.T) {
 	}, config.OidConfig)
 }
 
+func TestCheckConfig_RefreshProfiles(t *testing.T) {
+	profile.SetConfdPathAndCleanProfiles()
+
+	// language=yaml
+	rawInstanceConfig := []byte(`
+ip_address: 1.2.3.4
+community_string: abc
+profile: f5-big-ip
+`)
+	config, err := NewCheckConfig(rawInstanceConfig, []byte(``))
+	assert.Nil(t, err)
+	assert.Equal(t, "f5-big-ip", config.Profile)
+
+	// nothing is reloaded while the version doesn't change
+	config.Profiles = profile.ProfileConfigMap{}
+	assert.Nil(t, config.RefreshProfiles())
+	assert.Empty(t, config.Profiles)
+
+	// the profile set in the config is applied again
+	config.profilesVersion = profile.GetProfilesVersion() + 1
+	config.ProfileDef = nil
+	assert.Nil(t, config.RefreshProfiles())
+	assert.Equal(t, profile.GetProfilesVersion(), config.profilesVersion)
+	assert.Equal(t, 2, len(config.Profiles))
+	assert.Equal(t, "f5-big-ip", config.Profile)
+	assert.NotNil(t, config.ProfileDef)
+
+	// an autodetected profile is detected again
+	config.AutodetectProfile = true
+	config.profilesVersion = profile.GetProfilesVersion() + 1
+	assert.Nil(t, config.RefreshProfiles())
+	assert.Equal(t, "", config.Profile)
+
+	// a profile that no longer exists is reported
+	config.AutodetectProfile = false
+	config.Profile = "unknown-profile"
+	config.profilesVersion = profile.GetProfilesVersion() + 1
+	assert.EqualError(t, config.RefreshProfiles(), "unknown profile `unknown-profile`")
+}
+
 func Test_buildConfig_DetectMetricsRefreshInterval(t *testing.T) {
 	// language=yaml
 	rawInstanceConfig := []byte(`
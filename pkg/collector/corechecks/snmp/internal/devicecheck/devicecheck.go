@@ -289,6 +289,10 @@ func (d *DeviceCheck) getValuesAndTags() (bool, []string, *valuestore.ResultValu
 		}
 	}
 
+	if err = d.config.RefreshProfiles(); err != nil {
+		log.Warnf("%s: %s", d.config.IPAddress, err)
+	}
+
 	err = d.detectMetricsToMonitor(d.session)
 	if err != nil {
 		d.diagnoses.Add("error", "SNMP_FAILED_TO_DETECT_PROFILE", "Agent failed to detect a profile for this network device.")
@@ -58,6 +58,25 @@ type snmpSubnet struct {
 	// discoveredDevices contains device failures count with device deviceDigest as map key
 	// see also CheckConfig.DeviceDigest()
 	deviceFailures map[checkconfig.DeviceDigest]int
+
+	// deviceAuths contains the digest of the credentials that worked for the device
+	// with device deviceDigest as map key, see also Authentication.Digest()
+	deviceAuths map[checkconfig.DeviceDigest]string
+}
+
+// cachedDevice is a discovered device persisted in cache
+type cachedDevice struct {
+	IP         net.IP `json:"ip"`
+	AuthDigest string `json:"auth_digest,omitempty"`
+}
+
+// UnmarshalJSON supports the legacy cache format where only device IPs were stored
+func (c *cachedDevice) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &c.IP)
+	}
+	type device cachedDevice
+	return json.Unmarshal(data, (*device)(c))
 }
 
 type checkDeviceJob struct {
@@ -129,6 +148,7 @@ func (d *Discovery) discoverDevices() {
 		// as Discovery.discoveredDevices, we rely on Discovery.discDevMu mutex to protect against concurrent changes.
 		devices:        map[checkconfig.DeviceDigest]string{},
 		deviceFailures: map[checkconfig.DeviceDigest]int{},
+		deviceAuths:    map[checkconfig.DeviceDigest]string{},
 	}
 
 	d.loadCache(&subnet)
@@ -177,40 +197,88 @@ func (d *Discovery) discoverDevices() {
 
 func (d *Discovery) checkDevice(job checkDeviceJob) error {
 	deviceIP := job.currentIP.String()
-	config := *job.subnet.config // shallow copy
-	config.IPAddress = deviceIP
-	sess, err := d.sessionFactory(&config)
-	if err != nil {
-		return fmt.Errorf("error configure session for ip %s: %v", deviceIP, err)
-	}
 	deviceDigest := job.subnet.config.DeviceDigest(deviceIP)
-	if err := sess.Connect(); err != nil {
-		log.Debugf("subnet %s: SNMP connect to %s error: %v", d.config.Network, deviceIP, err)
-		d.deleteDevice(deviceDigest, job.subnet)
-	} else {
-		defer sess.Close()
-
-		oids := []string{sysObjectIDOid}
-		// Since `params<GoSNMP>.ContextEngineID` is empty
-		// `params.Get` might lead to multiple SNMP GET calls when using SNMP v3
-		// a first call might be needed to retrieve the engineID and then the call to get the oid values.
-		value, err := sess.Get(oids)
+
+	var sessionErr error
+	for _, auth := range d.getAuthenticationsToTry(deviceDigest, job.subnet) {
+		config := *job.subnet.config // shallow copy
+		config.IPAddress = deviceIP
+		config.SetAuthentication(auth)
+		sess, err := d.sessionFactory(&config)
 		if err != nil {
-			log.Debugf("subnet %s: SNMP get to %s error: %v", d.config.Network, deviceIP, err)
-			d.deleteDevice(deviceDigest, job.subnet)
-		} else if len(value.Variables) < 1 || value.Variables[0].Value == nil {
-			log.Debugf("subnet %s: SNMP get to %s no data", d.config.Network, deviceIP)
-			d.deleteDevice(deviceDigest, job.subnet)
-		} else {
-			log.Debugf("subnet %s: SNMP get to %s success: %v", d.config.Network, deviceIP, value.Variables[0].Value)
-			d.createDevice(deviceDigest, job.subnet, deviceIP, true)
+			sessionErr = fmt.Errorf("error configure session for ip %s: %v", deviceIP, err)
+			continue
+		}
+		if d.isDeviceReachable(sess, deviceIP) {
+			d.createDevice(deviceDigest, job.subnet, deviceIP, auth, true)
+			return nil
+		}
+	}
+
+	d.deleteDevice(deviceDigest, job.subnet)
+	return sessionErr
+}
+
+// getAuthenticationsToTry returns the candidate credentials for a device, starting
+// with the ones that worked during the previous discovery
+func (d *Discovery) getAuthenticationsToTry(deviceDigest checkconfig.DeviceDigest, subnet *snmpSubnet) []checkconfig.Authentication {
+	auths := subnet.config.GetAuthentications()
+
+	d.discDevMu.RLock()
+	knownAuth, found := subnet.deviceAuths[deviceDigest]
+	d.discDevMu.RUnlock()
+	if !found {
+		return auths
+	}
+
+	for i, auth := range auths {
+		if auth.Digest() == knownAuth {
+			return append(append([]checkconfig.Authentication{auth}, auths[:i]...), auths[i+1:]...)
 		}
 	}
-	return nil
+	return auths
+}
+
+func (d *Discovery) isDeviceReachable(sess session.Session, deviceIP string) bool {
+	if err := sess.Connect(); err != nil {
+		log.Debugf("subnet %s: SNMP connect to %s error: %v", d.config.Network, deviceIP, err)
+		return false
+	}
+	defer sess.Close()
+
+	oids := []string{sysObjectIDOid}
+	// Since `params<GoSNMP>.ContextEngineID` is empty
+	// `params.Get` might lead to multiple SNMP GET calls when using SNMP v3
+	// a first call might be needed to retrieve the engineID and then the call to get the oid values.
+	value, err := sess.Get(oids)
+	if err != nil {
+		log.Debugf("subnet %s: SNMP get to %s error: %v", d.config.Network, deviceIP, err)
+		return false
+	} else if len(value.Variables) < 1 || value.Variables[0].Value == nil {
+		log.Debugf("subnet %s: SNMP get to %s no data", d.config.Network, deviceIP)
+		return false
+	}
+	log.Debugf("subnet %s: SNMP get to %s success: %v", d.config.Network, deviceIP, value.Variables[0].Value)
+	return true
 }
 
-func (d *Discovery) createDevice(deviceDigest checkconfig.DeviceDigest, subnet *snmpSubnet, deviceIP string, writeCache bool) {
-	deviceCk, err := devicecheck.NewDeviceCheck(subnet.config, deviceIP, d.sessionFactory)
+func (d *Discovery) createDevice(deviceDigest checkconfig.DeviceDigest, subnet *snmpSubnet, deviceIP string, auth checkconfig.Authentication, writeCache bool) {
+	authDigest := auth.Digest()
+
+	d.discDevMu.RLock()
+	_, present := d.discoveredDevices[deviceDigest]
+	knownAuth := subnet.deviceAuths[deviceDigest]
+	d.discDevMu.RUnlock()
+	if present && knownAuth == authDigest {
+		return
+	}
+
+	deviceConfig := subnet.config.Copy()
+	deviceConfig.SetAuthentication(auth)
+	// the device check only uses the credentials that worked
+	deviceConfig.Authentications = nil
+
+	deviceCk, err := devicecheck.NewDeviceCheck(deviceConfig, deviceIP, d.sessionFactory)
 	if err != nil {
 		// should not happen since the deviceCheck is expected to be valid at this point
 		// and are only changing the device ip
@@ -221,7 +289,8 @@ func (d *Discovery) createDevice(deviceDigest checkconfig.DeviceDigest, subnet *
 	d.discDevMu.Lock()
 	defer d.discDevMu.Unlock()
 
-	if _, present := d.discoveredDevices[deviceDigest]; present {
+	// the device is replaced if it has been rediscovered with other credentials
+	if _, present := d.discoveredDevices[deviceDigest]; present && subnet.deviceAuths[deviceDigest] == authDigest {
 		return
 	}
 	device := Device{
@@ -232,6 +301,7 @@ func (d *Discovery) createDevice(deviceDigest checkconfig.DeviceDigest, subnet *
 	d.discoveredDevices[deviceDigest] = device
 	subnet.devices[deviceDigest] = deviceIP
 	subnet.deviceFailures[deviceDigest] = 0
+	subnet.deviceAuths[deviceDigest] = authDigest
 
 	if writeCache {
 		d.writeCache(subnet)
@@ -257,20 +327,21 @@ func (d *Discovery) deleteDevice(deviceDigest checkconfig.DeviceDigest, subnet *
 			delete(d.discoveredDevices, deviceDigest)
 			delete(subnet.devices, deviceDigest)
 			delete(subnet.deviceFailures, deviceDigest)
+			delete(subnet.deviceAuths, deviceDigest)
 			d.writeCache(subnet)
 		}
 	}
 }
 
-func (d *Discovery) readCache(subnet *snmpSubnet) ([]net.IP, error) {
+func (d *Discovery) readCache(subnet *snmpSubnet) ([]cachedDevice, error) {
 	cacheValue, err := persistentcache.Read(subnet.cacheKey)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't read cache for %s: %s", subnet.cacheKey, err)
 	}
 	if cacheValue == "" {
-		return []net.IP{}, nil
+		return []cachedDevice{}, nil
 	}
-	var devices []net.IP
+	var devices []cachedDevice
 	if err = json.Unmarshal([]byte(cacheValue), &devices); err != nil {
 		return nil, fmt.Errorf("couldn't unmarshal cache for %s: %s", subnet.cacheKey, err)
 	}
@@ -283,17 +354,34 @@ func (d *Discovery) loadCache(subnet *snmpSubnet) {
 		log.Errorf("subnet %s: error reading cache: %s", d.config.Network, err)
 		return
 	}
-	for _, deviceIP := range devices {
-		deviceDigest := subnet.config.DeviceDigest(deviceIP.String())
-		d.createDevice(deviceDigest, subnet, deviceIP.String(), false)
+	auths := subnet.config.GetAuthentications()
+	for _, device := range devices {
+		// Devices cached before credentials were remembered, or whose credentials are no longer
+		// configured, use the first credentials until they are checked by the next discovery
+		var auth checkconfig.Authentication
+		if len(auths) > 0 {
+			auth = auths[0]
+		}
+		for _, candidate := range auths {
+			if candidate.Digest() == device.AuthDigest {
+				auth = candidate
+				break
+			}
+		}
+		deviceIP := device.IP.String()
+		deviceDigest := subnet.config.DeviceDigest(deviceIP)
+		d.createDevice(deviceDigest, subnet, deviceIP, auth, false)
 	}
 }
 
 func (d *Discovery) writeCache(subnet *snmpSubnet) {
 	// We don't lock the subnet for now, because the discovery ought to be already locked
-	devices := make([]string, 0, len(subnet.devices))
-	for _, v := range subnet.devices {
-		devices = append(devices, v)
+	devices := make([]cachedDevice, 0, len(subnet.devices))
+	for deviceDigest, deviceIP := range subnet.devices {
+		devices = append(devices, cachedDevice{
+			IP:         net.ParseIP(deviceIP),
+			AuthDigest: subnet.deviceAuths[deviceDigest],
+		})
 	}
 
 	cacheValue, err := json.Marshal(devices)
@@ -6,6 +6,7 @@
 package discovery
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
 	"path/filepath"
@@ -209,6 +210,7 @@ func TestDiscovery_checkDevice(t *testing.T) {
 		cacheKey:       "abc:123",
 		devices:        map[checkconfig.DeviceDigest]string{},
 		deviceFailures: map[checkconfig.DeviceDigest]int{},
+		deviceAuths:    map[checkconfig.DeviceDigest]string{},
 	}
 
 	job := checkDeviceJob{
@@ -327,14 +329,16 @@ func TestDiscovery_createDevice(t *testing.T) {
 		cacheKey:       "abc:123",
 		devices:        map[checkconfig.DeviceDigest]string{},
 		deviceFailures: map[checkconfig.DeviceDigest]int{},
+		deviceAuths:    map[checkconfig.DeviceDigest]string{},
 	}
 
 	device1Digest := subnet.config.DeviceDigest("192.168.0.1")
 	device2Digest := subnet.config.DeviceDigest("192.168.0.2")
 	device3Digest := subnet.config.DeviceDigest("192.168.0.3")
-	discovery.createDevice(device1Digest, subnet, "192.168.0.1", true)
-	discovery.createDevice(device2Digest, subnet, "192.168.0.2", true)
-	discovery.createDevice(device3Digest, subnet, "192.168.0.3", false)
+	auth := checkConfig.GetAuthentications()[0]
+	discovery.createDevice(device1Digest, subnet, "192.168.0.1", auth, true)
+	discovery.createDevice(device2Digest, subnet, "192.168.0.2", auth, true)
+	discovery.createDevice(device3Digest, subnet, "192.168.0.3", auth, false)
 
 	assert.Equal(t, 3, len(discovery.discoveredDevices))
 
@@ -365,3 +369,97 @@ func TestDiscovery_createDevice(t *testing.T) {
 	discovery.deleteDevice(device1Digest, subnet) // really deletes the device
 	assert.Equal(t, 2, len(discovery.discoveredDevices))
 }
+
+func TestDiscovery_checkDeviceAuthentications(t *testing.T) {
+	SetTestRunPath()
+	checkConfig := &checkconfig.CheckConfig{
+		Network:           "192.168.0.0/32",
+		CommunityString:   "public",
+		DiscoveryInterval: 1,
+		DiscoveryWorkers:  1,
+		Authentications: []checkconfig.Authentication{
+			{CommunityString: "private"},
+			{SnmpVersion: "3", User: "monitor", AuthProtocol: "sha", AuthKey: "secret"},
+		},
+	}
+	ipAddr, ipNet, err := net.ParseCIDR(checkConfig.Network)
+	assert.Nil(t, err)
+	startingIP := ipAddr.Mask(ipNet.Mask)
+
+	subnet := &snmpSubnet{
+		config:         checkConfig,
+		startingIP:     startingIP,
+		network:        *ipNet,
+		cacheKey:       "abc:456",
+		devices:        map[checkconfig.DeviceDigest]string{},
+		deviceFailures: map[checkconfig.DeviceDigest]int{},
+		deviceAuths:    map[checkconfig.DeviceDigest]string{},
+	}
+	job := checkDeviceJob{
+		subnet:    subnet,
+		currentIP: startingIP,
+	}
+
+	packet := gosnmp.SnmpPacket{
+		Variables: []gosnmp.SnmpPDU{
+			{
+				Name:  "1.3.6.1.2.1.1.2.0",
+				Type:  gosnmp.ObjectIdentifier,
+				Value: "1.3.6.1.4.1.3375.2.1.3.4.1",
+			},
+		},
+	}
+
+	// only the SNMPv3 user is accepted by the device
+	var triedAuths []string
+	discovery := NewDiscovery(checkConfig, session.NewMockSession)
+	discovery.sessionFactory = func(config *checkconfig.CheckConfig) (session.Session, error) {
+		sess := session.CreateMockSession()
+		if config.User == "monitor" {
+			triedAuths = append(triedAuths, config.User)
+			sess.On("Get", []string{"1.3.6.1.2.1.1.2.0"}).Return(&packet, nil)
+		} else {
+			triedAuths = append(triedAuths, config.CommunityString)
+			sess.On("Get", []string{"1.3.6.1.2.1.1.2.0"}).Return((*gosnmp.SnmpPacket)(nil), fmt.Errorf("timeout"))
+		}
+		return sess, nil
+	}
+
+	assert.Nil(t, discovery.checkDevice(job))
+	assert.Equal(t, []string{"public", "private", "monitor"}, triedAuths)
+	assert.Equal(t, 1, len(discovery.discoveredDevices))
+
+	deviceDigest := checkConfig.DeviceDigest("192.168.0.0")
+	deviceCk := discovery.discoveredDevices[deviceDigest].deviceCheck
+	v3AuthDigest := checkConfig.Authentications[1].Digest()
+	assert.Equal(t, v3AuthDigest, subnet.deviceAuths[deviceDigest])
+
+	// the working credentials are tried first on the next discovery
+	triedAuths = nil
+	assert.Nil(t, discovery.checkDevice(job))
+	assert.Equal(t, []string{"monitor"}, triedAuths)
+	assert.Same(t, deviceCk, discovery.discoveredDevices[deviceDigest].deviceCheck)
+
+	// and remembered across restarts
+	devices, err := discovery.readCache(subnet)
+	assert.Nil(t, err)
+	assert.Equal(t, []cachedDevice{{IP: net.ParseIP("192.168.0.0"), AuthDigest: v3AuthDigest}}, devices)
+
+	discovery2 := NewDiscovery(checkConfig, session.NewMockSession)
+	subnet2 := *subnet
+	subnet2.devices = map[checkconfig.DeviceDigest]string{}
+	subnet2.deviceFailures = map[checkconfig.DeviceDigest]int{}
+	subnet2.deviceAuths = map[checkconfig.DeviceDigest]string{}
+	discovery2.loadCache(&subnet2)
+	assert.Equal(t, 1, len(discovery2.discoveredDevices))
+	assert.Equal(t, v3AuthDigest, subnet2.deviceAuths[deviceDigest])
+}
+
+func TestDiscovery_readLegacyCache(t *testing.T) {
+	var devices []cachedDevice
+	assert.Nil(t, json.Unmarshal([]byte(`["192.168.0.1",{"ip":"192.168.0.2","auth_digest":"abc"}]`), &devices))
+	assert.Equal(t, []cachedDevice{
+		{IP: net.ParseIP("192.168.0.1")},
+		{IP: net.ParseIP("192.168.0.2"), AuthDigest: "abc"},
+	}, devices)
+}
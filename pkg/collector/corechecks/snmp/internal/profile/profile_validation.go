@@ -0,0 +1,267 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mohae/deepcopy"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/configvalidation"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+)
+
+// ProfileValidation holds the problems found in a profile file
+type ProfileValidation struct {
+	Name string
+	File string
+	// Errors prevent the profile from being loaded: schema errors, invalid extends,
+	// invalid symbols and sysobjectid conflicts with other user profiles
+	Errors []string
+	// Warnings don't prevent the profile from being loaded, e.g. unknown fields, or
+	// symbols whose name or OID doesn't match the MIB symbols of the default profiles
+	Warnings []string
+}
+
+// IsValid returns true if the profile can be loaded
+func (v ProfileValidation) IsValid() bool {
+	return len(v.Errors) == 0
+}
+
+// ValidateUserProfiles validates the yaml profiles of the user profiles folder (`conf.d/snmp.d/profiles`)
+func ValidateUserProfiles() ([]ProfileValidation, error) {
+	files, err := listYamlFiles(getProfileConfdRoot(userProfilesFolder))
+	if err != nil {
+		return nil, err
+	}
+	return ValidateProfileFiles(files), nil
+}
+
+// ValidateProfileFiles validates profile files against the default profiles and the user profiles.
+// A file replaces the user profile with the same name.
+func ValidateProfileFiles(files []string) []ProfileValidation {
+	defaultProfiles := getYamlDefaultProfiles()
+	userProfiles := getYamlUserProfiles()
+	knownSymbols := newSymbolTable(defaultProfiles)
+
+	validations := make([]ProfileValidation, 0, len(files))
+	definitions := make(map[string]*profiledefinition.ProfileDefinition, len(files))
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".yaml")
+		validation := ProfileValidation{Name: name, File: file}
+		definition, warnings, err := readProfileDefinitionForValidation(file)
+		validation.Warnings = append(validation.Warnings, warnings...)
+		if err != nil {
+			validation.Errors = append(validation.Errors, err.Error())
+		} else {
+			definitions[name] = definition
+			userProfiles[name] = ProfileConfig{Definition: *definition, IsUserProfile: true}
+		}
+		validations = append(validations, validation)
+	}
+
+	for i := range validations {
+		validation := &validations[i]
+		definition, ok := definitions[validation.Name]
+		if !ok {
+			continue
+		}
+		validation.Errors = append(validation.Errors, validateProfileDefinition(validation.Name, definition, userProfiles, defaultProfiles)...)
+		validation.Errors = append(validation.Errors, validateSysObjectIDs(validation.Name, definition, userProfiles)...)
+		validation.Warnings = append(validation.Warnings, knownSymbols.validate(definition)...)
+	}
+	return validations
+}
+
+func listYamlFiles(folder string) ([]string, error) {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile dir %q: %w", folder, err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".yaml") {
+			files = append(files, filepath.Join(folder, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// readProfileDefinitionForValidation reads a profile like it's read when the
+// profiles are loaded. The unknown and duplicated fields, ignored when loading
+// the profile, are reported as warnings.
+func readProfileDefinitionForValidation(file string) (*profiledefinition.ProfileDefinition, []string, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read file: %w", err)
+	}
+	definition, err := parseProfileDefinition(buf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("schema error: %w", err)
+	}
+	var warnings []string
+	if err := yaml.UnmarshalStrict(buf, profiledefinition.NewProfileDefinition()); err != nil {
+		warnings = append(warnings, fmt.Sprintf("ignored fields: %s", err))
+	}
+	return definition, warnings, nil
+}
+
+// validateProfileDefinition expands the profile and runs the validation done when profiles are loaded
+func validateProfileDefinition(name string, definition *profiledefinition.ProfileDefinition, userProfiles, defaultProfiles ProfileConfigMap) []string {
+	// Abstract profiles are only validated as part of the profiles extending them
+	if strings.HasPrefix(name, "_") {
+		return nil
+	}
+	expanded := deepcopy.Copy(*definition).(profiledefinition.ProfileDefinition)
+	profiles := mergeProfiles(defaultProfiles, userProfiles)
+	if err := recursivelyExpandBaseProfiles(name, &expanded, expanded.Extends, []string{}, profiles, defaultProfiles); err != nil {
+		return []string{err.Error()}
+	}
+	profiledefinition.NormalizeMetrics(expanded.Metrics)
+	errors := configvalidation.ValidateEnrichMetadata(expanded.Metadata)
+	errors = append(errors, configvalidation.ValidateEnrichMetrics(expanded.Metrics)...)
+	errors = append(errors, configvalidation.ValidateEnrichMetricTags(expanded.MetricTags)...)
+	return errors
+}
+
+// validateSysObjectIDs reports invalid patterns and the patterns also used by another user profile,
+// devices matching them can't be assigned a profile
+func validateSysObjectIDs(name string, definition *profiledefinition.ProfileDefinition, userProfiles ProfileConfigMap) []string {
+	var errors []string
+	for _, pattern := range definition.SysObjectIDs {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errors = append(errors, fmt.Sprintf("invalid sysobjectid pattern `%s`: %s", pattern, err))
+			continue
+		}
+		if _, err := getOidPatternSpecificity(pattern); err != nil {
+			errors = append(errors, fmt.Sprintf("invalid sysobjectid pattern `%s`: %s", pattern, err))
+			continue
+		}
+		var conflicts []string
+		for otherName, other := range userProfiles {
+			if otherName == name || strings.HasPrefix(otherName, "_") {
+				continue
+			}
+			for _, otherPattern := range other.Definition.SysObjectIDs {
+				if otherPattern == pattern {
+					conflicts = append(conflicts, otherName)
+				}
+			}
+		}
+		if len(conflicts) > 0 {
+			sort.Strings(conflicts)
+			errors = append(errors, fmt.Sprintf("sysobjectid `%s` conflicts with profiles: %s", pattern, strings.Join(conflicts, ", ")))
+		}
+	}
+	return errors
+}
+
+// symbolTable indexes the MIB symbols declared by profiles by name and by OID
+type symbolTable struct {
+	oidsByName map[string]map[string]struct{}
+	namesByOID map[string]map[string]struct{}
+}
+
+func newSymbolTable(profiles ProfileConfigMap) *symbolTable {
+	table := &symbolTable{
+		oidsByName: make(map[string]map[string]struct{}),
+		namesByOID: make(map[string]map[string]struct{}),
+	}
+	for _, profile := range profiles {
+		for _, symbol := range profileSymbols(&profile.Definition) {
+			if symbol.Name == "" || symbol.OID == "" {
+				continue
+			}
+			addToSet(table.oidsByName, symbol.Name, symbol.OID)
+			addToSet(table.namesByOID, symbol.OID, symbol.Name)
+		}
+	}
+	return table
+}
+
+// validate reports the symbols whose OID is known with another name, or whose name is known with another OID
+func (t *symbolTable) validate(definition *profiledefinition.ProfileDefinition) []string {
+	var warnings []string
+	reported := make(map[profiledefinition.SymbolConfig]struct{})
+	for _, symbol := range profileSymbols(definition) {
+		if symbol.Name == "" || symbol.OID == "" {
+			continue
+		}
+		if _, ok := reported[symbol]; ok {
+			continue
+		}
+		reported[symbol] = struct{}{}
+		if names, ok := t.namesByOID[symbol.OID]; ok {
+			if _, ok := names[symbol.Name]; !ok {
+				warnings = append(warnings, fmt.Sprintf("unknown MIB symbol `%s`: OID %s is known as %s", symbol.Name, symbol.OID, joinSet(names)))
+			}
+			continue
+		}
+		if oids, ok := t.oidsByName[symbol.Name]; ok {
+			warnings = append(warnings, fmt.Sprintf("unknown MIB symbol `%s` (%s): the symbol is known with OID %s", symbol.Name, symbol.OID, joinSet(oids)))
+		}
+	}
+	return warnings
+}
+
+// profileSymbols returns the symbols declared by a profile with normalized OIDs
+func profileSymbols(definition *profiledefinition.ProfileDefinition) []profiledefinition.SymbolConfig {
+	var symbols []profiledefinition.SymbolConfig
+	add := func(symbol profiledefinition.SymbolConfig) {
+		symbols = append(symbols, profiledefinition.SymbolConfig{Name: symbol.Name, OID: strings.TrimPrefix(symbol.OID, ".")})
+	}
+	addTags := func(tags []profiledefinition.MetricTagConfig) {
+		for _, tag := range tags {
+			symbol := profiledefinition.SymbolConfig(tag.Symbol)
+			if symbol.OID == "" {
+				symbol.OID = tag.OID
+			}
+			add(symbol)
+			add(tag.Column)
+		}
+	}
+	for _, metric := range definition.Metrics {
+		add(metric.Symbol)
+		add(profiledefinition.SymbolConfig{Name: metric.Name, OID: metric.OID})
+		add(metric.Table)
+		for _, symbol := range metric.Symbols {
+			add(symbol)
+		}
+		addTags(metric.MetricTags)
+	}
+	addTags(definition.MetricTags)
+	for _, resource := range definition.Metadata {
+		for _, field := range resource.Fields {
+			add(field.Symbol)
+			for _, symbol := range field.Symbols {
+				add(symbol)
+			}
+		}
+		addTags(resource.IDTags)
+	}
+	return symbols
+}
+
+func addToSet(sets map[string]map[string]struct{}, key string, value string) {
+	if _, ok := sets[key]; !ok {
+		sets[key] = make(map[string]struct{})
+	}
+	sets[key][value] = struct{}{}
+}
+
+func joinSet(set map[string]struct{}) string {
+	values := make([]string, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,227 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const validationDefaultProfile = `
+sysobjectid: 1.3.6.1.4.1.9.*
+metrics:
+  - MIB: IF-MIB
+    symbol:
+      OID: 1.3.6.1.2.1.2.1.0
+      name: ifNumber
+`
+
+// setTestUserProfiles creates a confd folder with a default profile and the given user profiles
+func setTestUserProfiles(t *testing.T, userProfiles map[string]string) string {
+	confdPath := t.TempDir()
+	defaultProfilesPath := filepath.Join(confdPath, "snmp.d", defaultProfilesFolder)
+	userProfilesPath := filepath.Join(confdPath, "snmp.d", userProfilesFolder)
+	require.NoError(t, os.MkdirAll(defaultProfilesPath, 0755))
+	require.NoError(t, os.MkdirAll(userProfilesPath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(defaultProfilesPath, "cisco.yaml"), []byte(validationDefaultProfile), 0644))
+	for name, content := range userProfiles {
+		require.NoError(t, os.WriteFile(filepath.Join(userProfilesPath, name+".yaml"), []byte(content), 0644))
+	}
+	config.Datadog().SetWithoutSource("confd_path", confdPath)
+	t.Cleanup(SetConfdPathAndCleanProfiles)
+	return userProfilesPath
+}
+
+func TestValidateUserProfiles(t *testing.T) {
+	setTestUserProfiles(t, map[string]string{
+		"valid": `
+sysobjectid: 1.3.6.1.4.1.8072.*
+metrics:
+  - symbol:
+      OID: 1.3.6.1.2.1.2.1.0
+      name: ifNumber
+`,
+		"schema-error": `
+sysobjectid: 1.3.6.1.4.1.1.*
+metrics: true
+`,
+		"unknown-field": `
+sysobjectid: 1.3.6.1.4.1.5.*
+metrics:
+  - symbol:
+      OID: 1.3.6.1.2.1.2.1.0
+      name: ifNumber
+      unknown_field: true
+`,
+		"unknown-symbol": `
+sysobjectid: 1.3.6.1.4.1.2.*
+metrics:
+  - symbol:
+      OID: 1.3.6.1.2.1.2.1.0
+      name: ifCount
+`,
+		"conflict-a": `
+sysobjectid: 1.3.6.1.4.1.3.1
+`,
+		"conflict-b": `
+sysobjectid: 1.3.6.1.4.1.3.1
+`,
+		"invalid-symbol": `
+sysobjectid: 1.3.6.1.4.1.4.*
+metrics:
+  - symbol:
+      name: ifNumber
+`,
+	})
+
+	validations, err := ValidateUserProfiles()
+	require.NoError(t, err)
+	require.Len(t, validations, 7)
+	byName := make(map[string]ProfileValidation)
+	for _, validation := range validations {
+		byName[validation.Name] = validation
+	}
+
+	assert.True(t, byName["valid"].IsValid())
+	assert.Empty(t, byName["valid"].Warnings)
+
+	require.Len(t, byName["schema-error"].Errors, 1)
+	assert.Contains(t, byName["schema-error"].Errors[0], "schema error:")
+
+	// unknown fields are ignored when loading profiles, they don't prevent the reload
+	assert.True(t, byName["unknown-field"].IsValid())
+	require.Len(t, byName["unknown-field"].Warnings, 1)
+	assert.Contains(t, byName["unknown-field"].Warnings[0], "ignored fields:")
+	assert.Contains(t, byName["unknown-field"].Warnings[0], "unknown_field")
+
+	assert.True(t, byName["unknown-symbol"].IsValid())
+	assert.Equal(t, []string{"unknown MIB symbol `ifCount`: OID 1.3.6.1.2.1.2.1.0 is known as ifNumber"}, byName["unknown-symbol"].Warnings)
+
+	assert.Equal(t, []string{"sysobjectid `1.3.6.1.4.1.3.1` conflicts with profiles: conflict-b"}, byName["conflict-a"].Errors)
+	assert.Equal(t, []string{"sysobjectid `1.3.6.1.4.1.3.1` conflicts with profiles: conflict-a"}, byName["conflict-b"].Errors)
+
+	assert.False(t, byName["invalid-symbol"].IsValid())
+}
+
+func TestValidateProfileFiles(t *testing.T) {
+	setTestUserProfiles(t, map[string]string{
+		"existing": `
+sysobjectid: 1.3.6.1.4.1.8072.*
+`,
+	})
+	file := filepath.Join(t.TempDir(), "new.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+extends:
+  - cisco.yaml
+sysobjectid: 1.3.6.1.4.1.8072.*
+`), 0644))
+	missingBase := filepath.Join(t.TempDir(), "missing-base.yaml")
+	require.NoError(t, os.WriteFile(missingBase, []byte(`
+extends:
+  - unknown.yaml
+`), 0644))
+
+	validations := ValidateProfileFiles([]string{file, missingBase})
+	require.Len(t, validations, 2)
+	assert.Equal(t, "new", validations[0].Name)
+	assert.Equal(t, file, validations[0].File)
+	assert.Equal(t, []string{"sysobjectid `1.3.6.1.4.1.8072.*` conflicts with profiles: existing"}, validations[0].Errors)
+	assert.Equal(t, "missing-base", validations[1].Name)
+	assert.False(t, validations[1].IsValid())
+}
+
+func TestReloadUserProfiles(t *testing.T) {
+	userProfilesPath := setTestUserProfiles(t, map[string]string{
+		"my-profile": `
+sysobjectid: 1.3.6.1.4.1.8072.*
+`,
+	})
+	SetGlobalProfileConfigMap(nil)
+	profiles, err := loadYamlProfiles()
+	require.NoError(t, err)
+	require.Contains(t, profiles, "my-profile")
+	version := GetProfilesVersion()
+
+	// invalid profiles are not loaded
+	require.NoError(t, os.WriteFile(filepath.Join(userProfilesPath, "my-profile.yaml"), []byte(`
+sysobjectid: 1.3.6.1.4.1.8072.*
+metrics: true
+`), 0644))
+	assert.ErrorContains(t, reloadUserProfiles(), "invalid user profiles: profile \"my-profile\": schema error:")
+	assert.Equal(t, version, GetProfilesVersion())
+	assert.Contains(t, GetGlobalProfileConfigMap(), "my-profile")
+
+	// unknown fields are ignored, like when the profiles are loaded at startup
+	require.NoError(t, os.WriteFile(filepath.Join(userProfilesPath, "my-profile.yaml"), []byte(`
+sysobjectid: 1.3.6.1.4.1.8072.*
+unknown_field: true
+`), 0644))
+	require.NoError(t, reloadUserProfiles())
+	assert.Equal(t, version+1, GetProfilesVersion())
+
+	// valid profiles replace the loaded profiles
+	require.NoError(t, os.Remove(filepath.Join(userProfilesPath, "my-profile.yaml")))
+	require.NoError(t, os.WriteFile(filepath.Join(userProfilesPath, "other-profile.yaml"), []byte(`
+sysobjectid: 1.3.6.1.4.1.8072.*
+`), 0644))
+	require.NoError(t, reloadUserProfiles())
+	assert.Equal(t, version+2, GetProfilesVersion())
+	assert.NotContains(t, GetGlobalProfileConfigMap(), "my-profile")
+	assert.Contains(t, GetGlobalProfileConfigMap(), "other-profile")
+	assert.Contains(t, GetGlobalProfileConfigMap(), "cisco")
+
+	// a removed folder is the same as an empty one
+	require.NoError(t, os.RemoveAll(userProfilesPath))
+	require.NoError(t, reloadUserProfiles())
+	assert.Equal(t, version+3, GetProfilesVersion())
+	assert.NotContains(t, GetGlobalProfileConfigMap(), "other-profile")
+}
+
+func TestCheckUserProfiles(t *testing.T) {
+	userProfilesPath := setTestUserProfiles(t, nil)
+	watcherLastDigest = userProfilesDigest()
+	version := GetProfilesVersion()
+
+	checkUserProfiles()
+	assert.Equal(t, version, GetProfilesVersion())
+
+	require.NoError(t, os.WriteFile(filepath.Join(userProfilesPath, "my-profile.yaml"), []byte(`
+sysobjectid: 1.3.6.1.4.1.8072.*
+`), 0644))
+	checkUserProfiles()
+	assert.Equal(t, version+1, GetProfilesVersion())
+
+	checkUserProfiles()
+	assert.Equal(t, version+1, GetProfilesVersion())
+}
+
+func TestStopUserProfilesWatcher(t *testing.T) {
+	setTestUserProfiles(t, nil)
+
+	StartUserProfilesWatcher()
+	StartUserProfilesWatcher()
+	stop := watcherStop
+	require.NotNil(t, stop)
+
+	// the watcher runs until it's released by every check
+	StopUserProfilesWatcher()
+	assert.Equal(t, stop, watcherStop)
+	StopUserProfilesWatcher()
+	assert.Nil(t, watcherStop)
+	assert.Zero(t, watcherUsers)
+	_, open := <-stop
+	assert.False(t, open)
+
+	// extra calls are ignored
+	StopUserProfilesWatcher()
+	assert.Zero(t, watcherUsers)
+}
@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const userProfilesWatchInterval = 10 * time.Second
+
+var (
+	// profilesVersion is incremented each time the user profiles are reloaded,
+	// checks compare it to the version of their profiles to know when to refresh them
+	profilesVersion atomic.Uint64
+
+	// watcherMu protects the watcher state, the watcher runs while at least one check uses it
+	watcherMu         sync.Mutex
+	watcherUsers      int
+	watcherStop       chan struct{}
+	watcherLastDigest string
+)
+
+// GetProfilesVersion returns the version of the user profiles
+func GetProfilesVersion() uint64 {
+	return profilesVersion.Load()
+}
+
+// StartUserProfilesWatcher starts watching the user profiles folder. The watcher is shared by the
+// checks, it's started by the first call and runs until every call is matched by a call to
+// StopUserProfilesWatcher.
+// When a profile is added, changed or removed, the user profiles are validated and swapped if they are all valid.
+func StartUserProfilesWatcher() {
+	watcherMu.Lock()
+	defer watcherMu.Unlock()
+
+	watcherUsers++
+	if watcherUsers > 1 {
+		return
+	}
+
+	watcherLastDigest = userProfilesDigest()
+	stop := make(chan struct{})
+	watcherStop = stop
+	go func() {
+		ticker := time.NewTicker(userProfilesWatchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				checkUserProfiles()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopUserProfilesWatcher releases the watcher started by StartUserProfilesWatcher, it's stopped
+// when no check uses it anymore.
+func StopUserProfilesWatcher() {
+	watcherMu.Lock()
+	defer watcherMu.Unlock()
+
+	if watcherUsers == 0 {
+		return
+	}
+	watcherUsers--
+	if watcherUsers == 0 {
+		close(watcherStop)
+		watcherStop = nil
+	}
+}
+
+// checkUserProfiles reloads the user profiles if the folder changed since the last check
+func checkUserProfiles() {
+	watcherMu.Lock()
+	defer watcherMu.Unlock()
+
+	digest := userProfilesDigest()
+	if digest == watcherLastDigest {
+		return
+	}
+	watcherLastDigest = digest
+	if err := reloadUserProfiles(); err != nil {
+		log.Errorf("Keeping the current SNMP profiles: %s", err)
+	}
+}
+
+// reloadUserProfiles validates the user profiles and swaps the loaded profiles when they are all valid
+func reloadUserProfiles() error {
+	// A removed folder is the same as an empty one
+	validations, err := ValidateUserProfiles()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	var invalidProfiles []string
+	for _, validation := range validations {
+		for _, warning := range validation.Warnings {
+			log.Warnf("SNMP profile %q: %s", validation.Name, warning)
+		}
+		for _, validationErr := range validation.Errors {
+			invalidProfiles = append(invalidProfiles, fmt.Sprintf("profile %q: %s", validation.Name, validationErr))
+		}
+	}
+	if len(invalidProfiles) > 0 {
+		return fmt.Errorf("invalid user profiles: %s", strings.Join(invalidProfiles, "; "))
+	}
+
+	defaultProfilesMu.Lock()
+	defer defaultProfilesMu.Unlock()
+	// yaml profiles are cached, other sources read the user profiles each time they are loaded
+	if GetGlobalProfileConfigMap() != nil {
+		profiles, err := resolveProfiles(getYamlUserProfiles(), getYamlDefaultProfiles())
+		if err != nil {
+			return err
+		}
+		SetGlobalProfileConfigMap(profiles)
+	}
+	version := profilesVersion.Add(1)
+	log.Infof("Reloaded SNMP user profiles (version %d)", version)
+	return nil
+}
+
+// userProfilesDigest returns a digest of the names, sizes and modification times of the user profiles
+func userProfilesDigest() string {
+	entries, err := os.ReadDir(getProfileConfdRoot(userProfilesFolder))
+	if err != nil {
+		return ""
+	}
+	var files []string
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, fmt.Sprintf("%s:%d:%d", entry.Name(), info.Size(), info.ModTime().UnixNano()))
+	}
+	sort.Strings(files)
+	return strings.Join(files, ",")
+}
@@ -89,14 +89,24 @@ func readProfileDefinition(definitionFile string) (*profiledefinition.ProfileDef
 		return nil, fmt.Errorf("unable to read file %q: %w", filePath, err)
 	}
 
-	profileDefinition := profiledefinition.NewProfileDefinition()
-	err = yaml.Unmarshal(buf, profileDefinition)
+	profileDefinition, err := parseProfileDefinition(buf)
 	if err != nil {
 		return nil, fmt.Errorf("parse error in file %q: %w", filePath, err)
 	}
 	return profileDefinition, nil
 }
 
+// parseProfileDefinition decodes a yaml profile, unknown fields are ignored.
+// It's used both to load and to validate the profiles, so that a profile
+// loaded at startup is also accepted when the user profiles are reloaded.
+func parseProfileDefinition(buf []byte) (*profiledefinition.ProfileDefinition, error) {
+	profileDefinition := profiledefinition.NewProfileDefinition()
+	if err := yaml.Unmarshal(buf, profileDefinition); err != nil {
+		return nil, err
+	}
+	return profileDefinition, nil
+}
+
 func resolveProfileDefinitionPath(definitionFile string) string {
 	if filepath.IsAbs(definitionFile) {
 		return definitionFile
@@ -19,6 +19,7 @@ import (
 
 	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
 	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/topology"
 	"github.com/DataDog/datadog-agent/pkg/networkdevice/utils"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
@@ -74,6 +75,10 @@ func (ms *MetricSender) ReportNetworkDeviceMetadata(config *checkconfig.CheckCon
 		ms.sender.EventPlatformEvent(payloadBytes, eventplatform.EventTypeNetworkDevicesMetadata)
 	}
 
+	if config.CollectTopology && store != nil {
+		ms.reportTopologyChanges(config, topologyLinks, collectTime)
+	}
+
 	// Telemetry
 	for _, interfaceStatus := range interfaces {
 		status := string(computeInterfaceStatus(interfaceStatus.AdminStatus, interfaceStatus.OperStatus))
@@ -334,14 +339,62 @@ func buildNetworkTopologyMetadata(deviceID string, store *metadata.Store, interf
 		return nil
 	}
 
-	links := buildNetworkTopologyMetadataWithLLDP(deviceID, store, interfaces)
-	if len(links) == 0 {
-		links = buildNetworkTopologyMetadataWithCDP(deviceID, store, interfaces)
+	links, lldpIfIndexes := buildNetworkTopologyMetadataWithLLDP(deviceID, store, interfaces)
+
+	lldpLinkIDs := make(map[string]struct{}, len(links))
+	for _, link := range links {
+		lldpLinkIDs[link.ID] = struct{}{}
+	}
+
+	// CDP links are reported alongside LLDP links, except for the interfaces
+	// already having an LLDP neighbor since both protocols usually run on Cisco devices
+	for _, cdpLink := range buildNetworkTopologyMetadataWithCDP(deviceID, store, interfaces) {
+		if _, ok := lldpIfIndexes[cdpLink.ifIndex]; ok {
+			continue
+		}
+		// the IDs of the CDP links are prefixed with the source type only when they collide with
+		// the ID of an LLDP link, so that the IDs of the other links don't change
+		if _, ok := lldpLinkIDs[cdpLink.link.ID]; ok {
+			cdpLink.link.ID = deviceID + ":" + topologyLinkSourceTypeCDP + ":" + strings.TrimPrefix(cdpLink.link.ID, deviceID+":")
+		}
+		links = append(links, cdpLink.link)
 	}
 	return links
 }
 
-func buildNetworkTopologyMetadataWithLLDP(deviceID string, store *metadata.Store, interfaces []devicemetadata.InterfaceMetadata) []devicemetadata.TopologyLinkMetadata {
+// cdpLink is a link built from the CDP cache with the ifIndex of its local interface
+type cdpLink struct {
+	link    devicemetadata.TopologyLinkMetadata
+	ifIndex string
+}
+
+// reportTopologyChanges reports the links added, removed or changed since the previous collection,
+// links are not tracked while the device is unreachable
+func (ms *MetricSender) reportTopologyChanges(config *checkconfig.CheckConfig, topologyLinks []devicemetadata.TopologyLinkMetadata, collectTime time.Time) {
+	if ms.topologyLinkTracker == nil {
+		ms.topologyLinkTracker = topology.NewLinkTracker(config.DeviceID)
+	}
+	changes := ms.topologyLinkTracker.Update(topologyLinks)
+	if len(changes) == 0 {
+		return
+	}
+	for _, change := range changes {
+		log.Debugf("Topology change on device %s: %s %s", config.DeviceID, change.Type, change.Link.ID)
+	}
+	payloads := devicemetadata.BatchTopologyChanges(config.Namespace, config.ResolvedSubnetName, collectTime, devicemetadata.PayloadMetadataBatchSize, changes)
+	for _, payload := range payloads {
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			log.Errorf("Error marshalling topology changes: %s", err)
+			return
+		}
+		ms.sender.EventPlatformEvent(payloadBytes, eventplatform.EventTypeNetworkDevicesMetadata)
+	}
+}
+
+// buildNetworkTopologyMetadataWithLLDP returns the links built from the LLDP remote table, and the
+// ifIndexes of their local interfaces
+func buildNetworkTopologyMetadataWithLLDP(deviceID string, store *metadata.Store, interfaces []devicemetadata.InterfaceMetadata) ([]devicemetadata.TopologyLinkMetadata, map[string]struct{}) {
 	interfaceIndexByIDType := buildInterfaceIndexByIDType(interfaces)
 
 	remManAddrByLLDPRemIndex := getRemManIPAddrByLLDPRemIndex(store.GetColumnIndexes("lldp_remote_management.interface_id_type"))
@@ -349,10 +402,11 @@ func buildNetworkTopologyMetadataWithLLDP(deviceID string, store *metadata.Store
 	indexes := store.GetColumnIndexes("lldp_remote.interface_id") // using `lldp_remote.interface_id` to get indexes since it's expected to be always present
 	if len(indexes) == 0 {
 		log.Debugf("Unable to build links metadata: no lldp_remote indexes found")
-		return nil
+		return nil, nil
 	}
 	sort.Strings(indexes)
 	var links []devicemetadata.TopologyLinkMetadata
+	localIfIndexes := make(map[string]struct{}, len(indexes))
 	for _, strIndex := range indexes {
 		indexElems := strings.Split(strIndex, ".")
 
@@ -378,6 +432,14 @@ func buildNetworkTopologyMetadataWithLLDP(deviceID string, store *metadata.Store
 
 		resolvedLocalInterfaceID := resolveLocalInterface(deviceID, interfaceIndexByIDType, localInterfaceIDType, localInterfaceID)
 
+		// lldpLocPortNum is the ifIndex of the local interface on most devices, it's used
+		// when the local interface can't be resolved
+		if resolvedLocalInterfaceID != "" {
+			localIfIndexes[strings.TrimPrefix(resolvedLocalInterfaceID, deviceID+":")] = struct{}{}
+		} else {
+			localIfIndexes[localPortNum] = struct{}{}
+		}
+
 		// remEntryUniqueID: The combination of localPortNum and lldpRemIndex is expected to be unique for each entry in
 		//                   lldpRemTable. We don't include lldpRemTimeMark (used for filtering only recent data) since it can change often.
 		remEntryUniqueID := localPortNum + "." + lldpRemIndex
@@ -412,18 +474,18 @@ func buildNetworkTopologyMetadataWithLLDP(deviceID string, store *metadata.Store
 		}
 		links = append(links, newLink)
 	}
-	return links
+	return links, localIfIndexes
 }
 
 //nolint:revive // TODO(NDM) Fix revive linter
-func buildNetworkTopologyMetadataWithCDP(deviceID string, store *metadata.Store, _ []devicemetadata.InterfaceMetadata) []devicemetadata.TopologyLinkMetadata {
+func buildNetworkTopologyMetadataWithCDP(deviceID string, store *metadata.Store, _ []devicemetadata.InterfaceMetadata) []cdpLink {
 	indexes := store.GetColumnIndexes("cdp_remote.interface_id") // using `cdp_remote.interface_id` to get indexes since it's expected to be always present
 	if len(indexes) == 0 {
 		log.Debugf("Unable to build links metadata: no cdp_remote indexes found")
 		return nil
 	}
 	sort.Strings(indexes)
-	var links []devicemetadata.TopologyLinkMetadata
+	var links []cdpLink
 	for _, strIndex := range indexes {
 		indexElems := strings.Split(strIndex, ".")
 
@@ -471,7 +533,7 @@ func buildNetworkTopologyMetadataWithCDP(deviceID string, store *metadata.Store,
 				},
 			},
 		}
-		links = append(links, newLink)
+		links = append(links, cdpLink{link: newLink, ifIndex: cdpCacheIfIndex})
 	}
 	return links
 }
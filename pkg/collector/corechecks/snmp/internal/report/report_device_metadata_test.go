@@ -15,6 +15,7 @@ import (
 	"github.com/cihub/seelog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -24,6 +25,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/snmp/snmpintegration"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	internalmetadata "github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/metadata"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/profile"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/valuestore"
 )
@@ -990,3 +992,135 @@ func Test_getProfileVersion(t *testing.T) {
 		})
 	}
 }
+
+func Test_buildNetworkTopologyMetadata_LLDPAndCDP(t *testing.T) {
+	store := internalmetadata.NewMetadataStore()
+	// LLDP neighbor on local port 1
+	store.AddColumnValue("lldp_local.interface_id_type", "1", valuestore.ResultValue{Value: "5"})
+	store.AddColumnValue("lldp_local.interface_id", "1", valuestore.ResultValue{Value: "eth1"})
+	store.AddColumnValue("lldp_remote.interface_id_type", "0.1.1", valuestore.ResultValue{Value: "5"})
+	store.AddColumnValue("lldp_remote.interface_id", "0.1.1", valuestore.ResultValue{Value: "eth48"})
+	store.AddColumnValue("lldp_remote.chassis_id_type", "0.1.1", valuestore.ResultValue{Value: "7"})
+	store.AddColumnValue("lldp_remote.chassis_id", "0.1.1", valuestore.ResultValue{Value: "switch-1"})
+	// CDP neighbors on interfaces 1 and 2
+	for _, index := range []string{"1.1", "2.1"} {
+		store.AddColumnValue("cdp_remote.interface_id", index, valuestore.ResultValue{Value: "Gi0/" + index})
+		store.AddColumnValue("cdp_remote.device_id", index, valuestore.ResultValue{Value: "phone-" + index})
+	}
+	interfaces := []metadata.InterfaceMetadata{
+		{DeviceID: "default:1.2.3.4", Index: 1, Name: "eth1"},
+		{DeviceID: "default:1.2.3.4", Index: 2, Name: "eth2"},
+	}
+
+	links := buildNetworkTopologyMetadata("default:1.2.3.4", store, interfaces)
+
+	// the CDP neighbor of interface 1 is already reported by LLDP
+	assert.Len(t, links, 2)
+	assert.Equal(t, "default:1.2.3.4:1.1", links[0].ID)
+	assert.Equal(t, "lldp", links[0].SourceType)
+	assert.Equal(t, "default:1.2.3.4:1", links[0].Local.Interface.DDID)
+	assert.Equal(t, "default:1.2.3.4:2.1", links[1].ID)
+	assert.Equal(t, "cdp", links[1].SourceType)
+	assert.Equal(t, "default:1.2.3.4:2", links[1].Local.Interface.DDID)
+	assert.Equal(t, "phone-2.1", links[1].Remote.Device.ID)
+}
+
+func Test_buildNetworkTopologyMetadata_LLDPAndCDPIfIndexes(t *testing.T) {
+	store := internalmetadata.NewMetadataStore()
+	// LLDP neighbor on local port 3, whose local interface can't be resolved
+	store.AddColumnValue("lldp_local.interface_id", "3", valuestore.ResultValue{Value: "unknown"})
+	// LLDP neighbor on local port 4, resolved to the interface 6
+	store.AddColumnValue("lldp_local.interface_id_type", "4", valuestore.ResultValue{Value: "5"})
+	store.AddColumnValue("lldp_local.interface_id", "4", valuestore.ResultValue{Value: "eth6"})
+	for _, index := range []string{"0.3.1", "0.4.1"} {
+		store.AddColumnValue("lldp_remote.interface_id_type", index, valuestore.ResultValue{Value: "5"})
+		store.AddColumnValue("lldp_remote.interface_id", index, valuestore.ResultValue{Value: "eth48"})
+		store.AddColumnValue("lldp_remote.chassis_id_type", index, valuestore.ResultValue{Value: "7"})
+		store.AddColumnValue("lldp_remote.chassis_id", index, valuestore.ResultValue{Value: "switch-" + index})
+	}
+	// CDP neighbors on interfaces 3, 4 and 6
+	for _, index := range []string{"3.1", "4.1", "6.1"} {
+		store.AddColumnValue("cdp_remote.interface_id", index, valuestore.ResultValue{Value: "Gi0/" + index})
+		store.AddColumnValue("cdp_remote.device_id", index, valuestore.ResultValue{Value: "phone-" + index})
+	}
+	interfaces := []metadata.InterfaceMetadata{
+		{DeviceID: "default:1.2.3.4", Index: 4, Name: "eth4"},
+		{DeviceID: "default:1.2.3.4", Index: 6, Name: "eth6"},
+	}
+
+	links := buildNetworkTopologyMetadata("default:1.2.3.4", store, interfaces)
+
+	// the CDP neighbors of the interfaces 3 and 6 are already reported by LLDP, the ID of the
+	// CDP link of the interface 4 is prefixed since it collides with the LLDP link of the local port 4
+	require.Len(t, links, 3)
+	assert.Equal(t, "default:1.2.3.4:3.1", links[0].ID)
+	assert.Empty(t, links[0].Local.Interface.DDID)
+	assert.Equal(t, "default:1.2.3.4:4.1", links[1].ID)
+	assert.Equal(t, "default:1.2.3.4:6", links[1].Local.Interface.DDID)
+	assert.Equal(t, "default:1.2.3.4:cdp:4.1", links[2].ID)
+	assert.Equal(t, "cdp", links[2].SourceType)
+	assert.Equal(t, "default:1.2.3.4:4", links[2].Local.Interface.DDID)
+}
+
+func Test_metricSender_reportTopologyChanges(t *testing.T) {
+	sender := mocksender.NewMockSender("testID") // required to initiate aggregator
+	sender.On("EventPlatformEvent", mock.Anything, mock.Anything).Return()
+	ms := &MetricSender{
+		sender: sender,
+	}
+	config := &checkconfig.CheckConfig{
+		DeviceID:           "my-ns:1.2.3.4",
+		ResolvedSubnetName: "127.0.0.0/29",
+		Namespace:          "my-ns",
+	}
+	link := metadata.TopologyLinkMetadata{
+		ID:         "my-ns:1.2.3.4:1.1",
+		SourceType: "lldp",
+		Local: &metadata.TopologyLinkSide{
+			Device:    &metadata.TopologyLinkDevice{DDID: "my-ns:1.2.3.4"},
+			Interface: &metadata.TopologyLinkInterface{DDID: "my-ns:1.2.3.4:1", ID: "eth1"},
+		},
+		Remote: &metadata.TopologyLinkSide{
+			Device:    &metadata.TopologyLinkDevice{ID: "switch-1"},
+			Interface: &metadata.TopologyLinkInterface{ID: "eth48"},
+		},
+	}
+	collectTime := time.Unix(946684800, 0)
+
+	// the first collection is the baseline
+	ms.reportTopologyChanges(config, nil, collectTime)
+	sender.AssertNotCalled(t, "EventPlatformEvent", mock.Anything, mock.Anything)
+
+	ms.reportTopologyChanges(config, []metadata.TopologyLinkMetadata{link}, collectTime)
+
+	// language=json
+	event := []byte(`
+{
+  "subnet": "127.0.0.0/29",
+  "namespace": "my-ns",
+  "topology_changes": [
+    {
+      "device_id": "my-ns:1.2.3.4",
+      "type": "link_added",
+      "link": {
+        "id": "my-ns:1.2.3.4:1.1",
+        "source_type": "lldp",
+        "local": {
+          "device": {"dd_id": "my-ns:1.2.3.4"},
+          "interface": {"dd_id": "my-ns:1.2.3.4:1", "id": "eth1"}
+        },
+        "remote": {
+          "device": {"id": "switch-1"},
+          "interface": {"id": "eth48"}
+        }
+      }
+    }
+  ],
+  "collect_timestamp": 946684800
+}
+`)
+	compactEvent := new(bytes.Buffer)
+	err := json.Compact(compactEvent, event)
+	assert.NoError(t, err)
+	sender.AssertEventPlatformEvent(t, compactEvent.Bytes(), "network-devices-metadata")
+}
@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/persistentcache"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const engineCacheKeyPrefix = "snmp_engine"
+
+// for testing
+var timeNow = time.Now
+
+// engineParameters are the parameters of the SNMPv3 authoritative engine of a device.
+// They are cached to skip the engine discovery request after an agent restart.
+type engineParameters struct {
+	// engine IDs are arbitrary bytes, they are stored as []byte to be base64 encoded
+	EngineID        []byte `json:"engine_id"`
+	ContextEngineID []byte `json:"context_engine_id"`
+	EngineBoots     uint32 `json:"engine_boots"`
+	EngineTime      uint32 `json:"engine_time"`
+	// Timestamp is the unix time at which EngineTime was observed
+	Timestamp int64 `json:"timestamp"`
+}
+
+// engineCacheKey returns the cache key for the engine parameters of a device.
+// The address is hashed since the cache key is sanitized and would be ambiguous.
+func engineCacheKey(address string, port uint16) string {
+	h := fnv.New64()
+	// Hash write never returns an error
+	h.Write([]byte(address))                  //nolint:errcheck
+	h.Write([]byte(fmt.Sprintf(":%d", port))) //nolint:errcheck
+	return fmt.Sprintf("%s:%s", engineCacheKeyPrefix, strconv.FormatUint(h.Sum64(), 16))
+}
+
+func readEngineParameters(cacheKey string) *engineParameters {
+	cacheValue, err := persistentcache.Read(cacheKey)
+	if err != nil {
+		log.Debugf("couldn't read cache for %s: %s", cacheKey, err)
+		return nil
+	}
+	if cacheValue == "" {
+		return nil
+	}
+	params := &engineParameters{}
+	if err = json.Unmarshal([]byte(cacheValue), params); err != nil {
+		log.Debugf("couldn't unmarshal cache for %s: %s", cacheKey, err)
+		return nil
+	}
+	return params
+}
+
+func writeEngineParameters(cacheKey string, params *engineParameters) {
+	cacheValue, err := json.Marshal(params)
+	if err != nil {
+		log.Debugf("couldn't marshal cache for %s: %s", cacheKey, err)
+		return
+	}
+	if err = persistentcache.Write(cacheKey, string(cacheValue)); err != nil {
+		log.Debugf("couldn't write cache for %s: %s", cacheKey, err)
+	}
+}
+
+// applyCachedEngineParameters sets the engine parameters saved by a previous agent run,
+// the engine time is estimated from the time elapsed since it was saved.
+// If they are outdated, the device answers with a report and gosnmp retries with the right ones.
+func (s *GosnmpSession) applyCachedEngineParameters() {
+	params := readEngineParameters(s.engineCacheKey)
+	if params == nil || len(params.EngineID) == 0 {
+		return
+	}
+	usmParams, ok := s.gosnmpInst.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok {
+		return
+	}
+
+	engineTime := params.EngineTime
+	if elapsed := timeNow().Unix() - params.Timestamp; elapsed > 0 {
+		engineTime += uint32(elapsed)
+	}
+	usmParams.AuthoritativeEngineID = string(params.EngineID)
+	usmParams.AuthoritativeEngineBoots = params.EngineBoots
+	usmParams.AuthoritativeEngineTime = engineTime
+	s.gosnmpInst.ContextEngineID = string(params.ContextEngineID)
+	s.cachedEngine = params
+}
+
+// saveEngineParameters caches the engine parameters discovered by gosnmp if they changed
+func (s *GosnmpSession) saveEngineParameters() {
+	if s.engineCacheKey == "" {
+		return
+	}
+	usmParams, ok := s.gosnmpInst.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok || usmParams.AuthoritativeEngineID == "" {
+		return
+	}
+	if s.cachedEngine != nil &&
+		string(s.cachedEngine.EngineID) == usmParams.AuthoritativeEngineID &&
+		s.cachedEngine.EngineBoots == usmParams.AuthoritativeEngineBoots &&
+		string(s.cachedEngine.ContextEngineID) == s.gosnmpInst.ContextEngineID {
+		return
+	}
+
+	params := &engineParameters{
+		EngineID:        []byte(usmParams.AuthoritativeEngineID),
+		ContextEngineID: []byte(s.gosnmpInst.ContextEngineID),
+		EngineBoots:     usmParams.AuthoritativeEngineBoots,
+		EngineTime:      usmParams.AuthoritativeEngineTime,
+		Timestamp:       timeNow().Unix(),
+	}
+	writeEngineParameters(s.engineCacheKey, params)
+	s.cachedEngine = params
+}
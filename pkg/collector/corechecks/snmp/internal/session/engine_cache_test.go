@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+)
+
+func Test_engineParametersCache(t *testing.T) {
+	configmock.New(t).SetWithoutSource("run_path", t.TempDir())
+	now := time.Unix(1700000000, 0)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	config := checkconfig.CheckConfig{
+		IPAddress:    "1.2.3.4",
+		Port:         uint16(161),
+		User:         "myUser",
+		AuthKey:      "myAuthKey",
+		AuthProtocol: "sha",
+	}
+
+	// nothing cached yet, the engine will be discovered
+	s, err := NewGosnmpSession(&config)
+	require.NoError(t, err)
+	sess := s.(*GosnmpSession)
+	usmParams := sess.gosnmpInst.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	assert.Equal(t, "", usmParams.AuthoritativeEngineID)
+
+	// simulate the engine discovery done by gosnmp on the first request
+	usmParams.AuthoritativeEngineID = "\x80\x00\x1f\x88\x80\xff"
+	usmParams.AuthoritativeEngineBoots = 3
+	usmParams.AuthoritativeEngineTime = 1000
+	sess.gosnmpInst.ContextEngineID = "\x80\x00\x1f\x88\x80\xff"
+	sess.saveEngineParameters()
+
+	// a new session, e.g. after a restart, reuses the engine parameters
+	now = now.Add(60 * time.Second)
+	s, err = NewGosnmpSession(&config)
+	require.NoError(t, err)
+	sess = s.(*GosnmpSession)
+	usmParams = sess.gosnmpInst.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	assert.Equal(t, "\x80\x00\x1f\x88\x80\xff", usmParams.AuthoritativeEngineID)
+	assert.Equal(t, uint32(3), usmParams.AuthoritativeEngineBoots)
+	assert.Equal(t, uint32(1060), usmParams.AuthoritativeEngineTime)
+	assert.Equal(t, "\x80\x00\x1f\x88\x80\xff", sess.gosnmpInst.ContextEngineID)
+
+	// parameters are only saved again when the engine changes
+	usmParams.AuthoritativeEngineTime = 2000
+	sess.saveEngineParameters()
+	assert.Equal(t, uint32(1000), readEngineParameters(sess.engineCacheKey).EngineTime)
+	usmParams.AuthoritativeEngineBoots = 4
+	sess.saveEngineParameters()
+	assert.Equal(t, uint32(2000), readEngineParameters(sess.engineCacheKey).EngineTime)
+
+	// other devices and v2c sessions are not affected
+	config.IPAddress = "1.2.3.40"
+	s, err = NewGosnmpSession(&config)
+	require.NoError(t, err)
+	assert.Equal(t, "", s.(*GosnmpSession).gosnmpInst.SecurityParameters.(*gosnmp.UsmSecurityParameters).AuthoritativeEngineID)
+
+	s, err = NewGosnmpSession(&checkconfig.CheckConfig{IPAddress: "1.2.3.4", Port: 161, CommunityString: "public"})
+	require.NoError(t, err)
+	assert.Equal(t, "", s.(*GosnmpSession).engineCacheKey)
+}
@@ -39,6 +39,10 @@ type Session interface {
 // GosnmpSession is used to connect to a snmp device
 type GosnmpSession struct {
 	gosnmpInst gosnmp.GoSNMP
+
+	// SNMPv3 engine parameters cache, see engine_cache.go
+	engineCacheKey string
+	cachedEngine   *engineParameters
 }
 
 // Connect is used to create a new connection
@@ -53,17 +57,29 @@ func (s *GosnmpSession) Close() error {
 
 // Get will send a SNMPGET command
 func (s *GosnmpSession) Get(oids []string) (result *gosnmp.SnmpPacket, err error) {
-	return s.gosnmpInst.Get(oids)
+	result, err = s.gosnmpInst.Get(oids)
+	if err == nil {
+		s.saveEngineParameters()
+	}
+	return result, err
 }
 
 // GetBulk will send a SNMP BULKGET command
 func (s *GosnmpSession) GetBulk(oids []string, bulkMaxRepetitions uint32) (result *gosnmp.SnmpPacket, err error) {
-	return s.gosnmpInst.GetBulk(oids, 0, bulkMaxRepetitions)
+	result, err = s.gosnmpInst.GetBulk(oids, 0, bulkMaxRepetitions)
+	if err == nil {
+		s.saveEngineParameters()
+	}
+	return result, err
 }
 
 // GetNext will send a SNMP GETNEXT command
 func (s *GosnmpSession) GetNext(oids []string) (result *gosnmp.SnmpPacket, err error) {
-	return s.gosnmpInst.GetNext(oids)
+	result, err = s.gosnmpInst.GetNext(oids)
+	if err == nil {
+		s.saveEngineParameters()
+	}
+	return result, err
 }
 
 // GetVersion returns the snmp version used
@@ -133,6 +149,11 @@ func NewGosnmpSession(config *checkconfig.CheckConfig) (Session, error) {
 	s.gosnmpInst.Timeout = time.Duration(config.Timeout) * time.Second
 	s.gosnmpInst.Retries = config.Retries
 
+	if s.gosnmpInst.Version == gosnmp.Version3 {
+		s.engineCacheKey = engineCacheKey(config.IPAddress, config.Port)
+		s.applyCachedEngineParameters()
+	}
+
 	lvl, err := log.GetLogLevel()
 	if err != nil {
 		log.Warnf("failed to get logger: %s", err)
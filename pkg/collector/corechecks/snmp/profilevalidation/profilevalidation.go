@@ -0,0 +1,24 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package profilevalidation exposes the validation of SNMP profiles to the agent commands
+package profilevalidation
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/profile"
+)
+
+// ProfileValidation holds the errors and warnings found in a profile file
+type ProfileValidation = profile.ProfileValidation
+
+// ValidateUserProfiles validates the profiles of the user profiles folder (`conf.d/snmp.d/profiles`)
+func ValidateUserProfiles() ([]ProfileValidation, error) {
+	return profile.ValidateUserProfiles()
+}
+
+// ValidateProfileFiles validates profile files against the default profiles and the user profiles
+func ValidateProfileFiles(files []string) []ProfileValidation {
+	return profile.ValidateProfileFiles(files)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/common"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/devicecheck"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/discovery"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/profile"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/report"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
 	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
@@ -44,6 +45,7 @@ type Check struct {
 	discovery                  *discovery.Discovery
 	sessionFactory             session.Factory
 	workerRunDeviceCheckErrors *atomic.Uint64
+	watchingUserProfiles       bool
 }
 
 // Run executes the check
@@ -163,6 +165,13 @@ func (c *Check) Configure(senderManager sender.SenderManager, integrationConfigD
 			return fmt.Errorf("failed to create device check: %s", err)
 		}
 	}
+
+	// Changes to the user profiles are applied by the device checks without restarting them.
+	// The watcher is only taken once the check is configured, Cancel isn't called on failures.
+	if !c.watchingUserProfiles {
+		profile.StartUserProfilesWatcher()
+		c.watchingUserProfiles = true
+	}
 	return nil
 }
 
@@ -172,6 +181,10 @@ func (c *Check) Cancel() {
 		c.discovery.Stop()
 		c.discovery = nil
 	}
+	if c.watchingUserProfiles {
+		profile.StopUserProfilesWatcher()
+		c.watchingUserProfiles = false
+	}
 }
 
 // Interval returns the scheduling time for the check
@@ -2581,6 +2581,26 @@ community_string: public
 	chk.Cancel()
 }
 
+func TestCheckConfigureErrorDoesNotWatchProfiles(t *testing.T) {
+	deps := createDeps(t)
+	profile.SetConfdPathAndCleanProfiles()
+	chk := Check{sessionFactory: session.NewMockSession}
+
+	// language=yaml
+	rawInstanceConfig := []byte(`
+ip_address: 1.2.3.4
+community_string: public
+`)
+	// language=yaml
+	rawInitConfig := []byte(`
+service: [invalid]
+`)
+
+	err := chk.Configure(deps.Demultiplexer, integration.FakeConfigHash, rawInstanceConfig, rawInitConfig, "test")
+	assert.ErrorContains(t, err, "common configure failed")
+	assert.False(t, chk.watchingUserProfiles)
+}
+
 // Wait for discovery to be completed
 func waitForDiscoveredDevices(discovery *discovery.Discovery, expectedDeviceCount int, timeout time.Duration) ([]*devicecheck.DeviceCheck, error) {
 	timeoutTimer := time.After(timeout)
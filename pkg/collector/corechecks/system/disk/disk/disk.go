@@ -8,8 +8,10 @@
 package disk
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	yaml "gopkg.in/yaml.v2"
 
@@ -35,6 +37,13 @@ type diskConfig struct {
 	excludedMountpointRe *regexp.Regexp
 	allPartitions        bool
 	deviceTagRe          map[*regexp.Regexp][]string
+
+	forecastTimeToFull     bool
+	forecastWindow         time.Duration
+	forecastMinSamples     int
+	timeToFullServiceCheck bool
+	timeToFullWarning      time.Duration
+	timeToFullCritical     time.Duration
 }
 
 func (c *Check) excludeDisk(mountpoint, device, fstype string) bool {
@@ -83,7 +92,12 @@ func (c *Check) excludeDisk(mountpoint, device, fstype string) bool {
 
 func (c *Check) instanceConfigure(data integration.Data) error {
 	conf := make(map[interface{}]interface{})
-	c.cfg = &diskConfig{}
+	c.cfg = &diskConfig{
+		forecastWindow:     defaultForecastWindow,
+		forecastMinSamples: defaultForecastMinSamples,
+		timeToFullWarning:  defaultTimeToFullWarning,
+		timeToFullCritical: defaultTimeToFullCritical,
+	}
 	err := yaml.Unmarshal([]byte(data), &conf)
 	if err != nil {
 		return err
@@ -149,6 +163,44 @@ func (c *Check) instanceConfigure(data integration.Data) error {
 		}
 	}
 
+	forecastTimeToFull, found := conf["forecast_time_to_full"]
+	if forecastTimeToFull, ok := forecastTimeToFull.(bool); found && ok {
+		c.cfg.forecastTimeToFull = forecastTimeToFull
+	}
+
+	forecastWindow, found := conf["forecast_window"]
+	if forecastWindow, ok := forecastWindow.(int); found && ok {
+		if forecastWindow <= 0 {
+			return fmt.Errorf("forecast_window must be a positive number of seconds, got %d", forecastWindow)
+		}
+		c.cfg.forecastWindow = time.Duration(forecastWindow) * time.Second
+	}
+
+	forecastMinSamples, found := conf["forecast_min_samples"]
+	if forecastMinSamples, ok := forecastMinSamples.(int); found && ok {
+		// at least two points are needed to compute a growth rate
+		if forecastMinSamples < 2 || forecastMinSamples > forecastMaxSamplesPerMount {
+			return fmt.Errorf("forecast_min_samples must be between 2 and %d, got %d", forecastMaxSamplesPerMount, forecastMinSamples)
+		}
+		c.cfg.forecastMinSamples = forecastMinSamples
+	}
+
+	timeToFullServiceCheck, found := conf["time_to_full_service_check"]
+	if timeToFullServiceCheck, ok := timeToFullServiceCheck.(bool); found && ok {
+		c.cfg.timeToFullServiceCheck = timeToFullServiceCheck
+	}
+
+	// a threshold set to 0 disables the corresponding status
+	timeToFullWarning, found := conf["time_to_full_warning"]
+	if timeToFullWarning, ok := timeToFullWarning.(int); found && ok {
+		c.cfg.timeToFullWarning = time.Duration(timeToFullWarning) * time.Second
+	}
+
+	timeToFullCritical, found := conf["time_to_full_critical"]
+	if timeToFullCritical, ok := timeToFullCritical.(int); found && ok {
+		c.cfg.timeToFullCritical = time.Duration(timeToFullCritical) * time.Second
+	}
+
 	return nil
 }
 
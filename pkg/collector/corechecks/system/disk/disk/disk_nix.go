@@ -30,7 +30,8 @@ var (
 // Check stores disk-specific additional fields
 type Check struct {
 	core.CheckBase
-	cfg *diskConfig
+	cfg        *diskConfig
+	forecaster *usageForecaster
 }
 
 // Run executes the check
@@ -59,6 +60,11 @@ func (c *Check) collectPartitionMetrics(sender sender.Sender) error {
 		return err
 	}
 
+	if c.forecaster != nil && !c.forecaster.loaded {
+		c.forecaster.readFromCache()
+	}
+	seenMounts := make(map[string]struct{})
+
 	for _, partition := range partitions {
 		if c.excludeDisk(partition.Mountpoint, partition.Device, partition.Fstype) {
 			continue
@@ -93,6 +99,17 @@ func (c *Check) collectPartitionMetrics(sender sender.Sender) error {
 		tags = c.applyDeviceTags(partition.Device, partition.Mountpoint, tags)
 
 		c.sendPartitionMetrics(sender, usage, tags)
+
+		// mounts sharing a device, like bind mounts, are forecast separately
+		if c.forecaster != nil {
+			seenMounts[partition.Mountpoint] = struct{}{}
+			c.sendTimeToFull(sender, partition.Mountpoint, usage, tags)
+		}
+	}
+
+	if c.forecaster != nil {
+		c.forecaster.removeStaleMounts(seenMounts)
+		c.forecaster.writeInCache()
 	}
 
 	return nil
@@ -148,5 +165,12 @@ func (c *Check) Configure(senderManager sender.SenderManager, _ uint64, data int
 	if err != nil {
 		return err
 	}
-	return c.instanceConfigure(data)
+	if err := c.instanceConfigure(data); err != nil {
+		return err
+	}
+
+	if c.cfg.forecastTimeToFull {
+		c.forecaster = newUsageForecaster(forecastCacheKeyPrefix+":"+string(c.ID()), c.cfg.forecastWindow, c.cfg.forecastMinSamples)
+	}
+	return nil
 }
@@ -9,12 +9,16 @@ package disk
 import (
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/system/disk/io"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 )
 
 var (
@@ -246,3 +250,160 @@ func TestDiskCheckTags(t *testing.T) {
 	mock.AssertNumberOfCalls(t, "Rate", expectedRates)
 	mock.AssertNumberOfCalls(t, "Commit", 1)
 }
+
+func TestDiskCheckTimeToFull(t *testing.T) {
+	configmock.New(t).SetWithoutSource("run_path", t.TempDir())
+
+	start := time.Unix(1700000000, 0)
+	now := start
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	diskPartitions = diskSampler
+	ioCounters = diskIoSampler
+	// `/` grows by 1kB and 1 inode per second, `/boot/efi` does not change
+	diskUsage = func(mountpoint string) (*disk.UsageStat, error) {
+		if mountpoint != "/" {
+			return diskUsageSamples[mountpoint], nil
+		}
+		elapsed := uint64(now.Sub(start).Seconds())
+		return &disk.UsageStat{
+			Path:        "/",
+			Total:       100 * 1024 * 1024 * 1024,
+			Used:        1024*1024*1024 + elapsed*1024,
+			Free:        43200*1024 - elapsed*1024,
+			InodesTotal: 2000000,
+			InodesUsed:  1000 + elapsed,
+			InodesFree:  1000000 - elapsed,
+		}, nil
+	}
+
+	config := integration.Data([]byte("forecast_time_to_full: true\nforecast_window: 600\ntime_to_full_service_check: true"))
+	sda1Tags := []string{"device:/dev/sda1", "device_name:sda1", "mount_point:/boot/efi"}
+	sda2Tags := []string{"device:/dev/sda2", "device_name:sda2", "mount_point:/"}
+
+	for i := 0; i < 2; i++ {
+		diskCheck := newCheck().(*Check)
+		mock := mocksender.NewMockSender(diskCheck.ID())
+		mock.SetupAcceptAll()
+		require.NoError(t, diskCheck.Configure(mock.GetSenderManager(), integration.FakeConfigHash, config, nil, "test"))
+
+		require.NoError(t, diskCheck.Run())
+		mock.AssertNumberOfCalls(t, "ServiceCheck", 0)
+		now = now.Add(60 * time.Second)
+	}
+
+	// A new check instance resumes from the samples persisted by the previous ones
+	diskCheck := newCheck().(*Check)
+	mock := mocksender.NewMockSender(diskCheck.ID())
+	mock.SetupAcceptAll()
+	require.NoError(t, diskCheck.Configure(mock.GetSenderManager(), integration.FakeConfigHash, config, nil, "test"))
+	require.NoError(t, diskCheck.Run())
+
+	// 120 seconds elapsed, 43080kB and 999880 inodes left
+	mock.AssertMetric(t, "Gauge", "system.disk.time_to_full", 43080, "", sda2Tags)
+	mock.AssertMetric(t, "Gauge", "system.fs.inodes.time_to_full", 999880, "", sda2Tags)
+	mock.AssertServiceCheck(t, timeToFullServiceCheck, servicecheck.ServiceCheckCritical, "", sda2Tags, "/ is expected to be full soon (disk in 11h58m0s)")
+	mock.AssertServiceCheck(t, timeToFullServiceCheck, servicecheck.ServiceCheckOK, "", sda1Tags, "")
+	mock.AssertNumberOfCalls(t, "ServiceCheck", 2)
+}
+
+func TestDiskCheckTimeToFullSlowGrowth(t *testing.T) {
+	configmock.New(t).SetWithoutSource("run_path", t.TempDir())
+
+	start := time.Unix(1700000000, 0)
+	now := start
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	diskPartitions = diskSampler
+	ioCounters = diskIoSampler
+	// `/` grows by 1 byte per minute, so that it's full in more time than a
+	// time.Duration holds
+	diskUsage = func(mountpoint string) (*disk.UsageStat, error) {
+		if mountpoint != "/" {
+			return diskUsageSamples[mountpoint], nil
+		}
+		elapsed := uint64(now.Sub(start).Minutes())
+		return &disk.UsageStat{
+			Path:  "/",
+			Total: 100 * 1024 * 1024 * 1024 * 1024,
+			Used:  1024 + elapsed,
+			Free:  100*1024*1024*1024*1024 - 1024 - elapsed,
+		}, nil
+	}
+
+	config := integration.Data([]byte("forecast_time_to_full: true\nforecast_window: 600\ntime_to_full_service_check: true"))
+	sda2Tags := []string{"device:/dev/sda2", "device_name:sda2", "mount_point:/"}
+
+	diskCheck := newCheck().(*Check)
+	mock := mocksender.NewMockSender(diskCheck.ID())
+	mock.SetupAcceptAll()
+	require.NoError(t, diskCheck.Configure(mock.GetSenderManager(), integration.FakeConfigHash, config, nil, "test"))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, diskCheck.Run())
+		now = now.Add(60 * time.Second)
+	}
+
+	mock.AssertServiceCheck(t, timeToFullServiceCheck, servicecheck.ServiceCheckOK, "", sda2Tags, "")
+}
+
+func TestDiskCheckTimeToFullSharedDevice(t *testing.T) {
+	configmock.New(t).SetWithoutSource("run_path", t.TempDir())
+
+	start := time.Unix(1700000000, 0)
+	now := start
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	// two tmpfs mounts share the same device, only /run grows
+	diskPartitions = func(_ bool) ([]disk.PartitionStat, error) {
+		return []disk.PartitionStat{
+			{Device: "tmpfs", Mountpoint: "/run", Fstype: "tmpfs"},
+			{Device: "tmpfs", Mountpoint: "/dev/shm", Fstype: "tmpfs"},
+		}, nil
+	}
+	ioCounters = diskIoSampler
+	diskUsage = func(mountpoint string) (*disk.UsageStat, error) {
+		elapsed := uint64(now.Sub(start).Seconds())
+		if mountpoint == "/run" {
+			return &disk.UsageStat{Path: mountpoint, Total: 100 * 1024 * 1024, Used: elapsed * 1024, Free: 43200*1024 - elapsed*1024}, nil
+		}
+		return &disk.UsageStat{Path: mountpoint, Total: 1024 * 1024 * 1024, Used: 1024, Free: 1024*1024*1024 - 1024}, nil
+	}
+
+	config := integration.Data([]byte("forecast_time_to_full: true\nforecast_window: 600\ntime_to_full_service_check: true"))
+	runTags := []string{"device:tmpfs", "device_name:tmpfs", "mount_point:/run"}
+	shmTags := []string{"device:tmpfs", "device_name:tmpfs", "mount_point:/dev/shm"}
+
+	diskCheck := newCheck().(*Check)
+	mock := mocksender.NewMockSender(diskCheck.ID())
+	mock.SetupAcceptAll()
+	require.NoError(t, diskCheck.Configure(mock.GetSenderManager(), integration.FakeConfigHash, config, nil, "test"))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, diskCheck.Run())
+		now = now.Add(60 * time.Second)
+	}
+
+	// the samples of the mounts aren't mixed
+	require.Len(t, diskCheck.forecaster.samples, 2)
+	require.Len(t, diskCheck.forecaster.samples["/run"], 3)
+	require.Len(t, diskCheck.forecaster.samples["/dev/shm"], 3)
+	mock.AssertMetric(t, "Gauge", "system.disk.time_to_full", 43080, "", runTags)
+	mock.AssertServiceCheck(t, timeToFullServiceCheck, servicecheck.ServiceCheckCritical, "", runTags, "/run is expected to be full soon (disk in 11h58m0s)")
+	mock.AssertServiceCheck(t, timeToFullServiceCheck, servicecheck.ServiceCheckOK, "", shmTags, "")
+}
+
+func TestDiskCheckForecastConfig(t *testing.T) {
+	diskCheck := newCheck().(*Check)
+	mock := mocksender.NewMockSender(diskCheck.ID())
+
+	err := diskCheck.Configure(mock.GetSenderManager(), integration.FakeConfigHash, []byte("forecast_time_to_full: true\nforecast_min_samples: 1"), nil, "test")
+	require.Error(t, err)
+
+	err = diskCheck.Configure(mock.GetSenderManager(), integration.FakeConfigHash, []byte("time_to_full_warning: 3600\ntime_to_full_critical: 0"), nil, "test")
+	require.NoError(t, err)
+	require.Nil(t, diskCheck.forecaster)
+	require.Equal(t, time.Hour, diskCheck.cfg.timeToFullWarning)
+	require.Equal(t, time.Duration(0), diskCheck.cfg.timeToFullCritical)
+}
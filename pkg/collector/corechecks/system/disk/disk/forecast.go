@@ -0,0 +1,223 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+
+package disk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	"github.com/DataDog/datadog-agent/pkg/persistentcache"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	timeToFullServiceCheck = "disk.time_to_full"
+
+	defaultForecastWindow      = time.Hour
+	defaultTimeToFullWarning   = 7 * 24 * time.Hour
+	defaultTimeToFullCritical  = 24 * time.Hour
+	defaultForecastMinSamples  = 3
+	forecastMaxSamplesPerMount = 60
+	forecastCacheKeyPrefix     = "disk_forecast"
+)
+
+// for testing
+var timeNow = time.Now
+
+// usageSample is a point-in-time usage of a partition, kept in the rolling window
+type usageSample struct {
+	Timestamp  int64  `json:"ts"`
+	UsedBytes  uint64 `json:"used"`
+	UsedInodes uint64 `json:"inodes_used"`
+}
+
+// usageForecaster keeps a rolling window of usage samples per mount point and
+// extrapolates it linearly to estimate when the partition will be full.
+// The window is persisted so that estimates survive agent restarts.
+type usageForecaster struct {
+	cacheKey   string
+	window     time.Duration
+	minSamples int
+	samples    map[string][]usageSample
+	loaded     bool
+}
+
+func newUsageForecaster(cacheKey string, window time.Duration, minSamples int) *usageForecaster {
+	return &usageForecaster{
+		cacheKey:   cacheKey,
+		window:     window,
+		minSamples: minSamples,
+		samples:    make(map[string][]usageSample),
+	}
+}
+
+// addSample records the current usage of a mount point, dropping the samples that
+// fell out of the window. Samples closer than window/forecastMaxSamplesPerMount
+// to the previous one are ignored to bound the size of the persisted window.
+func (f *usageForecaster) addSample(mountpoint string, now time.Time, usage *disk.UsageStat) {
+	samples := f.samples[mountpoint]
+
+	oldest := now.Add(-f.window).Unix()
+	start := 0
+	for start < len(samples) && (samples[start].Timestamp < oldest || samples[start].Timestamp > now.Unix()) {
+		start++
+	}
+	samples = samples[start:]
+
+	spacing := int64((f.window / forecastMaxSamplesPerMount).Seconds())
+	if len(samples) > 0 && now.Unix()-samples[len(samples)-1].Timestamp < spacing {
+		f.samples[mountpoint] = samples
+		return
+	}
+
+	f.samples[mountpoint] = append(samples, usageSample{
+		Timestamp:  now.Unix(),
+		UsedBytes:  usage.Used,
+		UsedInodes: usage.InodesUsed,
+	})
+}
+
+// timeToFull returns the estimated number of seconds before `free` reaches 0 given the
+// growth rate of the values returned by `used` over the window. ok is false when
+// there are not enough samples, infinite is true when the usage is not growing.
+func (f *usageForecaster) timeToFull(mountpoint string, free uint64, used func(usageSample) uint64) (seconds float64, infinite bool, ok bool) {
+	samples := f.samples[mountpoint]
+	if len(samples) < f.minSamples || len(samples) < 2 {
+		return 0, false, false
+	}
+
+	slope, ok := linearRegressionSlope(samples, used)
+	if !ok {
+		return 0, false, false
+	}
+	if slope <= 0 {
+		return 0, true, true
+	}
+	return float64(free) / slope, false, true
+}
+
+// linearRegressionSlope returns the least squares slope of used(sample) against time, in units per second
+func linearRegressionSlope(samples []usageSample, used func(usageSample) uint64) (float64, bool) {
+	// Timestamps are centered on the first sample to preserve precision
+	origin := samples[0].Timestamp
+	n := float64(len(samples))
+	var sumX, sumY float64
+	for _, s := range samples {
+		sumX += float64(s.Timestamp - origin)
+		sumY += float64(used(s))
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var covariance, variance float64
+	for _, s := range samples {
+		dx := float64(s.Timestamp-origin) - meanX
+		covariance += dx * (float64(used(s)) - meanY)
+		variance += dx * dx
+	}
+	if variance == 0 {
+		return 0, false
+	}
+	return covariance / variance, true
+}
+
+func (f *usageForecaster) readFromCache() {
+	f.loaded = true
+	cacheValue, err := persistentcache.Read(f.cacheKey)
+	if err != nil {
+		log.Errorf("couldn't read cache for %s: %s", f.cacheKey, err)
+	}
+	if cacheValue == "" {
+		return
+	}
+	var samples map[string][]usageSample
+	if err = json.Unmarshal([]byte(cacheValue), &samples); err != nil {
+		log.Errorf("couldn't unmarshal cache for %s: %s", f.cacheKey, err)
+		return
+	}
+	f.samples = samples
+}
+
+func (f *usageForecaster) writeInCache() {
+	cacheValue, err := json.Marshal(f.samples)
+	if err != nil {
+		log.Errorf("Disk forecast: couldn't marshal cache for %s: %s", f.cacheKey, err)
+		return
+	}
+
+	if err = persistentcache.Write(f.cacheKey, string(cacheValue)); err != nil {
+		log.Errorf("Disk forecast: couldn't write cache for %s: %s", f.cacheKey, err)
+	}
+}
+
+// removeStaleMounts forgets about the mount points that were not seen during the last run
+func (f *usageForecaster) removeStaleMounts(seen map[string]struct{}) {
+	for mountpoint := range f.samples {
+		if _, found := seen[mountpoint]; !found {
+			delete(f.samples, mountpoint)
+		}
+	}
+}
+
+// sendTimeToFull forecasts the usage of a mount point, the metrics and the service check are
+// tagged with the mount point since several mount points can share a device
+func (c *Check) sendTimeToFull(sender sender.Sender, mountpoint string, usage *disk.UsageStat, tags []string) {
+	f := c.forecaster
+	f.addSample(mountpoint, timeNow(), usage)
+	tags = append(tags[:len(tags):len(tags)], "mount_point:"+mountpoint)
+
+	status := servicecheck.ServiceCheckOK
+	var messages []string
+
+	bytesTTF, bytesInfinite, estimated := f.timeToFull(mountpoint, usage.Free, func(s usageSample) uint64 { return s.UsedBytes })
+	if estimated && !bytesInfinite {
+		sender.Gauge(fmt.Sprintf(diskMetric, "time_to_full"), bytesTTF, "", tags)
+		status, messages = c.timeToFullStatus(status, messages, "disk", bytesTTF)
+	}
+
+	// Some filesystems (vfat, btrfs...) do not report inodes
+	if usage.InodesTotal > 0 {
+		inodesTTF, inodesInfinite, inodesOk := f.timeToFull(mountpoint, usage.InodesFree, func(s usageSample) uint64 { return s.UsedInodes })
+		if inodesOk && !inodesInfinite {
+			sender.Gauge(fmt.Sprintf(inodeMetric, "time_to_full"), inodesTTF, "", tags)
+			status, messages = c.timeToFullStatus(status, messages, "inodes", inodesTTF)
+		}
+		estimated = estimated || inodesOk
+	}
+
+	if c.cfg.timeToFullServiceCheck && estimated {
+		message := ""
+		if len(messages) > 0 {
+			message = fmt.Sprintf("%s is expected to be full soon (%s)", mountpoint, strings.Join(messages, ", "))
+		}
+		sender.ServiceCheck(timeToFullServiceCheck, status, "", tags, message)
+	}
+}
+
+// timeToFullStatus compares the time to full with the thresholds in seconds,
+// as a slowly filling disk can be full in more time than a time.Duration holds
+func (c *Check) timeToFullStatus(status servicecheck.ServiceCheckStatus, messages []string, resource string, seconds float64) (servicecheck.ServiceCheckStatus, []string) {
+	switch {
+	case c.cfg.timeToFullCritical > 0 && seconds < c.cfg.timeToFullCritical.Seconds():
+		status = servicecheck.ServiceCheckCritical
+	case c.cfg.timeToFullWarning > 0 && seconds < c.cfg.timeToFullWarning.Seconds():
+		if status != servicecheck.ServiceCheckCritical {
+			status = servicecheck.ServiceCheckWarning
+		}
+	default:
+		return status, messages
+	}
+	// below a threshold, the time to full fits in a time.Duration
+	timeToFull := time.Duration(seconds * float64(time.Second))
+	return status, append(messages, fmt.Sprintf("%s in %s", resource, timeToFull.Truncate(time.Second)))
+}
@@ -0,0 +1,9 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package psi implements the pressure stall information (PSI) check.
+// It reports host-level pressure from /proc/pressure and per-container pressure
+// and memory events from the cgroup v2 hierarchy.
+package psi
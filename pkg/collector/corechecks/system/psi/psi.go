@@ -0,0 +1,233 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package psi
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/tagger"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cgroups"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/optional"
+)
+
+const (
+	// CheckName is the name of the check
+	CheckName = "psi"
+
+	cgroupCacheValidity = 2 * time.Second
+)
+
+// resources exposing pressure stall information, both in /proc/pressure and in cgroup v2
+var resources = []string{"cpu", "memory", "io"}
+
+type psiInstanceConfig struct {
+	CollectHostPressure      bool `yaml:"collect_host_pressure"`
+	CollectContainerPressure bool `yaml:"collect_container_pressure"`
+	CollectMemoryEvents      bool `yaml:"collect_memory_events"`
+}
+
+// cgroupLister is the subset of cgroups.Reader used by the check
+type cgroupLister interface {
+	RefreshCgroups(cacheValidity time.Duration) error
+	ListCgroups() []cgroups.Cgroup
+}
+
+// Check reports pressure stall information at host and container level
+type Check struct {
+	core.CheckBase
+	instance     psiInstanceConfig
+	procPath     string
+	cgroupReader cgroupLister
+}
+
+// Factory creates a new check factory
+func Factory() optional.Option[func() check.Check] {
+	return optional.NewOption(newCheck)
+}
+
+func newCheck() check.Check {
+	return &Check{
+		CheckBase: core.NewCheckBase(CheckName),
+	}
+}
+
+// Configure parses the check configuration and init the check
+func (c *Check) Configure(senderManager sender.SenderManager, _ uint64, rawInstance integration.Data, rawInitConfig integration.Data, source string) error {
+	err := c.CommonConfigure(senderManager, rawInitConfig, rawInstance, source)
+	if err != nil {
+		return err
+	}
+
+	c.instance = psiInstanceConfig{
+		CollectHostPressure:      true,
+		CollectContainerPressure: true,
+		CollectMemoryEvents:      true,
+	}
+	if err := yaml.Unmarshal(rawInstance, &c.instance); err != nil {
+		return err
+	}
+
+	c.procPath = "/proc"
+	if config.Datadog().IsSet("procfs_path") {
+		c.procPath = config.Datadog().GetString("procfs_path")
+	}
+
+	if c.instance.CollectContainerPressure || c.instance.CollectMemoryEvents {
+		c.cgroupReader = newCgroupReader()
+	}
+
+	return nil
+}
+
+func newCgroupReader() cgroupLister {
+	var hostPrefix string
+	procPath := config.Datadog().GetString("container_proc_root")
+	if strings.HasPrefix(procPath, "/host") {
+		hostPrefix = "/host"
+	}
+
+	reader, err := cgroups.NewReader(
+		cgroups.WithProcPath(procPath),
+		cgroups.WithHostPrefix(hostPrefix),
+		cgroups.WithReaderFilter(cgroups.ContainerFilter),
+		cgroups.WithPIDMapper(config.Datadog().GetString("container_pid_mapper")),
+	)
+	if err != nil {
+		log.Infof("Unable to initialize cgroup reader, container pressure will not be collected: %v", err)
+		return nil
+	}
+
+	// PSI and memory.events are only exposed by cgroup v2
+	if reader.CgroupVersion() != 2 {
+		log.Infof("Container pressure is only available with cgroup v2, detected cgroup v%d", reader.CgroupVersion())
+		return nil
+	}
+
+	return reader
+}
+
+// Run executes the check
+func (c *Check) Run() error {
+	sender, err := c.GetSender()
+	if err != nil {
+		return err
+	}
+
+	if c.instance.CollectHostPressure {
+		c.collectHostPressure(sender)
+	}
+
+	if c.cgroupReader != nil {
+		if err := c.collectContainerPressure(sender); err != nil {
+			log.Warnf("Unable to collect container pressure: %v", err)
+		}
+	}
+
+	sender.Commit()
+	return nil
+}
+
+func (c *Check) collectHostPressure(sender sender.Sender) {
+	for _, resource := range resources {
+		var some, full cgroups.PSIStats
+		if err := cgroups.ParsePSIFile(filepath.Join(c.procPath, "pressure", resource), &some, &full); err != nil {
+			// PSI is disabled by default on some distributions (psi=1 kernel parameter)
+			log.Debugf("Unable to read host %s pressure: %v", resource, err)
+			continue
+		}
+
+		reportPSI(sender, "system.pressure."+resource+".some", some, nil)
+		reportPSI(sender, "system.pressure."+resource+".full", full, nil)
+	}
+}
+
+func (c *Check) collectContainerPressure(sender sender.Sender) error {
+	if err := c.cgroupReader.RefreshCgroups(cgroupCacheValidity); err != nil {
+		return err
+	}
+
+	for _, cg := range c.cgroupReader.ListCgroups() {
+		containerID := cg.Identifier()
+		tags, err := tagger.Tag(types.NewEntityID(types.ContainerID, containerID).String(), tagger.ChecksCardinality())
+		if err != nil {
+			log.Debugf("Unable to get tags for container %s, skipping: %v", containerID, err)
+			continue
+		}
+
+		// Reading the controllers individually as some may not be delegated to the container cgroup
+		memoryStats := &cgroups.MemoryStats{}
+		if err := cg.GetMemoryStats(memoryStats); err != nil {
+			log.Debugf("Unable to get memory stats for container %s: %v", containerID, err)
+			memoryStats = nil
+		}
+
+		if c.instance.CollectContainerPressure {
+			cpuStats := &cgroups.CPUStats{}
+			if err := cg.GetCPUStats(cpuStats); err == nil {
+				reportPSI(sender, "container.pressure.cpu.some", cpuStats.PSISome, tags)
+				reportPSI(sender, "container.pressure.cpu.full", cpuStats.PSIFull, tags)
+			} else {
+				log.Debugf("Unable to get cpu stats for container %s: %v", containerID, err)
+			}
+
+			if memoryStats != nil {
+				reportPSI(sender, "container.pressure.memory.some", memoryStats.PSISome, tags)
+				reportPSI(sender, "container.pressure.memory.full", memoryStats.PSIFull, tags)
+			}
+
+			ioStats := &cgroups.IOStats{}
+			if err := cg.GetIOStats(ioStats); err == nil {
+				reportPSI(sender, "container.pressure.io.some", ioStats.PSISome, tags)
+				reportPSI(sender, "container.pressure.io.full", ioStats.PSIFull, tags)
+			} else {
+				log.Debugf("Unable to get io stats for container %s: %v", containerID, err)
+			}
+		}
+
+		if c.instance.CollectMemoryEvents && memoryStats != nil {
+			reportCount(sender, "container.memory.events.low", memoryStats.LowEvents, tags)
+			reportCount(sender, "container.memory.events.high", memoryStats.HighEvents, tags)
+			reportCount(sender, "container.memory.events.max", memoryStats.MaxEvents, tags)
+			reportCount(sender, "container.memory.events.oom", memoryStats.OOMEvents, tags)
+			reportCount(sender, "container.memory.events.oom_kill", memoryStats.OOMKiilEvents, tags)
+		}
+	}
+
+	return nil
+}
+
+// reportPSI sends averages as percentage gauges and the total stall time (in microseconds) as a monotonic count
+func reportPSI(sender sender.Sender, prefix string, stats cgroups.PSIStats, tags []string) {
+	reportGauge(sender, prefix+".avg10", stats.Avg10, tags)
+	reportGauge(sender, prefix+".avg60", stats.Avg60, tags)
+	reportGauge(sender, prefix+".avg300", stats.Avg300, tags)
+	reportCount(sender, prefix+".total", stats.Total, tags)
+}
+
+func reportGauge(sender sender.Sender, metricName string, value *float64, tags []string) {
+	if value != nil {
+		sender.Gauge(metricName, *value, "", tags)
+	}
+}
+
+func reportCount(sender sender.Sender, metricName string, value *uint64, tags []string) {
+	if value != nil {
+		sender.MonotonicCount(metricName, float64(*value), "", tags)
+	}
+}
@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package psi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/tagger/taggerimpl"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/util/cgroups"
+	"github.com/DataDog/datadog-agent/pkg/util/pointer"
+)
+
+type fakeCgroupLister struct {
+	cgroups []cgroups.Cgroup
+}
+
+func (f *fakeCgroupLister) RefreshCgroups(time.Duration) error {
+	return nil
+}
+
+func (f *fakeCgroupLister) ListCgroups() []cgroups.Cgroup {
+	return f.cgroups
+}
+
+func writePressureFile(t *testing.T, procPath, resource, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Join(procPath, "pressure"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(procPath, "pressure", resource), []byte(content), 0644))
+}
+
+func TestHostPressure(t *testing.T) {
+	procPath := t.TempDir()
+	writePressureFile(t, procPath, "cpu", "some avg10=1.50 avg60=2.00 avg300=0.75 total=123456\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n")
+	writePressureFile(t, procPath, "memory", "some avg10=10.00 avg60=5.00 avg300=1.00 total=987654\nfull avg10=4.00 avg60=2.00 avg300=0.50 total=43210\n")
+	// io pressure is missing, the check should skip it
+
+	check := newCheck().(*Check)
+	mock := mocksender.NewMockSender(check.ID())
+	mock.SetupAcceptAll()
+
+	require.NoError(t, check.Configure(mock.GetSenderManager(), 0, []byte("collect_container_pressure: false\ncollect_memory_events: false"), nil, "test"))
+	check.procPath = procPath
+
+	require.NoError(t, check.Run())
+
+	mock.AssertMetric(t, "Gauge", "system.pressure.cpu.some.avg10", 1.5, "", nil)
+	mock.AssertMetric(t, "Gauge", "system.pressure.cpu.some.avg60", 2.0, "", nil)
+	mock.AssertMetric(t, "Gauge", "system.pressure.cpu.some.avg300", 0.75, "", nil)
+	mock.AssertMetric(t, "MonotonicCount", "system.pressure.cpu.some.total", 123456, "", nil)
+	mock.AssertMetric(t, "Gauge", "system.pressure.cpu.full.avg10", 0, "", nil)
+	mock.AssertMetric(t, "Gauge", "system.pressure.memory.some.avg10", 10, "", nil)
+	mock.AssertMetric(t, "Gauge", "system.pressure.memory.full.avg60", 2, "", nil)
+	mock.AssertMetric(t, "MonotonicCount", "system.pressure.memory.full.total", 43210, "", nil)
+	mock.AssertNotCalled(t, "Gauge", "system.pressure.io.some.avg10", 0.0, "", []string(nil))
+	mock.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestContainerPressure(t *testing.T) {
+	fakeTagger := taggerimpl.SetupFakeTagger(t)
+	defer fakeTagger.ResetTagger()
+	fakeTagger.SetTags(types.NewEntityID(types.ContainerID, "cID1").String(), "foo", []string{"image_name:foo"}, nil, nil, nil)
+
+	check := newCheck().(*Check)
+	mock := mocksender.NewMockSender(check.ID())
+	mock.SetupAcceptAll()
+
+	require.NoError(t, check.Configure(mock.GetSenderManager(), 0, []byte("collect_host_pressure: false"), nil, "test"))
+	check.cgroupReader = &fakeCgroupLister{
+		cgroups: []cgroups.Cgroup{
+			&cgroups.MockCgroup{
+				ID: "cID1",
+				CPU: &cgroups.CPUStats{
+					PSISome: cgroups.PSIStats{Avg10: pointer.Ptr(12.5), Total: pointer.Ptr(uint64(1000))},
+				},
+				Memory: &cgroups.MemoryStats{
+					PSISome:       cgroups.PSIStats{Avg60: pointer.Ptr(3.0)},
+					PSIFull:       cgroups.PSIStats{Avg300: pointer.Ptr(1.0)},
+					HighEvents:    pointer.Ptr(uint64(42)),
+					OOMKiilEvents: pointer.Ptr(uint64(1)),
+				},
+				IOStats: &cgroups.IOStats{
+					PSIFull: cgroups.PSIStats{Total: pointer.Ptr(uint64(500))},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, check.Run())
+
+	expectedTags := []string{"image_name:foo"}
+	mock.AssertMetric(t, "Gauge", "container.pressure.cpu.some.avg10", 12.5, "", expectedTags)
+	mock.AssertMetric(t, "MonotonicCount", "container.pressure.cpu.some.total", 1000, "", expectedTags)
+	mock.AssertMetric(t, "Gauge", "container.pressure.memory.some.avg60", 3, "", expectedTags)
+	mock.AssertMetric(t, "Gauge", "container.pressure.memory.full.avg300", 1, "", expectedTags)
+	mock.AssertMetric(t, "MonotonicCount", "container.pressure.io.full.total", 500, "", expectedTags)
+	mock.AssertMetric(t, "MonotonicCount", "container.memory.events.high", 42, "", expectedTags)
+	mock.AssertMetric(t, "MonotonicCount", "container.memory.events.oom_kill", 1, "", expectedTags)
+	mock.AssertNotCalled(t, "MonotonicCount", "container.memory.events.low", 0.0, "", expectedTags)
+	mock.AssertNumberOfCalls(t, "Gauge", 3)
+	mock.AssertNumberOfCalls(t, "MonotonicCount", 4)
+}
@@ -164,20 +164,21 @@ var defaultAllowedRCIntegrations = []string{}
 
 // ConfigurationProviders helps unmarshalling `config_providers` config param
 type ConfigurationProviders struct {
-	Name                    string `mapstructure:"name"`
-	Polling                 bool   `mapstructure:"polling"`
-	PollInterval            string `mapstructure:"poll_interval"`
-	TemplateURL             string `mapstructure:"template_url"`
-	TemplateDir             string `mapstructure:"template_dir"`
-	Username                string `mapstructure:"username"`
-	Password                string `mapstructure:"password"`
-	CAFile                  string `mapstructure:"ca_file"`
-	CAPath                  string `mapstructure:"ca_path"`
-	CertFile                string `mapstructure:"cert_file"`
-	KeyFile                 string `mapstructure:"key_file"`
-	Token                   string `mapstructure:"token"`
-	GraceTimeSeconds        int    `mapstructure:"grace_time_seconds"`
-	DegradedDeadlineMinutes int    `mapstructure:"degraded_deadline_minutes"`
+	Name                    string            `mapstructure:"name"`
+	Polling                 bool              `mapstructure:"polling"`
+	PollInterval            string            `mapstructure:"poll_interval"`
+	TemplateURL             string            `mapstructure:"template_url"`
+	TemplateDir             string            `mapstructure:"template_dir"`
+	Username                string            `mapstructure:"username"`
+	Password                string            `mapstructure:"password"`
+	CAFile                  string            `mapstructure:"ca_file"`
+	CAPath                  string            `mapstructure:"ca_path"`
+	CertFile                string            `mapstructure:"cert_file"`
+	KeyFile                 string            `mapstructure:"key_file"`
+	Token                   string            `mapstructure:"token"`
+	Headers                 map[string]string `mapstructure:"headers"`
+	GraceTimeSeconds        int               `mapstructure:"grace_time_seconds"`
+	DegradedDeadlineMinutes int               `mapstructure:"degraded_deadline_minutes"`
 }
 
 // Listeners helps unmarshalling `listeners` config param
@@ -322,7 +323,9 @@ func InitConfig(config pkgconfigmodel.Config) {
 	config.BindEnvAndSetDefault("secret_backend_skip_checks", false)
 	config.BindEnvAndSetDefault("secret_backend_remove_trailing_line_break", false)
 	config.BindEnvAndSetDefault("secret_refresh_interval", 0)
+	config.BindEnvAndSetDefault("secret_refresh_all_handles", false)
 	config.SetDefault("secret_audit_file_max_size", 0)
+	config.SetKnown("secret_backends")
 
 	// IPC API server timeout
 	config.BindEnvAndSetDefault("server_timeout", 30)
@@ -423,6 +426,8 @@ func InitConfig(config pkgconfigmodel.Config) {
 	config.BindEnvAndSetDefault("network_devices.snmp_traps.bind_host", "0.0.0.0")
 	config.BindEnvAndSetDefault("network_devices.snmp_traps.stop_timeout", 5) // in seconds
 	config.SetKnown("network_devices.snmp_traps.users")
+	config.SetKnown("network_devices.snmp_traps.metric_rules")
+	config.BindEnvAndSetDefault("network_devices.snmp_traps.dedup_window", 0) // in seconds
 
 	// NetFlow
 	config.SetKnown("network_devices.netflow.listeners")
@@ -432,9 +437,14 @@ func InitConfig(config pkgconfigmodel.Config) {
 	config.SetKnown("network_devices.netflow.aggregator_flow_context_ttl")
 	config.SetKnown("network_devices.netflow.aggregator_port_rollup_threshold")
 	config.SetKnown("network_devices.netflow.aggregator_rollup_tracker_refresh_interval")
+	config.SetKnown("network_devices.netflow.aggregator_top_talkers")
 	config.BindEnvAndSetDefault("network_devices.netflow.enabled", "false")
 	bindEnvAndSetLogsConfigKeys(config, "network_devices.netflow.forwarder.")
 	config.BindEnvAndSetDefault("network_devices.netflow.reverse_dns_enrichment_enabled", false)
+	config.BindEnvAndSetDefault("network_devices.netflow.sampling_rate_extrapolation_enabled", false)
+	config.BindEnvAndSetDefault("network_devices.netflow.enrichment.geoip_database", "")
+	config.BindEnvAndSetDefault("network_devices.netflow.enrichment.asn_database", "")
+	config.BindEnvAndSetDefault("network_devices.netflow.enrichment.cidr_labels_file", "")
 
 	// Network Path
 	config.BindEnvAndSetDefault("network_path.connections_monitoring.enabled", false)
@@ -447,6 +457,9 @@ func InitConfig(config pkgconfigmodel.Config) {
 	config.BindEnvAndSetDefault("network_path.collector.pathtest_ttl", "15m")
 	config.BindEnvAndSetDefault("network_path.collector.pathtest_interval", "5m")
 	config.BindEnvAndSetDefault("network_path.collector.flush_interval", "10s")
+	config.SetKnown("network_path.collector.scheduled_tests")
+	config.BindEnvAndSetDefault("network_path.collector.probes_per_hop", 3)
+	config.BindEnvAndSetDefault("network_path.collector.packet_loss_threshold", 5.0)
 	bindEnvAndSetLogsConfigKeys(config, "network_path.forwarder.")
 
 	// Kube ApiServer
@@ -803,12 +816,28 @@ func InitConfig(config pkgconfigmodel.Config) {
 	// Remote process collector
 	config.BindEnvAndSetDefault("workloadmeta.local_process_collector.collection_interval", DefaultLocalProcessCollectorInterval)
 
+	// Systemd collector: the units matching the patterns are collected as workloadmeta entities
+	config.BindEnvAndSetDefault("workloadmeta.systemd_collector.enabled", false)
+	config.BindEnvAndSetDefault("workloadmeta.systemd_collector.unit_patterns", []string{"*.service"})
+	config.BindEnvAndSetDefault("workloadmeta.systemd_collector.collection_interval", 30*time.Second)
+
+	// Event journal: the last events of each entity, bounded per kind, shown by `agent workload-list --history`
+	config.BindEnvAndSetDefault("workloadmeta.event_journal.max_events_per_entity", 10)
+	config.BindEnvAndSetDefault("workloadmeta.event_journal.max_entities_per_kind", 1000)
+
 	// Tagger Component
 	// This is a temporary/transient flag used to slowly migrate to a new internal implementation of the tagger.
 	// If set to true, the tagger will store all entities in a 2-layered map, the first map is indexed by prefix, and the second one is indexed by id.
 	// If set to false, the tagger will use the default implementation by storing entities in a one-layer map from plain strings to Tag Entities.
 	// TODO: remove this config option when the migration is finalised.
 	config.BindEnvAndSetDefault("tagger.tagstore_use_composite_entity_id", false)
+	// Warm start: the tag store is periodically written to disk and restored at startup. The restored tags
+	// expire after the grace period unless the collectors reconfirm them, and snapshots older than max_age are ignored.
+	config.BindEnvAndSetDefault("tagger.warm_start.enabled", false)
+	config.BindEnvAndSetDefault("tagger.warm_start.path", filepath.Join(defaultRunPath, "tagger-snapshot.json"))
+	config.BindEnvAndSetDefault("tagger.warm_start.snapshot_interval", 1*time.Minute)
+	config.BindEnvAndSetDefault("tagger.warm_start.grace_period", 5*time.Minute)
+	config.BindEnvAndSetDefault("tagger.warm_start.max_age", 1*time.Hour)
 
 	// SBOM configuration
 	config.BindEnvAndSetDefault("sbom.enabled", false)
@@ -1019,6 +1048,9 @@ func agent(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("origin_detection_unified", false)
 	config.BindEnv("env")
 	config.BindEnvAndSetDefault("tag_value_split_separator", map[string]string{})
+	// tag_derivation_rules is a list of rules computing container and pod tags from other tags.
+	// It can be set through the environment as a JSON string.
+	config.BindEnv("tag_derivation_rules")
 	config.BindEnvAndSetDefault("conf_path", ".")
 	config.BindEnvAndSetDefault("confd_path", defaultConfdPath)
 	config.BindEnvAndSetDefault("additional_checksd", defaultAdditionalChecksPath)
@@ -2134,18 +2166,20 @@ func ResolveSecrets(config pkgconfigmodel.Config, secretResolver secrets.Compone
 	// We have to init the secrets package before we can use it to decrypt
 	// anything.
 	secretResolver.Configure(secrets.ConfigParams{
-		Command:          config.GetString("secret_backend_command"),
-		Arguments:        config.GetStringSlice("secret_backend_arguments"),
-		Timeout:          config.GetInt("secret_backend_timeout"),
-		MaxSize:          config.GetInt("secret_backend_output_max_size"),
-		RefreshInterval:  config.GetInt("secret_refresh_interval"),
-		GroupExecPerm:    config.GetBool("secret_backend_command_allow_group_exec_perm"),
-		RemoveLinebreak:  config.GetBool("secret_backend_remove_trailing_line_break"),
-		RunPath:          config.GetString("run_path"),
-		AuditFileMaxSize: config.GetInt("secret_audit_file_max_size"),
+		Command:           config.GetString("secret_backend_command"),
+		Arguments:         config.GetStringSlice("secret_backend_arguments"),
+		Timeout:           config.GetInt("secret_backend_timeout"),
+		MaxSize:           config.GetInt("secret_backend_output_max_size"),
+		RefreshInterval:   config.GetInt("secret_refresh_interval"),
+		GroupExecPerm:     config.GetBool("secret_backend_command_allow_group_exec_perm"),
+		RemoveLinebreak:   config.GetBool("secret_backend_remove_trailing_line_break"),
+		RunPath:           config.GetString("run_path"),
+		AuditFileMaxSize:  config.GetInt("secret_audit_file_max_size"),
+		Backends:          secretBackends(config),
+		RefreshAllHandles: config.GetBool("secret_refresh_all_handles"),
 	})
 
-	if config.GetString("secret_backend_command") != "" {
+	if config.GetString("secret_backend_command") != "" || config.IsSet("secret_backends") {
 		// Viper doesn't expose the final location of the file it
 		// loads. Since we are searching for 'datadog.yaml' in multiple
 		// locations we let viper determine the one to use before
@@ -2171,6 +2205,26 @@ func ResolveSecrets(config pkgconfigmodel.Config, secretResolver secrets.Compone
 	return nil
 }
 
+// secretBackends returns the native secret backends configured in `secret_backends`
+func secretBackends(config pkgconfigmodel.Reader) []secrets.BackendConfig {
+	if !config.IsSet("secret_backends") {
+		return nil
+	}
+	// the setting is a list of mappings, it's converted through YAML to support
+	// the different map types returned by the config
+	rawBackends, err := yaml.Marshal(config.Get("secret_backends"))
+	if err != nil {
+		log.Errorf("Invalid secret_backends setting: %s", err)
+		return nil
+	}
+	var backends []secrets.BackendConfig
+	if err := yaml.Unmarshal(rawBackends, &backends); err != nil {
+		log.Errorf("Invalid secret_backends setting: %s", err)
+		return nil
+	}
+	return backends
+}
+
 // confgAssignAtPath assigns a value to the given setting of the config
 // This works around viper issues that prevent us from assigning to fields that have a dot in the
 // name (example: 'additional_endpoints.http://url.com') and also allows us to assign to individual
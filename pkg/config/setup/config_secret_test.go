@@ -184,3 +184,35 @@ func TestProxyWithSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretBackends(t *testing.T) {
+	t.Setenv("TEST_SECRET_API_KEY", "resolved_api_key")
+
+	config := newTestConf()
+	configPath := filepath.Join(t.TempDir(), "datadog.yaml")
+	os.WriteFile(configPath, []byte(`
+secret_backends:
+  - type: env
+    ttl: 60
+  - type: file
+    prefix: docker
+    config:
+      root: /run/secrets
+api_key: ENC[env:TEST_SECRET_API_KEY]
+`), 0600)
+	config.SetConfigFile(configPath)
+	require.NoError(t, config.ReadInConfig())
+
+	assert.Equal(t, []secrets.BackendConfig{
+		{Type: "env", TTL: 60},
+		{Type: "file", Prefix: "docker", Config: map[string]interface{}{"root": "/run/secrets"}},
+	}, secretBackends(config))
+
+	resolver := fxutil.Test[secrets.Component](t, fx.Options(
+		secretsimpl.MockModule(),
+		nooptelemetry.Module(),
+	))
+	_, err := LoadDatadogCustom(config, "unit_test", optional.NewOption[secrets.Component](resolver), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "resolved_api_key", config.GetString("api_key"))
+}
@@ -673,6 +673,8 @@ func TestNetworkPathDefaults(t *testing.T) {
 	assert.Equal(t, 15*time.Minute, config.GetDuration("network_path.collector.pathtest_ttl"))
 	assert.Equal(t, 5*time.Minute, config.GetDuration("network_path.collector.pathtest_interval"))
 	assert.Equal(t, 10*time.Second, config.GetDuration("network_path.collector.flush_interval"))
+	assert.Equal(t, 3, config.GetInt("network_path.collector.probes_per_hop"))
+	assert.Equal(t, 5.0, config.GetFloat64("network_path.collector.packet_loss_threshold"))
 }
 
 func TestUsePodmanLogsAndDockerPathOverride(t *testing.T) {
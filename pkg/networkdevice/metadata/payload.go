@@ -45,6 +45,7 @@ type NetworkDevicesMetadata struct {
 	NetflowExporters []NetflowExporter      `json:"netflow_exporters,omitempty"`
 	Diagnoses        []DiagnosisMetadata    `json:"diagnoses,omitempty"`
 	DeviceOIDs       []DeviceOID            `json:"device_oids,omitempty"`
+	TopologyChanges  []TopologyChange       `json:"topology_changes,omitempty"`
 	CollectTimestamp int64                  `json:"collect_timestamp"`
 }
 
@@ -137,6 +138,26 @@ type TopologyLinkMetadata struct {
 	Remote     *TopologyLinkSide `json:"remote"`
 }
 
+// TopologyChangeType enum type
+type TopologyChangeType string
+
+const (
+	// TopologyLinkAdded means a link was discovered since the previous collection
+	TopologyLinkAdded = TopologyChangeType("link_added")
+	// TopologyLinkRemoved means a link is no longer discovered
+	TopologyLinkRemoved = TopologyChangeType("link_removed")
+	// TopologyNeighborChanged means the remote side of a link changed since the previous collection
+	TopologyNeighborChanged = TopologyChangeType("neighbor_changed")
+)
+
+// TopologyChange contains a change of the topology links of a device between two collections
+type TopologyChange struct {
+	DeviceID     string                `json:"device_id"`
+	Type         TopologyChangeType    `json:"type"`
+	Link         TopologyLinkMetadata  `json:"link"`
+	PreviousLink *TopologyLinkMetadata `json:"previous_link,omitempty"`
+}
+
 // NetflowExporter contains netflow exporters info
 type NetflowExporter struct {
 	ID        string `json:"id"` // used by backend as unique id (e.g. in cache)
@@ -68,6 +68,21 @@ func BatchDeviceScan(namespace string, collectTime time.Time, batchSize int, dev
 	return payloads
 }
 
+// BatchTopologyChanges batches topology changes across multiple NetworkDevicesMetadata payloads.
+func BatchTopologyChanges(namespace string, subnet string, collectTime time.Time, batchSize int, changes []TopologyChange) []NetworkDevicesMetadata {
+	var payloads []NetworkDevicesMetadata
+	var resourceCount int
+
+	curPayload := newNetworkDevicesMetadata(namespace, subnet, collectTime)
+
+	for _, change := range changes {
+		payloads, curPayload, resourceCount = appendToPayloads(namespace, subnet, collectTime, batchSize, resourceCount, payloads, curPayload)
+		curPayload.TopologyChanges = append(curPayload.TopologyChanges, change)
+	}
+	payloads = append(payloads, curPayload)
+	return payloads
+}
+
 func newNetworkDevicesMetadata(namespace string, subnet string, collectTime time.Time) NetworkDevicesMetadata {
 	return NetworkDevicesMetadata{
 		Subnet:           subnet,
@@ -117,3 +117,25 @@ func Test_batchPayloads(t *testing.T) {
 	assert.Len(t, payloads[7].Diagnoses, 51)
 	assert.Equal(t, diagnoses[49:100], payloads[7].Diagnoses)
 }
+
+func Test_batchTopologyChanges(t *testing.T) {
+	collectTime := mockTimeNow()
+	var changes []TopologyChange
+	for i := 0; i < 150; i++ {
+		changes = append(changes, TopologyChange{
+			DeviceID: "default:1.2.3.4",
+			Type:     TopologyLinkAdded,
+			Link:     TopologyLinkMetadata{ID: fmt.Sprintf("default:1.2.3.4:%d.1", i)},
+		})
+	}
+
+	payloads := BatchTopologyChanges("my-ns", "127.0.0.0/30", collectTime, 100, changes)
+
+	require.Len(t, payloads, 2)
+	assert.Equal(t, "my-ns", payloads[0].Namespace)
+	assert.Equal(t, "127.0.0.0/30", payloads[0].Subnet)
+	assert.Equal(t, int64(946684800), payloads[0].CollectTimestamp)
+	assert.Equal(t, changes[0:100], payloads[0].TopologyChanges)
+	assert.Equal(t, changes[100:150], payloads[1].TopologyChanges)
+	assert.Empty(t, payloads[1].Links)
+}
@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package topology
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+// Node is a device of the topology graph
+type Node struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	IPAddress string `json:"ip_address,omitempty"`
+	// Discovered is false for neighbors that are only known from the links of other devices
+	Discovered bool `json:"discovered"`
+}
+
+// Edge is a link between the interfaces of two nodes of the topology graph
+type Edge struct {
+	Source          string `json:"source"`
+	SourceInterface string `json:"source_interface,omitempty"`
+	Target          string `json:"target"`
+	TargetInterface string `json:"target_interface,omitempty"`
+	SourceType      string `json:"source_type"`
+}
+
+// Graph is the topology graph built from the links of the discovered devices
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+
+	nodeIndexes map[string]int
+	nodeIDByIP  map[string]string
+	edgeKeys    map[string]struct{}
+}
+
+// NewGraph returns an empty Graph
+func NewGraph() *Graph {
+	return &Graph{
+		Nodes:       []Node{},
+		Edges:       []Edge{},
+		nodeIndexes: make(map[string]int),
+		nodeIDByIP:  make(map[string]string),
+		edgeKeys:    make(map[string]struct{}),
+	}
+}
+
+// AddDevice adds a discovered device, devices must be added before the links referencing them
+func (g *Graph) AddDevice(id string, name string, ipAddress string) {
+	g.addNode(Node{ID: id, Name: name, IPAddress: ipAddress, Discovered: true})
+	if ipAddress != "" {
+		g.nodeIDByIP[ipAddress] = id
+	}
+}
+
+// AddLinks adds the links of a device, a link reported by both of its sides is added once
+func (g *Graph) AddLinks(links []metadata.TopologyLinkMetadata) {
+	for _, link := range links {
+		if link.Local == nil || link.Local.Device == nil || link.Remote == nil {
+			continue
+		}
+		source := link.Local.Device.DDID
+		target := g.remoteNodeID(link.Remote.Device)
+		if source == "" || target == "" {
+			continue
+		}
+		edge := Edge{
+			Source:          source,
+			SourceInterface: interfaceName(link.Local.Interface),
+			Target:          target,
+			TargetInterface: interfaceName(link.Remote.Interface),
+			SourceType:      link.SourceType,
+		}
+		key, reverseKey := edgeKeys(edge)
+		if _, ok := g.edgeKeys[reverseKey]; ok {
+			continue
+		}
+		if _, ok := g.edgeKeys[key]; ok {
+			continue
+		}
+		g.edgeKeys[key] = struct{}{}
+		g.Edges = append(g.Edges, edge)
+	}
+}
+
+// JSON returns the indented JSON representation of the graph
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// DOT returns the Graphviz DOT representation of the graph, neighbors that
+// were not discovered are drawn with a dashed line
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("graph topology {\n")
+	for _, node := range g.Nodes {
+		label := node.ID
+		if node.Name != "" {
+			label = node.Name
+		}
+		if node.IPAddress != "" {
+			label += "\n" + node.IPAddress
+		}
+		b.WriteString("  " + strconv.Quote(node.ID) + " [label=" + strconv.Quote(label))
+		if !node.Discovered {
+			b.WriteString(", style=dashed")
+		}
+		b.WriteString("];\n")
+	}
+	for _, edge := range g.Edges {
+		b.WriteString("  " + strconv.Quote(edge.Source) + " -- " + strconv.Quote(edge.Target))
+		b.WriteString(" [taillabel=" + strconv.Quote(edge.SourceInterface) + ", headlabel=" + strconv.Quote(edge.TargetInterface))
+		b.WriteString(", label=" + strconv.Quote(edge.SourceType) + "];\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (g *Graph) addNode(node Node) {
+	if index, ok := g.nodeIndexes[node.ID]; ok {
+		// a discovered device replaces a neighbor added by a previous link
+		if node.Discovered && !g.Nodes[index].Discovered {
+			g.Nodes[index] = node
+		}
+		return
+	}
+	g.nodeIndexes[node.ID] = len(g.Nodes)
+	g.Nodes = append(g.Nodes, node)
+}
+
+// remoteNodeID returns the node of the remote device of a link, remote devices
+// are matched to discovered devices by IP address, other neighbors are
+// identified by their chassis ID or their name
+func (g *Graph) remoteNodeID(device *metadata.TopologyLinkDevice) string {
+	if device == nil {
+		return ""
+	}
+	if id, ok := g.nodeIDByIP[device.IPAddress]; ok && device.IPAddress != "" {
+		return id
+	}
+	var id string
+	switch {
+	case device.ID != "":
+		id = "neighbor:" + device.ID
+	case device.Name != "":
+		id = "neighbor:" + device.Name
+	case device.IPAddress != "":
+		id = "neighbor:" + device.IPAddress
+	default:
+		return ""
+	}
+	g.addNode(Node{ID: id, Name: device.Name, IPAddress: device.IPAddress})
+	return id
+}
+
+func interfaceName(linkInterface *metadata.TopologyLinkInterface) string {
+	if linkInterface == nil {
+		return ""
+	}
+	if linkInterface.ID != "" {
+		return linkInterface.ID
+	}
+	return linkInterface.DDID
+}
+
+func edgeKeys(edge Edge) (string, string) {
+	source := edge.Source + "|" + edge.SourceInterface
+	target := edge.Target + "|" + edge.TargetInterface
+	return source + "|" + target, target + "|" + source
+}
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package topology
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+func graphLink(localDevice string, localInterface string, remoteID string, remoteName string, remoteIP string, remoteInterface string) metadata.TopologyLinkMetadata {
+	return metadata.TopologyLinkMetadata{
+		ID:         localDevice + ":" + localInterface,
+		SourceType: "lldp",
+		Local: &metadata.TopologyLinkSide{
+			Device:    &metadata.TopologyLinkDevice{DDID: localDevice},
+			Interface: &metadata.TopologyLinkInterface{ID: localInterface},
+		},
+		Remote: &metadata.TopologyLinkSide{
+			Device:    &metadata.TopologyLinkDevice{ID: remoteID, Name: remoteName, IPAddress: remoteIP},
+			Interface: &metadata.TopologyLinkInterface{ID: remoteInterface},
+		},
+	}
+}
+
+func newTestGraph() *Graph {
+	graph := NewGraph()
+	graph.AddDevice("default:10.0.0.1", "core-1", "10.0.0.1")
+	graph.AddDevice("default:10.0.0.2", "access-1", "10.0.0.2")
+	graph.AddLinks([]metadata.TopologyLinkMetadata{
+		graphLink("default:10.0.0.1", "eth1", "aa:aa", "access-1", "10.0.0.2", "eth48"),
+		graphLink("default:10.0.0.1", "eth2", "bb:bb", "phone-1", "", "port1"),
+	})
+	graph.AddLinks([]metadata.TopologyLinkMetadata{
+		// reverse side of the first link
+		graphLink("default:10.0.0.2", "eth48", "cc:cc", "core-1", "10.0.0.1", "eth1"),
+	})
+	return graph
+}
+
+func TestGraph(t *testing.T) {
+	graph := newTestGraph()
+
+	assert.Equal(t, []Node{
+		{ID: "default:10.0.0.1", Name: "core-1", IPAddress: "10.0.0.1", Discovered: true},
+		{ID: "default:10.0.0.2", Name: "access-1", IPAddress: "10.0.0.2", Discovered: true},
+		{ID: "neighbor:bb:bb", Name: "phone-1"},
+	}, graph.Nodes)
+	assert.Equal(t, []Edge{
+		{Source: "default:10.0.0.1", SourceInterface: "eth1", Target: "default:10.0.0.2", TargetInterface: "eth48", SourceType: "lldp"},
+		{Source: "default:10.0.0.1", SourceInterface: "eth2", Target: "neighbor:bb:bb", TargetInterface: "port1", SourceType: "lldp"},
+	}, graph.Edges)
+}
+
+func TestGraphDOT(t *testing.T) {
+	expected := `graph topology {
+  "default:10.0.0.1" [label="core-1\n10.0.0.1"];
+  "default:10.0.0.2" [label="access-1\n10.0.0.2"];
+  "neighbor:bb:bb" [label="phone-1", style=dashed];
+  "default:10.0.0.1" -- "default:10.0.0.2" [taillabel="eth1", headlabel="eth48", label="lldp"];
+  "default:10.0.0.1" -- "neighbor:bb:bb" [taillabel="eth2", headlabel="port1", label="lldp"];
+}
+`
+	assert.Equal(t, expected, newTestGraph().DOT())
+}
+
+func TestGraphJSON(t *testing.T) {
+	graph := NewGraph()
+	graph.AddDevice("default:10.0.0.1", "core-1", "10.0.0.1")
+
+	// language=json
+	expected := `{
+  "nodes": [
+    {
+      "id": "default:10.0.0.1",
+      "name": "core-1",
+      "ip_address": "10.0.0.1",
+      "discovered": true
+    }
+  ],
+  "edges": []
+}`
+	actual, err := graph.JSON()
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(actual))
+}
@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package topology implements the tracking and the export of the topology links of network devices
+package topology
+
+import (
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+// LinkTracker keeps the topology links of a device to report the changes between collections
+type LinkTracker struct {
+	deviceID string
+	links    map[string]metadata.TopologyLinkMetadata
+}
+
+// NewLinkTracker returns a new LinkTracker for a device
+func NewLinkTracker(deviceID string) *LinkTracker {
+	return &LinkTracker{
+		deviceID: deviceID,
+	}
+}
+
+// Update records the links of the last collection and returns the changes since the previous one,
+// no change is returned for the first collection
+func (t *LinkTracker) Update(links []metadata.TopologyLinkMetadata) []metadata.TopologyChange {
+	previousLinks := t.links
+	t.links = make(map[string]metadata.TopologyLinkMetadata, len(links))
+	for _, link := range links {
+		t.links[link.ID] = link
+	}
+	if previousLinks == nil {
+		return nil
+	}
+
+	var changes []metadata.TopologyChange
+	var added []metadata.TopologyLinkMetadata
+	for _, link := range links {
+		previousLink, ok := previousLinks[link.ID]
+		if !ok {
+			added = append(added, link)
+			continue
+		}
+		if !sameNeighbor(previousLink, link) {
+			changes = append(changes, t.newChange(metadata.TopologyNeighborChanged, link, &previousLink))
+		}
+	}
+
+	// removed links are sorted to report stable changes
+	var removed []metadata.TopologyLinkMetadata
+	for id, link := range previousLinks {
+		if _, ok := t.links[id]; !ok {
+			removed = append(removed, link)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].ID < removed[j].ID })
+
+	// a link replacing a removed link of the same local interface is a neighbor change,
+	// LLDP and CDP remote entries get a new index when the neighbor changes
+	replaced := make(map[string]bool)
+	for _, link := range added {
+		previousLink, ok := findReplacedLink(removed, replaced, link)
+		if ok {
+			replaced[previousLink.ID] = true
+			changes = append(changes, t.newChange(metadata.TopologyNeighborChanged, link, &previousLink))
+		} else {
+			changes = append(changes, t.newChange(metadata.TopologyLinkAdded, link, nil))
+		}
+	}
+	for _, link := range removed {
+		if !replaced[link.ID] {
+			changes = append(changes, t.newChange(metadata.TopologyLinkRemoved, link, nil))
+		}
+	}
+	return changes
+}
+
+// findReplacedLink returns the first removed link of the local interface of the link that isn't already replaced
+func findReplacedLink(removed []metadata.TopologyLinkMetadata, replaced map[string]bool, link metadata.TopologyLinkMetadata) (metadata.TopologyLinkMetadata, bool) {
+	key := localInterfaceKey(link)
+	if key == "" {
+		return metadata.TopologyLinkMetadata{}, false
+	}
+	for _, removedLink := range removed {
+		if !replaced[removedLink.ID] && localInterfaceKey(removedLink) == key {
+			return removedLink, true
+		}
+	}
+	return metadata.TopologyLinkMetadata{}, false
+}
+
+func (t *LinkTracker) newChange(changeType metadata.TopologyChangeType, link metadata.TopologyLinkMetadata, previousLink *metadata.TopologyLinkMetadata) metadata.TopologyChange {
+	return metadata.TopologyChange{
+		DeviceID:     t.deviceID,
+		Type:         changeType,
+		Link:         link,
+		PreviousLink: previousLink,
+	}
+}
+
+// localInterfaceKey identifies the local interface of a link, it's empty if the interface is unknown
+func localInterfaceKey(link metadata.TopologyLinkMetadata) string {
+	if link.Local == nil || link.Local.Interface == nil {
+		return ""
+	}
+	if link.Local.Interface.DDID != "" {
+		return link.SourceType + ":" + link.Local.Interface.DDID
+	}
+	if link.Local.Interface.ID != "" {
+		return link.SourceType + ":" + link.Local.Interface.IDType + ":" + link.Local.Interface.ID
+	}
+	return ""
+}
+
+// sameNeighbor returns true if both links connect the same interfaces, descriptions are ignored
+func sameNeighbor(a, b metadata.TopologyLinkMetadata) bool {
+	return localInterfaceKey(a) == localInterfaceKey(b) &&
+		remoteDevice(a) == remoteDevice(b) &&
+		remoteInterface(a) == remoteInterface(b)
+}
+
+func remoteDevice(link metadata.TopologyLinkMetadata) metadata.TopologyLinkDevice {
+	if link.Remote == nil || link.Remote.Device == nil {
+		return metadata.TopologyLinkDevice{}
+	}
+	device := *link.Remote.Device
+	device.Description = ""
+	return device
+}
+
+func remoteInterface(link metadata.TopologyLinkMetadata) metadata.TopologyLinkInterface {
+	if link.Remote == nil || link.Remote.Interface == nil {
+		return metadata.TopologyLinkInterface{}
+	}
+	remoteInterface := *link.Remote.Interface
+	remoteInterface.Description = ""
+	return remoteInterface
+}
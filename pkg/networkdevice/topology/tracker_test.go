@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package topology
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+func newLink(id string, localInterface string, remoteDevice string, remoteInterface string) metadata.TopologyLinkMetadata {
+	return metadata.TopologyLinkMetadata{
+		ID:         "default:1.2.3.4:" + id,
+		SourceType: "lldp",
+		Local: &metadata.TopologyLinkSide{
+			Device:    &metadata.TopologyLinkDevice{DDID: "default:1.2.3.4"},
+			Interface: &metadata.TopologyLinkInterface{DDID: "default:1.2.3.4:" + localInterface},
+		},
+		Remote: &metadata.TopologyLinkSide{
+			Device:    &metadata.TopologyLinkDevice{ID: remoteDevice, IDType: "mac_address", Name: "switch-" + remoteDevice},
+			Interface: &metadata.TopologyLinkInterface{ID: remoteInterface, IDType: "interface_name"},
+		},
+	}
+}
+
+func TestLinkTracker(t *testing.T) {
+	tracker := NewLinkTracker("default:1.2.3.4")
+
+	linkA := newLink("1.1", "1", "aa:aa", "eth1")
+	linkB := newLink("2.1", "2", "bb:bb", "eth2")
+	linkC := newLink("3.1", "3", "cc:cc", "eth3")
+
+	// first collection is the baseline
+	assert.Nil(t, tracker.Update([]metadata.TopologyLinkMetadata{linkA, linkB}))
+	assert.Empty(t, tracker.Update([]metadata.TopologyLinkMetadata{linkA, linkB}))
+
+	// descriptions are ignored
+	linkADescription := newLink("1.1", "1", "aa:aa", "eth1")
+	linkADescription.Remote.Device.Description = "uptime 10 days"
+	assert.Empty(t, tracker.Update([]metadata.TopologyLinkMetadata{linkADescription, linkB}))
+
+	// link removed and link added
+	assert.Equal(t, []metadata.TopologyChange{
+		{DeviceID: "default:1.2.3.4", Type: metadata.TopologyLinkAdded, Link: linkC},
+		{DeviceID: "default:1.2.3.4", Type: metadata.TopologyLinkRemoved, Link: linkB},
+	}, tracker.Update([]metadata.TopologyLinkMetadata{linkADescription, linkC}))
+
+	// neighbor changed with the same entry
+	linkANewPort := newLink("1.1", "1", "aa:aa", "eth9")
+	assert.Equal(t, []metadata.TopologyChange{
+		{DeviceID: "default:1.2.3.4", Type: metadata.TopologyNeighborChanged, Link: linkANewPort, PreviousLink: &linkADescription},
+	}, tracker.Update([]metadata.TopologyLinkMetadata{linkANewPort, linkC}))
+
+	// neighbor changed with a new entry of the same local interface
+	linkANewNeighbor := newLink("1.2", "1", "dd:dd", "eth1")
+	assert.Equal(t, []metadata.TopologyChange{
+		{DeviceID: "default:1.2.3.4", Type: metadata.TopologyNeighborChanged, Link: linkANewNeighbor, PreviousLink: &linkANewPort},
+	}, tracker.Update([]metadata.TopologyLinkMetadata{linkANewNeighbor, linkC}))
+
+	// all links removed
+	assert.Equal(t, []metadata.TopologyChange{
+		{DeviceID: "default:1.2.3.4", Type: metadata.TopologyLinkRemoved, Link: linkANewNeighbor},
+		{DeviceID: "default:1.2.3.4", Type: metadata.TopologyLinkRemoved, Link: linkC},
+	}, tracker.Update(nil))
+}
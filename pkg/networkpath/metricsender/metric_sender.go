@@ -6,7 +6,10 @@
 // Package metricsender holds the interface used to send Metrics with Agent Sender and Statsd sender
 package metricsender
 
+import "github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+
 // MetricSender is an interface used to send Metrics with Agent Sender and Statsd sender
 type MetricSender interface {
 	Gauge(metricName string, value float64, tags []string)
+	ServiceCheck(checkName string, status servicecheck.ServiceCheckStatus, tags []string, message string)
 }
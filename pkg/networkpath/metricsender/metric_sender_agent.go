@@ -5,7 +5,10 @@
 
 package metricsender
 
-import "github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+import (
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+)
 
 // agentMetricSender sends metrics using Agent sender.Sender
 type agentMetricSender struct {
@@ -24,3 +27,8 @@ func NewMetricSenderAgent(sender sender.Sender) MetricSender {
 func (s *agentMetricSender) Gauge(metricName string, value float64, tags []string) {
 	s.sender.Gauge(metricName, value, "", tags)
 }
+
+// ServiceCheck service check sender
+func (s *agentMetricSender) ServiceCheck(checkName string, status servicecheck.ServiceCheckStatus, tags []string, message string) {
+	s.sender.ServiceCheck(checkName, status, "", tags, message)
+}
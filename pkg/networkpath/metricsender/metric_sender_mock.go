@@ -9,6 +9,7 @@ package metricsender
 
 import (
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 )
 
 // MockReceivedMetric holds in-memory mock metrics
@@ -19,9 +20,18 @@ type MockReceivedMetric struct {
 	Tags       []string
 }
 
+// MockReceivedServiceCheck holds in-memory mock service checks
+type MockReceivedServiceCheck struct {
+	Name    string
+	Status  servicecheck.ServiceCheckStatus
+	Tags    []string
+	Message string
+}
+
 // MockMetricSender holds in-memory mock metrics
 type MockMetricSender struct {
-	Metrics []MockReceivedMetric
+	Metrics       []MockReceivedMetric
+	ServiceChecks []MockReceivedServiceCheck
 }
 
 // Compile-time check to ensure that MockMetricSender conforms to the MetricSender interface
@@ -41,3 +51,13 @@ func (s *MockMetricSender) Gauge(metricName string, value float64, tags []string
 		Tags:       tags,
 	})
 }
+
+// ServiceCheck service check sender
+func (s *MockMetricSender) ServiceCheck(checkName string, status servicecheck.ServiceCheckStatus, tags []string, message string) {
+	s.ServiceChecks = append(s.ServiceChecks, MockReceivedServiceCheck{
+		Name:    checkName,
+		Status:  status,
+		Tags:    tags,
+		Message: message,
+	})
+}
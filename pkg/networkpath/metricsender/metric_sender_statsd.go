@@ -7,6 +7,8 @@ package metricsender
 
 import (
 	ddgostatsd "github.com/DataDog/datadog-go/v5/statsd"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 )
 
 type statsdMetricSender struct {
@@ -27,3 +29,13 @@ var _ MetricSender = (*statsdMetricSender)(nil)
 func (s *statsdMetricSender) Gauge(metricName string, value float64, tags []string) {
 	s.statsdClient.Gauge(metricName, value, tags, 1) //nolint:errcheck
 }
+
+// ServiceCheck service check sender
+func (s *statsdMetricSender) ServiceCheck(checkName string, status servicecheck.ServiceCheckStatus, tags []string, message string) {
+	s.statsdClient.ServiceCheck(&ddgostatsd.ServiceCheck{ //nolint:errcheck
+		Name:    checkName,
+		Status:  ddgostatsd.ServiceCheckStatus(status),
+		Tags:    tags,
+		Message: message,
+	})
+}
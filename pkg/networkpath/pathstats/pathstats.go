@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package pathstats computes latency, packet loss and jitter statistics
+// from several traceroutes run against the same destination
+package pathstats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/utils"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/metricsender"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+)
+
+// PacketLossServiceCheck is the service check reporting whether the end-to-end
+// packet loss of a path is below the configured threshold
+const PacketLossServiceCheck = "network_path.packet_loss"
+
+// Stats holds the probes sent to a hop (or to the destination) and their RTTs in milliseconds
+type Stats struct {
+	TTL       int
+	IPAddress string
+	Sent      int
+	Received  int
+	RTTs      []float64
+}
+
+// PacketLoss returns the percentage of probes that didn't get a response
+func (s Stats) PacketLoss() float64 {
+	if s.Sent == 0 {
+		return 0
+	}
+	return float64(s.Sent-s.Received) / float64(s.Sent) * 100
+}
+
+// Percentile returns the nearest-rank percentile of the RTTs, p is between 0 and 100
+func (s Stats) Percentile(p float64) float64 {
+	if len(s.RTTs) == 0 {
+		return 0
+	}
+	rtts := make([]float64, len(s.RTTs))
+	copy(rtts, s.RTTs)
+	sort.Float64s(rtts)
+	rank := int(math.Ceil(p / 100 * float64(len(rtts))))
+	if rank < 1 {
+		rank = 1
+	}
+	return rtts[rank-1]
+}
+
+// Jitter returns the mean absolute difference between consecutive RTTs
+func (s Stats) Jitter() float64 {
+	if len(s.RTTs) < 2 {
+		return 0
+	}
+	var sum float64
+	for i := 1; i < len(s.RTTs); i++ {
+		sum += math.Abs(s.RTTs[i] - s.RTTs[i-1])
+	}
+	return sum / float64(len(s.RTTs)-1)
+}
+
+// PathStats holds the statistics of each intermediate hop and of the destination
+type PathStats struct {
+	Hops        []Stats
+	Destination Stats
+}
+
+// Compute aggregates the traceroutes run against the same destination.
+// Hops are matched by TTL, the IP reported for a hop is the one that answered most often.
+// A probe is counted for a hop only when the traceroute went past it without reaching the destination.
+func Compute(paths []payload.NetworkPath) PathStats {
+	var stats PathStats
+	hopsByTTL := make(map[int]*Stats)
+	ipCountsByTTL := make(map[int]map[string]int)
+
+	for _, path := range paths {
+		stats.Destination.Sent++
+		stats.Destination.IPAddress = path.Destination.IPAddress
+		for _, hop := range path.Hops {
+			if hop.Reachable && hop.IPAddress == path.Destination.IPAddress {
+				stats.Destination.Received++
+				stats.Destination.RTTs = append(stats.Destination.RTTs, hop.RTT)
+				break
+			}
+			hopStats, ok := hopsByTTL[hop.TTL]
+			if !ok {
+				hopStats = &Stats{TTL: hop.TTL}
+				hopsByTTL[hop.TTL] = hopStats
+				ipCountsByTTL[hop.TTL] = make(map[string]int)
+			}
+			hopStats.Sent++
+			if hop.Reachable {
+				hopStats.Received++
+				hopStats.RTTs = append(hopStats.RTTs, hop.RTT)
+				ipCountsByTTL[hop.TTL][hop.IPAddress]++
+			}
+		}
+	}
+
+	for ttl, hopStats := range hopsByTTL {
+		hopStats.IPAddress = mostFrequent(ipCountsByTTL[ttl])
+		if hopStats.IPAddress == "" {
+			hopStats.IPAddress = fmt.Sprintf("unknown_hop_%d", ttl)
+		}
+		stats.Hops = append(stats.Hops, *hopStats)
+	}
+	sort.Slice(stats.Hops, func(i, j int) bool {
+		return stats.Hops[i].TTL < stats.Hops[j].TTL
+	})
+	return stats
+}
+
+func mostFrequent(counts map[string]int) string {
+	var best string
+	for ip, count := range counts {
+		if count > counts[best] || (count == counts[best] && ip < best) {
+			best = ip
+		}
+	}
+	return best
+}
+
+// Submit sends the latency, packet loss and jitter metrics of the hops and of the destination,
+// and a service check that is critical when the end-to-end packet loss exceeds lossThreshold (in percent)
+func Submit(sender metricsender.MetricSender, stats PathStats, lossThreshold float64, tags []string) {
+	for _, hop := range stats.Hops {
+		hopTags := append(utils.CopyStrings(tags),
+			"hop_ttl:"+strconv.Itoa(hop.TTL),
+			"hop_ip_address:"+hop.IPAddress,
+		)
+		submitStats(sender, "network_path.hop.", hop, hopTags)
+	}
+	submitStats(sender, "network_path.path.", stats.Destination, tags)
+
+	status := servicecheck.ServiceCheckOK
+	message := ""
+	if loss := stats.Destination.PacketLoss(); loss > lossThreshold {
+		status = servicecheck.ServiceCheckCritical
+		message = fmt.Sprintf("packet loss %.1f%% is above the threshold of %.1f%%", loss, lossThreshold)
+	}
+	sender.ServiceCheck(PacketLossServiceCheck, status, tags, message)
+}
+
+func submitStats(sender metricsender.MetricSender, prefix string, stats Stats, tags []string) {
+	sender.Gauge(prefix+"packet_loss", stats.PacketLoss(), tags)
+	if len(stats.RTTs) == 0 {
+		return
+	}
+	sender.Gauge(prefix+"latency.p50", stats.Percentile(50), tags)
+	sender.Gauge(prefix+"latency.p95", stats.Percentile(95), tags)
+	sender.Gauge(prefix+"latency.max", stats.Percentile(100), tags)
+	if len(stats.RTTs) > 1 {
+		sender.Gauge(prefix+"jitter", stats.Jitter(), tags)
+	}
+}
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+//go:build test
+
+package pathstats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/metricsender"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+)
+
+func newPath(hops ...payload.NetworkPathHop) payload.NetworkPath {
+	return payload.NetworkPath{
+		Destination: payload.NetworkPathDestination{Hostname: "dest", IPAddress: "10.0.0.9"},
+		Hops:        hops,
+	}
+}
+
+func TestCompute(t *testing.T) {
+	paths := []payload.NetworkPath{
+		newPath(
+			payload.NetworkPathHop{TTL: 1, IPAddress: "10.0.0.1", Reachable: true, RTT: 1},
+			payload.NetworkPathHop{TTL: 2, IPAddress: "10.0.1.1", Reachable: true, RTT: 4},
+			payload.NetworkPathHop{TTL: 3, IPAddress: "10.0.0.9", Reachable: true, RTT: 10},
+		),
+		newPath(
+			payload.NetworkPathHop{TTL: 1, IPAddress: "10.0.0.1", Reachable: true, RTT: 3},
+			payload.NetworkPathHop{TTL: 2, IPAddress: "unknown_hop_2"},
+			payload.NetworkPathHop{TTL: 3, IPAddress: "10.0.0.9", Reachable: true, RTT: 14},
+		),
+		newPath(
+			payload.NetworkPathHop{TTL: 1, IPAddress: "10.0.0.1", Reachable: true, RTT: 2},
+			payload.NetworkPathHop{TTL: 2, IPAddress: "10.0.2.1", Reachable: true, RTT: 6},
+			payload.NetworkPathHop{TTL: 3, IPAddress: "unknown_hop_3"},
+		),
+	}
+
+	stats := Compute(paths)
+
+	assert.Equal(t, []Stats{
+		{TTL: 1, IPAddress: "10.0.0.1", Sent: 3, Received: 3, RTTs: []float64{1, 3, 2}},
+		{TTL: 2, IPAddress: "10.0.1.1", Sent: 3, Received: 2, RTTs: []float64{4, 6}},
+		{TTL: 3, IPAddress: "unknown_hop_3", Sent: 1, Received: 0},
+	}, stats.Hops)
+	assert.Equal(t, Stats{IPAddress: "10.0.0.9", Sent: 3, Received: 2, RTTs: []float64{10, 14}}, stats.Destination)
+}
+
+func TestStats(t *testing.T) {
+	s := Stats{Sent: 5, Received: 4, RTTs: []float64{10, 30, 20, 40}}
+
+	assert.InDelta(t, 20.0, s.PacketLoss(), 0.001)
+	assert.Equal(t, 20.0, s.Percentile(50))
+	assert.Equal(t, 40.0, s.Percentile(95))
+	assert.Equal(t, 40.0, s.Percentile(100))
+	assert.Equal(t, 10.0, s.Percentile(0))
+	// |30-10| + |20-30| + |40-20| = 50
+	assert.InDelta(t, 50.0/3, s.Jitter(), 0.001)
+
+	empty := Stats{}
+	assert.Equal(t, 0.0, empty.PacketLoss())
+	assert.Equal(t, 0.0, empty.Percentile(50))
+	assert.Equal(t, 0.0, empty.Jitter())
+}
+
+func TestSubmit(t *testing.T) {
+	stats := PathStats{
+		Hops: []Stats{
+			{TTL: 1, IPAddress: "10.0.0.1", Sent: 2, Received: 2, RTTs: []float64{1, 3}},
+			{TTL: 2, IPAddress: "unknown_hop_2", Sent: 2, Received: 0},
+		},
+		Destination: Stats{IPAddress: "10.0.0.9", Sent: 2, Received: 1, RTTs: []float64{10}},
+	}
+	sender := &metricsender.MockMetricSender{}
+	tags := []string{"destination_hostname:dest"}
+
+	Submit(sender, stats, 10, tags)
+
+	hop1Tags := []string{"destination_hostname:dest", "hop_ttl:1", "hop_ip_address:10.0.0.1"}
+	hop2Tags := []string{"destination_hostname:dest", "hop_ttl:2", "hop_ip_address:unknown_hop_2"}
+	assert.Equal(t, []metricsender.MockReceivedMetric{
+		{MetricType: metrics.GaugeType, Name: "network_path.hop.packet_loss", Value: 0, Tags: hop1Tags},
+		{MetricType: metrics.GaugeType, Name: "network_path.hop.latency.p50", Value: 1, Tags: hop1Tags},
+		{MetricType: metrics.GaugeType, Name: "network_path.hop.latency.p95", Value: 3, Tags: hop1Tags},
+		{MetricType: metrics.GaugeType, Name: "network_path.hop.latency.max", Value: 3, Tags: hop1Tags},
+		{MetricType: metrics.GaugeType, Name: "network_path.hop.jitter", Value: 2, Tags: hop1Tags},
+		{MetricType: metrics.GaugeType, Name: "network_path.hop.packet_loss", Value: 100, Tags: hop2Tags},
+		{MetricType: metrics.GaugeType, Name: "network_path.path.packet_loss", Value: 50, Tags: tags},
+		{MetricType: metrics.GaugeType, Name: "network_path.path.latency.p50", Value: 10, Tags: tags},
+		{MetricType: metrics.GaugeType, Name: "network_path.path.latency.p95", Value: 10, Tags: tags},
+		{MetricType: metrics.GaugeType, Name: "network_path.path.latency.max", Value: 10, Tags: tags},
+	}, sender.Metrics)
+	assert.Equal(t, []metricsender.MockReceivedServiceCheck{
+		{
+			Name:    "network_path.packet_loss",
+			Status:  servicecheck.ServiceCheckCritical,
+			Tags:    tags,
+			Message: "packet loss 50.0% is above the threshold of 10.0%",
+		},
+	}, sender.ServiceChecks)
+
+	sender = &metricsender.MockMetricSender{}
+	Submit(sender, stats, 50, tags)
+	assert.Equal(t, []metricsender.MockReceivedServiceCheck{
+		{Name: "network_path.packet_loss", Status: servicecheck.ServiceCheckOK, Tags: tags},
+	}, sender.ServiceChecks)
+}
@@ -18,6 +18,8 @@ const (
 	ProtocolTCP Protocol = "TCP"
 	// ProtocolUDP is the UDP protocol.
 	ProtocolUDP Protocol = "UDP"
+	// ProtocolICMP is the ICMP protocol.
+	ProtocolICMP Protocol = "ICMP"
 )
 
 // PathOrigin origin of the path e.g. network_traffic, network_path_integration
@@ -40,6 +42,15 @@ type NetworkPathHop struct {
 	Reachable bool    `json:"reachable"`
 }
 
+// NetworkPathFlow encapsulates the hops discovered
+// for a single flow of a multipath traceroute, load
+// balancers (ECMP) can route flows through different hops
+type NetworkPathFlow struct {
+	FlowID     int              `json:"flow_id"`
+	SourcePort uint16           `json:"source_port,omitempty"`
+	Hops       []NetworkPathHop `json:"hops"`
+}
+
 // NetworkPathSource encapsulates information
 // about the source of a path
 type NetworkPathSource struct {
@@ -70,5 +81,6 @@ type NetworkPath struct {
 	Source      NetworkPathSource      `json:"source"`
 	Destination NetworkPathDestination `json:"destination"`
 	Hops        []NetworkPathHop       `json:"hops"`
+	Paths       []NetworkPathFlow      `json:"paths,omitempty"` // all the paths of a multipath traceroute, Hops contains the first one
 	Tags        []string               `json:"tags,omitempty"`
 }
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package paris
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/ipv4"
+)
+
+// icmpProber builds ICMP echo request probes. Load balancers hash the first
+// bytes of the ICMP header, including the checksum. The flow ID is encoded in
+// the identifier and the TTL in the sequence number; the payload compensates the
+// sequence number so the checksum stays constant within a flow.
+type icmpProber struct {
+	srcIP    net.IP
+	dstIP    net.IP
+	id       uint16 // identifier of the first flow
+	numPaths uint16
+}
+
+func newICMPProber(srcIP net.IP, dstIP net.IP, id uint16, numPaths uint16) *icmpProber {
+	return &icmpProber{
+		srcIP:    srcIP,
+		dstIP:    dstIP,
+		id:       id,
+		numPaths: numPaths,
+	}
+}
+
+func (i *icmpProber) path(flowID uint16) *Path {
+	return &Path{
+		FlowID: flowID,
+		ICMPID: i.id + flowID,
+	}
+}
+
+func (i *icmpProber) probe(flowID uint16, ttl uint8) (*ipv4.Header, []byte, error) {
+	ipLayer := &layers.IPv4{
+		Version:  4,
+		TTL:      ttl,
+		Id:       uint16(ttl),
+		Protocol: layers.IPProtocolICMPv4,
+		SrcIP:    i.srcIP,
+		DstIP:    i.dstIP,
+	}
+	icmpLayer := &layers.ICMPv4{
+		TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoRequest, 0),
+		Id:       i.id + flowID,
+		Seq:      uint16(ttl),
+	}
+	// seq + ^seq = 0xffff which is a no-op in one's complement arithmetic
+	payload := make([]byte, 2)
+	binary.BigEndian.PutUint16(payload, ^uint16(ttl))
+	return serializeProbe(ipLayer, icmpLayer, gopacket.Payload(payload))
+}
+
+func (i *icmpProber) match(response *icmpResponse) (probeResponse, bool) {
+	var id, seq uint16
+	switch response.TypeCode.Type() {
+	case layers.ICMPv4TypeEchoReply:
+		if !response.SrcIP.Equal(i.dstIP) {
+			return probeResponse{}, false
+		}
+		id, seq = response.ID, response.Seq
+	default:
+		if response.InnerProtocol != layers.IPProtocolICMPv4 ||
+			!i.srcIP.Equal(response.InnerSrcIP) ||
+			!i.dstIP.Equal(response.InnerDstIP) ||
+			response.InnerTransport[0] != layers.ICMPv4TypeEchoRequest {
+			return probeResponse{}, false
+		}
+		id = binary.BigEndian.Uint16(response.InnerTransport[4:6])
+		seq = binary.BigEndian.Uint16(response.InnerTransport[6:8])
+	}
+	if id < i.id || id-i.id >= i.numPaths || seq > 0xff {
+		return probeResponse{}, false
+	}
+
+	return probeResponse{
+		FlowID:   id - i.id,
+		TTL:      uint8(seq),
+		IP:       response.SrcIP,
+		ICMPType: response.TypeCode,
+		IsDest:   response.SrcIP.Equal(i.dstIP),
+	}, true
+}
@@ -0,0 +1,266 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package paris adds Paris traceroute style UDP and ICMP implementations to the agent.
+//
+// Load balancers spread packets over equal-cost paths (ECMP) using a hash of the
+// flow identifier (addresses, protocol, ports or ICMP checksum). A Paris traceroute
+// keeps that identifier constant for all the probes of a flow so they follow the same
+// path, and varies it between flows to enumerate the different paths.
+package paris
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/ipv4"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Protocol is the protocol used by the probes
+type Protocol string
+
+const (
+	// ProtocolUDP sends UDP probes, the flows differ by their source port
+	ProtocolUDP Protocol = "udp"
+	// ProtocolICMP sends ICMP echo requests, the flows differ by their identifier and checksum
+	ProtocolICMP Protocol = "icmp"
+
+	// MaxNumPaths is the maximum number of flows probed by a traceroute
+	MaxNumPaths = 32
+
+	// DefaultSourcePort is the first source port used by UDP probes
+	DefaultSourcePort = 33000
+)
+
+type (
+	// Traceroute encapsulates the data needed to run a Paris traceroute
+	Traceroute struct {
+		Target   net.IP
+		DestPort uint16 // only used by UDP probes
+		Protocol Protocol
+		NumPaths uint16
+		MinTTL   uint8
+		MaxTTL   uint8
+		Delay    time.Duration // delay between sending the probes of the different flows
+		Timeout  time.Duration // timeout waiting for the responses of the probes with the same TTL
+	}
+
+	// Results encapsulates a response from the Paris traceroute
+	Results struct {
+		Source   net.IP
+		Target   net.IP
+		DstPort  uint16
+		Protocol Protocol
+		Paths    []*Path
+	}
+
+	// Path encapsulates the hops discovered for a single flow
+	Path struct {
+		FlowID uint16
+		// SrcPort is the source port of the UDP probes of the flow
+		SrcPort uint16
+		// ICMPID is the identifier of the ICMP probes of the flow
+		ICMPID uint16
+		Hops   []*Hop
+	}
+
+	// Hop encapsulates information about a single
+	// hop in a Paris traceroute
+	Hop struct {
+		IP       net.IP
+		ICMPType layers.ICMPv4TypeCode
+		RTT      time.Duration
+		IsDest   bool
+	}
+
+	// probeResponse identifies the probe an ICMP response answers
+	probeResponse struct {
+		FlowID   uint16
+		TTL      uint8
+		IP       net.IP
+		ICMPType layers.ICMPv4TypeCode
+		IsDest   bool
+	}
+
+	// prober builds the probes of a protocol and matches their responses
+	prober interface {
+		probe(flowID uint16, ttl uint8) (*ipv4.Header, []byte, error)
+		match(response *icmpResponse) (probeResponse, bool)
+		path(flowID uint16) *Path
+	}
+
+	rawConnWrapper interface {
+		SetReadDeadline(t time.Time) error
+		ReadFrom(b []byte) (*ipv4.Header, []byte, *ipv4.ControlMessage, error)
+		WriteTo(h *ipv4.Header, p []byte, cm *ipv4.ControlMessage) error
+	}
+)
+
+// Traceroute runs the traceroute, all the flows are probed in parallel one TTL at a time
+func (t *Traceroute) Traceroute() (*Results, error) {
+	numPaths := t.NumPaths
+	if numPaths == 0 {
+		numPaths = 1
+	}
+	if numPaths > MaxNumPaths {
+		return nil, fmt.Errorf("number of paths %d is greater than the maximum of %d", numPaths, MaxNumPaths)
+	}
+
+	srcIP, err := localAddrForHost(t.Target, t.DestPort)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local address for target: %w", err)
+	}
+
+	// Create a raw ICMP listener to catch ICMP responses, it is
+	// also used to send ICMP probes
+	icmpConn, err := net.ListenPacket("ip4:icmp", srcIP.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ICMP listener: %w", err)
+	}
+	defer icmpConn.Close()
+	// RawConn is necessary to set the TTL and ID fields
+	rawICMPConn, err := ipv4.NewRawConn(icmpConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raw ICMP listener: %w", err)
+	}
+
+	var p prober
+	sendConn := rawConnWrapper(rawICMPConn)
+	// the IP IDs and ICMP identifiers are randomized to avoid mixing up the
+	// responses of concurrent traceroutes
+	switch t.Protocol {
+	case ProtocolUDP:
+		udpConn, err := net.ListenPacket("ip4:udp", srcIP.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create UDP socket: %w", err)
+		}
+		defer udpConn.Close()
+		rawUDPConn, err := ipv4.NewRawConn(udpConn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get raw UDP socket: %w", err)
+		}
+		sendConn = rawUDPConn
+		p = newUDPProber(srcIP, t.Target, uint16(DefaultSourcePort+rand.Intn(10000)), t.DestPort, numPaths, uint16(rand.Intn(0xffff-0xff)))
+	case ProtocolICMP:
+		p = newICMPProber(srcIP, t.Target, uint16(rand.Intn(0xffff-MaxNumPaths)), numPaths)
+	default:
+		return nil, fmt.Errorf("unsupported protocol %q", t.Protocol)
+	}
+
+	paths, err := t.run(p, sendConn, rawICMPConn, numPaths)
+	if err != nil {
+		return nil, err
+	}
+	return &Results{
+		Source:   srcIP,
+		Target:   t.Target,
+		DstPort:  t.DestPort,
+		Protocol: t.Protocol,
+		Paths:    paths,
+	}, nil
+}
+
+// run probes every flow with an increasing TTL until all flows reached the target or MaxTTL
+func (t *Traceroute) run(p prober, sendConn rawConnWrapper, icmpConn rawConnWrapper, numPaths uint16) ([]*Path, error) {
+	paths := make([]*Path, 0, numPaths)
+	for flowID := uint16(0); flowID < numPaths; flowID++ {
+		paths = append(paths, p.path(flowID))
+	}
+
+	reached := make([]bool, numPaths)
+	for ttl := int(t.MinTTL); ttl <= int(t.MaxTTL); ttl++ {
+		sentAt := make(map[uint16]time.Time, numPaths)
+		for flowID := uint16(0); flowID < numPaths; flowID++ {
+			if reached[flowID] {
+				continue
+			}
+			header, packet, err := p.probe(flowID, uint8(ttl))
+			if err != nil {
+				return nil, fmt.Errorf("failed to create probe with TTL %d: %w", ttl, err)
+			}
+			if len(sentAt) > 0 && t.Delay > 0 {
+				time.Sleep(t.Delay)
+			}
+			if err := sendConn.WriteTo(header, packet, nil); err != nil {
+				return nil, fmt.Errorf("failed to send probe with TTL %d: %w", ttl, err)
+			}
+			sentAt[flowID] = time.Now()
+		}
+		if len(sentAt) == 0 {
+			break
+		}
+
+		responses, err := listenResponses(icmpConn, p, uint8(ttl), sentAt, t.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run traceroute: %w", err)
+		}
+		for flowID := range sentAt {
+			hop, ok := responses[flowID]
+			if !ok {
+				hop = &Hop{IP: net.IP{}}
+			}
+			log.Tracef("Discovered hop for flow %d: %+v", flowID, hop)
+			paths[flowID].Hops = append(paths[flowID].Hops, hop)
+			reached[flowID] = hop.IsDest
+		}
+	}
+	return paths, nil
+}
+
+// listenResponses reads ICMP responses until all the probes sent for ttl are answered or the timeout expires
+func listenResponses(conn rawConnWrapper, p prober, ttl uint8, sentAt map[uint16]time.Time, timeout time.Duration) (map[uint16]*Hop, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	hops := make(map[uint16]*Hop, len(sentAt))
+	buf := make([]byte, 1500)
+	for len(hops) < len(sentAt) {
+		select {
+		case <-ctx.Done():
+			log.Tracef("timed out waiting for %d responses", len(sentAt)-len(hops))
+			return hops, nil
+		default:
+		}
+		err := conn.SetReadDeadline(time.Now().Add(time.Millisecond * 100))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read: %w", err)
+		}
+		header, packet, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if nerr, ok := err.(*net.OpError); ok && nerr.Timeout() {
+				continue
+			}
+			return nil, err
+		}
+		received := time.Now()
+
+		response, err := parseICMP(header, packet)
+		if err != nil {
+			log.Tracef("failed to parse ICMP packet: %s", err.Error())
+			continue
+		}
+		match, ok := p.match(response)
+		if !ok || match.TTL != ttl {
+			continue
+		}
+		start, sent := sentAt[match.FlowID]
+		if _, found := hops[match.FlowID]; !sent || found {
+			continue
+		}
+		hops[match.FlowID] = &Hop{
+			IP:       match.IP,
+			ICMPType: match.ICMPType,
+			RTT:      received.Sub(start),
+			IsDest:   match.IsDest,
+		}
+	}
+	return hops, nil
+}
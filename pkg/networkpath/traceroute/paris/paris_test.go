@@ -0,0 +1,280 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package paris
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/ipv4"
+)
+
+var (
+	srcIP    = net.ParseIP("192.168.1.10").To4()
+	targetIP = net.ParseIP("8.8.8.8").To4()
+	firstHop = net.ParseIP("10.0.0.1").To4()
+	// the second hop is load balanced between two routers
+	secondHops = []net.IP{net.ParseIP("10.0.1.1").To4(), net.ParseIP("10.0.2.1").To4()}
+)
+
+type packet struct {
+	header  *ipv4.Header
+	payload []byte
+}
+
+// fakeNetwork answers the probes it receives like a network where the
+// second hop is load balanced on the flow identifier of the probes
+type fakeNetwork struct {
+	t         *testing.T
+	responses []packet
+	// silentFlows are the flow hashes for which routers do not answer
+	silentHops map[string]bool
+	checksums  map[uint16]uint16
+}
+
+func newFakeNetwork(t *testing.T) *fakeNetwork {
+	return &fakeNetwork{
+		t:          t,
+		silentHops: make(map[string]bool),
+		checksums:  make(map[uint16]uint16),
+	}
+}
+
+func (f *fakeNetwork) SetReadDeadline(_ time.Time) error {
+	return nil
+}
+
+func (f *fakeNetwork) ReadFrom(b []byte) (*ipv4.Header, []byte, *ipv4.ControlMessage, error) {
+	if len(f.responses) == 0 {
+		return nil, nil, nil, &net.OpError{Op: "read", Err: timeoutError{}}
+	}
+	response := f.responses[0]
+	f.responses = f.responses[1:]
+	n := copy(b, response.payload)
+	return response.header, b[:n], nil, nil
+}
+
+func (f *fakeNetwork) WriteTo(h *ipv4.Header, p []byte, _ *ipv4.ControlMessage) error {
+	var flowHash uint16
+	switch h.Protocol {
+	case int(layers.IPProtocolUDP):
+		flowHash = binary.BigEndian.Uint16(p[0:2]) // source port
+	case int(layers.IPProtocolICMPv4):
+		flowHash = binary.BigEndian.Uint16(p[2:4]) // checksum
+		id := binary.BigEndian.Uint16(p[4:6])
+		if checksum, ok := f.checksums[id]; ok {
+			assert.Equal(f.t, checksum, flowHash, "the checksum of the probes of a flow must be constant")
+		}
+		f.checksums[id] = flowHash
+	}
+
+	var router net.IP
+	switch h.TTL {
+	case 1:
+		router = firstHop
+	case 2:
+		router = secondHops[flowHash%2]
+	default:
+		router = targetIP
+	}
+	if f.silentHops[router.String()] {
+		return nil
+	}
+
+	var icmpLayer *layers.ICMPv4
+	var icmpPayload []byte
+	switch {
+	case router.Equal(targetIP) && h.Protocol == int(layers.IPProtocolICMPv4):
+		icmpLayer = &layers.ICMPv4{
+			TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoReply, 0),
+			Id:       binary.BigEndian.Uint16(p[4:6]),
+			Seq:      binary.BigEndian.Uint16(p[6:8]),
+		}
+		icmpPayload = p[8:]
+	case router.Equal(targetIP):
+		icmpLayer = &layers.ICMPv4{TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeDestinationUnreachable, layers.ICMPv4CodePort)}
+		icmpPayload = quote(f.t, h, p)
+	default:
+		icmpLayer = &layers.ICMPv4{TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeTimeExceeded, layers.ICMPv4CodeTTLExceeded)}
+		icmpPayload = quote(f.t, h, p)
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{ComputeChecksums: true}, icmpLayer, gopacket.Payload(icmpPayload))
+	require.NoError(f.t, err)
+	f.responses = append(f.responses, packet{
+		header: &ipv4.Header{
+			Version:  4,
+			Len:      ipv4.HeaderLen,
+			Protocol: int(layers.IPProtocolICMPv4),
+			Src:      router,
+			Dst:      h.Src,
+		},
+		payload: buf.Bytes(),
+	})
+	return nil
+}
+
+// quote returns the IP header and first 8 bytes of the payload of a packet, as included in ICMP errors
+func quote(t *testing.T, h *ipv4.Header, p []byte) []byte {
+	header := *h
+	header.TotalLen = ipv4.HeaderLen + len(p)
+	headerBytes, err := header.Marshal()
+	require.NoError(t, err)
+	return append(headerBytes, p[:quotedTransportLen]...)
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestTraceroute_run(t *testing.T) {
+	tests := []struct {
+		name   string
+		prober prober
+	}{
+		{
+			name:   "udp",
+			prober: newUDPProber(srcIP, targetIP, 33000, 33434, 4, 1000),
+		},
+		{
+			name:   "icmp",
+			prober: newICMPProber(srcIP, targetIP, 4242, 4),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := newFakeNetwork(t)
+			tr := &Traceroute{MinTTL: 1, MaxTTL: 10, Timeout: 50 * time.Millisecond}
+
+			paths, err := tr.run(tt.prober, network, network, 4)
+			require.NoError(t, err)
+			require.Len(t, paths, 4)
+
+			seenSecondHops := make(map[string]struct{})
+			for flowID, path := range paths {
+				assert.Equal(t, uint16(flowID), path.FlowID)
+				require.Len(t, path.Hops, 3)
+				assert.Equal(t, firstHop, path.Hops[0].IP)
+				assert.False(t, path.Hops[0].IsDest)
+				assert.Equal(t, uint8(layers.ICMPv4TypeTimeExceeded), path.Hops[0].ICMPType.Type())
+				assert.False(t, path.Hops[1].IsDest)
+				seenSecondHops[path.Hops[1].IP.String()] = struct{}{}
+				assert.Equal(t, targetIP, path.Hops[2].IP)
+				assert.True(t, path.Hops[2].IsDest)
+			}
+			// the flows went through both load balanced routers
+			assert.Equal(t, map[string]struct{}{"10.0.1.1": {}, "10.0.2.1": {}}, seenSecondHops)
+		})
+	}
+}
+
+func TestTraceroute_run_silentHop(t *testing.T) {
+	network := newFakeNetwork(t)
+	network.silentHops[firstHop.String()] = true
+	tr := &Traceroute{MinTTL: 1, MaxTTL: 10, Timeout: 10 * time.Millisecond}
+
+	paths, err := tr.run(newUDPProber(srcIP, targetIP, 33000, 33434, 1, 1000), network, network, 1)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.Len(t, paths[0].Hops, 3)
+	assert.Equal(t, net.IP{}, paths[0].Hops[0].IP)
+	assert.Equal(t, time.Duration(0), paths[0].Hops[0].RTT)
+	assert.Equal(t, targetIP, paths[0].Hops[2].IP)
+}
+
+func TestTraceroute_run_unreachable(t *testing.T) {
+	network := newFakeNetwork(t)
+	network.silentHops[targetIP.String()] = true
+	tr := &Traceroute{MinTTL: 1, MaxTTL: 5, Timeout: 10 * time.Millisecond}
+
+	paths, err := tr.run(newICMPProber(srcIP, targetIP, 4242, 2), network, network, 2)
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+	for _, path := range paths {
+		require.Len(t, path.Hops, 5)
+		assert.False(t, path.Hops[4].IsDest)
+	}
+}
+
+func TestICMPProber_constantChecksum(t *testing.T) {
+	p := newICMPProber(srcIP, targetIP, 100, 2)
+	checksums := make(map[uint16]struct{})
+	for ttl := uint8(1); ttl < 30; ttl++ {
+		_, packet, err := p.probe(1, ttl)
+		require.NoError(t, err)
+		checksums[binary.BigEndian.Uint16(packet[2:4])] = struct{}{}
+	}
+	assert.Len(t, checksums, 1)
+
+	_, otherFlow, err := p.probe(0, 1)
+	require.NoError(t, err)
+	assert.NotContains(t, checksums, binary.BigEndian.Uint16(otherFlow[2:4]))
+}
+
+func TestProber_match(t *testing.T) {
+	udp := newUDPProber(srcIP, targetIP, 33000, 33434, 2, 1000)
+	header, probe, err := udp.probe(1, 7)
+	require.NoError(t, err)
+
+	response := &icmpResponse{
+		SrcIP:          firstHop,
+		TypeCode:       layers.CreateICMPv4TypeCode(layers.ICMPv4TypeTimeExceeded, 0),
+		InnerSrcIP:     srcIP,
+		InnerDstIP:     targetIP,
+		InnerIPID:      uint16(header.ID),
+		InnerProtocol:  layers.IPProtocolUDP,
+		InnerTransport: probe[:quotedTransportLen],
+	}
+	match, ok := udp.match(response)
+	require.True(t, ok)
+	assert.Equal(t, probeResponse{FlowID: 1, TTL: 7, IP: firstHop, ICMPType: response.TypeCode}, match)
+
+	// probe of another traceroute
+	other := newUDPProber(srcIP, targetIP, 40000, 33434, 2, 1000)
+	_, ok = other.match(response)
+	assert.False(t, ok)
+
+	// response to an ICMP probe
+	icmp := newICMPProber(srcIP, targetIP, 100, 2)
+	_, ok = icmp.match(response)
+	assert.False(t, ok)
+}
+
+func TestParseICMP(t *testing.T) {
+	network := newFakeNetwork(t)
+	udp := newUDPProber(srcIP, targetIP, 33000, 33434, 1, 1000)
+	header, probe, err := udp.probe(0, 1)
+	require.NoError(t, err)
+	require.NoError(t, network.WriteTo(header, probe, nil))
+
+	buf := make([]byte, 1500)
+	responseHeader, payload, _, err := network.ReadFrom(buf)
+	require.NoError(t, err)
+	response, err := parseICMP(responseHeader, payload)
+	require.NoError(t, err)
+	assert.Equal(t, firstHop, response.SrcIP)
+	assert.Equal(t, uint8(layers.ICMPv4TypeTimeExceeded), response.TypeCode.Type())
+	assert.Equal(t, srcIP, response.InnerSrcIP)
+	assert.Equal(t, targetIP, response.InnerDstIP)
+	assert.Equal(t, uint16(1001), response.InnerIPID)
+	assert.Equal(t, layers.IPProtocolUDP, response.InnerProtocol)
+	assert.Equal(t, probe[:quotedTransportLen], response.InnerTransport)
+
+	_, err = parseICMP(&ipv4.Header{Version: 4, Protocol: int(layers.IPProtocolTCP), Src: firstHop, Dst: srcIP}, payload)
+	assert.ErrorContains(t, err, "invalid IP header for ICMP packet")
+
+	_, err = parseICMP(responseHeader, payload[:12])
+	assert.ErrorContains(t, err, "failed to decode inner IP header")
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package paris
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/ipv4"
+)
+
+// udpProbePayload is the constant payload of UDP probes, keeping the payload
+// constant keeps the UDP checksum constant within a flow
+var udpProbePayload = []byte("NSMNC\x00\x00\x00")
+
+// udpProber builds UDP probes. The flow ID is encoded in the source port, which
+// load balancers hash, and the TTL in the IP ID, which they do not hash.
+type udpProber struct {
+	srcIP      net.IP
+	dstIP      net.IP
+	srcPort    uint16 // source port of the first flow
+	dstPort    uint16
+	numPaths   uint16
+	ipIDOffset uint16
+}
+
+func newUDPProber(srcIP net.IP, dstIP net.IP, srcPort uint16, dstPort uint16, numPaths uint16, ipIDOffset uint16) *udpProber {
+	return &udpProber{
+		srcIP:      srcIP,
+		dstIP:      dstIP,
+		srcPort:    srcPort,
+		dstPort:    dstPort,
+		numPaths:   numPaths,
+		ipIDOffset: ipIDOffset,
+	}
+}
+
+func (u *udpProber) path(flowID uint16) *Path {
+	return &Path{
+		FlowID:  flowID,
+		SrcPort: u.srcPort + flowID,
+	}
+}
+
+func (u *udpProber) probe(flowID uint16, ttl uint8) (*ipv4.Header, []byte, error) {
+	ipLayer := &layers.IPv4{
+		Version:  4,
+		TTL:      ttl,
+		Id:       u.ipIDOffset + uint16(ttl),
+		Protocol: layers.IPProtocolUDP,
+		SrcIP:    u.srcIP,
+		DstIP:    u.dstIP,
+	}
+	udpLayer := &layers.UDP{
+		SrcPort: layers.UDPPort(u.srcPort + flowID),
+		DstPort: layers.UDPPort(u.dstPort),
+	}
+	if err := udpLayer.SetNetworkLayerForChecksum(ipLayer); err != nil {
+		return nil, nil, err
+	}
+	return serializeProbe(ipLayer, udpLayer, gopacket.Payload(udpProbePayload))
+}
+
+func (u *udpProber) match(response *icmpResponse) (probeResponse, bool) {
+	if response.InnerProtocol != layers.IPProtocolUDP ||
+		!u.srcIP.Equal(response.InnerSrcIP) ||
+		!u.dstIP.Equal(response.InnerDstIP) {
+		return probeResponse{}, false
+	}
+	srcPort := binary.BigEndian.Uint16(response.InnerTransport[0:2])
+	dstPort := binary.BigEndian.Uint16(response.InnerTransport[2:4])
+	if dstPort != u.dstPort || srcPort < u.srcPort || srcPort-u.srcPort >= u.numPaths {
+		return probeResponse{}, false
+	}
+	ttl := response.InnerIPID - u.ipIDOffset
+	if ttl > 0xff {
+		return probeResponse{}, false
+	}
+
+	return probeResponse{
+		FlowID:   srcPort - u.srcPort,
+		TTL:      uint8(ttl),
+		IP:       response.SrcIP,
+		ICMPType: response.TypeCode,
+		// the target answers UDP probes with a port unreachable message
+		IsDest: response.SrcIP.Equal(u.dstIP),
+	}, true
+}
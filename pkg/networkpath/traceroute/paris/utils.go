@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package paris
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/ipv4"
+)
+
+const (
+	// quotedTransportLen is the length of the transport header quoted in ICMP errors (RFC 792)
+	quotedTransportLen = 8
+)
+
+// icmpResponse encapsulates the data from an ICMP
+// response packet needed for matching
+type icmpResponse struct {
+	SrcIP    net.IP
+	DstIP    net.IP
+	TypeCode layers.ICMPv4TypeCode
+	// ID and Seq are set for echo replies
+	ID  uint16
+	Seq uint16
+	// the Inner fields describe the probe quoted by time exceeded
+	// and destination unreachable messages
+	InnerSrcIP    net.IP
+	InnerDstIP    net.IP
+	InnerIPID     uint16
+	InnerProtocol layers.IPProtocol
+	// InnerTransport contains the first 8 bytes of the transport header of the probe
+	InnerTransport []byte
+}
+
+func localAddrForHost(destIP net.IP, destPort uint16) (net.IP, error) {
+	// this is a quick way to get the local address for connecting to the host
+	// using UDP as the network type to avoid actually creating a connection to
+	// the host, just get the OS to give us a local IP
+	conn, err := net.Dial("udp4", net.JoinHostPort(destIP.String(), strconv.Itoa(int(destPort))))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	localAddr := conn.LocalAddr()
+
+	localUDPAddr, ok := localAddr.(*net.UDPAddr)
+	if !ok {
+		return nil, fmt.Errorf("invalid address type for %s: want %T, got %T", localAddr, localUDPAddr, localAddr)
+	}
+
+	return localUDPAddr.IP, nil
+}
+
+// serializeProbe serializes the probe layers and splits the IPv4 header from the payload
+func serializeProbe(ipLayer *layers.IPv4, transportLayers ...gopacket.SerializableLayer) (*ipv4.Header, []byte, error) {
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	err := gopacket.SerializeLayers(buf, opts, append([]gopacket.SerializableLayer{ipLayer}, transportLayers...)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to serialize packet: %w", err)
+	}
+	packet := buf.Bytes()
+
+	var ipHdr ipv4.Header
+	if err := ipHdr.Parse(packet[:ipv4.HeaderLen]); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse IP header: %w", err)
+	}
+
+	return &ipHdr, packet[ipv4.HeaderLen:], nil
+}
+
+// parseICMP takes in an IPv4 header and payload and tries to convert to an ICMP
+// message, it returns all the fields from the packet we need to match it with a probe
+func parseICMP(header *ipv4.Header, payload []byte) (*icmpResponse, error) {
+	if header.Protocol != int(layers.IPProtocolICMPv4) || header.Version != 4 ||
+		header.Src == nil || header.Dst == nil {
+		return nil, fmt.Errorf("invalid IP header for ICMP packet: %+v", header)
+	}
+
+	var icmpLayer layers.ICMPv4
+	if err := icmpLayer.DecodeFromBytes(payload, gopacket.NilDecodeFeedback); err != nil {
+		return nil, fmt.Errorf("failed to decode ICMP packet: %w", err)
+	}
+	response := &icmpResponse{
+		SrcIP:    header.Src,
+		DstIP:    header.Dst,
+		TypeCode: icmpLayer.TypeCode,
+	}
+
+	switch icmpLayer.TypeCode.Type() {
+	case layers.ICMPv4TypeEchoReply:
+		response.ID = icmpLayer.Id
+		response.Seq = icmpLayer.Seq
+	case layers.ICMPv4TypeTimeExceeded, layers.ICMPv4TypeDestinationUnreachable:
+		var innerIPLayer layers.IPv4
+		if err := innerIPLayer.DecodeFromBytes(icmpLayer.Payload, gopacket.NilDecodeFeedback); err != nil {
+			return nil, fmt.Errorf("failed to decode inner IP header: %w", err)
+		}
+		if len(innerIPLayer.Payload) < quotedTransportLen {
+			return nil, fmt.Errorf("quoted transport header is too short: %d bytes", len(innerIPLayer.Payload))
+		}
+		response.InnerSrcIP = innerIPLayer.SrcIP
+		response.InnerDstIP = innerIPLayer.DstIP
+		response.InnerIPID = innerIPLayer.Id
+		response.InnerProtocol = innerIPLayer.Protocol
+		response.InnerTransport = innerIPLayer.Payload[:quotedTransportLen]
+	default:
+		return nil, fmt.Errorf("unexpected ICMP type %s", icmpLayer.TypeCode)
+	}
+	return response, nil
+}
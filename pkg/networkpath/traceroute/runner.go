@@ -12,17 +12,15 @@ import (
 	"math/rand"
 	"net"
 	"os"
-	"sort"
 	"time"
 
-	"github.com/Datadog/dublin-traceroute/go/dublintraceroute/probes/probev4"
-	"github.com/Datadog/dublin-traceroute/go/dublintraceroute/results"
 	"github.com/vishvananda/netns"
 
 	telemetryComponent "github.com/DataDog/datadog-agent/comp/core/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/paris"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/tcp"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
@@ -147,9 +145,9 @@ func (r *Runner) RunTraceroute(ctx context.Context, cfg Config) (payload.Network
 			tracerouteRunnerTelemetry.failedRuns.Inc()
 			return payload.NetworkPath{}, err
 		}
-	case payload.ProtocolUDP:
-		log.Tracef("Running UDP traceroute for: %+v", cfg)
-		pathResult, err = r.runUDP(cfg, hname, dest, maxTTL, timeout)
+	case payload.ProtocolUDP, payload.ProtocolICMP:
+		log.Tracef("Running %s traceroute for: %+v", protocol, cfg)
+		pathResult, err = r.runParis(cfg, hname, dest, maxTTL, protocol)
 		if err != nil {
 			tracerouteRunnerTelemetry.failedRuns.Inc()
 			return payload.NetworkPath{}, err
@@ -163,32 +161,46 @@ func (r *Runner) RunTraceroute(ctx context.Context, cfg Config) (payload.Network
 	return pathResult, nil
 }
 
-func (r *Runner) runUDP(cfg Config, hname string, dest net.IP, maxTTL uint8, timeout time.Duration) (payload.NetworkPath, error) {
-	destPort, srcPort, useSourcePort := getPorts(cfg.DestPort)
-
-	dt := &probev4.UDPv4{
-		Target:     dest,
-		SrcPort:    srcPort,
-		DstPort:    destPort,
-		UseSrcPort: useSourcePort,
-		NumPaths:   uint16(DefaultNumPaths),
-		MinTTL:     uint8(DefaultMinTTL), // TODO: what's a good value?
-		MaxTTL:     maxTTL,
-		Delay:      time.Duration(DefaultDelay) * time.Millisecond, // TODO: what's a good value?
-		Timeout:    timeout,                                        // TODO: what's a good value?
-		BrokenNAT:  false,
+func (r *Runner) runParis(cfg Config, hname string, dest net.IP, maxTTL uint8, protocol payload.Protocol) (payload.NetworkPath, error) {
+	numPaths := cfg.NumPaths
+	if numPaths == 0 {
+		numPaths = DefaultNumPaths
+	}
+	if numPaths > paris.MaxNumPaths {
+		return payload.NetworkPath{}, fmt.Errorf("invalid number of paths %d, the maximum is %d", numPaths, paris.MaxNumPaths)
 	}
 
-	results, err := dt.Traceroute()
-	if err != nil {
-		return payload.NetworkPath{}, fmt.Errorf("traceroute run failed: %s", err.Error())
+	// the timeout applies to each TTL since all the flows are probed in parallel
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = setup.DefaultNetworkPathTimeout * time.Millisecond
+	}
+
+	tr := paris.Traceroute{
+		Target:   dest,
+		NumPaths: numPaths,
+		MinTTL:   uint8(DefaultMinTTL),
+		MaxTTL:   maxTTL,
+		Delay:    time.Duration(DefaultDelay) * time.Millisecond,
+		Timeout:  timeout,
+	}
+	var destPort uint16
+	switch protocol {
+	case payload.ProtocolUDP:
+		tr.Protocol = paris.ProtocolUDP
+		destPort, _, _ = getPorts(cfg.DestPort)
+		tr.DestPort = destPort
+	case payload.ProtocolICMP:
+		tr.Protocol = paris.ProtocolICMP
 	}
 
-	pathResult, err := r.processUDPResults(results, hname, cfg.DestHostname, destPort, dest)
+	results, err := tr.Traceroute()
 	if err != nil {
-		return payload.NetworkPath{}, err
+		return payload.NetworkPath{}, fmt.Errorf("traceroute run failed: %s", err.Error())
 	}
-	log.Tracef("UDP Results: %+v", pathResult)
+
+	pathResult := r.processParisResults(results, hname, cfg.DestHostname, destPort, protocol)
+	log.Tracef("%s Results: %+v", protocol, pathResult)
 
 	return pathResult, nil
 }
@@ -280,15 +292,10 @@ func (r *Runner) processTCPResults(res *tcp.Results, hname string, destinationHo
 	return traceroutePath, nil
 }
 
-func (r *Runner) processUDPResults(res *results.Results, hname string, destinationHost string, destinationPort uint16, destinationIP net.IP) (payload.NetworkPath, error) {
-	type node struct {
-		node  string
-		probe *results.Probe
-	}
-
+func (r *Runner) processParisResults(res *paris.Results, hname string, destinationHost string, destinationPort uint16, protocol payload.Protocol) payload.NetworkPath {
 	traceroutePath := payload.NetworkPath{
 		PathtraceID: payload.NewPathtraceID(),
-		Protocol:    payload.ProtocolUDP,
+		Protocol:    protocol,
 		Timestamp:   time.Now().UnixMilli(),
 		Source: payload.NetworkPathSource{
 			Hostname:  hname,
@@ -297,94 +304,59 @@ func (r *Runner) processUDPResults(res *results.Results, hname string, destinati
 		Destination: payload.NetworkPathDestination{
 			Hostname:  getDestinationHostname(destinationHost),
 			Port:      destinationPort,
-			IPAddress: destinationIP.String(),
+			IPAddress: res.Target.String(),
 		},
 	}
 
-	flowIDs := make([]int, 0, len(res.Flows))
-	for flowID := range res.Flows {
-		flowIDs = append(flowIDs, int(flowID))
-	}
-	sort.Ints(flowIDs)
-
-	for _, flowID := range flowIDs {
-		hops := res.Flows[uint16(flowID)]
-		if len(hops) == 0 {
-			log.Tracef("No hops for flow ID %d", flowID)
-			continue
-		}
-		var nodes []node
-		// add first hop
-		localAddr := hops[0].Sent.IP.SrcIP
-
-		// get hardware interface info
-		if r.gatewayLookup != nil {
-			src := util.AddressFromNetIP(localAddr)
-			dst := util.AddressFromNetIP(hops[0].Sent.IP.DstIP)
-
-			traceroutePath.Source.Via = r.gatewayLookup.LookupWithIPs(src, dst, r.nsIno)
-		}
-
-		firstNodeName := localAddr.String()
-		nodes = append(nodes, node{node: firstNodeName, probe: &hops[0]})
+	// get hardware interface info
+	if r.gatewayLookup != nil {
+		src := util.AddressFromNetIP(res.Source)
+		dst := util.AddressFromNetIP(res.Target)
 
-		// then add all the other hops
-		for _, hop := range hops {
-			hop := hop
-			nodename := fmt.Sprintf("unknown_hop_%d", hop.Sent.IP.TTL)
-			if hop.Received != nil {
-				nodename = hop.Received.IP.SrcIP.String()
-			}
-			nodes = append(nodes, node{node: nodename, probe: &hop})
+		traceroutePath.Source.Via = r.gatewayLookup.LookupWithIPs(src, dst, r.nsIno)
+	}
 
-			if hop.IsLast {
-				break
-			}
-		}
-		// add edges
-		if len(nodes) <= 1 {
-			// no edges to add if there is only one node
-			continue
+	// the flows share most of their hops, reverse DNS lookups are done once per IP
+	hostnames := make(map[string]string)
+	for _, path := range res.Paths {
+		flow := payload.NetworkPathFlow{
+			FlowID:     int(path.FlowID),
+			SourcePort: path.SrcPort,
 		}
-
-		// start at node 1. Each node back-references the previous one
-		for idx := 1; idx < len(nodes); idx++ {
-			if idx >= len(nodes) {
-				// we are at the second-to-last node
-				break
-			}
-			prev := nodes[idx-1]
-			cur := nodes[idx]
-
-			edgeLabel := ""
-			if idx == 1 {
-				edgeLabel += fmt.Sprintf(
-					"srcport %d\ndstport %d",
-					cur.probe.Sent.UDP.SrcPort,
-					cur.probe.Sent.UDP.DstPort,
-				)
-			}
-			if prev.probe.NATID != cur.probe.NATID {
-				edgeLabel += "\nNAT detected"
+		for i, hop := range path.Hops {
+			ttl := i + int(DefaultMinTTL)
+			isReachable := false
+			hopname := fmt.Sprintf("unknown_hop_%d", ttl)
+			hostname := hopname
+
+			if !hop.IP.Equal(net.IP{}) {
+				isReachable = true
+				hopname = hop.IP.String()
+				if _, ok := hostnames[hopname]; !ok {
+					hostnames[hopname] = getHostname(hopname)
+				}
+				hostname = hostnames[hopname]
 			}
-			edgeLabel += fmt.Sprintf("\n%d.%d ms", int(cur.probe.RttUsec/1000), int(cur.probe.RttUsec%1000))
 
-			isReachable := cur.probe.Received != nil
-			ip := cur.node
-			durationMs := float64(cur.probe.RttUsec) / 1000
-
-			hop := payload.NetworkPathHop{
-				TTL:       idx,
-				IPAddress: ip,
-				Hostname:  getHostname(cur.node),
-				RTT:       durationMs,
+			flow.Hops = append(flow.Hops, payload.NetworkPathHop{
+				TTL:       ttl,
+				IPAddress: hopname,
+				Hostname:  hostname,
+				RTT:       float64(hop.RTT.Microseconds()) / float64(1000),
 				Reachable: isReachable,
-			}
-			traceroutePath.Hops = append(traceroutePath.Hops, hop)
+			})
 		}
+		traceroutePath.Paths = append(traceroutePath.Paths, flow)
+	}
+	if len(traceroutePath.Paths) > 0 {
+		traceroutePath.Hops = traceroutePath.Paths[0].Hops
+	}
+	// a single path is already fully described by Hops
+	if len(traceroutePath.Paths) == 1 {
+		traceroutePath.Paths = nil
 	}
 
-	return traceroutePath, nil
+	return traceroutePath
 }
 
 func getPorts(configDestPort uint16) (uint16, uint16, bool) {
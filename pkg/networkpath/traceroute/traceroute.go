@@ -35,6 +35,9 @@ type (
 		// Protocol is the protocol to use
 		// for traceroute, default is UDP
 		Protocol payload.Protocol
+		// NumPaths is the number of flows probed to discover
+		// load balanced paths, only used by UDP and ICMP
+		NumPaths uint16
 	}
 
 	// Traceroute defines an interface for running
@@ -45,6 +45,10 @@ type Conn interface {
 const (
 	contentTypeProtobuf = "application/protobuf"
 	contentTypeJSON     = "application/json"
+
+	// tracerouteProbeDelay is the delay between the probes of the paths sent
+	// for each TTL, see traceroute.DefaultDelay
+	tracerouteProbeDelay = 50 * time.Millisecond
 )
 
 var (
@@ -225,14 +229,26 @@ func (r *RemoteSysProbeUtil) GetPing(clientID string, host string, count int, in
 	return body, nil
 }
 
+// tracerouteHTTPTimeout returns the timeout of a traceroute request. For each
+// TTL, the probes of the paths are sent one delay apart before waiting for the
+// responses, and extra time is allowed for the system probe communication
+// overhead.
+func tracerouteHTTPTimeout(maxTTL uint8, timeout time.Duration, numPaths uint16) time.Duration {
+	perTTL := timeout
+	if numPaths > 1 {
+		perTTL += time.Duration(numPaths-1) * tracerouteProbeDelay
+	}
+	return perTTL*time.Duration(maxTTL) + 10*time.Second
+}
+
 // GetTraceroute returns the results of a traceroute to a host
-func (r *RemoteSysProbeUtil) GetTraceroute(clientID string, host string, port uint16, protocol nppayload.Protocol, maxTTL uint8, timeout time.Duration) ([]byte, error) {
-	httpTimeout := timeout*time.Duration(maxTTL) + 10*time.Second // allow extra time for the system probe communication overhead, calculate full timeout for TCP traceroute
+func (r *RemoteSysProbeUtil) GetTraceroute(clientID string, host string, port uint16, protocol nppayload.Protocol, maxTTL uint8, timeout time.Duration, numPaths uint16) ([]byte, error) {
+	httpTimeout := tracerouteHTTPTimeout(maxTTL, timeout, numPaths)
 	log.Tracef("Network Path traceroute HTTP request timeout: %s", httpTimeout)
 	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/%s?client_id=%s&port=%d&max_ttl=%d&timeout=%d&protocol=%s", tracerouteURL, host, clientID, port, maxTTL, timeout, protocol), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/%s?client_id=%s&port=%d&max_ttl=%d&timeout=%d&protocol=%s&num_paths=%d", tracerouteURL, host, clientID, port, maxTTL, timeout, protocol, numPaths), nil)
 	if err != nil {
 		return nil, err
 	}
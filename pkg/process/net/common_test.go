@@ -0,0 +1,24 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux || windows
+
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracerouteHTTPTimeout(t *testing.T) {
+	// a single path only waits for the responses of each TTL
+	assert.Equal(t, 40*time.Second, tracerouteHTTPTimeout(30, time.Second, 0))
+	assert.Equal(t, 40*time.Second, tracerouteHTTPTimeout(30, time.Second, 1))
+
+	// the probes of the other paths are sent one delay apart for each TTL
+	assert.Equal(t, 30*(time.Second+31*tracerouteProbeDelay)+10*time.Second, tracerouteHTTPTimeout(30, time.Second, 32))
+}
@@ -122,6 +122,12 @@ func (c *cgroupV2) GetMemoryStats(stats *MemoryStats) error {
 		}
 
 		switch key {
+		case "low":
+			stats.LowEvents = &intVal
+		case "high":
+			stats.HighEvents = &intVal
+		case "max":
+			stats.MaxEvents = &intVal
 		case "oom":
 			stats.OOMEvents = &intVal
 		case "oom_kill":
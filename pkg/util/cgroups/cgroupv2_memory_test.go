@@ -132,6 +132,9 @@ func TestCgroupV2MemoryStats(t *testing.T) {
 		KernelMemory:  pointer.Ptr(uint64(49152)),
 		OOMEvents:     pointer.Ptr(uint64(3)),
 		OOMKiilEvents: pointer.Ptr(uint64(0)),
+		LowEvents:     pointer.Ptr(uint64(0)),
+		HighEvents:    pointer.Ptr(uint64(1)),
+		MaxEvents:     pointer.Ptr(uint64(2)),
 		Peak:          pointer.Ptr(uint64(7000000)),
 		PSISome: PSIStats{
 			Avg10:  pointer.Ptr(0.0),
@@ -132,6 +132,12 @@ func parse2ColumnStats(fr fileReader, path string, keyColumn, valueColumn int, v
 	return err
 }
 
+// ParsePSIFile reads a pressure file outside of the cgroup hierarchy (typically `/proc/pressure/*`)
+// and fills the given PSIStats. fullPsi may be nil if the `full` line is not needed.
+func ParsePSIFile(path string, somePsi, fullPsi *PSIStats) error {
+	return parsePSI(defaultFileReader, path, somePsi, fullPsi)
+}
+
 // format is "some avg10=0.00 avg60=0.00 avg300=0.00 total=0"
 func parsePSI(fr fileReader, path string, somePsi, fullPsi *PSIStats) error {
 	return parseColumnStats(fr, path, func(fields []string) error {
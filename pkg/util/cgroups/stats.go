@@ -48,6 +48,9 @@ type MemoryStats struct {
 	// This field is mapped to `memory.failcnt` for cgroupv1 and to "oom" in `memory.event`, it does not mean an OOMKill event happened.
 	OOMEvents     *uint64 // Number (no unit).
 	OOMKiilEvents *uint64 // cgroupv2 only
+	LowEvents     *uint64 // cgroupv2 only, number of times usage went below the `low` boundary under reclaim
+	HighEvents    *uint64 // cgroupv2 only, number of times usage exceeded the `high` boundary and got throttled
+	MaxEvents     *uint64 // cgroupv2 only, number of times usage was about to exceed the `max` boundary
 
 	Limit             *uint64
 	MinThreshold      *uint64 // cgroupv2 only
@@ -81,6 +84,7 @@ type CPUStats struct {
 	SchedulerQuota  *uint64
 
 	PSISome PSIStats
+	PSIFull PSIStats // cgroupv2 only, requires kernel 5.13+
 }
 
 // PIDStats store stats about running threads and processes